@@ -8,7 +8,7 @@ import (
 
 // NewBuildDistCmd creates a new build-dist command.
 func NewBuildDistCmd(m Manager) *cobra.Command {
-	var all bool
+	var all, skipStages bool
 
 	cmd := &cobra.Command{
 		Use:   "build-dist [environment]",
@@ -24,13 +24,24 @@ mutations are forbidden.
 If the --all (-a) flag is used, all schemas in the registry are rendered and written 
 to the dist directory, skipping the mutation check.
 
+Alongside the schemas, a manifest.json is written to [repo root]/dist/[env], describing
+each built schema (key, ID, visibility, SHA-256 digest, source path, draft,
+dependencies and family status), together with an index.json for each family of a built schema in
+[repo root]/dist/[env]/index/[public|private]/[domain(s)]/[family], listing the
+family's available versions.
+
+If a promotionOrder is configured, schemas which have not yet been deployed to the stage
+before the environment are refused, as deploying them would skip that stage. Use
+jsm promote to build the schemas deployed to the previous stage. The --skip-stages flag
+overrides this check.
+
 WARNING: Using the --all (-a) flag is NOT recommended in a deployment pipeline, as it 
 bypasses safety checks and may deploy unintended changes. It is primarily intended 
 for local troubleshooting or manual overrides.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			env := args[0]
-			if err := m.BuildDist(cmd.Context(), config.Env(env), all); err != nil {
+			if err := m.BuildDist(cmd.Context(), config.Env(env), all, skipStages); err != nil {
 				return err
 			}
 			return nil
@@ -38,6 +49,8 @@ for local troubleshooting or manual overrides.`,
 	}
 
 	cmd.Flags().BoolVarP(&all, "all", "a", false, "Render and build all schemas, skipping mutation checks")
+	cmd.Flags().BoolVar(&skipStages, "skip-stages", false,
+		"Build schemas which have not been deployed to the previous stage of the promotion order")
 
 	return cmd
 }
@@ -17,7 +17,11 @@ func NewCheckChangesCmd(m Manager) *cobra.Command {
 Check for schema changes against the latest deployment anchor for the given environment.
 Use this command in a CI/CD pipeline to prevent deploying a schema change to an environment which does not permit
 schema mutation. Your production environment should never permit schema mutation, but it is recommended to allow 
-mutations in dev environments to allow teams to iterate on a new schema together before the contract is locked down.`,
+mutations in dev environments to allow teams to iterate on a new schema together before the contract is locked down.
+
+By default, any edit to the source of a deployed schema is a mutation. If the environment sets
+mutationCheck: rendered, only edits which change the canonical (RFC 8785) form of the rendered schema are mutations.
+If it sets mutationPolicy: annotations-allowed, edits confined to annotations such as description are permitted.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
@@ -1,6 +1,13 @@
 package app
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"github.com/bitshepherds/json-schema-manager/internal/schema"
@@ -8,18 +15,39 @@ import (
 
 // NewCreateSchemaCmd returns a new cobra command for creating a schema.
 func NewCreateSchemaCmd(mgr Manager) *cobra.Command {
+	var scaffold string
+	var interactive bool
+
 	cmd := &cobra.Command{
 		Use:   "create-schema [domain/family]",
 		Short: "Create a new JSON schema",
-		Long:  `Create a completely new JSON schema family in the registry. The 1.0.0 version of the schema will be created.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `
+Create a completely new JSON schema family in the registry. The 1.0.0 version of the schema will be created.
+
+With --template, the schema is generated from a scaffold in the scaffolds directory of the registry, so
+--template event uses scaffolds/event.schema.json.tmpl.
+
+With --interactive, you are prompted for the title, description, x-public flag and initial properties of
+the schema, which are used to generate it, along with a starter pass test document.`,
+		Args: cobra.ExactArgs(1),
 		Example: `
 jsm create-schema "domain-a/family-a"
 jsm create-schema "domain-a/subdomain-b/family-c"
+jsm create-schema --template event "domain-a/family-a"
+jsm create-schema --interactive "domain-a/family-a"
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			domainAndFamily := args[0]
-			key, err := mgr.CreateSchema(domainAndFamily)
+			opts := schema.ScaffoldOptions{Scaffold: scaffold}
+			if interactive {
+				details, err := promptSchemaDetails(cmd.InOrStdin(), cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+				opts.Details = details
+			}
+
+			key, err := mgr.CreateSchema(domainAndFamily, opts)
 			if err != nil {
 				return err
 			}
@@ -29,6 +57,10 @@ jsm create-schema "domain-a/subdomain-b/family-c"
 			if err == nil {
 				cmd.Println("The schema and its test documents can be found here:")
 				cmd.Printf("  %s\n\n", s.Path(schema.HomeDir))
+				if opts.Details != nil {
+					cmd.Printf("A starter pass document has been written to %s.\n",
+						filepath.Join("pass", schema.StarterPassDocument))
+				}
 				cmd.Println("Add JSON documents to the `pass` directory that you expect to PASS validation.")
 				cmd.Println("Add JSON documents to the `fail` directory that you expect to FAIL validation.")
 				cmd.Printf("Then run `jsm validate %s` to test the schema with these documents.\n", key)
@@ -38,5 +70,93 @@ jsm create-schema "domain-a/subdomain-b/family-c"
 		},
 	}
 
+	cmd.Flags().StringVarP(&scaffold, "template", "t", "",
+		"Generate the schema from the scaffold with this name in the scaffolds directory of the registry")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false,
+		"Prompt for the title, description, x-public flag and initial properties of the schema")
 	return cmd
 }
+
+// prompter asks questions on out, and reads the answers from in.
+type prompter struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask writes the question and returns the trimmed answer, or def if the answer is blank.
+func (p *prompter) ask(question, def string) (string, error) {
+	if _, err := fmt.Fprint(p.out, question); err != nil {
+		return "", err
+	}
+	if !p.in.Scan() {
+		if err := p.in.Err(); err != nil {
+			return "", err
+		}
+		return "", io.ErrUnexpectedEOF
+	}
+	if answer := strings.TrimSpace(p.in.Text()); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// confirm asks a yes or no question, to which the answer is no unless it starts with y.
+func (p *prompter) confirm(question string) (bool, error) {
+	answer, err := p.ask(question+" [y/N]: ", "n")
+	if err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+// promptSchemaDetails prompts for the details of a new schema. Properties are prompted for until a blank
+// property name is given, and a property name which is already used is asked for again.
+func promptSchemaDetails(in io.Reader, out io.Writer) (*schema.SchemaDetails, error) {
+	p := &prompter{in: bufio.NewScanner(in), out: out}
+	d := &schema.SchemaDetails{}
+
+	var err error
+	if d.Title, err = p.ask("Title: ", ""); err != nil {
+		return nil, err
+	}
+	if d.Description, err = p.ask("Description: ", ""); err != nil {
+		return nil, err
+	}
+	if d.Public, err = p.confirm("Publish the schema publicly (x-public)?"); err != nil {
+		return nil, err
+	}
+
+	types := strings.Join(schema.PropertyTypes, ", ")
+	for {
+		var prop schema.PropertyDetails
+		if prop.Name, err = p.ask("Property name (leave blank to finish): ", ""); err != nil {
+			return nil, err
+		}
+		if prop.Name == "" {
+			return d, nil
+		}
+		if slices.ContainsFunc(d.Properties, func(q schema.PropertyDetails) bool { return q.Name == prop.Name }) {
+			if _, err = fmt.Fprintf(p.out, "%s is already a property\n", prop.Name); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for {
+			if prop.Type, err = p.ask("Type of "+prop.Name+" ("+types+") [string]: ", "string"); err != nil {
+				return nil, err
+			}
+			if slices.Contains(schema.PropertyTypes, prop.Type) {
+				break
+			}
+			if _, err = fmt.Fprintf(p.out, "%s is not one of %s\n", prop.Type, types); err != nil {
+				return nil, err
+			}
+		}
+
+		if prop.Required, err = p.confirm("Is " + prop.Name + " required?"); err != nil {
+			return nil, err
+		}
+		d.Properties = append(d.Properties, prop)
+	}
+}
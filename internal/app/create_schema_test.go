@@ -1,10 +1,12 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -40,7 +42,7 @@ func TestCreateSchemaCmd(t *testing.T) {
 		require.NoError(t, os.MkdirAll(s.Path(schema.HomeDir), 0o755))
 		require.NoError(t, os.WriteFile(s.Path(schema.FilePath), []byte("{}"), 0o600))
 
-		mgr.On("CreateSchema", domainAndFamily).Return(key, nil).Once()
+		mgr.On("CreateSchema", domainAndFamily, schema.ScaffoldOptions{}).Return(key, nil).Once()
 
 		cmd.SetArgs([]string{domainAndFamily})
 		err := cmd.ExecuteContext(context.Background())
@@ -68,7 +70,8 @@ func TestCreateSchemaCmd(t *testing.T) {
 		t.Parallel()
 		mgr, cmd := setup(t)
 		domainAndFamily := "test-domain/test-family"
-		mgr.On("CreateSchema", domainAndFamily).Return(schema.Key(""), errors.New("manager error")).Once()
+		mgr.On("CreateSchema", domainAndFamily, schema.ScaffoldOptions{}).
+			Return(schema.Key(""), errors.New("manager error")).Once()
 
 		cmd.SetArgs([]string{domainAndFamily})
 		err := cmd.ExecuteContext(context.Background())
@@ -76,4 +79,115 @@ func TestCreateSchemaCmd(t *testing.T) {
 		assert.Equal(t, "manager error", err.Error())
 		mgr.AssertExpectations(t)
 	})
+
+	t.Run("template", func(t *testing.T) {
+		t.Parallel()
+		mgr, cmd := setup(t)
+		domainAndFamily := "test-domain/test-family"
+		mgr.On("CreateSchema", domainAndFamily, schema.ScaffoldOptions{Scaffold: "event"}).
+			Return(schema.Key("test-domain_test-family_1_0_0"), nil).Once()
+
+		cmd.SetArgs([]string{"--template", "event", domainAndFamily})
+		require.NoError(t, cmd.ExecuteContext(context.Background()))
+		mgr.AssertExpectations(t)
+	})
+
+	t.Run("interactive", func(t *testing.T) {
+		t.Parallel()
+		mgr, cmd := setup(t)
+		domainAndFamily := "test-domain/test-family"
+		key := schema.Key("test-domain_test-family_1_0_0")
+		s := schema.New(key, mgr.Registry())
+		require.NoError(t, os.MkdirAll(s.Path(schema.HomeDir), 0o755))
+		require.NoError(t, os.WriteFile(s.Path(schema.FilePath), []byte("{}"), 0o600))
+
+		mgr.On("CreateSchema", domainAndFamily, schema.ScaffoldOptions{
+			Scaffold: "entity",
+			Details: &schema.SchemaDetails{
+				Title:      "Customer",
+				Public:     true,
+				Properties: []schema.PropertyDetails{{Name: "id", Type: "string", Required: true}},
+			},
+		}).Return(key, nil).Once()
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetIn(strings.NewReader("Customer\n\ny\nid\n\ny\n\n"))
+		cmd.SetArgs([]string{"-i", "-t", "entity", domainAndFamily})
+		require.NoError(t, cmd.ExecuteContext(context.Background()))
+		mgr.AssertExpectations(t)
+		assert.Contains(t, out.String(), "A starter pass document has been written")
+	})
+
+	t.Run("interactive input ends early", func(t *testing.T) {
+		t.Parallel()
+		_, cmd := setup(t)
+		cmd.SetIn(strings.NewReader("Customer\n"))
+		cmd.SetArgs([]string{"--interactive", "test-domain/test-family"})
+		err := cmd.ExecuteContext(context.Background())
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestPromptSchemaDetails(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all details", func(t *testing.T) {
+		t.Parallel()
+		in := strings.NewReader(strings.Join([]string{
+			" Order placed ", "An order was placed", "YES",
+			"orderId", "", "y",
+			"total", "decimal", "number", "n",
+			"lines", "array", "",
+			"",
+		}, "\n") + "\n")
+		var out bytes.Buffer
+
+		d, err := promptSchemaDetails(in, &out)
+		require.NoError(t, err)
+		assert.Equal(t, &schema.SchemaDetails{
+			Title:       "Order placed",
+			Description: "An order was placed",
+			Public:      true,
+			Properties: []schema.PropertyDetails{
+				{Name: "orderId", Type: "string", Required: true},
+				{Name: "total", Type: "number"},
+				{Name: "lines", Type: "array"},
+			},
+		}, d)
+		assert.Contains(t, out.String(), "Title: ")
+		assert.Contains(t, out.String(), "decimal is not one of string, number")
+	})
+
+	t.Run("duplicate property name", func(t *testing.T) {
+		t.Parallel()
+		in := strings.NewReader(strings.Join([]string{
+			"", "", "n",
+			"orderId", "", "y",
+			"orderId", "total", "number", "n",
+			"",
+		}, "\n") + "\n")
+		var out bytes.Buffer
+
+		d, err := promptSchemaDetails(in, &out)
+		require.NoError(t, err)
+		assert.Equal(t, []schema.PropertyDetails{
+			{Name: "orderId", Type: "string", Required: true},
+			{Name: "total", Type: "number"},
+		}, d.Properties)
+		assert.Contains(t, out.String(), "orderId is already a property")
+	})
+
+	t.Run("no properties", func(t *testing.T) {
+		t.Parallel()
+		d, err := promptSchemaDetails(strings.NewReader("\n\n\n\n"), io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, &schema.SchemaDetails{}, d)
+	})
+
+	t.Run("input ends early", func(t *testing.T) {
+		t.Parallel()
+		_, err := promptSchemaDetails(strings.NewReader("Title\nDescription\nn\nname\n"), io.Discard)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
 }
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCmd returns a new cobra command for checking the structure of the registry.
+func NewDoctorCmd(mgr Manager) *cobra.Command {
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the whole registry for structural problems",
+		Long: `
+Scan the whole registry and report every structural problem at once, with a suggestion of how
+to fix each. The problems reported are:
+
+  - schema files whose names are not valid keys
+  - schema files which are not in the directory their key implies
+  - schemas without a pass or fail directory
+  - JSON files in a schema family which are neither schemas nor test documents
+  - version directories which are not numbers, and so are ignored
+  - version directories which hold no schema file
+
+With --fix, the problems which are safe to fix are fixed: missing pass and fail directories are
+created, and version directories which hold no files at all are removed.
+
+The command fails if any problem remains unfixed, so it can be run in CI.`,
+		Args: cobra.NoArgs,
+		Example: `
+  jsm doctor
+  jsm doctor --fix`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return mgr.Doctor(cmd.Context(), fix)
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Fix the problems which are safe to fix")
+	return cmd
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func TestNewDoctorCmd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("report", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Doctor", mock.Anything, false).Return(nil)
+
+		cmd := NewDoctorCmd(m)
+		cmd.SetArgs([]string{})
+		require.NoError(t, cmd.Execute())
+		m.AssertExpectations(t)
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Doctor", mock.Anything, true).Return(errors.New("problems remain"))
+
+		cmd := NewDoctorCmd(m)
+		cmd.SetArgs([]string{"--fix"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.ErrorContains(t, cmd.Execute(), "problems remain")
+	})
+}
+
+func TestCLIManager_Doctor(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// newRegistry returns a registry holding a schema without a fail directory, and a stray JSON file.
+	newRegistry := func(t *testing.T) *schema.Registry {
+		t.Helper()
+		registry := setupTestRegistry(t)
+		homeDir := filepath.Join(registry.RootDirectory(), "domain", "family", "1", "0", "0")
+		require.NoError(t, os.MkdirAll(filepath.Join(homeDir, "pass"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(homeDir, "domain_family_1_0_0.schema.json"), []byte("{}"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(homeDir, "doc.json"), []byte("{}"), 0o600))
+		return registry
+	}
+
+	t.Run("problems", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, newRegistry(t), nil, nil, nil, &buf)
+
+		err := mgr.Doctor(context.Background(), false)
+		var target *schema.RegistryProblemsError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, 2, target.Count)
+		assert.Contains(t, buf.String(), "fail directory missing")
+		assert.Contains(t, buf.String(), "Fix: Create the fail directory")
+		assert.Contains(t, buf.String(), "doc.json is not a schema file")
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		t.Parallel()
+		registry := newRegistry(t)
+		require.NoError(t, os.Remove(filepath.Join(registry.RootDirectory(), "domain", "family", "1", "0", "0",
+			"doc.json")))
+
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, nil, nil, &buf)
+		require.NoError(t, mgr.Doctor(context.Background(), true))
+		assert.Contains(t, buf.String(), "🔧 Fixed: fail directory missing")
+		assert.Contains(t, buf.String(), "No structural problems remain")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mgr := NewCLIManager(logger, newRegistry(t), nil, nil, nil, io.Discard)
+		require.ErrorIs(t, mgr.Doctor(ctx, false), context.Canceled)
+	})
+}
@@ -2,6 +2,9 @@ package app
 
 import (
 	"fmt"
+	"slices"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
 )
 
 // formatValue implements pflag.Value to provide a custom type name in help text
@@ -44,3 +47,35 @@ func (p *pathValue) Set(v string) error {
 func (p *pathValue) Type() string {
 	return "<path>"
 }
+
+// gitBackendValue implements pflag.Value to provide a custom type name in help text
+// and validation for git backends.
+type gitBackendValue string
+
+func (g *gitBackendValue) String() string {
+	return string(*g)
+}
+
+func (g *gitBackendValue) Set(v string) error {
+	if !slices.Contains(config.GitBackends, config.GitBackend(v)) {
+		return fmt.Errorf("must be '%s' or '%s'", config.GitBackendCLI, config.GitBackendGo)
+	}
+	*g = gitBackendValue(v)
+	return nil
+}
+
+func (g *gitBackendValue) Type() string {
+	return "<backend>"
+}
+
+// backend returns the git backend selected by the flag, or, if it is not set, by the registry configuration.
+func (g *gitBackendValue) backend(cfg *config.Config) config.GitBackend {
+	switch {
+	case *g != "":
+		return config.GitBackend(*g)
+	case cfg != nil && cfg.Git.Backend != "":
+		return cfg.Git.Backend
+	default:
+		return config.GitBackendCLI
+	}
+}
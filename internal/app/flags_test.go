@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
 )
 
 func TestFormatValue(t *testing.T) {
@@ -47,3 +49,31 @@ func TestPathValue(t *testing.T) {
 		assert.Equal(t, "/some/path", p.String())
 	})
 }
+
+func TestGitBackendValue(t *testing.T) {
+	t.Parallel()
+
+	goCfg := &config.Config{Git: config.GitConfig{Backend: config.GitBackendGo}}
+
+	t.Run("unset", func(t *testing.T) {
+		t.Parallel()
+		var g gitBackendValue
+		assert.Equal(t, "<backend>", g.Type())
+		assert.Equal(t, config.GitBackendCLI, g.backend(nil))
+		assert.Equal(t, config.GitBackendGo, g.backend(goCfg))
+	})
+
+	t.Run("set overrides config", func(t *testing.T) {
+		t.Parallel()
+		var g gitBackendValue
+		require.NoError(t, g.Set("cli"))
+		assert.Equal(t, "cli", g.String())
+		assert.Equal(t, config.GitBackendCLI, g.backend(goCfg))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Parallel()
+		var g gitBackendValue
+		require.EqualError(t, g.Set("svn"), "must be 'cli' or 'go'")
+	})
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// NewHistoryCmd returns a new cobra command for reporting the deployment history of environments.
+func NewHistoryCmd(mgr Manager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history [environment] [target]",
+		Short: "Show which schemas were deployed to each environment, and when",
+		Long: `
+Report the deployments recorded by the JSM deployment tags of an environment, oldest first.
+The schemas shipped by each deployment are those added, modified or removed since the
+previous deployment to the environment.
+
+If no environment is given, or the only argument is not an environment, the deployments
+to every environment are reported.
+
+If a target is given, only the deployments which changed the targeted schemas are reported,
+as a timeline for each schema. Use this to find out when a schema reached an environment.`,
+		Args: cobra.MaximumNArgs(2),
+		Example: `
+  jsm history
+  jsm history prod
+  jsm history prod "domain-b_person_1_1_0"
+  jsm history "domain-b/person" -o json`,
+	}
+
+	outputVal := formatValue(formatText)
+	cmd.Flags().VarP(&outputVal, "output", "o", "Output format (text, json)")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		var env config.Env
+		if len(args) > 0 {
+			cfg, err := mgr.Registry().Config()
+			if err != nil {
+				return err
+			}
+			if _, envErr := cfg.EnvConfig(config.Env(args[0])); envErr == nil || len(args) == 2 {
+				env, args = config.Env(args[0]), args[1:]
+			}
+		}
+
+		var target *schema.ResolvedTarget
+		if len(args) > 0 {
+			t, err := schema.NewTargetResolver(mgr.Registry(), args[0]).Resolve()
+			if err != nil {
+				return err
+			}
+			target = &t
+		}
+
+		return mgr.History(cmd.Context(), env, target, string(outputVal))
+	}
+
+	return cmd
+}
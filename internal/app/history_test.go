@@ -0,0 +1,181 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func TestNewHistoryCmd(t *testing.T) {
+	t.Parallel()
+
+	isKey := func(k schema.Key) any {
+		return mock.MatchedBy(func(rt *schema.ResolvedTarget) bool { return rt != nil && rt.Key != nil && *rt.Key == k })
+	}
+	noTarget := mock.MatchedBy(func(rt *schema.ResolvedTarget) bool { return rt == nil })
+
+	tests := []struct {
+		name      string
+		args      []string
+		setupMock func(m *MockManager)
+		wantErr   bool
+	}{
+		{
+			name: "No arguments reports every environment",
+			args: []string{},
+			setupMock: func(m *MockManager) {
+				m.On("History", mock.Anything, config.Env(""), noTarget, "text").Return(nil)
+			},
+		},
+		{
+			name: "Environment",
+			args: []string{"prod", "-o", "json"},
+			setupMock: func(m *MockManager) {
+				m.On("History", mock.Anything, config.Env("prod"), noTarget, "json").Return(nil)
+			},
+		},
+		{
+			name: "Environment and target",
+			args: []string{"prod", "domain_family_1_0_0"},
+			setupMock: func(m *MockManager) {
+				m.On("History", mock.Anything, config.Env("prod"), isKey("domain_family_1_0_0"), "text").Return(nil)
+			},
+		},
+		{
+			name: "Target in every environment",
+			args: []string{"domain_family_1_0_0"},
+			setupMock: func(m *MockManager) {
+				m.On("History", mock.Anything, config.Env(""), isKey("domain_family_1_0_0"), "text").Return(nil)
+			},
+		},
+		{
+			name:    "Invalid target",
+			args:    []string{"prod", "!!"},
+			wantErr: true,
+		},
+		{
+			name:    "Too many arguments",
+			args:    []string{"prod", "domain", "extra"},
+			wantErr: true,
+		},
+		{
+			name: "Manager error",
+			args: []string{"staging", "domain"},
+			setupMock: func(m *MockManager) {
+				m.On("History", mock.Anything, config.Env("staging"), mock.Anything, "text").
+					Return(fmt.Errorf("boom"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := &MockManager{registry: setupTestRegistry(t)}
+			if tt.setupMock != nil {
+				tt.setupMock(m)
+			}
+
+			cmd := NewHistoryCmd(m)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			err := cmd.Execute()
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCLIManager_History(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	historyGitter := func(registry *schema.Registry) *MockGitter {
+		path := func(k schema.Key) string { return filepath.Join(registry.RootDirectory(), string(k)+".schema.json") }
+		return &MockGitter{
+			DeploymentsFunc: func(_ context.Context, env config.Env) ([]repo.Deployment, error) {
+				return []repo.Deployment{
+					{Env: env, Tag: "jsm-deploy/prod/1", Time: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)},
+					{Env: env, Tag: "jsm-deploy/prod/2", Time: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)},
+				}, nil
+			},
+			ChangesBetweenFunc: func(_ context.Context, from, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+				if from == "" {
+					return []repo.Change{{Path: path("domain_person_1_0_0"), Status: repo.ChangeAdded}}, nil
+				}
+				return []repo.Change{{Path: path("domain_person_1_1_0"), Status: repo.ChangeAdded}}, nil
+			},
+		}
+	}
+
+	t.Run("text output for an environment", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, historyGitter(registry), nil, &buf)
+
+		require.NoError(t, mgr.History(context.Background(), "prod", nil, formatText))
+		assert.Equal(t, `prod
+  2026-01-01 09:00:00 UTC  jsm-deploy/prod/1
+      + domain_person_1_0_0
+  2026-02-01 09:00:00 UTC  jsm-deploy/prod/2
+      + domain_person_1_1_0
+`, buf.String())
+	})
+
+	t.Run("JSON output for a schema", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, historyGitter(registry), nil, &buf)
+
+		k := schema.Key("domain_person_1_1_0")
+		require.NoError(t, mgr.History(context.Background(), "", &schema.ResolvedTarget{Key: &k}, formatJSON))
+		assert.Contains(t, buf.String(), `"target": "domain_person_1_1_0"`)
+		assert.Contains(t, buf.String(), `"tag": "jsm-deploy/prod/2"`)
+		assert.NotContains(t, buf.String(), `"tag": "jsm-deploy/prod/1"`)
+	})
+
+	t.Run("invalid environment", func(t *testing.T) {
+		t.Parallel()
+		mgr := NewCLIManager(logger, setupTestRegistry(t), nil, &MockGitter{}, nil, io.Discard)
+		require.Error(t, mgr.History(context.Background(), "invalid", nil, formatText))
+	})
+
+	t.Run("config error", func(t *testing.T) {
+		t.Parallel()
+		mgr := NewCLIManager(logger, &schema.Registry{}, nil, &MockGitter{}, nil, io.Discard)
+		require.Error(t, mgr.History(context.Background(), "prod", nil, formatText))
+	})
+
+	t.Run("git error", func(t *testing.T) {
+		t.Parallel()
+		gitter := &MockGitter{
+			DeploymentsFunc: func(_ context.Context, _ config.Env) ([]repo.Deployment, error) {
+				return nil, fmt.Errorf("failed to list deployment tags")
+			},
+		}
+		mgr := NewCLIManager(logger, setupTestRegistry(t), nil, gitter, nil, io.Discard)
+		require.ErrorContains(t, mgr.History(context.Background(), "", nil, formatText), "failed to list")
+	})
+}
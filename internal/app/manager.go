@@ -3,13 +3,18 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"net/http"
 	"slices"
 	"strings"
 
 	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/publish"
 	"github.com/bitshepherds/json-schema-manager/internal/repo"
 	"github.com/bitshepherds/json-schema-manager/internal/report"
 	"github.com/bitshepherds/json-schema-manager/internal/schema"
@@ -23,12 +28,21 @@ type Manager interface {
 		useColour bool, continueOnError bool, testScope schema.TestScope, skipCompatible bool,
 		readyChan chan<- struct{}) error
 	Registry() *schema.Registry
-	CreateSchema(domainAndFamilyName string) (schema.Key, error)
+	CreateSchema(domainAndFamilyName string, opts schema.ScaffoldOptions) (schema.Key, error)
 	CreateSchemaVersion(k schema.Key, rt schema.ReleaseType) (schema.Key, error)
 	RenderSchema(ctx context.Context, target schema.ResolvedTarget, env config.Env) ([]byte, error)
 	CheckChanges(ctx context.Context, envName config.Env) error
+	Plan(ctx context.Context, envName config.Env, format string) error
 	TagDeployment(ctx context.Context, envName config.Env) error
-	BuildDist(ctx context.Context, envName config.Env, all bool) error
+	BuildDist(ctx context.Context, envName config.Env, all bool, skipStages bool) error
+	Promote(ctx context.Context, from, to config.Env) error
+	Rollback(ctx context.Context, envName config.Env, tag bool) error
+	Publish(ctx context.Context, envName config.Env) error
+	Owners(ctx context.Context, target schema.ResolvedTarget, format string, codeOwners bool) error
+	History(ctx context.Context, envName config.Env, target *schema.ResolvedTarget, format string) error
+	Vendor(ctx context.Context, url, file string) error
+	Doctor(ctx context.Context, fix bool) error
+	Move(ctx context.Context, from, to string, copyFamily bool) error
 }
 
 // Ensure the interface is satisfied.
@@ -80,8 +94,8 @@ func (l *LazyManager) Registry() *schema.Registry {
 }
 
 // CreateSchema implements the Manager interface.
-func (l *LazyManager) CreateSchema(domainAndFamilyName string) (schema.Key, error) {
-	return l.check().CreateSchema(domainAndFamilyName)
+func (l *LazyManager) CreateSchema(domainAndFamilyName string, opts schema.ScaffoldOptions) (schema.Key, error) {
+	return l.check().CreateSchema(domainAndFamilyName, opts)
 }
 
 // CreateSchemaVersion implements the Manager interface.
@@ -99,14 +113,64 @@ func (l *LazyManager) CheckChanges(ctx context.Context, envName config.Env) erro
 	return l.check().CheckChanges(ctx, envName)
 }
 
+// Plan implements the Manager interface.
+func (l *LazyManager) Plan(ctx context.Context, envName config.Env, format string) error {
+	return l.check().Plan(ctx, envName, format)
+}
+
 // TagDeployment implements the Manager interface.
 func (l *LazyManager) TagDeployment(ctx context.Context, envName config.Env) error {
 	return l.check().TagDeployment(ctx, envName)
 }
 
 // BuildDist implements the Manager interface.
-func (l *LazyManager) BuildDist(ctx context.Context, envName config.Env, all bool) error {
-	return l.check().BuildDist(ctx, envName, all)
+func (l *LazyManager) BuildDist(ctx context.Context, envName config.Env, all bool, skipStages bool) error {
+	return l.check().BuildDist(ctx, envName, all, skipStages)
+}
+
+// Promote implements the Manager interface.
+func (l *LazyManager) Promote(ctx context.Context, from, to config.Env) error {
+	return l.check().Promote(ctx, from, to)
+}
+
+// Rollback implements the Manager interface.
+func (l *LazyManager) Rollback(ctx context.Context, envName config.Env, tag bool) error {
+	return l.check().Rollback(ctx, envName, tag)
+}
+
+// Publish implements the Manager interface.
+func (l *LazyManager) Publish(ctx context.Context, envName config.Env) error {
+	return l.check().Publish(ctx, envName)
+}
+
+// Owners implements the Manager interface.
+func (l *LazyManager) Owners(ctx context.Context, target schema.ResolvedTarget, format string, codeOwners bool) error {
+	return l.check().Owners(ctx, target, format, codeOwners)
+}
+
+// History implements the Manager interface.
+func (l *LazyManager) History(
+	ctx context.Context,
+	envName config.Env,
+	target *schema.ResolvedTarget,
+	format string,
+) error {
+	return l.check().History(ctx, envName, target, format)
+}
+
+// Vendor implements the Manager interface.
+func (l *LazyManager) Vendor(ctx context.Context, url, file string) error {
+	return l.check().Vendor(ctx, url, file)
+}
+
+// Doctor implements the Manager interface.
+func (l *LazyManager) Doctor(ctx context.Context, fix bool) error {
+	return l.check().Doctor(ctx, fix)
+}
+
+// Move implements the Manager interface.
+func (l *LazyManager) Move(ctx context.Context, from, to string, copyFamily bool) error {
+	return l.check().Move(ctx, from, to, copyFamily)
 }
 
 // Ensure the interface is satisfied.
@@ -119,6 +183,7 @@ type CLIManager struct {
 	tester         *schema.Tester
 	gitter         repo.Gitter
 	distBuilder    schema.DistBuilder
+	newPublisher   publish.Factory
 	reporterWriter io.Writer
 }
 
@@ -137,18 +202,24 @@ func NewCLIManager(
 		tester:         t,
 		gitter:         g,
 		distBuilder:    db,
+		newPublisher:   publish.NewFactory(fsh.NewEnvProvider(), http.DefaultClient),
 		reporterWriter: rw,
 	}
 }
 
+// SetPublisherFactory controls how the publishers of environments are created.
+func (m *CLIManager) SetPublisherFactory(f publish.Factory) {
+	m.newPublisher = f
+}
+
 // Registry returns the underlying schema registry.
 func (m *CLIManager) Registry() *schema.Registry {
 	return m.registry
 }
 
-// CreateSchema creates a new schema with the given name.
-func (m *CLIManager) CreateSchema(domainAndFamilyName string) (schema.Key, error) {
-	s, err := m.registry.CreateSchema(domainAndFamilyName)
+// CreateSchema creates a new schema with the given name, from the scaffold and details in the options.
+func (m *CLIManager) CreateSchema(domainAndFamilyName string, opts schema.ScaffoldOptions) (schema.Key, error) {
+	s, err := m.registry.CreateSchemaFromScaffold(domainAndFamilyName, opts)
 	if err != nil {
 		return schema.Key(""), err
 	}
@@ -239,10 +310,7 @@ func (m *CLIManager) handleWatchEvent(ctx context.Context, event schema.WatchEve
 	testScope schema.TestScope, skipCompatible bool,
 ) {
 	// Filter events based on target
-	if target.Key != nil && event.Key != *target.Key {
-		return
-	}
-	if target.Scope != nil && !event.Key.InScope(*target.Scope) {
+	if event.Partial == "" && !inTarget(event.Key, target) {
 		return
 	}
 
@@ -255,10 +323,18 @@ func (m *CLIManager) handleWatchEvent(ctx context.Context, event schema.WatchEve
 	var tr *schema.TestReport
 	var err error
 
-	if event.TestPath != "" {
+	switch {
+	case event.Partial != "":
+		m.logger.Info("Partial changed:", "partial", event.Partial)
+		m.registry.Reset()
+		tr, err = m.testPartialDependents(ctx, tester, event.Partial, target)
+		if err == nil && tr == nil {
+			return
+		}
+	case event.TestPath != "":
 		m.logger.Info("Test changed:", "schema", event.Key, "test", event.TestPath)
 		tr, err = tester.TestSpecificDocument(ctx, event.Key, event.TestPath)
-	} else {
+	default:
 		m.logger.Info("Schema changed:", "schema", event.Key)
 		m.registry.Reset()
 		tr, err = tester.TestSingleSchema(ctx, event.Key)
@@ -282,6 +358,40 @@ func (m *CLIManager) handleWatchEvent(ctx context.Context, event schema.WatchEve
 	}
 }
 
+// inTarget returns true if the schema with the given key is targeted.
+func inTarget(k schema.Key, target schema.ResolvedTarget) bool {
+	if target.Key != nil && k != *target.Key {
+		return false
+	}
+	if target.Scope != nil && !k.InScope(*target.Scope) {
+		return false
+	}
+	return true
+}
+
+// testPartialDependents tests the targeted schemas which include the given partial.
+// If none are targeted, it returns a nil report.
+func (m *CLIManager) testPartialDependents(ctx context.Context, tester *schema.Tester, partial string,
+	target schema.ResolvedTarget,
+) (*schema.TestReport, error) {
+	dependents, err := m.registry.PartialDependents(ctx, partial)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []schema.Key
+	for _, k := range dependents {
+		if inTarget(k, target) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	return tester.TestSchemas(ctx, keys)
+}
+
 // RenderSchema renders a schema for a specific environment.
 func (m *CLIManager) RenderSchema(_ context.Context, target schema.ResolvedTarget, env config.Env) ([]byte, error) {
 	m.logger.Debug("rendering schema", "target", target, "env", env)
@@ -331,44 +441,215 @@ func (m *CLIManager) RenderSchema(_ context.Context, target schema.ResolvedTarge
 func (m *CLIManager) CheckChanges(ctx context.Context, envName config.Env) error {
 	m.logger.Debug("checking changes", "env", envName)
 
-	cfg, err := m.registry.Config()
+	envCfg, anchor, changes, err := m.changesSinceDeployment(ctx, envName)
 	if err != nil {
 		return err
 	}
 
-	envCfg, err := cfg.EnvConfig(envName)
+	if !envCfg.AllowSchemaMutation {
+		muts, mErr := m.mutations(ctx, envCfg, anchor, changes)
+		if mErr != nil {
+			return mErr
+		}
+		if len(muts) > 0 {
+			modifiedPaths := make([]string, 0, len(muts))
+			for _, mut := range muts {
+				modifiedPaths = append(modifiedPaths, mut.path)
+			}
+			return &schema.ChangedDeployedSchemasError{Paths: modifiedPaths}
+		}
+	}
+
+	_, _ = fmt.Fprintln(m.reporterWriter, "All changes are valid")
+	return nil
+}
+
+// Plan reports what deploying the schemas changed since the last deployment to an environment would do:
+// the schemas which would be added, and the deployed schemas which would be changed, and why. Unlike
+// CheckChanges, it does not fail if deployed schemas would be changed in an environment which does not
+// permit schema mutation, but explains why the deployment would be refused.
+func (m *CLIManager) Plan(ctx context.Context, envName config.Env, format string) error {
+	m.logger.Debug("planning deployment", "env", envName, "format", format)
+
+	envCfg, anchor, changes, err := m.changesSinceDeployment(ctx, envName)
 	if err != nil {
 		return err
 	}
 
-	anchor, err := m.gitter.GetLatestAnchor(ctx, envName)
+	muts, err := m.mutations(ctx, envCfg, anchor, changes)
 	if err != nil {
 		return err
 	}
 
+	plan := &schema.DeploymentPlan{Env: envName, Anchor: anchor, MutationsAllowed: envCfg.AllowSchemaMutation}
+	for _, change := range changes {
+		if !change.IsNew() {
+			continue
+		}
+		ps, pErr := m.registry.PlanSchema(ctx, m.gitter, anchor, change.Path, "", envCfg)
+		if pErr != nil {
+			return pErr
+		}
+		plan.Added = append(plan.Added, ps)
+	}
+	for _, mut := range muts {
+		ps, pErr := m.registry.PlanSchema(ctx, m.gitter, anchor, mut.path, mut.reason, envCfg)
+		if pErr != nil {
+			return pErr
+		}
+		plan.Mutated = append(plan.Mutated, ps)
+	}
+
+	var reporter report.PlanReporter = &report.PlanTextReporter{}
+	if format == formatJSON {
+		reporter = &report.PlanJSONReporter{}
+	}
+	return reporter.Write(m.reporterWriter, plan)
+}
+
+// changesSinceDeployment returns the configuration of an environment, the anchor revision of its last
+// deployment, and the schemas which have changed since.
+func (m *CLIManager) changesSinceDeployment(
+	ctx context.Context,
+	envName config.Env,
+) (*config.EnvConfig, repo.Revision, []repo.Change, error) {
+	cfg, err := m.registry.Config()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	envCfg, err := cfg.EnvConfig(envName)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	anchor, err := m.gitter.GetLatestAnchor(ctx, envName)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	changes, err := m.gitter.GetSchemaChanges(ctx, anchor, m.registry.RootDirectory(), schema.SchemaSuffix)
 	if err != nil {
-		return err
+		return nil, "", nil, err
 	}
+	return envCfg, anchor, changes, nil
+}
 
-	if !envCfg.AllowSchemaMutation {
-		var modifiedPaths []string
-		for _, change := range changes {
-			if !change.IsNew {
-				modifiedPaths = append(modifiedPaths, change.Path)
-			}
+// mutation is a deployed schema which has been mutated since the anchor.
+type mutation struct {
+	path   string
+	reason schema.MutationReason
+}
+
+// mutations returns the deployed schemas which have been mutated since the anchor. Deleting or renaming a
+// deployed schema removes it from its ID, so is a mutation of the schema at its old path. A change to a
+// partial is a change to every schema which includes it, and a new schema is a change to every schema
+// which references it via a version range. If the environment compares rendered output, then modified
+// schemas whose canonical rendered output is unchanged under its mutation policy are not mutations.
+func (m *CLIManager) mutations(
+	ctx context.Context,
+	envCfg *config.EnvConfig,
+	anchor repo.Revision,
+	changes []repo.Change,
+) ([]mutation, error) {
+	var muts []mutation
+	newPaths := make(map[string]bool)
+	for _, change := range changes {
+		switch change.Status {
+		case repo.ChangeAdded:
+			newPaths[change.Path] = true
+		case repo.ChangeRenamed:
+			newPaths[change.Path] = true
+			muts = append(muts, mutation{path: change.OldPath, reason: schema.MutationRenamed})
+		case repo.ChangeDeleted:
+			muts = append(muts, mutation{path: change.Path, reason: schema.MutationDeleted})
+		default:
+			muts = append(muts, mutation{path: change.Path, reason: schema.MutationModified})
 		}
+	}
 
-		if len(modifiedPaths) > 0 {
-			return &schema.ChangedDeployedSchemasError{Paths: modifiedPaths}
+	partialDependents, err := m.registry.ChangedPartialDependents(ctx, m.gitter, anchor)
+	if err != nil {
+		return nil, err
+	}
+	muts = m.appendMutations(muts, newPaths, partialDependents, schema.MutationPartialChanged)
+
+	if envCfg.ComparesRenderedOutput() {
+		if muts, err = m.renderedMutations(ctx, envCfg, anchor, muts); err != nil {
+			return nil, err
 		}
 	}
 
-	_, _ = fmt.Fprintln(m.reporterWriter, "All changes are valid")
-	return nil
+	// A version range which resolves to a new version references a different schema, which is always a mutation.
+	var newKeys []schema.Key
+	for p := range newPaths {
+		if k, kErr := m.registry.KeyFromSchemaPath(p); kErr == nil {
+			newKeys = append(newKeys, k)
+		}
+	}
+	rangeDependents, err := m.registry.RangeDependents(ctx, envCfg, newKeys)
+	if err != nil {
+		return nil, err
+	}
+	return m.appendMutations(muts, newPaths, rangeDependents, schema.MutationRangeResolved), nil
+}
+
+// appendMutations appends mutations of the schemas with the given keys to muts, unless they are
+// new or already present.
+func (m *CLIManager) appendMutations(
+	muts []mutation,
+	newPaths map[string]bool,
+	keys []schema.Key,
+	reason schema.MutationReason,
+) []mutation {
+	for _, k := range keys {
+		p := schema.New(k, m.registry).Path(schema.FilePath)
+		if !newPaths[p] && !slices.ContainsFunc(muts, func(mut mutation) bool { return mut.path == p }) {
+			muts = append(muts, mutation{path: p, reason: reason})
+		}
+	}
+	return muts
+}
+
+// renderedMutations returns those of the given mutations whose canonical rendered output for the
+// environment has changed since the anchor. Schemas which have been deleted or renamed are always mutations.
+func (m *CLIManager) renderedMutations(
+	ctx context.Context,
+	envCfg *config.EnvConfig,
+	anchor repo.Revision,
+	muts []mutation,
+) ([]mutation, error) {
+	var mutated []mutation
+	var keys []schema.Key
+	reasons := make(map[schema.Key]schema.MutationReason)
+	for _, mut := range muts {
+		if mut.reason == schema.MutationDeleted || mut.reason == schema.MutationRenamed {
+			mutated = append(mutated, mut)
+			continue
+		}
+		k, err := m.registry.KeyFromSchemaPath(mut.path)
+		if err != nil {
+			mutated = append(mutated, mut)
+			continue
+		}
+		keys = append(keys, k)
+		reasons[k] = mut.reason
+	}
+
+	changed, err := m.registry.RenderedMutations(ctx, m.gitter, anchor, keys, envCfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range changed {
+		mutated = append(mutated, mutation{path: schema.New(k, m.registry).Path(schema.FilePath), reason: reasons[k]})
+	}
+	return mutated, nil
 }
 
-// TagDeployment ensures that a successful deployment is tagged in git.
+// TagDeployment ensures that a successful deployment is tagged in git. The tag records the schemas in the
+// distribution built for the environment, if there is one. The deployment of a promotion distribution is
+// tagged at the revision it was promoted from, as later changes were not deployed, and the deployment of a
+// rollback distribution is recorded with a rollback tag instead.
 func (m *CLIManager) TagDeployment(ctx context.Context, envName config.Env) error {
 	m.logger.Debug("tagging deployment", "env", envName)
 
@@ -381,7 +662,25 @@ func (m *CLIManager) TagDeployment(ctx context.Context, envName config.Env) erro
 		return envErr
 	}
 
-	tagName, err := m.gitter.TagDeploymentSuccess(ctx, envName)
+	dist, err := publish.LoadDistribution(envName, m.distBuilder.DistDir(envName))
+	var missing *publish.MissingDistributionError
+	switch {
+	case errors.As(err, &missing):
+		m.logger.Debug("no distribution to record in the deployment tag", "env", envName)
+		return m.tagDeployment(ctx, envName, &schema.Manifest{Env: envName})
+	case err != nil:
+		return err
+	case dist.Manifest.RolledBack != "":
+		return m.tagRollback(ctx, envName, dist.Manifest.RolledBack, dist.Manifest.Restored)
+	default:
+		return m.tagDeployment(ctx, envName, dist.Manifest)
+	}
+}
+
+// tagDeployment tags a successful deployment of the schemas in the manifest in git. The tag points to the
+// revision a promotion was built from, or to HEAD otherwise.
+func (m *CLIManager) tagDeployment(ctx context.Context, envName config.Env, manifest *schema.Manifest) error {
+	tagName, err := m.gitter.TagDeploymentSuccess(ctx, envName, manifest.Promoted, deployedSchemas(manifest))
 	if err != nil {
 		if tagName != "" {
 			m.logger.Warn("tag was created but could not be pushed", "tag", tagName, "error", err)
@@ -395,9 +694,37 @@ func (m *CLIManager) TagDeployment(ctx context.Context, envName config.Env) erro
 	return nil
 }
 
-// BuildDist builds a distribution directory for the given environment.
-func (m *CLIManager) BuildDist(ctx context.Context, envName config.Env, all bool) error {
-	m.logger.Debug("building distribution", "env", envName, "all", all)
+// tagRollback records the rollback of a deployment with a rollback tag, which moves the anchor of the
+// environment back to the deployment restored.
+func (m *CLIManager) tagRollback(ctx context.Context, envName config.Env, rolledBack, restored repo.Revision) error {
+	tagName, err := m.gitter.TagRollback(ctx, envName, rolledBack, restored)
+	if err != nil {
+		if tagName != "" {
+			m.logger.Warn("tag was created but could not be pushed", "tag", tagName, "error", err)
+			_, _ = fmt.Fprintf(m.reporterWriter, "🏷️  Tag created locally but failed to push: %s\n", tagName)
+			return nil
+		}
+		return err
+	}
+
+	_, _ = fmt.Fprintf(m.reporterWriter, "🏷️  Successfully tagged and pushed rollback: %s\n", tagName)
+	return nil
+}
+
+// deployedSchemas returns the schemas of a distribution, as recorded by a deployment tag.
+func deployedSchemas(manifest *schema.Manifest) []repo.DeployedSchema {
+	schemas := make([]repo.DeployedSchema, 0, len(manifest.Schemas))
+	for _, e := range manifest.Schemas {
+		schemas = append(schemas, repo.DeployedSchema{Key: string(e.Key), SHA256: e.SHA256})
+	}
+	return schemas
+}
+
+// BuildDist builds a distribution directory for the given environment. If a promotion order is configured,
+// schemas which have not been deployed to the stage before the environment are refused, unless skipStages
+// is true.
+func (m *CLIManager) BuildDist(ctx context.Context, envName config.Env, all bool, skipStages bool) error {
+	m.logger.Debug("building distribution", "env", envName, "all", all, "skipStages", skipStages)
 
 	var count int
 	var err error
@@ -414,6 +741,12 @@ func (m *CLIManager) BuildDist(ctx context.Context, envName config.Env, all bool
 			return anchorErr
 		}
 
+		if !skipStages {
+			if sErr := m.checkStages(ctx, envName, anchor); sErr != nil {
+				return sErr
+			}
+		}
+
 		count, err = m.distBuilder.BuildChanged(ctx, envName, anchor)
 	}
 
@@ -429,3 +762,300 @@ func (m *CLIManager) BuildDist(ctx context.Context, envName config.Env, all bool
 	_, _ = fmt.Fprintf(m.reporterWriter, "📂 Successfully built %d schemas to distribution directory\n", count)
 	return nil
 }
+
+// checkStages returns an error if the schemas changed since the given anchor of an environment include any
+// which have not been deployed to the stage before it in the promotion order.
+func (m *CLIManager) checkStages(ctx context.Context, envName config.Env, anchor repo.Revision) error {
+	cfg, err := m.registry.Config()
+	if err != nil {
+		return err
+	}
+	stage, ok := cfg.PreviousStage(envName)
+	if !ok {
+		return nil
+	}
+
+	stageAnchor, err := m.gitter.GetLatestAnchor(ctx, stage)
+	if err != nil {
+		return err
+	}
+	keys, err := m.registry.Unpromoted(ctx, m.gitter, anchor, stageAnchor)
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return &schema.UnpromotedSchemasError{Env: envName, Stage: stage, Keys: keys}
+	}
+	return nil
+}
+
+// Promote builds a distribution for one environment of the schemas which have been deployed to another, but
+// not yet to it. The schemas are built as they were at the latest deployment to the environment they are
+// promoted from, once they have passed their tests there. If a promotion order is configured, schemas may only
+// be promoted to an environment from the stage before it.
+func (m *CLIManager) Promote(ctx context.Context, from, to config.Env) error {
+	m.logger.Debug("promoting schemas", "from", from, "to", to)
+
+	cfg, err := m.registry.Config()
+	if err != nil {
+		return err
+	}
+	if err = cfg.ValidatePromotion(from, to); err != nil {
+		return err
+	}
+	ec, err := cfg.EnvConfig(to)
+	if err != nil {
+		return err
+	}
+
+	p, err := m.registry.Promotion(ctx, m.gitter, from, to)
+	if err != nil {
+		return err
+	}
+	if len(p.Keys()) == 0 {
+		_, _ = fmt.Fprintf(m.reporterWriter, "No schemas to promote from %s to %s\n", from, to)
+		return nil
+	}
+	if len(p.Modified) > 0 && !ec.AllowSchemaMutation {
+		paths := make([]string, 0, len(p.Modified))
+		for _, k := range p.Modified {
+			paths = append(paths, schema.New(k, p.Registry()).Path(schema.FilePath))
+		}
+		return &schema.ChangedDeployedSchemasError{Paths: paths}
+	}
+
+	tr, err := schema.NewTester(p.Registry()).TestSchemas(ctx, p.Keys())
+	if err != nil {
+		return err
+	}
+	if len(tr.FailedTests) > 0 || len(tr.FailedMigrations) > 0 {
+		if rErr := (&report.TextReporter{}).Write(m.reporterWriter, tr); rErr != nil {
+			return rErr
+		}
+		return &schema.PromotionTestsFailedError{From: from, Anchor: p.FromAnchor}
+	}
+
+	count, err := m.distBuilder.BuildPromotion(ctx, p)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(m.reporterWriter,
+		"📂 Successfully built %d schemas promoted from %s (%s) to distribution directory\n", count, from, p.FromAnchor)
+	return nil
+}
+
+// Rollback builds a distribution which undoes the latest deployment to an environment. It holds the schemas
+// the deployment changed, as they were at the deployment before it, and its manifest lists the schemas the
+// deployment introduced, which are to be removed. If tag is true, the rollback is recorded straight away with
+// a rollback tag, which moves the anchor of the environment back to the deployment restored.
+func (m *CLIManager) Rollback(ctx context.Context, envName config.Env, tag bool) error {
+	m.logger.Debug("rolling back deployment", "env", envName, "tag", tag)
+
+	rb, err := m.registry.Rollback(ctx, m.gitter, envName)
+	if err != nil {
+		return err
+	}
+	count, err := m.distBuilder.BuildRollback(ctx, rb)
+	if err != nil {
+		return err
+	}
+
+	restored := rb.Restored.String()
+	if restored == "" {
+		restored = "before its first deployment"
+	}
+	_, _ = fmt.Fprintf(m.reporterWriter, "↩️  Built rollback of %s from %s to %s: %d schemas restored, %d removed\n",
+		envName, rb.RolledBack, restored, count, len(rb.Removed))
+	for _, k := range rb.Removed {
+		_, _ = fmt.Fprintf(m.reporterWriter, "  - %s\n", k)
+	}
+
+	if !tag {
+		return nil
+	}
+	return m.tagRollback(ctx, envName, rb.RolledBack, rb.Restored)
+}
+
+// Vendor copies the schema in file into the registry's vendor directory as the copy of the schema at url, so
+// that schemas which reference url can be compiled offline.
+func (m *CLIManager) Vendor(_ context.Context, url, file string) error {
+	m.logger.Debug("vendoring schema", "url", url, "file", file)
+
+	vs, err := m.registry.Vendor(url, file)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(m.reporterWriter, "📦 Vendored %s as %s/%s (sha256:%s)\n",
+		url, schema.VendorDir, vs.File, vs.SHA256)
+	return nil
+}
+
+// Doctor reports every structural problem in the registry, with a suggestion of how to fix each. If fix is
+// true, the problems which are safe to fix are fixed. An error is returned if any problem remains unfixed.
+func (m *CLIManager) Doctor(ctx context.Context, fix bool) error {
+	m.logger.Debug("checking registry structure", "fix", fix)
+
+	problems, err := m.registry.Doctor(ctx, fix)
+	if err != nil {
+		return err
+	}
+
+	unfixed := 0
+	for _, p := range problems {
+		if p.Fixed {
+			_, _ = fmt.Fprintf(m.reporterWriter, "🔧 Fixed: %s\n", p.Err)
+			continue
+		}
+		unfixed++
+		_, _ = fmt.Fprintf(m.reporterWriter, "❌ %s\n   Fix: %s\n", p.Err, p.Fix)
+	}
+
+	if unfixed > 0 {
+		return &schema.RegistryProblemsError{Count: unfixed}
+	}
+	_, _ = fmt.Fprintln(m.reporterWriter, "✅ No structural problems remain in the registry")
+	return nil
+}
+
+// Move moves the schema family from to the family to, renaming its schemas and rewriting every reference to
+// them. If copyFamily is true, the family is copied to the new location instead, leaving the versions at the old
+// location in place.
+func (m *CLIManager) Move(ctx context.Context, from, to string, copyFamily bool) error {
+	m.logger.Debug("moving schema family", "from", from, "to", to, "copy", copyFamily)
+
+	fm, err := m.registry.MoveFamily(ctx, m.gitter, from, to, copyFamily)
+	if err != nil {
+		return err
+	}
+
+	verb := "🚚 Moved"
+	if fm.Copied {
+		verb = "📋 Copied"
+	}
+	_, _ = fmt.Fprintf(m.reporterWriter, "%s %s to %s\n", verb, fm.From, fm.To)
+	for _, k := range slices.Sorted(maps.Keys(fm.Keys)) {
+		_, _ = fmt.Fprintf(m.reporterWriter, "  %s -> %s\n", k, fm.Keys[k])
+	}
+	if len(fm.Rewritten) > 0 {
+		_, _ = fmt.Fprintf(m.reporterWriter, "✏️  Rewrote the references in %d files:\n", len(fm.Rewritten))
+		for _, path := range fm.Rewritten {
+			_, _ = fmt.Fprintf(m.reporterWriter, "  %s\n", path)
+		}
+	}
+	return nil
+}
+
+// Publish uploads the distribution built for the given environment to the store configured for it, then
+// tags the deployment, or the rollback of a rollback distribution. The deployment is only tagged once every
+// file has been uploaded.
+func (m *CLIManager) Publish(ctx context.Context, envName config.Env) error {
+	m.logger.Debug("publishing distribution", "env", envName)
+
+	cfg, err := m.registry.Config()
+	if err != nil {
+		return err
+	}
+
+	ec, err := cfg.EnvConfig(envName)
+	if err != nil {
+		return err
+	}
+
+	p, err := m.newPublisher(ec)
+	if err != nil {
+		return err
+	}
+
+	dist, err := publish.LoadDistribution(envName, m.distBuilder.DistDir(envName))
+	if err != nil {
+		return err
+	}
+
+	res, err := p.Publish(ctx, dist)
+	if err != nil {
+		return fmt.Errorf("publishing %s failed after uploading %d files: %w", envName, res.Uploaded, err)
+	}
+
+	_, _ = fmt.Fprintf(m.reporterWriter, "🚀 Successfully published %d files (%d unchanged)\n",
+		res.Uploaded, res.Unchanged)
+	if res.Deleted > 0 {
+		_, _ = fmt.Fprintf(m.reporterWriter, "🗑️  Deleted %d schemas removed by the rollback\n", res.Deleted)
+	}
+	if dist.Manifest.RolledBack != "" {
+		return m.tagRollback(ctx, envName, dist.Manifest.RolledBack, dist.Manifest.Restored)
+	}
+	return m.tagDeployment(ctx, envName, dist.Manifest)
+}
+
+// Owners reports the ownership details recorded in the family.yml files of the targeted schema families.
+// If codeOwners is true, the report is written in the GitHub CODEOWNERS format instead.
+func (m *CLIManager) Owners(ctx context.Context, target schema.ResolvedTarget, format string, codeOwners bool) error {
+	m.logger.Debug("reporting owners", "target", target, "format", format, "codeOwners", codeOwners)
+
+	var scope schema.SearchScope
+	switch {
+	case target.Key != nil:
+		scope = target.Key.FamilyScope()
+	case target.Scope != nil:
+		scope = *target.Scope
+	default:
+		return &schema.NoSchemaTargetsError{}
+	}
+
+	owners, err := m.registry.FamilyOwners(ctx, scope)
+	if err != nil {
+		return err
+	}
+
+	var reporter report.OwnersReporter
+	switch {
+	case codeOwners:
+		prefix, pErr := m.registry.GitRelativeRootDirectory(ctx)
+		if pErr != nil {
+			return pErr
+		}
+		reporter = &report.CodeOwnersReporter{PathPrefix: prefix}
+	case format == formatJSON:
+		reporter = &report.OwnersJSONReporter{}
+	default:
+		reporter = &report.OwnersTextReporter{}
+	}
+
+	return reporter.Write(m.reporterWriter, owners)
+}
+
+// History reports the deployments to the given environment, or to every environment if envName is empty,
+// and the schemas each deployment shipped. If target is not nil, only the deployments of the targeted
+// schemas are reported.
+func (m *CLIManager) History(
+	ctx context.Context,
+	envName config.Env,
+	target *schema.ResolvedTarget,
+	format string,
+) error {
+	m.logger.Debug("reporting deployment history", "env", envName, "target", target, "format", format)
+
+	cfg, err := m.registry.Config()
+	if err != nil {
+		return err
+	}
+
+	envs := cfg.Envs()
+	if envName != "" {
+		if _, err = cfg.EnvConfig(envName); err != nil {
+			return err
+		}
+		envs = []config.Env{envName}
+	}
+
+	h, err := m.registry.DeploymentHistory(ctx, m.gitter, envs, target)
+	if err != nil {
+		return err
+	}
+
+	var reporter report.HistoryReporter = &report.HistoryTextReporter{}
+	if format == formatJSON {
+		reporter = &report.HistoryJSONReporter{}
+	}
+	return reporter.Write(m.reporterWriter, h)
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/bitshepherds/json-schema-manager/internal/config"
 	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/publish"
 	"github.com/bitshepherds/json-schema-manager/internal/repo"
 	"github.com/bitshepherds/json-schema-manager/internal/schema"
 	"github.com/bitshepherds/json-schema-manager/internal/validator"
@@ -44,6 +45,8 @@ func (m *mockCompiler) SupportedSchemaVersions() []validator.Draft {
 	return []validator.Draft{validator.Draft7}
 }
 
+func (m *mockCompiler) AddVendored(_ string, _ validator.JSONSchema) {}
+
 func (m *mockCompiler) Clear() {}
 
 type failingCompiler struct {
@@ -92,9 +95,12 @@ func (m *mockValidator) Validate(_ validator.JSONDocument) error {
 }
 
 type MockDistBuilder struct {
-	BuildAllFunc      func(ctx context.Context, env config.Env) (int, error)
-	BuildChangedFunc  func(ctx context.Context, env config.Env, anchor repo.Revision) (int, error)
-	SetNumWorkersFunc func(n int)
+	BuildAllFunc       func(ctx context.Context, env config.Env) (int, error)
+	BuildChangedFunc   func(ctx context.Context, env config.Env, anchor repo.Revision) (int, error)
+	BuildPromotionFunc func(ctx context.Context, p *schema.Promotion) (int, error)
+	BuildRollbackFunc  func(ctx context.Context, rb *schema.Rollback) (int, error)
+	SetNumWorkersFunc  func(n int)
+	DistDirFunc        func(env config.Env) string
 }
 
 func (m *MockDistBuilder) BuildAll(ctx context.Context, env config.Env) (int, error) {
@@ -111,12 +117,33 @@ func (m *MockDistBuilder) BuildChanged(ctx context.Context, env config.Env, anch
 	return 0, nil
 }
 
+func (m *MockDistBuilder) BuildPromotion(ctx context.Context, p *schema.Promotion) (int, error) {
+	if m.BuildPromotionFunc != nil {
+		return m.BuildPromotionFunc(ctx, p)
+	}
+	return 0, nil
+}
+
+func (m *MockDistBuilder) BuildRollback(ctx context.Context, rb *schema.Rollback) (int, error) {
+	if m.BuildRollbackFunc != nil {
+		return m.BuildRollbackFunc(ctx, rb)
+	}
+	return 0, nil
+}
+
 func (m *MockDistBuilder) SetNumWorkers(n int) {
 	if m.SetNumWorkersFunc != nil {
 		m.SetNumWorkersFunc(n)
 	}
 }
 
+func (m *MockDistBuilder) DistDir(env config.Env) string {
+	if m.DistDirFunc != nil {
+		return m.DistDirFunc(env)
+	}
+	return filepath.Join("dist", string(env))
+}
+
 func setupTestRegistry(t *testing.T) *schema.Registry {
 	t.Helper()
 	regDir := t.TempDir()
@@ -559,7 +586,7 @@ func TestCLIManager_CreateSchema(t *testing.T) {
 		tester := schema.NewTester(registry)
 		mgr := NewCLIManager(logger, registry, tester, &MockGitter{}, nil, io.Discard)
 
-		key, err := mgr.CreateSchema("test-domain/test-family")
+		key, err := mgr.CreateSchema("test-domain/test-family", schema.ScaffoldOptions{})
 		require.NoError(t, err)
 		assert.Equal(t, schema.Key("test-domain_test-family_1_0_0"), key)
 	})
@@ -570,9 +597,23 @@ func TestCLIManager_CreateSchema(t *testing.T) {
 		tester := schema.NewTester(registry)
 		mgr := NewCLIManager(logger, registry, tester, &MockGitter{}, nil, io.Discard)
 
-		_, err := mgr.CreateSchema("INVALID/scope")
+		_, err := mgr.CreateSchema("INVALID/scope", schema.ScaffoldOptions{})
 		require.Error(t, err)
 	})
+
+	t.Run("successful create with details", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		tester := schema.NewTester(registry)
+		mgr := NewCLIManager(logger, registry, tester, &MockGitter{}, nil, io.Discard)
+
+		key, err := mgr.CreateSchema("test-domain/test-family", schema.ScaffoldOptions{
+			Details: &schema.SchemaDetails{Title: "Test"},
+		})
+		require.NoError(t, err)
+		s := schema.New(key, registry)
+		assert.FileExists(t, filepath.Join(s.Path(schema.HomeDir), "pass", schema.StarterPassDocument))
+	})
 }
 
 func TestCLIManager_CreateSchemaVersion(t *testing.T) {
@@ -1028,11 +1069,11 @@ func TestCLIManager_TagDeployment(t *testing.T) {
 	t.Parallel()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	registry := setupTestRegistry(t)
-	mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, io.Discard)
+	mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, &MockDistBuilder{}, io.Discard)
 
 	t.Run("config error", func(t *testing.T) {
 		t.Parallel()
-		m := NewCLIManager(logger, &schema.Registry{}, nil, &MockGitter{}, nil, io.Discard)
+		m := NewCLIManager(logger, &schema.Registry{}, nil, &MockGitter{}, &MockDistBuilder{}, io.Discard)
 		err := m.TagDeployment(context.Background(), "prod")
 		require.Error(t, err)
 	})
@@ -1065,7 +1106,7 @@ func TestCLIManager_TagDeployment(t *testing.T) {
 			os.WriteFile(filepath.Join(dir, "json-schema-manager-config.yml"), []byte(testConfigData), 0o600),
 		)
 		r, _ := schema.NewRegistry(dir, &mockCompiler{}, fsh.NewPathResolver(), fsh.NewEnvProvider())
-		m := NewCLIManager(logger, r, nil, &MockGitter{}, nil, io.Discard)
+		m := NewCLIManager(logger, r, nil, &MockGitter{}, &MockDistBuilder{}, io.Discard)
 
 		// This calls g.TagDeploymentSuccess() which will fail push but return tagName
 		err := m.TagDeployment(context.Background(), "prod")
@@ -1076,11 +1117,11 @@ func TestCLIManager_TagDeployment(t *testing.T) {
 		t.Parallel()
 		r := setupTestRegistry(t)
 		mockGitter := &MockGitter{
-			TagDeploymentFunc: func(_ context.Context, _ config.Env) (string, error) {
+			TagDeploymentFunc: func(context.Context, config.Env, repo.Revision, []repo.DeployedSchema) (string, error) {
 				return "", fmt.Errorf("failed to create git tag")
 			},
 		}
-		m := NewCLIManager(logger, r, nil, mockGitter, nil, io.Discard)
+		m := NewCLIManager(logger, r, nil, mockGitter, &MockDistBuilder{}, io.Discard)
 
 		err := m.TagDeployment(context.Background(), "prod")
 		require.Error(t, err)
@@ -1116,7 +1157,7 @@ func TestCLIManager_TagDeployment(t *testing.T) {
 		cfgPath := filepath.Join(repoDir, "json-schema-manager-config.yml")
 		require.NoError(t, os.WriteFile(cfgPath, []byte(testConfigData), 0o600))
 		r, _ := schema.NewRegistry(repoDir, &mockCompiler{}, fsh.NewPathResolver(), fsh.NewEnvProvider())
-		m := NewCLIManager(logger, r, nil, &MockGitter{}, nil, io.Discard)
+		m := NewCLIManager(logger, r, nil, &MockGitter{}, &MockDistBuilder{}, io.Discard)
 
 		err := m.TagDeployment(context.Background(), "prod")
 		require.NoError(t, err)
@@ -1159,16 +1200,100 @@ func TestCLIManager_TagDeployment(t *testing.T) {
 		r, _ := schema.NewRegistry(dir, &mockCompiler{}, fsh.NewPathResolver(), fsh.NewEnvProvider())
 
 		mockGitter := &MockGitter{
-			TagDeploymentFunc: func(_ context.Context, _ config.Env) (string, error) {
+			TagDeploymentFunc: func(context.Context, config.Env, repo.Revision, []repo.DeployedSchema) (string, error) {
 				return "jsm-deploy/prod/failed-push", fmt.Errorf("git push failed")
 			},
 		}
 
-		m := NewCLIManager(logger, r, nil, mockGitter, nil, io.Discard)
+		m := NewCLIManager(logger, r, nil, mockGitter, &MockDistBuilder{}, io.Discard)
 
 		err := m.TagDeployment(context.Background(), "prod")
 		require.NoError(t, err) // Should return nil if tag created but push failed
 	})
+	t.Run("records the distributed schemas", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		manifest := `{"env":"prod","schemas":[{"key":"domain_family_1_0_0","sha256":"abc123"}]}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, schema.ManifestFile), []byte(manifest), 0o600))
+
+		var got []repo.DeployedSchema
+		mockGitter := &MockGitter{
+			TagDeploymentFunc: func(_ context.Context, _ config.Env, at repo.Revision, s []repo.DeployedSchema) (
+				string, error,
+			) {
+				assert.Empty(t, at)
+				got = s
+				return "jsm-deploy/prod/1", nil
+			},
+		}
+		builder := &MockDistBuilder{DistDirFunc: func(_ config.Env) string { return dir }}
+		m := NewCLIManager(logger, setupTestRegistry(t), nil, mockGitter, builder, io.Discard)
+
+		require.NoError(t, m.TagDeployment(context.Background(), "prod"))
+		assert.Equal(t, []repo.DeployedSchema{{Key: "domain_family_1_0_0", SHA256: "abc123"}}, got)
+	})
+
+	t.Run("tags a promotion at the promoted revision", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		manifest := `{"env":"prod","schemas":[{"key":"domain_family_1_0_0","sha256":"abc123"}],` +
+			`"promoted":"jsm-deploy/staging/1"}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, schema.ManifestFile), []byte(manifest), 0o600))
+
+		var got repo.Revision
+		mockGitter := &MockGitter{
+			TagDeploymentFunc: func(_ context.Context, _ config.Env, at repo.Revision, _ []repo.DeployedSchema) (
+				string, error,
+			) {
+				got = at
+				return "jsm-deploy/prod/1", nil
+			},
+		}
+		builder := &MockDistBuilder{DistDirFunc: func(_ config.Env) string { return dir }}
+		m := NewCLIManager(logger, setupTestRegistry(t), nil, mockGitter, builder, io.Discard)
+
+		require.NoError(t, m.TagDeployment(context.Background(), "prod"))
+		assert.Equal(t, repo.Revision("jsm-deploy/staging/1"), got)
+	})
+
+	t.Run("records a rollback", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		manifest := `{"env":"prod","schemas":[],"rolledBack":"jsm-deploy/prod/2","restored":"jsm-deploy/prod/1"}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, schema.ManifestFile), []byte(manifest), 0o600))
+
+		var rolledBack, restored repo.Revision
+		mockGitter := &MockGitter{
+			TagDeploymentFunc: func(context.Context, config.Env, repo.Revision, []repo.DeployedSchema) (string, error) {
+				t.Error("deployment should not be tagged")
+				return "", nil
+			},
+			TagRollbackFunc: func(_ context.Context, _ config.Env, rb, rs repo.Revision) (string, error) {
+				rolledBack, restored = rb, rs
+				return "jsm-rollback/prod/1", nil
+			},
+		}
+		builder := &MockDistBuilder{DistDirFunc: func(_ config.Env) string { return dir }}
+		out := &bytes.Buffer{}
+		m := NewCLIManager(logger, setupTestRegistry(t), nil, mockGitter, builder, out)
+
+		require.NoError(t, m.TagDeployment(context.Background(), "prod"))
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/2"), rolledBack)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), restored)
+		assert.Contains(t, out.String(), "Successfully tagged and pushed rollback: jsm-rollback/prod/1")
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, schema.ManifestFile), []byte("{"), 0o600))
+
+		builder := &MockDistBuilder{DistDirFunc: func(_ config.Env) string { return dir }}
+		m := NewCLIManager(logger, setupTestRegistry(t), nil, &MockGitter{}, builder, io.Discard)
+
+		var target *publish.InvalidManifestError
+		require.ErrorAs(t, m.TagDeployment(context.Background(), "prod"), &target)
+	})
 }
 
 func TestCLIManager_BuildDist(t *testing.T) {
@@ -1186,7 +1311,7 @@ func TestCLIManager_BuildDist(t *testing.T) {
 		}
 		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, mockBuilder, io.Discard)
 
-		err := mgr.BuildDist(context.Background(), "prod", true)
+		err := mgr.BuildDist(context.Background(), "prod", true, false)
 		require.NoError(t, err)
 	})
 
@@ -1201,7 +1326,7 @@ func TestCLIManager_BuildDist(t *testing.T) {
 		}
 		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, mockBuilder, io.Discard)
 
-		err := mgr.BuildDist(context.Background(), "prod", false)
+		err := mgr.BuildDist(context.Background(), "prod", false, false)
 		require.NoError(t, err)
 	})
 
@@ -1215,7 +1340,7 @@ func TestCLIManager_BuildDist(t *testing.T) {
 		}
 		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, mockBuilder, io.Discard)
 
-		err := mgr.BuildDist(context.Background(), "prod", true)
+		err := mgr.BuildDist(context.Background(), "prod", true, false)
 		require.NoError(t, err)
 	})
 
@@ -1229,7 +1354,7 @@ func TestCLIManager_BuildDist(t *testing.T) {
 		}
 		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, mockBuilder, io.Discard)
 
-		err := mgr.BuildDist(context.Background(), "prod", true)
+		err := mgr.BuildDist(context.Background(), "prod", true, false)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "build failed")
 	})
@@ -1240,12 +1365,12 @@ func TestCLIManager_BuildDist(t *testing.T) {
 		// Use a gitter that returns a mutation error
 		mockGitter := &MockGitter{
 			GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
-				return []repo.Change{{Path: "mutated.schema.json", IsNew: false}}, nil
+				return []repo.Change{{Path: "mutated.schema.json", Status: repo.ChangeModified}}, nil
 			},
 		}
 		mgr := NewCLIManager(logger, registry, nil, mockGitter, nil, io.Discard)
 
-		err := mgr.BuildDist(context.Background(), "prod", false)
+		err := mgr.BuildDist(context.Background(), "prod", false, false)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "cannot modify deployed schemas")
 	})
@@ -1265,7 +1390,7 @@ func TestCLIManager_BuildDist(t *testing.T) {
 		}
 		mgr := NewCLIManager(logger, registry, nil, mockGitter, nil, io.Discard)
 
-		err := mgr.BuildDist(context.Background(), "prod", false)
+		err := mgr.BuildDist(context.Background(), "prod", false, false)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "git anchor failed")
 	})
@@ -1280,30 +1405,183 @@ func TestCLIManager_BuildDist(t *testing.T) {
 		}
 		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, mockBuilder, io.Discard)
 
-		err := mgr.BuildDist(context.Background(), "prod", false)
+		err := mgr.BuildDist(context.Background(), "prod", false, false)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "build changed failed")
 	})
 }
 
+type mockPublisher struct {
+	publishFunc func(ctx context.Context, dist *publish.Distribution) (publish.Result, error)
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, dist *publish.Distribution) (publish.Result, error) {
+	return m.publishFunc(ctx, dist)
+}
+
+func TestCLIManager_Publish(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// distBuilder returns a builder whose prod distribution has been built.
+	distBuilder := func(t *testing.T) *MockDistBuilder {
+		t.Helper()
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, schema.ManifestFile), []byte(`{"schemas":[]}`), 0o600))
+		return &MockDistBuilder{DistDirFunc: func(_ config.Env) string { return dir }}
+	}
+
+	t.Run("success tags deployment", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		tagged := false
+		mockGitter := &MockGitter{
+			TagDeploymentFunc: func(_ context.Context, env config.Env, _ repo.Revision, _ []repo.DeployedSchema) (
+				string, error,
+			) {
+				tagged = true
+				return "jsm-deploy/" + string(env) + "/1", nil
+			},
+		}
+		out := &bytes.Buffer{}
+		mgr := NewCLIManager(logger, registry, nil, mockGitter, distBuilder(t), out)
+		mgr.SetPublisherFactory(func(ec *config.EnvConfig) (publish.Publisher, error) {
+			assert.Equal(t, config.Env("prod"), ec.Env)
+			return &mockPublisher{
+				publishFunc: func(_ context.Context, dist *publish.Distribution) (publish.Result, error) {
+					assert.Equal(t, config.Env("prod"), dist.Env)
+					return publish.Result{Uploaded: 2, Unchanged: 3}, nil
+				},
+			}, nil
+		})
+
+		require.NoError(t, mgr.Publish(context.Background(), "prod"))
+		assert.True(t, tagged)
+		assert.Contains(t, out.String(), "Successfully published 2 files (3 unchanged)")
+	})
+
+	t.Run("success tags rollback", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		manifest := `{"env":"prod","schemas":[],"rolledBack":"jsm-deploy/prod/1","removed":["domain_family_1_1_0"]}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, schema.ManifestFile), []byte(manifest), 0o600))
+		var rolledBack repo.Revision
+		mockGitter := &MockGitter{
+			TagRollbackFunc: func(_ context.Context, _ config.Env, rb, _ repo.Revision) (string, error) {
+				rolledBack = rb
+				return "jsm-rollback/prod/1", nil
+			},
+		}
+		builder := &MockDistBuilder{DistDirFunc: func(_ config.Env) string { return dir }}
+		out := &bytes.Buffer{}
+		mgr := NewCLIManager(logger, setupTestRegistry(t), nil, mockGitter, builder, out)
+		mgr.SetPublisherFactory(func(_ *config.EnvConfig) (publish.Publisher, error) {
+			return &mockPublisher{
+				publishFunc: func(_ context.Context, dist *publish.Distribution) (publish.Result, error) {
+					assert.Equal(t, []schema.Key{"domain_family_1_1_0"}, dist.Manifest.Removed)
+					return publish.Result{Uploaded: 1, Deleted: 1}, nil
+				},
+			}, nil
+		})
+
+		require.NoError(t, mgr.Publish(context.Background(), "prod"))
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), rolledBack)
+		assert.Contains(t, out.String(), "Deleted 1 schemas removed by the rollback")
+	})
+
+	t.Run("publish error does not tag deployment", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		mockGitter := &MockGitter{
+			TagDeploymentFunc: func(context.Context, config.Env, repo.Revision, []repo.DeployedSchema) (string, error) {
+				t.Error("deployment should not be tagged")
+				return "", nil
+			},
+		}
+		mgr := NewCLIManager(logger, registry, nil, mockGitter, distBuilder(t), io.Discard)
+		mgr.SetPublisherFactory(func(_ *config.EnvConfig) (publish.Publisher, error) {
+			return &mockPublisher{
+				publishFunc: func(_ context.Context, _ *publish.Distribution) (publish.Result, error) {
+					return publish.Result{Uploaded: 1}, fmt.Errorf("upload failed")
+				},
+			}, nil
+		})
+
+		err := mgr.Publish(context.Background(), "prod")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "after uploading 1 files: upload failed")
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, distBuilder(t), io.Discard)
+
+		err := mgr.Publish(context.Background(), "prod")
+		var target *publish.NotConfiguredError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("invalid environment", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, distBuilder(t), io.Discard)
+
+		require.Error(t, mgr.Publish(context.Background(), "invalid"))
+	})
+
+	t.Run("config error", func(t *testing.T) {
+		t.Parallel()
+		mgr := NewCLIManager(logger, &schema.Registry{}, nil, &MockGitter{}, distBuilder(t), io.Discard)
+
+		require.Error(t, mgr.Publish(context.Background(), "prod"))
+	})
+
+	t.Run("distribution not built", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, &MockDistBuilder{
+			DistDirFunc: func(_ config.Env) string { return t.TempDir() },
+		}, io.Discard)
+		mgr.SetPublisherFactory(func(_ *config.EnvConfig) (publish.Publisher, error) {
+			return &mockPublisher{}, nil
+		})
+
+		err := mgr.Publish(context.Background(), "prod")
+		var target *publish.MissingDistributionError
+		require.ErrorAs(t, err, &target)
+	})
+}
+
 func TestNewBuildDistCmd(t *testing.T) {
 	t.Parallel()
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		mockMgr := &MockManager{}
 		cmd := NewBuildDistCmd(mockMgr)
-		mockMgr.On("BuildDist", mock.Anything, config.Env("prod"), false).Return(nil)
+		mockMgr.On("BuildDist", mock.Anything, config.Env("prod"), false, false).Return(nil)
 		cmd.SetArgs([]string{"prod"})
 		err := cmd.Execute()
 		require.NoError(t, err)
 		mockMgr.AssertExpectations(t)
 	})
 
+	t.Run("skip stages", func(t *testing.T) {
+		t.Parallel()
+		mockMgr := &MockManager{}
+		cmd := NewBuildDistCmd(mockMgr)
+		mockMgr.On("BuildDist", mock.Anything, config.Env("prod"), false, true).Return(nil)
+		cmd.SetArgs([]string{"prod", "--skip-stages"})
+		err := cmd.Execute()
+		require.NoError(t, err)
+		mockMgr.AssertExpectations(t)
+	})
+
 	t.Run("error", func(t *testing.T) {
 		t.Parallel()
 		mockMgr := &MockManager{}
 		cmd := NewBuildDistCmd(mockMgr)
-		mockMgr.On("BuildDist", mock.Anything, config.Env("prod"), false).Return(errors.New("build failed"))
+		mockMgr.On("BuildDist", mock.Anything, config.Env("prod"), false, false).Return(errors.New("build failed"))
 		cmd.SetArgs([]string{"prod"})
 		err := cmd.Execute()
 		require.Error(t, err)
@@ -1354,8 +1632,8 @@ func TestLazyManager_Delegation(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test CreateSchema delegation
-	mockMgr.On("CreateSchema", "domain/family").Return(schema.Key("domain_family_1_0_0"), nil)
-	key2, err := lazy.CreateSchema("domain/family")
+	mockMgr.On("CreateSchema", "domain/family", schema.ScaffoldOptions{}).Return(schema.Key("domain_family_1_0_0"), nil)
+	key2, err := lazy.CreateSchema("domain/family", schema.ScaffoldOptions{})
 	require.NoError(t, err)
 	assert.Equal(t, schema.Key("domain_family_1_0_0"), key2)
 
@@ -1382,9 +1660,54 @@ func TestLazyManager_Delegation(t *testing.T) {
 	err = lazy.TagDeployment(ctx, config.Env("prod"))
 	require.NoError(t, err)
 
+	// Test Plan delegation
+	mockMgr.On("Plan", ctx, config.Env("prod"), "json").Return(nil)
+	err = lazy.Plan(ctx, config.Env("prod"), "json")
+	require.NoError(t, err)
+
 	// Test BuildDist delegation
-	mockMgr.On("BuildDist", ctx, config.Env("prod"), false).Return(nil)
-	err = lazy.BuildDist(ctx, config.Env("prod"), false)
+	mockMgr.On("BuildDist", ctx, config.Env("prod"), false, false).Return(nil)
+	err = lazy.BuildDist(ctx, config.Env("prod"), false, false)
+	require.NoError(t, err)
+
+	// Test Promote delegation
+	mockMgr.On("Promote", ctx, config.Env("dev"), config.Env("prod")).Return(nil)
+	err = lazy.Promote(ctx, "dev", "prod")
+	require.NoError(t, err)
+
+	// Test Rollback delegation
+	mockMgr.On("Rollback", ctx, config.Env("prod"), true).Return(nil)
+	err = lazy.Rollback(ctx, "prod", true)
+	require.NoError(t, err)
+
+	// Test Publish delegation
+	mockMgr.On("Publish", ctx, config.Env("prod")).Return(nil)
+	err = lazy.Publish(ctx, config.Env("prod"))
+	require.NoError(t, err)
+
+	// Test Owners delegation
+	mockMgr.On("Owners", ctx, target, "json", true).Return(nil)
+	err = lazy.Owners(ctx, target, "json", true)
+	require.NoError(t, err)
+
+	// Test History delegation
+	mockMgr.On("History", ctx, config.Env("prod"), &target, "text").Return(nil)
+	err = lazy.History(ctx, "prod", &target, "text")
+	require.NoError(t, err)
+
+	// Test Vendor delegation
+	mockMgr.On("Vendor", ctx, "https://geojson.org/schema/Point.json", "Point.json").Return(nil)
+	err = lazy.Vendor(ctx, "https://geojson.org/schema/Point.json", "Point.json")
+	require.NoError(t, err)
+
+	// Test Doctor delegation
+	mockMgr.On("Doctor", ctx, true).Return(nil)
+	err = lazy.Doctor(ctx, true)
+	require.NoError(t, err)
+
+	// Test Move delegation
+	mockMgr.On("Move", ctx, "old/family", "new/family", false).Return(nil)
+	err = lazy.Move(ctx, "old/family", "new/family", false)
 	require.NoError(t, err)
 
 	// Test WatchValidation delegation
@@ -1576,3 +1899,413 @@ type failingWriter struct{}
 func (f *failingWriter) Write(_ []byte) (n int, err error) {
 	return 0, fmt.Errorf("write failed")
 }
+
+// setupPartialsRegistry creates a registry containing a money partial, which is included by
+// domain_order_1_0_0 but not by domain_plain_1_0_0.
+func setupPartialsRegistry(t *testing.T) *schema.Registry {
+	t.Helper()
+	registry := setupTestRegistry(t)
+	partialsDir := filepath.Join(registry.RootDirectory(), schema.PartialsDir)
+	require.NoError(t, os.MkdirAll(partialsDir, 0o755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(partialsDir, "money"+schema.PartialSuffix), []byte(`{"type": "number"}`), 0o600,
+	))
+
+	for k, content := range map[schema.Key]string{
+		"domain_order_1_0_0": `{"properties": {"price": {{ template "money" }}}}`,
+		"domain_plain_1_0_0": `{"type": "object"}`,
+	} {
+		s := schema.New(k, registry)
+		require.NoError(t, os.MkdirAll(filepath.Join(s.Path(schema.HomeDir), "pass"), 0o755))
+		require.NoError(t, os.MkdirAll(filepath.Join(s.Path(schema.HomeDir), "fail"), 0o755))
+		require.NoError(t, os.WriteFile(s.Path(schema.FilePath), []byte(content), 0o600))
+	}
+	return registry
+}
+
+func TestCLIManager_handleWatchEvent_Partial(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("dependent schemas are tested", func(t *testing.T) {
+		t.Parallel()
+		registry := setupPartialsRegistry(t)
+		for _, k := range []schema.Key{"domain_order_1_0_0", "domain_plain_1_0_0"} {
+			passDir := filepath.Join(schema.New(k, registry).Path(schema.HomeDir), "pass")
+			require.NoError(t, os.WriteFile(filepath.Join(passDir, "doc.json"), []byte("{}"), 0o600))
+		}
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+
+		mgr.handleWatchEvent(context.Background(), schema.WatchEvent{Partial: "money"}, schema.ResolvedTarget{},
+			false, "json", false, false, schema.TestScopeLocal, false)
+		assert.Contains(t, buf.String(), `"domain_order_1_0_0"`)
+		assert.NotContains(t, buf.String(), "domain_plain_1_0_0")
+	})
+
+	t.Run("no targeted dependents", func(t *testing.T) {
+		t.Parallel()
+		registry := setupPartialsRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+		key := schema.Key("domain_plain_1_0_0")
+
+		mgr.handleWatchEvent(context.Background(), schema.WatchEvent{Partial: "money"}, schema.ResolvedTarget{Key: &key},
+			false, "json", false, false, schema.TestScopeLocal, false)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("dependents cannot be found", func(t *testing.T) {
+		t.Parallel()
+		registry := setupPartialsRegistry(t)
+		require.NoError(t, os.WriteFile(
+			filepath.Join(registry.RootDirectory(), schema.PartialsDir, "bad"+schema.PartialSuffix),
+			[]byte("{{ end }}"), 0o600,
+		))
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+
+		mgr.handleWatchEvent(context.Background(), schema.WatchEvent{Partial: "money"}, schema.ResolvedTarget{},
+			false, "json", false, false, schema.TestScopeLocal, false)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestCLIManager_CheckChanges_Partials(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	partialChanges := func(registry *schema.Registry, schemaChanges []repo.Change) *MockGitter {
+		return &MockGitter{
+			GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix == schema.PartialSuffix {
+					p := filepath.Join(registry.RootDirectory(), schema.PartialsDir, "money"+schema.PartialSuffix)
+					return []repo.Change{{Path: p}}, nil
+				}
+				return schemaChanges, nil
+			},
+		}
+	}
+
+	t.Run("deployed dependents are changed", func(t *testing.T) {
+		t.Parallel()
+		registry := setupPartialsRegistry(t)
+		mgr := NewCLIManager(logger, registry, nil, partialChanges(registry, nil), nil, io.Discard)
+
+		err := mgr.CheckChanges(context.Background(), "prod")
+		var mutationErr *schema.ChangedDeployedSchemasError
+		require.ErrorAs(t, err, &mutationErr)
+		assert.Equal(t, []string{schema.New("domain_order_1_0_0", registry).Path(schema.FilePath)}, mutationErr.Paths)
+	})
+
+	t.Run("new dependents are not changed", func(t *testing.T) {
+		t.Parallel()
+		registry := setupPartialsRegistry(t)
+		p := schema.New("domain_order_1_0_0", registry).Path(schema.FilePath)
+		mgr := NewCLIManager(logger, registry, nil,
+			partialChanges(registry, []repo.Change{{Path: p, Status: repo.ChangeAdded}}), nil, io.Discard)
+
+		require.NoError(t, mgr.CheckChanges(context.Background(), "prod"))
+	})
+
+	t.Run("partial changes cannot be read", func(t *testing.T) {
+		t.Parallel()
+		registry := setupPartialsRegistry(t)
+		gitter := &MockGitter{
+			GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix == schema.PartialSuffix {
+					return nil, errors.New("git failed")
+				}
+				return nil, nil
+			},
+		}
+		mgr := NewCLIManager(logger, registry, nil, gitter, nil, io.Discard)
+
+		require.EqualError(t, mgr.CheckChanges(context.Background(), "prod"), "git failed")
+	})
+}
+
+func TestCLIManager_CheckChanges_VersionRanges(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	registry := setupTestRegistry(t)
+	for k, content := range map[schema.Key]string{
+		"domain_person_1_0_0": `{}`,
+		"domain_person_1_1_0": `{}`,
+		"domain_latest_1_0_0": `{"$ref": "{{ JSM "domain_person_1" }}"}`,
+	} {
+		s := schema.New(k, registry)
+		require.NoError(t, os.MkdirAll(s.Path(schema.HomeDir), 0o755))
+		require.NoError(t, os.WriteFile(s.Path(schema.FilePath), []byte(content), 0o600))
+	}
+	gitter := &MockGitter{
+		GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+			if suffix != schema.SchemaSuffix {
+				return nil, nil
+			}
+			p := schema.New("domain_person_1_1_0", registry).Path(schema.FilePath)
+			return []repo.Change{{Path: p, Status: repo.ChangeAdded}}, nil
+		},
+	}
+	mgr := NewCLIManager(logger, registry, nil, gitter, nil, io.Discard)
+
+	err := mgr.CheckChanges(context.Background(), "prod")
+	var mutationErr *schema.ChangedDeployedSchemasError
+	require.ErrorAs(t, err, &mutationErr)
+	assert.Equal(t, []string{schema.New("domain_latest_1_0_0", registry).Path(schema.FilePath)}, mutationErr.Paths)
+}
+
+func TestCLIManager_CheckChanges_RenderedMutationCheck(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const current = `{"type": "object", "required": ["name"]}`
+	setup := func(t *testing.T) (*schema.Registry, string) {
+		t.Helper()
+		registry := setupTestRegistry(t)
+		cfg, err := registry.Config()
+		require.NoError(t, err)
+		cfg.Environments["prod"].MutationCheck = config.MutationCheckRendered
+
+		s := schema.New("domain_person_1_0_0", registry)
+		require.NoError(t, os.MkdirAll(s.Path(schema.HomeDir), 0o755))
+		require.NoError(t, os.WriteFile(s.Path(schema.FilePath), []byte(current), 0o600))
+		return registry, s.Path(schema.FilePath)
+	}
+	gitterFor := func(paths []string, anchorSrc string) *MockGitter {
+		return &MockGitter{
+			GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix != schema.SchemaSuffix {
+					return nil, nil
+				}
+				changes := make([]repo.Change, 0, len(paths))
+				for _, p := range paths {
+					changes = append(changes, repo.Change{Path: p})
+				}
+				return changes, nil
+			},
+			GetFileAtRevisionFunc: func(_ context.Context, _ repo.Revision, _ string) ([]byte, error) {
+				return []byte(anchorSrc), nil
+			},
+		}
+	}
+
+	t.Run("cosmetic edit", func(t *testing.T) {
+		t.Parallel()
+		registry, fp := setup(t)
+		var buf bytes.Buffer
+		gitter := gitterFor([]string{fp}, "{\n  \"required\": [ \"name\" ],\n  \"type\": \"object\"\n}\n")
+		mgr := NewCLIManager(logger, registry, nil, gitter, nil, &buf)
+
+		require.NoError(t, mgr.CheckChanges(context.Background(), "prod"))
+		assert.Contains(t, buf.String(), "All changes are valid")
+	})
+
+	t.Run("semantic edit", func(t *testing.T) {
+		t.Parallel()
+		registry, fp := setup(t)
+		mgr := NewCLIManager(logger, registry, nil, gitterFor([]string{fp}, `{"type": "object"}`), nil, io.Discard)
+
+		err := mgr.CheckChanges(context.Background(), "prod")
+		var mutationErr *schema.ChangedDeployedSchemasError
+		require.ErrorAs(t, err, &mutationErr)
+		assert.Equal(t, []string{fp}, mutationErr.Paths)
+	})
+
+	t.Run("deleted schema", func(t *testing.T) {
+		t.Parallel()
+		registry, _ := setup(t)
+		deleted := schema.New("domain_deleted_1_0_0", registry).Path(schema.FilePath)
+		mgr := NewCLIManager(logger, registry, nil, gitterFor([]string{deleted}, current), nil, io.Discard)
+
+		err := mgr.CheckChanges(context.Background(), "prod")
+		var mutationErr *schema.ChangedDeployedSchemasError
+		require.ErrorAs(t, err, &mutationErr)
+		assert.Equal(t, []string{deleted}, mutationErr.Paths)
+	})
+
+	t.Run("git error", func(t *testing.T) {
+		t.Parallel()
+		registry, fp := setup(t)
+		gitter := gitterFor([]string{fp}, current)
+		gitter.GetFileAtRevisionFunc = func(_ context.Context, _ repo.Revision, _ string) ([]byte, error) {
+			return nil, errors.New("git show failed")
+		}
+		mgr := NewCLIManager(logger, registry, nil, gitter, nil, io.Discard)
+
+		err := mgr.CheckChanges(context.Background(), "prod")
+		require.ErrorContains(t, err, "git show failed")
+	})
+}
+
+func TestCLIManager_CheckChanges_AnnotationsAllowed(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name      string
+		anchorSrc string
+		wantErr   bool
+	}{
+		{name: "description fixed", anchorSrc: `{"type": "object", "description": "A persn"}`},
+		{name: "annotation added", anchorSrc: `{"type": "object"}`},
+		{name: "type changed", anchorSrc: `{"type": "array", "description": "A person"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			registry := setupTestRegistry(t)
+			cfg, err := registry.Config()
+			require.NoError(t, err)
+			cfg.Environments["prod"].MutationPolicy = config.MutationPolicyAnnotationsAllowed
+
+			s := schema.New("domain_person_1_0_0", registry)
+			require.NoError(t, os.MkdirAll(s.Path(schema.HomeDir), 0o755))
+			require.NoError(t, os.WriteFile(s.Path(schema.FilePath),
+				[]byte(`{"type": "object", "description": "A person"}`), 0o600))
+
+			gitter := &MockGitter{
+				GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+					if suffix != schema.SchemaSuffix {
+						return nil, nil
+					}
+					return []repo.Change{{Path: s.Path(schema.FilePath)}}, nil
+				},
+				GetFileAtRevisionFunc: func(_ context.Context, _ repo.Revision, _ string) ([]byte, error) {
+					return []byte(tt.anchorSrc), nil
+				},
+			}
+			mgr := NewCLIManager(logger, registry, nil, gitter, nil, io.Discard)
+
+			err = mgr.CheckChanges(context.Background(), "prod")
+			if tt.wantErr {
+				var mutationErr *schema.ChangedDeployedSchemasError
+				require.ErrorAs(t, err, &mutationErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCLIManager_CheckChanges_DeletedAndRenamed(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name   string
+		status repo.ChangeStatus
+	}{
+		{name: "deleted schema", status: repo.ChangeDeleted},
+		{name: "renamed schema", status: repo.ChangeRenamed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			registry := setupPartialsRegistry(t)
+			oldPath := schema.New("domain_gone_1_0_0", registry).Path(schema.FilePath)
+			change := repo.Change{Path: oldPath, Status: tt.status}
+			if tt.status == repo.ChangeRenamed {
+				change = repo.Change{
+					Path:    schema.New("domain_plain_1_0_0", registry).Path(schema.FilePath),
+					OldPath: oldPath,
+					Status:  tt.status,
+				}
+			}
+			gitter := &MockGitter{
+				GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+					if suffix != schema.SchemaSuffix {
+						return nil, nil
+					}
+					return []repo.Change{change}, nil
+				},
+			}
+
+			for _, mutationCheck := range []config.MutationCheck{config.MutationCheckSource, config.MutationCheckRendered} {
+				cfg, err := registry.Config()
+				require.NoError(t, err)
+				cfg.Environments["prod"].MutationCheck = mutationCheck
+
+				mgr := NewCLIManager(logger, registry, nil, gitter, nil, io.Discard)
+				err = mgr.CheckChanges(context.Background(), "prod")
+				var mutationErr *schema.ChangedDeployedSchemasError
+				require.ErrorAs(t, err, &mutationErr, mutationCheck)
+				assert.Equal(t, []string{oldPath}, mutationErr.Paths)
+			}
+		})
+	}
+}
+
+func TestCLIManager_Plan(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	planGitter := func(registry *schema.Registry) *MockGitter {
+		plain := schema.New("domain_plain_1_0_0", registry).Path(schema.FilePath)
+		deleted := schema.New("domain_gone_1_0_0", registry).Path(schema.FilePath)
+		return &MockGitter{
+			GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix == schema.PartialSuffix {
+					p := filepath.Join(registry.RootDirectory(), schema.PartialsDir, "money"+schema.PartialSuffix)
+					return []repo.Change{{Path: p}}, nil
+				}
+				return []repo.Change{{Path: plain, Status: repo.ChangeAdded}, {Path: deleted, Status: repo.ChangeDeleted}}, nil
+			},
+		}
+	}
+
+	t.Run("text output explains a refused deployment", func(t *testing.T) {
+		t.Parallel()
+		registry := setupPartialsRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, planGitter(registry), nil, &buf)
+
+		require.NoError(t, mgr.Plan(context.Background(), "prod", formatText))
+		out := buf.String()
+		assert.Contains(t, out, "Schemas to add (1):\n  + domain_plain_1_0_0")
+		assert.Contains(t, out, "~ domain_gone_1_0_0\n      because it has been deleted")
+		assert.Contains(t, out, "because it has been deleted")
+		assert.Contains(t, out, "~ domain_order_1_0_0")
+		assert.Contains(t, out, "because a partial it includes has changed")
+		assert.Contains(t, out, "This deployment would be refused")
+	})
+
+	t.Run("JSON output", func(t *testing.T) {
+		t.Parallel()
+		registry := setupPartialsRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, planGitter(registry), nil, &buf)
+
+		require.NoError(t, mgr.Plan(context.Background(), "prod", formatJSON))
+		assert.Contains(t, buf.String(), `"reason": "partial-changed"`)
+		assert.Contains(t, buf.String(), `"blocked": true`)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+
+		require.NoError(t, mgr.Plan(context.Background(), "prod", formatText))
+		assert.Contains(t, buf.String(), "No changes to deploy")
+	})
+
+	t.Run("invalid environment", func(t *testing.T) {
+		t.Parallel()
+		mgr := NewCLIManager(logger, setupTestRegistry(t), nil, &MockGitter{}, nil, io.Discard)
+		require.Error(t, mgr.Plan(context.Background(), "invalid", formatText))
+	})
+
+	t.Run("changes cannot be read", func(t *testing.T) {
+		t.Parallel()
+		gitter := &MockGitter{
+			GetSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+				return nil, errors.New("git failed")
+			},
+		}
+		mgr := NewCLIManager(logger, setupTestRegistry(t), nil, gitter, nil, io.Discard)
+		require.EqualError(t, mgr.Plan(context.Background(), "prod", formatText), "git failed")
+	})
+}
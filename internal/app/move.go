@@ -0,0 +1,38 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewMoveCmd returns a new cobra command for moving a schema family to another domain or family name.
+func NewMoveCmd(mgr Manager) *cobra.Command {
+	var copyFamily bool
+
+	cmd := &cobra.Command{
+		Use:   "move [old-domain/family] [new-domain/family]",
+		Short: "Move a schema family to another domain or family name",
+		Long: `
+Move a schema family, relocating its version directories and family.yml file, renaming every
+schema file to its new key, and rewriting every {{ JSM }} and {{ JSMRef }} reference to the
+family in the schemas and partials of the registry.
+
+A schema which has been deployed to an environment which does not allow schema mutation
+cannot be moved, since that would remove it from the environment. Nor can a deployed schema
+which references the family be rewritten. In that case the move is refused.
+
+With --copy, the family is copied to the new location instead, creating new versions there
+which have never been deployed. The deployed versions, and the references to them, are left
+in place.`,
+		Args: cobra.ExactArgs(2),
+		Example: `
+  jsm move "customer/b2c-customer" "crm/customer/b2c-customer"
+  jsm move "customer/b2c-customer" "crm/customer/b2c-customer" --copy`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mgr.Move(cmd.Context(), args[0], args[1], copyFamily)
+		},
+	}
+
+	cmd.Flags().BoolVar(&copyFamily, "copy", false,
+		"Copy the family to create new versions at the new location, leaving the deployed versions in place")
+	return cmd
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func TestNewMoveCmd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("move", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Move", mock.Anything, "old/family", "new/family", false).Return(nil)
+
+		cmd := NewMoveCmd(m)
+		cmd.SetArgs([]string{"old/family", "new/family"})
+		require.NoError(t, cmd.Execute())
+		m.AssertExpectations(t)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Move", mock.Anything, "old/family", "new/family", true).Return(errors.New("move failed"))
+
+		cmd := NewMoveCmd(m)
+		cmd.SetArgs([]string{"old/family", "new/family", "--copy"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.ErrorContains(t, cmd.Execute(), "move failed")
+	})
+
+	t.Run("missing destination", func(t *testing.T) {
+		t.Parallel()
+		cmd := NewMoveCmd(&MockManager{})
+		cmd.SetArgs([]string{"old/family"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.Error(t, cmd.Execute())
+	})
+}
+
+func TestCLIManager_Move(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// newRegistry returns a registry holding a schema family, and a schema which references it.
+	newRegistry := func(t *testing.T) *schema.Registry {
+		t.Helper()
+		registry := setupTestRegistry(t)
+		for k, content := range map[schema.Key]string{
+			"old_family_1_0_0": `{}`,
+			"other_user_1_0_0": `{"$ref": "{{ JSM "old_family_1_0_0" }}"}`,
+		} {
+			s := schema.New(k, registry)
+			require.NoError(t, os.MkdirAll(s.Path(schema.HomeDir), 0o755))
+			require.NoError(t, os.WriteFile(s.Path(schema.FilePath), []byte(content), 0o600))
+		}
+		return registry
+	}
+
+	t.Run("move", func(t *testing.T) {
+		t.Parallel()
+		registry := newRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+
+		require.NoError(t, mgr.Move(context.Background(), "old/family", "new/family", false))
+		assert.Contains(t, buf.String(), "🚚 Moved old/family to new/family")
+		assert.Contains(t, buf.String(), "old_family_1_0_0 -> new_family_1_0_0")
+		assert.Contains(t, buf.String(), "Rewrote the references in 1 files")
+		assert.FileExists(t, schema.New("new_family_1_0_0", registry).Path(schema.FilePath))
+		assert.NoDirExists(t, filepath.Join(registry.RootDirectory(), "old"))
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		t.Parallel()
+		registry := newRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+
+		require.NoError(t, mgr.Move(context.Background(), "old/family", "new/family", true))
+		assert.Contains(t, buf.String(), "📋 Copied old/family to new/family")
+		assert.NotContains(t, buf.String(), "Rewrote")
+		assert.FileExists(t, schema.New("old_family_1_0_0", registry).Path(schema.FilePath))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mgr := NewCLIManager(logger, newRegistry(t), nil, &MockGitter{}, nil, io.Discard)
+		var target *schema.FamilyExistsError
+		require.ErrorAs(t, mgr.Move(context.Background(), "old/family", "other/user", false), &target)
+	})
+}
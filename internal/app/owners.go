@@ -0,0 +1,49 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// NewOwnersCmd returns a new cobra command for reporting the owners of schema families.
+func NewOwnersCmd(mgr Manager) *cobra.Command {
+	var codeOwners bool
+
+	cmd := &cobra.Command{
+		Use:   "owners [target]",
+		Short: "Show the owners of schema families",
+		Long: `
+Report the ownership details recorded in the family.yml file of each targeted schema family.
+If no target is given, every family in the registry is reported.
+
+With --codeowners, a GitHub CODEOWNERS file is written instead, assigning each family
+directory to the codeOwners listed in its family.yml file.`,
+		Args: cobra.MaximumNArgs(1),
+		Example: `
+  jsm owners
+  jsm owners "domain/family"
+  jsm owners "domain_family_1_0_0" -o json
+  jsm owners --codeowners > .github/CODEOWNERS`,
+	}
+
+	outputVal := formatValue(formatText)
+	cmd.Flags().VarP(&outputVal, "output", "o", "Output format (text, json)")
+	cmd.Flags().BoolVar(&codeOwners, "codeowners", false, "Output a GitHub CODEOWNERS file")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		arg := schema.AllArg
+		if len(args) > 0 {
+			arg = args[0]
+		}
+
+		target, err := schema.NewTargetResolver(mgr.Registry(), arg).Resolve()
+		if err != nil {
+			return err
+		}
+
+		return mgr.Owners(cmd.Context(), target, string(outputVal), codeOwners)
+	}
+
+	return cmd
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+func TestNewOwnersCmd(t *testing.T) {
+	t.Parallel()
+
+	key := schema.Key("domain_family_1_0_0")
+
+	tests := []struct {
+		name      string
+		args      []string
+		setupMock func(m *MockManager)
+		wantErr   bool
+	}{
+		{
+			name: "No target reports all families",
+			args: []string{},
+			setupMock: func(m *MockManager) {
+				m.On("Owners", mock.Anything, mock.MatchedBy(func(rt schema.ResolvedTarget) bool {
+					return rt.Scope != nil && *rt.Scope == ""
+				}), "text", false).Return(nil)
+			},
+		},
+		{
+			name: "Key target with JSON output",
+			args: []string{"domain_family_1_0_0", "-o", "json"},
+			setupMock: func(m *MockManager) {
+				m.On("Owners", mock.Anything, mock.MatchedBy(func(rt schema.ResolvedTarget) bool {
+					return rt.Key != nil && *rt.Key == key
+				}), "json", false).Return(nil)
+			},
+		},
+		{
+			name: "Scope target with CODEOWNERS output",
+			args: []string{"domain", "--codeowners"},
+			setupMock: func(m *MockManager) {
+				m.On("Owners", mock.Anything, mock.MatchedBy(func(rt schema.ResolvedTarget) bool {
+					return rt.Scope != nil && *rt.Scope == "domain"
+				}), "text", true).Return(nil)
+			},
+		},
+		{
+			name:    "Invalid target",
+			args:    []string{"!!"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid output format",
+			args:    []string{"-o", "yaml"},
+			wantErr: true,
+		},
+		{
+			name: "Manager error",
+			args: []string{},
+			setupMock: func(m *MockManager) {
+				m.On("Owners", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("boom"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := &MockManager{registry: setupTestRegistry(t)}
+			if tt.setupMock != nil {
+				tt.setupMock(m)
+			}
+
+			cmd := NewOwnersCmd(m)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			err := cmd.Execute()
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+// setupOwnersTestRegistry creates a registry using a real compiler, with two families in domain-a,
+// only one of which has a family.yml file.
+func setupOwnersTestRegistry(t *testing.T) *schema.Registry {
+	t.Helper()
+	regDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(regDir, config.JsmRegistryConfigFile), []byte(testConfig), 0o600))
+	registry, err := schema.NewRegistry(regDir, validator.NewSanthoshCompiler(), fsh.NewPathResolver(),
+		fsh.NewEnvProvider())
+	require.NoError(t, err)
+
+	for _, k := range []schema.Key{"domain-a_family-a_1_0_0", "domain-a_family-b_1_0_0"} {
+		s := schema.New(k, registry)
+		require.NoError(t, os.MkdirAll(s.Path(schema.HomeDir), 0o755))
+		require.NoError(t, os.WriteFile(s.Path(schema.FilePath), []byte("{}"), 0o600))
+	}
+
+	familyYml := "owner: team-a\nslackChannel: \"#team-a\"\ncodeOwners: [\"@myorg/team-a\"]\n"
+	require.NoError(t, os.WriteFile(
+		filepath.Join(regDir, "domain-a", "family-a", schema.FamilyMetadataFile), []byte(familyYml), 0o600))
+
+	return registry
+}
+
+func TestCLIManager_Owners(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("text output for all families", func(t *testing.T) {
+		t.Parallel()
+		registry := setupOwnersTestRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+
+		scope := schema.SearchScope("")
+		err := mgr.Owners(context.Background(), schema.ResolvedTarget{Scope: &scope}, "text", false)
+		require.NoError(t, err)
+
+		out := buf.String()
+		assert.Contains(t, out, "domain-a/family-a")
+		assert.Contains(t, out, "team-a")
+		assert.Contains(t, out, "#team-a")
+		assert.Contains(t, out, "domain-a/family-b")
+	})
+
+	t.Run("JSON output for a key reports its family", func(t *testing.T) {
+		t.Parallel()
+		registry := setupOwnersTestRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+
+		key := schema.Key("domain-a_family-a_1_0_0")
+		err := mgr.Owners(context.Background(), schema.ResolvedTarget{Key: &key}, "json", false)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `[{"family": "domain-a/family-a", "metadata": {
+			"owner": "team-a", "slackChannel": "#team-a", "codeOwners": ["@myorg/team-a"]}}]`, buf.String())
+	})
+
+	t.Run("CODEOWNERS output", func(t *testing.T) {
+		t.Parallel()
+		registry := setupOwnersTestRegistry(t)
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, &buf)
+
+		scope := schema.SearchScope("domain-a")
+		err := mgr.Owners(context.Background(), schema.ResolvedTarget{Scope: &scope}, "text", true)
+		require.NoError(t, err)
+
+		out := buf.String()
+		assert.Contains(t, out, "/domain-a/family-a/ @myorg/team-a\n")
+		assert.NotContains(t, out, "family-b")
+	})
+
+	t.Run("no target", func(t *testing.T) {
+		t.Parallel()
+		registry := setupOwnersTestRegistry(t)
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, io.Discard)
+
+		err := mgr.Owners(context.Background(), schema.ResolvedTarget{}, "text", false)
+		require.ErrorAs(t, err, new(*schema.NoSchemaTargetsError))
+	})
+
+	t.Run("invalid family metadata", func(t *testing.T) {
+		t.Parallel()
+		registry := setupOwnersTestRegistry(t)
+		require.NoError(t, os.WriteFile(
+			filepath.Join(registry.RootDirectory(), "domain-a", "family-b", schema.FamilyMetadataFile),
+			[]byte("owner: \"\"\n"), 0o600))
+		mgr := NewCLIManager(logger, registry, nil, &MockGitter{}, nil, io.Discard)
+
+		scope := schema.SearchScope("")
+		err := mgr.Owners(context.Background(), schema.ResolvedTarget{Scope: &scope}, "text", false)
+		require.ErrorAs(t, err, new(*schema.InvalidFamilyMetadataError))
+	})
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+// NewPlanCmd creates a new plan command.
+func NewPlanCmd(m Manager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan [environment]",
+		Short: "Show what deploying the current changes to an environment would do",
+		Long: `
+Compare the registry with the latest deployment anchor for the given environment, and
+report what deploying it would do, without changing anything.
+
+The schemas which would be added are listed, followed by the deployed schemas which
+would be changed and why: their source has been modified or deleted, a partial they
+include has changed, or a version range they reference now resolves to a new version.
+Each schema is shown with its visibility, canonical ID and any dependencies which have
+been added or removed.
+
+If the environment does not permit schema mutation and deployed schemas would be changed,
+the plan explains why check-changes would refuse the deployment. The plan itself always
+succeeds, so use check-changes to gate a pipeline.
+
+Use -o json to produce output for a bot which comments on pull requests.`,
+		Example: `
+  # Preview a production deployment
+  jsm plan prod
+
+  # Produce a plan for a pull request comment
+  jsm plan prod -o json`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	outputVal := formatValue(formatText)
+	cmd.Flags().VarP(&outputVal, "output", "o", "Output format (text, json)")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return m.Plan(cmd.Context(), config.Env(args[0]), string(outputVal))
+	}
+
+	return cmd
+}
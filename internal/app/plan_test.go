@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+func TestNewPlanCmd(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		args      []string
+		setupMock func(m *MockManager)
+		wantErr   string
+	}{
+		{
+			name: "text output by default",
+			args: []string{"prod"},
+			setupMock: func(m *MockManager) {
+				m.On("Plan", mock.Anything, config.Env("prod"), "text").Return(nil)
+			},
+		},
+		{
+			name: "JSON output",
+			args: []string{"prod", "-o", "json"},
+			setupMock: func(m *MockManager) {
+				m.On("Plan", mock.Anything, config.Env("prod"), "json").Return(nil)
+			},
+		},
+		{
+			name: "manager error",
+			args: []string{"prod"},
+			setupMock: func(m *MockManager) {
+				m.On("Plan", mock.Anything, config.Env("prod"), "text").Return(errors.New("plan failed"))
+			},
+			wantErr: "plan failed",
+		},
+		{
+			name:    "invalid output format",
+			args:    []string{"prod", "-o", "yaml"},
+			wantErr: "invalid argument",
+		},
+		{
+			name:    "requires an environment",
+			args:    []string{},
+			wantErr: "accepts 1 arg(s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := &MockManager{}
+			if tt.setupMock != nil {
+				tt.setupMock(m)
+			}
+			cmd := NewPlanCmd(m)
+			assert.Equal(t, "plan [environment]", cmd.Use)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+// NewPromoteCmd returns a new cobra command for promoting schemas from one environment to another.
+func NewPromoteCmd(mgr Manager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "promote [from environment] [to environment]",
+		Short: "Build the schemas deployed to one environment for the next",
+		Long: `
+Build a distribution for the [to environment] of the schemas which have been deployed to the
+[from environment], but not yet to the [to environment]. These are the schemas which changed
+between the latest deployment tags of the two environments.
+
+The schemas are built as they were at the latest deployment to the [from environment], so later
+changes which have not been deployed there are not promoted. They must first pass their tests at
+that revision. If the [to environment] does not permit schema mutation, promoting changes to
+schemas already deployed there is refused.
+
+If a promotionOrder is configured, schemas may only be promoted to an environment from the stage
+before it. Once the distribution has been deployed, record the deployment with tag-deployment,
+or use publish to do both.`,
+		Args: cobra.ExactArgs(2),
+		Example: `
+  jsm promote dev prod`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mgr.Promote(cmd.Context(), config.Env(args[0]), config.Env(args[1]))
+		},
+	}
+
+	return cmd
+}
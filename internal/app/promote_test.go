@@ -0,0 +1,261 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func TestNewPromoteCmd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Promote", mock.Anything, config.Env("dev"), config.Env("prod")).Return(nil)
+
+		cmd := NewPromoteCmd(m)
+		cmd.SetArgs([]string{"dev", "prod"})
+		require.NoError(t, cmd.Execute())
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Promote", mock.Anything, config.Env("dev"), config.Env("prod")).Return(errors.New("promote failed"))
+
+		cmd := NewPromoteCmd(m)
+		cmd.SetArgs([]string{"dev", "prod"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.ErrorContains(t, cmd.Execute(), "promote failed")
+	})
+
+	t.Run("one environment", func(t *testing.T) {
+		t.Parallel()
+		cmd := NewPromoteCmd(&MockManager{})
+		cmd.SetArgs([]string{"prod"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.Error(t, cmd.Execute())
+	})
+}
+
+const promotionTestConfig = `
+environments:
+  dev:
+    privateUrlRoot: "https://dev.json-schemas.internal.myorg.io/"
+    publicUrlRoot: "https://dev.json-schemas.myorg.io/"
+    allowSchemaMutation: true
+  prod:
+    privateUrlRoot: "https://json-schemas.internal.myorg.io/"
+    publicUrlRoot: "https://json-schemas.myorg.io/"
+    isProduction: true
+promotionOrder: [dev, prod]
+`
+
+// promotionRepo is a git repository holding a registry with dev and prod environments.
+type promotionRepo struct {
+	t        *testing.T
+	dir      string
+	registry *schema.Registry
+}
+
+// newPromotionRepo creates a git repository holding a registry, and records a deployment of it to prod.
+func newPromotionRepo(t *testing.T) *promotionRepo {
+	t.Helper()
+	pr := &promotionRepo{t: t, dir: t.TempDir()}
+	regDir := filepath.Join(pr.dir, "registry")
+	require.NoError(t, os.MkdirAll(regDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(regDir, config.JsmRegistryConfigFile),
+		[]byte(promotionTestConfig), 0o600))
+
+	pr.git("init")
+	pr.git("config", "user.email", "t@t.com")
+	pr.git("config", "user.name", "t")
+	pr.git("config", "tag.gpgSign", "false")
+
+	var err error
+	pr.registry, err = schema.NewRegistry(regDir, &mockCompiler{}, fsh.NewPathResolver(), fsh.NewEnvProvider())
+	require.NoError(t, err)
+	pr.commit("domain_a_1_0_0")
+	pr.git("tag", "jsm-deploy/prod/20260101-090000")
+	return pr
+}
+
+func (pr *promotionRepo) git(args ...string) {
+	pr.t.Helper()
+	out, err := exec.CommandContext(context.Background(), "git", append([]string{"-C", pr.dir}, args...)...).
+		CombinedOutput()
+	require.NoError(pr.t, err, string(out))
+}
+
+// commit adds a schema with a passing test document, and commits it. Git does not track empty directories,
+// so the fail directory holds a placeholder.
+func (pr *promotionRepo) commit(k schema.Key) {
+	pr.t.Helper()
+	s := schema.New(k, pr.registry)
+	home := s.Path(schema.HomeDir)
+	require.NoError(pr.t, os.MkdirAll(filepath.Join(home, "pass"), 0o755))
+	require.NoError(pr.t, os.MkdirAll(filepath.Join(home, "fail"), 0o755))
+	require.NoError(pr.t, os.WriteFile(s.Path(schema.FilePath), []byte(`{"type": "object"}`), 0o600))
+	require.NoError(pr.t, os.WriteFile(filepath.Join(home, "pass", "valid.json"), []byte(`{}`), 0o600))
+	require.NoError(pr.t, os.WriteFile(filepath.Join(home, "fail", ".gitkeep"), nil, 0o600))
+	pr.git("add", ".")
+	pr.git("commit", "-m", string(k))
+}
+
+func (pr *promotionRepo) manager(out io.Writer) (*CLIManager, schema.DistBuilder) {
+	pr.t.Helper()
+	cfg, err := pr.registry.Config()
+	require.NoError(pr.t, err)
+	gitter := repo.NewCLIGitter(cfg, fsh.NewPathResolver(), pr.dir)
+	builder, err := schema.NewFSDistBuilder(context.Background(), pr.registry, cfg, gitter, "dist")
+	require.NoError(pr.t, err)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewCLIManager(logger, pr.registry, nil, gitter, builder, out), builder
+}
+
+func TestCLIManager_Promote(t *testing.T) {
+	t.Parallel()
+
+	t.Run("builds the schemas deployed to the previous stage", func(t *testing.T) {
+		t.Parallel()
+		pr := newPromotionRepo(t)
+		pr.commit("domain_b_1_0_0")
+		pr.git("tag", "jsm-deploy/dev/20260102-090000")
+		pr.commit("domain_c_1_0_0")
+
+		var buf bytes.Buffer
+		mgr, builder := pr.manager(&buf)
+		require.NoError(t, mgr.Promote(context.Background(), "dev", "prod"))
+		assert.Contains(t, buf.String(), "Successfully built 1 schemas promoted from dev (jsm-deploy/dev/20260102-090000)")
+
+		privateDir := filepath.Join(builder.DistDir("prod"), "private")
+		assert.FileExists(t, filepath.Join(privateDir, "domain_b_1_0_0.schema.json"))
+		assert.NoFileExists(t, filepath.Join(privateDir, "domain_c_1_0_0.schema.json"))
+	})
+
+	t.Run("nothing to promote", func(t *testing.T) {
+		t.Parallel()
+		pr := newPromotionRepo(t)
+		pr.git("tag", "jsm-deploy/dev/20260102-090000")
+
+		var buf bytes.Buffer
+		mgr, _ := pr.manager(&buf)
+		require.NoError(t, mgr.Promote(context.Background(), "dev", "prod"))
+		assert.Equal(t, "No schemas to promote from dev to prod\n", buf.String())
+	})
+
+	t.Run("changes to deployed schemas are refused", func(t *testing.T) {
+		t.Parallel()
+		pr := newPromotionRepo(t)
+		s := schema.New("domain_a_1_0_0", pr.registry)
+		require.NoError(t, os.WriteFile(s.Path(schema.FilePath), []byte(`{"type": "string"}`), 0o600))
+		pr.git("commit", "-am", "mutate")
+		pr.git("tag", "jsm-deploy/dev/20260102-090000")
+
+		mgr, _ := pr.manager(io.Discard)
+		var target *schema.ChangedDeployedSchemasError
+		require.ErrorAs(t, mgr.Promote(context.Background(), "dev", "prod"), &target)
+		assert.Equal(t, []string{s.Path(schema.FilePath)}, target.Paths)
+	})
+
+	t.Run("failing tests are refused", func(t *testing.T) {
+		t.Parallel()
+		pr := newPromotionRepo(t)
+		pr.commit("domain_b_1_0_0")
+		// Every document is valid against the mock compiler's schemas, so a fail test always fails.
+		home := schema.New("domain_b_1_0_0", pr.registry).Path(schema.HomeDir)
+		require.NoError(t, os.WriteFile(filepath.Join(home, "fail", "invalid.json"), []byte(`{}`), 0o600))
+		pr.git("add", ".")
+		pr.git("commit", "-m", "fail test")
+		pr.git("tag", "jsm-deploy/dev/20260102-090000")
+
+		var buf bytes.Buffer
+		mgr, _ := pr.manager(&buf)
+		var target *schema.PromotionTestsFailedError
+		require.ErrorAs(t, mgr.Promote(context.Background(), "dev", "prod"), &target)
+		assert.Contains(t, buf.String(), "invalid.json")
+	})
+
+	t.Run("skipped stage", func(t *testing.T) {
+		t.Parallel()
+		pr := newPromotionRepo(t)
+		mgr, _ := pr.manager(io.Discard)
+
+		var target *config.InvalidPromotionError
+		require.ErrorAs(t, mgr.Promote(context.Background(), "prod", "dev"), &target)
+	})
+
+	t.Run("no deployments to promote from", func(t *testing.T) {
+		t.Parallel()
+		pr := newPromotionRepo(t)
+		mgr, _ := pr.manager(io.Discard)
+
+		var target *schema.NoDeploymentsError
+		require.ErrorAs(t, mgr.Promote(context.Background(), "dev", "prod"), &target)
+	})
+
+	t.Run("config error", func(t *testing.T) {
+		t.Parallel()
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		mgr := NewCLIManager(logger, &schema.Registry{}, nil, &MockGitter{}, nil, io.Discard)
+		require.Error(t, mgr.Promote(context.Background(), "dev", "prod"))
+	})
+}
+
+func TestCLIManager_BuildDist_PromotionOrder(t *testing.T) {
+	t.Parallel()
+
+	pr := newPromotionRepo(t)
+	pr.commit("domain_b_1_0_0")
+	pr.git("tag", "jsm-deploy/dev/20260102-090000")
+	pr.commit("domain_c_1_0_0")
+	mgr, _ := pr.manager(io.Discard)
+
+	t.Run("schemas not deployed to the previous stage are refused", func(t *testing.T) {
+		t.Parallel()
+		var target *schema.UnpromotedSchemasError
+		require.ErrorAs(t, mgr.BuildDist(context.Background(), "prod", false, false), &target)
+		assert.Equal(t, config.Env("dev"), target.Stage)
+		assert.Equal(t, []schema.Key{"domain_c_1_0_0"}, target.Keys)
+	})
+
+	t.Run("the first stage is not checked", func(t *testing.T) {
+		t.Parallel()
+		dev, _ := pr.manager(io.Discard)
+		dev.distBuilder = &MockDistBuilder{}
+		require.NoError(t, dev.BuildDist(context.Background(), "dev", false, false))
+	})
+
+	t.Run("the check can be skipped", func(t *testing.T) {
+		t.Parallel()
+		skip, _ := pr.manager(io.Discard)
+		var built int
+		skip.distBuilder = &MockDistBuilder{
+			BuildChangedFunc: func(_ context.Context, _ config.Env, _ repo.Revision) (int, error) {
+				built++
+				return 2, nil
+			},
+		}
+		require.NoError(t, skip.BuildDist(context.Background(), "prod", false, true))
+		assert.Equal(t, 1, built)
+	})
+}
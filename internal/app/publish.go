@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+// NewPublishCmd creates a new publish command.
+func NewPublishCmd(m Manager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish [environment]",
+		Short: "Publish a distribution to the store configured for an environment",
+		Long: `
+Upload the distribution built by build-dist for the specified environment from
+[repo root]/dist/[env] to the store configured in the environment's publish settings
+in json-schema-manager-config.yml.
+
+Two stores are supported:
+
+  s3              Each file is uploaded to an Amazon S3, or S3-compatible, bucket. Files which
+                  the bucket already holds with the same SHA-256 digest are skipped. Schemas are
+                  uploaded first and the manifest last, so that consumers never see a manifest
+                  referring to schemas which have not been uploaded yet.
+
+  schemaRegistry  Each schema is registered with a Confluent-compatible Schema Registry, under
+                  a subject derived from its key. Schemas which are already registered are
+                  skipped, and the schemas referenced with {{ JSM }} are registered first, as
+                  references.
+
+Once every file has been uploaded, the deployment is tagged as with tag-deployment.
+If any upload fails, the deployment is not tagged.`,
+		Example: `
+  # Build and publish the schemas changed since the last production deployment
+  jsm build-dist prod
+  jsm publish prod`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return m.Publish(cmd.Context(), config.Env(args[0]))
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+func TestNewPublishCmd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		cmd := NewPublishCmd(m)
+		assert.Equal(t, "publish [environment]", cmd.Use)
+
+		m.On("Publish", mock.Anything, config.Env("prod")).Return(nil)
+		cmd.SetArgs([]string{"prod"})
+		cmd.SetOut(new(bytes.Buffer))
+		require.NoError(t, cmd.Execute())
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		cmd := NewPublishCmd(m)
+
+		m.On("Publish", mock.Anything, config.Env("prod")).Return(errors.New("publish failed"))
+		cmd.SetArgs([]string{"prod"})
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		require.EqualError(t, cmd.Execute(), "publish failed")
+	})
+
+	t.Run("requires an environment", func(t *testing.T) {
+		t.Parallel()
+		cmd := NewPublishCmd(&MockManager{})
+		cmd.SetArgs([]string{})
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		require.Error(t, cmd.Execute())
+	})
+}
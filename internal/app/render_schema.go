@@ -25,6 +25,7 @@ func NewRenderSchemaCmd(mgr Manager) *cobra.Command {
   jsm render-schema "domain_family_1_0_0"
   jsm render-schema -k "domain_family_1_0_0" --env dev
   jsm render-schema "https://js.myorg.com/domain_family_1_0_0.schema.json"
+  jsm render-schema "domain_family_1_0_0" --at jsm-deploy/prod/20260130-120000
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var targetArg string
@@ -69,6 +70,7 @@ func NewRenderSchemaCmd(mgr Manager) *cobra.Command {
 	cmd.Flags().StringVarP(&keyStr, "key", "k", "", "Identify a target schema by its key")
 	cmd.Flags().StringVarP(&idStr, "id", "i", "", "Identify a target schema by its canonical ID")
 	cmd.Flags().StringVarP(&envStr, "env", "e", "", "The environment to use for rendering (defaults to production)")
+	cmd.Flags().String(AtFlagName, "", "Render the schema as it was at the given git revision (tag, branch or hash)")
 
 	return cmd
 }
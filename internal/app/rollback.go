@@ -0,0 +1,43 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+// NewRollbackCmd returns a new cobra command for rolling back the latest deployment to an environment.
+func NewRollbackCmd(mgr Manager) *cobra.Command {
+	var tag bool
+
+	cmd := &cobra.Command{
+		Use:   "rollback [environment]",
+		Short: "Build a distribution which undoes the latest deployment to an environment",
+		Long: `
+Build a distribution for the environment which undoes its latest deployment, by comparing the
+latest deployment tag with the deployment tag before it. Deployments which have already been
+rolled back are skipped, so repeated rollbacks step back through the environment's deployments.
+
+Schemas which the deployment modified or deleted are written to [repo root]/dist/[env] as they
+were at the previous deployment. Schemas which it introduced are listed in the "removed" property
+of the manifest.json, for deployment tooling to remove, together with the deployment rolled back
+("rolledBack") and the deployment restored ("restored").
+
+Publishing the distribution with jsm publish, or recording its deployment with tag-deployment,
+creates a rollback tag named jsm-rollback/[env]/[timestamp], which moves the environment's anchor
+back to the deployment restored. Use the --tag flag to create the rollback tag straight away,
+when the distribution is deployed by other means.`,
+		Args: cobra.ExactArgs(1),
+		Example: `
+  jsm rollback prod
+  jsm rollback prod --tag`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mgr.Rollback(cmd.Context(), config.Env(args[0]), tag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&tag, "tag", false,
+		"Create the rollback tag, moving the environment's anchor back to the deployment restored")
+
+	return cmd
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func TestNewRollbackCmd(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		tag  bool
+	}{
+		{name: "build only", args: []string{"prod"}},
+		{name: "tag", args: []string{"prod", "--tag"}, tag: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := &MockManager{}
+			m.On("Rollback", mock.Anything, config.Env("prod"), tt.tag).Return(nil)
+
+			cmd := NewRollbackCmd(m)
+			cmd.SetArgs(tt.args)
+			require.NoError(t, cmd.Execute())
+			m.AssertExpectations(t)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Rollback", mock.Anything, config.Env("prod"), false).Return(errors.New("rollback failed"))
+
+		cmd := NewRollbackCmd(m)
+		cmd.SetArgs([]string{"prod"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.ErrorContains(t, cmd.Execute(), "rollback failed")
+	})
+}
+
+func TestCLIManager_Rollback(t *testing.T) {
+	t.Parallel()
+
+	// newRollbackRepo creates a repository with two deployments to prod: the first of domain_a, and the
+	// second modifying domain_a and adding domain_b.
+	newRollbackRepo := func(t *testing.T) *promotionRepo {
+		t.Helper()
+		pr := newPromotionRepo(t)
+		a := schema.New("domain_a_1_0_0", pr.registry)
+		require.NoError(t, os.WriteFile(a.Path(schema.FilePath), []byte(`{"type": "string"}`), 0o600))
+		pr.commit("domain_b_1_0_0")
+		pr.git("tag", "jsm-deploy/prod/20260102-090000")
+		return pr
+	}
+
+	t.Run("builds the previous deployment", func(t *testing.T) {
+		t.Parallel()
+		pr := newRollbackRepo(t)
+
+		var buf bytes.Buffer
+		mgr, builder := pr.manager(&buf)
+		require.NoError(t, mgr.Rollback(context.Background(), "prod", false))
+		assert.Equal(t, "↩️  Built rollback of prod from jsm-deploy/prod/20260102-090000 to "+
+			"jsm-deploy/prod/20260101-090000: 1 schemas restored, 1 removed\n  - domain_b_1_0_0\n", buf.String())
+
+		privateDir := filepath.Join(builder.DistDir("prod"), "private")
+		data, err := os.ReadFile(filepath.Join(privateDir, "domain_a_1_0_0.schema.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"object"`)
+		assert.NoFileExists(t, filepath.Join(privateDir, "domain_b_1_0_0.schema.json"))
+	})
+
+	t.Run("tagging moves the anchor back", func(t *testing.T) {
+		t.Parallel()
+		pr := newRollbackRepo(t)
+		origin := t.TempDir()
+		require.NoError(t, exec.CommandContext(context.Background(), "git", "init", "--bare", origin).Run())
+		pr.git("remote", "add", "origin", origin)
+
+		var buf bytes.Buffer
+		mgr, _ := pr.manager(&buf)
+		require.NoError(t, mgr.Rollback(context.Background(), "prod", true))
+		assert.Contains(t, buf.String(), "Successfully tagged and pushed rollback: jsm-rollback/prod/")
+
+		anchor, err := mgr.gitter.GetLatestAnchor(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Contains(t, anchor.String(), "jsm-rollback/prod/")
+
+		// A second rollback steps back past the first deployment.
+		buf.Reset()
+		require.NoError(t, mgr.Rollback(context.Background(), "prod", false))
+		assert.Contains(t, buf.String(), "from jsm-deploy/prod/20260101-090000 to before its first deployment: "+
+			"0 schemas restored, 1 removed")
+	})
+
+	t.Run("nothing to roll back", func(t *testing.T) {
+		t.Parallel()
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		mgr := NewCLIManager(logger, setupTestRegistry(t), nil, &MockGitter{}, &MockDistBuilder{}, io.Discard)
+
+		var target *schema.NothingToRollBackError
+		require.ErrorAs(t, mgr.Rollback(context.Background(), "prod", false), &target)
+	})
+
+	t.Run("build error", func(t *testing.T) {
+		t.Parallel()
+		pr := newRollbackRepo(t)
+		mgr, _ := pr.manager(io.Discard)
+		mgr.distBuilder = &MockDistBuilder{
+			BuildRollbackFunc: func(context.Context, *schema.Rollback) (int, error) {
+				return 0, errors.New("build failed")
+			},
+		}
+		require.ErrorContains(t, mgr.Rollback(context.Background(), "prod", true), "build failed")
+	})
+
+	t.Run("tag error", func(t *testing.T) {
+		t.Parallel()
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		g := &MockGitter{
+			DeploymentsFunc: func(context.Context, config.Env) ([]repo.Deployment, error) {
+				return []repo.Deployment{{Env: "prod", Tag: "jsm-deploy/prod/1"}}, nil
+			},
+			TagRollbackFunc: func(context.Context, config.Env, repo.Revision, repo.Revision) (string, error) {
+				return "", errors.New("failed to create git tag")
+			},
+		}
+		mgr := NewCLIManager(logger, setupTestRegistry(t), nil, g, &MockDistBuilder{}, io.Discard)
+		require.ErrorContains(t, mgr.Rollback(context.Background(), "prod", true), "failed to create git tag")
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 
 	"github.com/spf13/cobra"
 
 	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/publish"
 	"github.com/bitshepherds/json-schema-manager/internal/repo"
 	"github.com/bitshepherds/json-schema-manager/internal/schema"
 	"github.com/bitshepherds/json-schema-manager/internal/validator"
@@ -19,6 +21,10 @@ var Version = "dev"
 // CreateRegistryCmdName is the name of the command to create a new registry.
 const CreateRegistryCmdName = "create-registry"
 
+// AtFlagName is the name of the flag which commands that read the registry at a git revision use for the
+// revision.
+const AtFlagName = "at"
+
 // Banner with colour codes and escaped backticks.
 var Banner = "\033[32m" + `
        _______ ____  _   __                 
@@ -61,6 +67,7 @@ func NewRootCmd(
 	var debug bool
 	var noColour bool
 	var registryPath string
+	var gitBackend gitBackendValue
 
 	rootCmd := &cobra.Command{
 		Use:           "jsm",
@@ -101,9 +108,19 @@ func NewRootCmd(
 				logger.Warn("logging to file disabled", "error", err)
 			}
 
-			tester := schema.NewTester(registry)
 			cfg, _ := registry.Config()
-			gitter := repo.NewCLIGitter(cfg, pathResolver, "")
+			if cfg != nil {
+				if err = cfg.Git.ValidateBackend(gitBackend.backend(cfg)); err != nil {
+					return err
+				}
+			}
+			gitter := repo.NewGitter(gitBackend.backend(cfg), cfg, pathResolver, "")
+			registry, err = registryAtRevision(cmd, registry, gitter, compiler, pathResolver, envProvider)
+			if err != nil {
+				return err
+			}
+
+			tester := schema.NewTester(registry)
 			distBuilder, err := schema.NewFSDistBuilder(cmd.Context(), registry, cfg, gitter, "dist")
 			if err != nil {
 				return fmt.Errorf("failed to initialise distribution builder: %w", err)
@@ -111,6 +128,7 @@ func NewRootCmd(
 
 			// 3. Hydrate the Lazy Wrapper
 			realMgr := NewCLIManager(logger, registry, tester, gitter, distBuilder, stdout)
+			realMgr.SetPublisherFactory(publish.NewFactory(envProvider, http.DefaultClient))
 			lazy.SetInner(realMgr)
 
 			return nil
@@ -123,6 +141,7 @@ func NewRootCmd(
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&registryPath, "registry", "r", "", "path to registry (overrides env/config)")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
+	rootCmd.PersistentFlags().Var(&gitBackend, "git-backend", "Git implementation to use (cli, go); overrides config")
 
 	rootCmd.PersistentFlags().BoolVarP(&noColour, "nocolour", "c", false, "Disable colour in output")
 	// Support alternate spellings
@@ -141,12 +160,48 @@ func NewRootCmd(
 	rootCmd.AddCommand(NewCreateSchemaVersionCmd(lazy))
 	rootCmd.AddCommand(NewRenderSchemaCmd(lazy))
 	rootCmd.AddCommand(NewCheckChangesCmd(lazy))
+	rootCmd.AddCommand(NewPlanCmd(lazy))
 	rootCmd.AddCommand(NewTagDeploymentCmd(lazy))
+	rootCmd.AddCommand(NewHistoryCmd(lazy))
 	rootCmd.AddCommand(NewBuildDistCmd(lazy))
+	rootCmd.AddCommand(NewPromoteCmd(lazy))
+	rootCmd.AddCommand(NewRollbackCmd(lazy))
+	rootCmd.AddCommand(NewPublishCmd(lazy))
+	rootCmd.AddCommand(NewOwnersCmd(lazy))
+	rootCmd.AddCommand(NewVendorCmd(lazy))
+	rootCmd.AddCommand(NewDoctorCmd(lazy))
+	rootCmd.AddCommand(NewMoveCmd(lazy))
 
 	return rootCmd
 }
 
+// registryAtRevision returns a registry holding the schemas of the given registry as they were at the git
+// revision given by the command's --at flag. If the command has no --at flag, or it is not set, the given
+// registry is returned.
+func registryAtRevision(
+	cmd *cobra.Command,
+	registry *schema.Registry,
+	gitter repo.Gitter,
+	compiler validator.Compiler,
+	pathResolver fsh.PathResolver,
+	envProvider fsh.EnvProvider,
+) (*schema.Registry, error) {
+	at, _ := cmd.Flags().GetString(AtFlagName)
+	if at == "" {
+		return registry, nil
+	}
+
+	fsys, err := gitter.RevisionFS(cmd.Context(), repo.Revision(at), registry.RootDirectory())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry at %s: %w", at, err)
+	}
+	revRegistry, err := schema.NewRegistryFS(registry.RootDirectory(), fsys, compiler, pathResolver, envProvider)
+	if err != nil {
+		return nil, fmt.Errorf("registry initialisation at %s failed: %w", at, err)
+	}
+	return revRegistry, nil
+}
+
 // isCompletionCommand returns true if the command or any of its parents is the "completion" command.
 func isCompletionCommand(cmd *cobra.Command) bool {
 	for c := cmd; c != nil; c = c.Parent() {
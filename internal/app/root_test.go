@@ -2,14 +2,20 @@ package app
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io/fs"
 	"log/slog"
 	"testing"
+	"testing/fstest"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bitshepherds/json-schema-manager/internal/config"
 	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
 	"github.com/bitshepherds/json-schema-manager/internal/schema"
 )
 
@@ -108,3 +114,72 @@ func TestRootCmd(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestRegistryAtRevision(t *testing.T) {
+	t.Parallel()
+
+	newCmd := func(t *testing.T, at string) *cobra.Command {
+		t.Helper()
+		cmd := &cobra.Command{}
+		cmd.SetContext(context.Background())
+		cmd.Flags().String(AtFlagName, "", "")
+		if at != "" {
+			require.NoError(t, cmd.Flags().Set(AtFlagName, at))
+		}
+		return cmd
+	}
+	pathResolver := fsh.NewPathResolver()
+	envProvider := fsh.NewEnvProvider()
+
+	t.Run("no revision", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		gitter := &MockGitter{RevisionFSFunc: func(_ context.Context, _ repo.Revision, _ string) (fs.FS, error) {
+			return nil, errors.New("should not be called")
+		}}
+
+		got, err := registryAtRevision(newCmd(t, ""), registry, gitter, &mockCompiler{}, pathResolver, envProvider)
+		require.NoError(t, err)
+		assert.Same(t, registry, got)
+
+		got, err = registryAtRevision(&cobra.Command{}, registry, gitter, &mockCompiler{}, pathResolver, envProvider)
+		require.NoError(t, err)
+		assert.Same(t, registry, got)
+	})
+
+	t.Run("revision", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		gitter := &MockGitter{RevisionFSFunc: func(_ context.Context, rev repo.Revision, dir string) (fs.FS, error) {
+			assert.Equal(t, repo.Revision("v1"), rev)
+			assert.Equal(t, registry.RootDirectory(), dir)
+			return fstest.MapFS{config.JsmRegistryConfigFile: {Data: []byte(testConfig)}}, nil
+		}}
+
+		got, err := registryAtRevision(newCmd(t, "v1"), registry, gitter, &mockCompiler{}, pathResolver, envProvider)
+		require.NoError(t, err)
+		assert.NotSame(t, registry, got)
+		assert.Equal(t, registry.RootDirectory(), got.RootDirectory())
+	})
+
+	t.Run("revision cannot be read", func(t *testing.T) {
+		t.Parallel()
+		gitter := &MockGitter{RevisionFSFunc: func(_ context.Context, _ repo.Revision, _ string) (fs.FS, error) {
+			return nil, fs.ErrNotExist
+		}}
+
+		_, err := registryAtRevision(newCmd(t, "v1"), setupTestRegistry(t), gitter, &mockCompiler{}, pathResolver,
+			envProvider)
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		assert.ErrorContains(t, err, "failed to read registry at v1")
+	})
+
+	t.Run("registry at revision is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := registryAtRevision(newCmd(t, "v1"), setupTestRegistry(t), &MockGitter{}, &mockCompiler{},
+			pathResolver, envProvider)
+		var target *config.MissingConfigError
+		require.ErrorAs(t, err, &target)
+	})
+}
@@ -16,7 +16,8 @@ func NewTagDeploymentCmd(mgr Manager) *cobra.Command {
 		Long: `
 Create and push an environment-specific git tag to mark a successful deployment of schemas. 
 This tag will be used as the anchor for future 'check-changes' runs to verify that 
-already-deployed schemas are not modified in environments where mutations are forbidden.`,
+already-deployed schemas are not modified in environments where mutations are forbidden.
+The tag message records the key and digest of every schema in the environment's distribution.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"io/fs"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/mock"
 
@@ -45,8 +47,8 @@ func (m *MockManager) WatchValidation(ctx context.Context, target schema.Resolve
 	return args.Error(0)
 }
 
-func (m *MockManager) CreateSchema(domainAndFamilyName string) (schema.Key, error) {
-	args := m.Called(domainAndFamilyName)
+func (m *MockManager) CreateSchema(domainAndFamilyName string, opts schema.ScaffoldOptions) (schema.Key, error) {
+	args := m.Called(domainAndFamilyName, opts)
 	k, _ := args.Get(0).(schema.Key)
 	return k, args.Error(1)
 }
@@ -68,21 +70,77 @@ func (m *MockManager) CheckChanges(ctx context.Context, envName config.Env) erro
 	return args.Error(0)
 }
 
+func (m *MockManager) Plan(ctx context.Context, envName config.Env, format string) error {
+	args := m.Called(ctx, envName, format)
+	return args.Error(0)
+}
+
 func (m *MockManager) TagDeployment(ctx context.Context, envName config.Env) error {
 	args := m.Called(ctx, envName)
 	return args.Error(0)
 }
 
-func (m *MockManager) BuildDist(ctx context.Context, envName config.Env, all bool) error {
-	args := m.Called(ctx, envName, all)
+func (m *MockManager) BuildDist(ctx context.Context, envName config.Env, all bool, skipStages bool) error {
+	args := m.Called(ctx, envName, all, skipStages)
+	return args.Error(0)
+}
+
+func (m *MockManager) Promote(ctx context.Context, from, to config.Env) error {
+	args := m.Called(ctx, from, to)
+	return args.Error(0)
+}
+
+func (m *MockManager) Rollback(ctx context.Context, envName config.Env, tag bool) error {
+	args := m.Called(ctx, envName, tag)
+	return args.Error(0)
+}
+
+func (m *MockManager) Publish(ctx context.Context, envName config.Env) error {
+	args := m.Called(ctx, envName)
+	return args.Error(0)
+}
+
+func (m *MockManager) Owners(ctx context.Context, target schema.ResolvedTarget, format string, codeOwners bool) error {
+	args := m.Called(ctx, target, format, codeOwners)
+	return args.Error(0)
+}
+
+func (m *MockManager) History(
+	ctx context.Context,
+	envName config.Env,
+	target *schema.ResolvedTarget,
+	format string,
+) error {
+	args := m.Called(ctx, envName, target, format)
+	return args.Error(0)
+}
+
+func (m *MockManager) Vendor(ctx context.Context, url, file string) error {
+	args := m.Called(ctx, url, file)
+	return args.Error(0)
+}
+
+func (m *MockManager) Doctor(ctx context.Context, fix bool) error {
+	args := m.Called(ctx, fix)
+	return args.Error(0)
+}
+
+func (m *MockManager) Move(ctx context.Context, from, to string, copyFamily bool) error {
+	args := m.Called(ctx, from, to, copyFamily)
 	return args.Error(0)
 }
 
 // MockGitter is a test mock for the repo.Gitter interface.
 type MockGitter struct {
-	GetLatestAnchorFunc  func(ctx context.Context, env config.Env) (repo.Revision, error)
-	TagDeploymentFunc    func(ctx context.Context, env config.Env) (string, error)
-	GetSchemaChangesFunc func(ctx context.Context, anchor repo.Revision, sourceDir, suffix string) ([]repo.Change, error)
+	GetLatestAnchorFunc   func(ctx context.Context, env config.Env) (repo.Revision, error)
+	TagDeploymentFunc     func(context.Context, config.Env, repo.Revision, []repo.DeployedSchema) (string, error)
+	GetSchemaChangesFunc  func(ctx context.Context, anchor repo.Revision, sourceDir, suffix string) ([]repo.Change, error)
+	GetFileAtRevisionFunc func(ctx context.Context, rev repo.Revision, path string) ([]byte, error)
+	RevisionFSFunc        func(ctx context.Context, rev repo.Revision, dir string) (fs.FS, error)
+	ChangesBetweenFunc    func(ctx context.Context, from, to repo.Revision, dir, suffix string) ([]repo.Change, error)
+	DeploymentsFunc       func(ctx context.Context, env config.Env) ([]repo.Deployment, error)
+	TagRollbackFunc       func(ctx context.Context, env config.Env, rolledBack, restored repo.Revision) (string, error)
+	RollbacksFunc         func(ctx context.Context, env config.Env) ([]repo.Rollback, error)
 }
 
 func (m *MockGitter) GetLatestAnchor(ctx context.Context, env config.Env) (repo.Revision, error) {
@@ -92,9 +150,14 @@ func (m *MockGitter) GetLatestAnchor(ctx context.Context, env config.Env) (repo.
 	return "HEAD", nil
 }
 
-func (m *MockGitter) TagDeploymentSuccess(ctx context.Context, env config.Env) (string, error) {
+func (m *MockGitter) TagDeploymentSuccess(
+	ctx context.Context,
+	env config.Env,
+	at repo.Revision,
+	schemas []repo.DeployedSchema,
+) (string, error) {
 	if m.TagDeploymentFunc != nil {
-		return m.TagDeploymentFunc(ctx, env)
+		return m.TagDeploymentFunc(ctx, env, at, schemas)
 	}
 	return "jsm-deploy/prod/20260130-120000", nil
 }
@@ -109,3 +172,53 @@ func (m *MockGitter) GetSchemaChanges(
 	}
 	return nil, nil
 }
+
+func (m *MockGitter) GetSchemaChangesBetween(
+	ctx context.Context,
+	from, to repo.Revision,
+	sourceDir, suffix string,
+) ([]repo.Change, error) {
+	if m.ChangesBetweenFunc != nil {
+		return m.ChangesBetweenFunc(ctx, from, to, sourceDir, suffix)
+	}
+	return nil, nil
+}
+
+func (m *MockGitter) Deployments(ctx context.Context, env config.Env) ([]repo.Deployment, error) {
+	if m.DeploymentsFunc != nil {
+		return m.DeploymentsFunc(ctx, env)
+	}
+	return nil, nil
+}
+
+func (m *MockGitter) TagRollback(
+	ctx context.Context,
+	env config.Env,
+	rolledBack, restored repo.Revision,
+) (string, error) {
+	if m.TagRollbackFunc != nil {
+		return m.TagRollbackFunc(ctx, env, rolledBack, restored)
+	}
+	return "jsm-rollback/prod/20260130-120000", nil
+}
+
+func (m *MockGitter) Rollbacks(ctx context.Context, env config.Env) ([]repo.Rollback, error) {
+	if m.RollbacksFunc != nil {
+		return m.RollbacksFunc(ctx, env)
+	}
+	return nil, nil
+}
+
+func (m *MockGitter) GetFileAtRevision(ctx context.Context, rev repo.Revision, path string) ([]byte, error) {
+	if m.GetFileAtRevisionFunc != nil {
+		return m.GetFileAtRevisionFunc(ctx, rev, path)
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (m *MockGitter) RevisionFS(ctx context.Context, rev repo.Revision, dir string) (fs.FS, error) {
+	if m.RevisionFSFunc != nil {
+		return m.RevisionFSFunc(ctx, rev, dir)
+	}
+	return fstest.MapFS{}, nil
+}
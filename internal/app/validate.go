@@ -40,7 +40,10 @@ IDENTIFYING MULTIPLE SCHEMAS
   jsm validate "domain/family" - targets all schemas within the given family
 
 ALL SCHEMAS
-  jsm validate all`,
+  jsm validate all
+
+AT A GIT REVISION
+  jsm validate all --at main`,
 	}
 
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed test results")
@@ -64,6 +67,8 @@ ALL SCHEMAS
 		"Skip provider compatibility checks against earlier versions")
 	var watch bool
 	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch for changes and rerun tests")
+	cmd.Flags().String(AtFlagName, "", "Validate the schemas as they were at the given git revision (tag, branch or hash)")
+	cmd.MarkFlagsMutuallyExclusive(AtFlagName, "watch")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		var arg string
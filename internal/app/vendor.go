@@ -0,0 +1,46 @@
+package app
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewVendorCmd returns a new cobra command for managing the vendored copies of schemas which are referenced
+// by $ref but not managed by JSM.
+func NewVendorCmd(mgr Manager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vendor",
+		Short: "Manage vendored copies of external schemas referenced by $ref",
+		Long: `
+Schemas which are not managed by JSM, such as GeoJSON or CloudEvents, can be referenced by $ref
+once a copy of them has been vendored into [registry root]/vendor. Referenced schemas are only
+ever loaded from their vendored copies, so validation never reads the network.
+
+Each vendored copy is listed in vendor/vendor.lock.yml with the URL it is the copy of, and its
+sha256 digest, which is checked whenever the registry is loaded.`,
+	}
+
+	cmd.AddCommand(newVendorAddCmd(mgr))
+	return cmd
+}
+
+// newVendorAddCmd returns a new cobra command for vendoring a schema.
+func newVendorAddCmd(mgr Manager) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add [url] [file]",
+		Short: "Vendor a copy of the schema at a URL",
+		Long: `
+Copy the schema in the file into the registry's vendor directory, as the copy of the schema at
+the URL, and record it in vendor/vendor.lock.yml. The copy is written to
+vendor/[host]/[path of the URL]. Adding a URL again replaces its copy.
+
+The file is typically downloaded from the URL, e.g. with curl, so that it can be reviewed before
+it is committed.`,
+		Args: cobra.ExactArgs(2),
+		Example: `
+  curl -o Point.json https://geojson.org/schema/Point.json
+  jsm vendor add https://geojson.org/schema/Point.json Point.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mgr.Vendor(cmd.Context(), args[0], args[1])
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func TestNewVendorCmd(t *testing.T) {
+	t.Parallel()
+
+	const pointURL = "https://geojson.org/schema/Point.json"
+
+	t.Run("add", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Vendor", mock.Anything, pointURL, "Point.json").Return(nil)
+
+		cmd := NewVendorCmd(m)
+		cmd.SetArgs([]string{"add", pointURL, "Point.json"})
+		require.NoError(t, cmd.Execute())
+		m.AssertExpectations(t)
+	})
+
+	t.Run("add error", func(t *testing.T) {
+		t.Parallel()
+		m := &MockManager{}
+		m.On("Vendor", mock.Anything, pointURL, "Point.json").Return(errors.New("vendor failed"))
+
+		cmd := NewVendorCmd(m)
+		cmd.SetArgs([]string{"add", pointURL, "Point.json"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.ErrorContains(t, cmd.Execute(), "vendor failed")
+	})
+
+	t.Run("add without a file", func(t *testing.T) {
+		t.Parallel()
+		cmd := NewVendorCmd(&MockManager{})
+		cmd.SetArgs([]string{"add", pointURL})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.Error(t, cmd.Execute())
+	})
+}
+
+func TestCLIManager_Vendor(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		registry := setupTestRegistry(t)
+		src := filepath.Join(t.TempDir(), "Point.json")
+		require.NoError(t, os.WriteFile(src, []byte(`{"type": "object"}`), 0o600))
+
+		var buf bytes.Buffer
+		mgr := NewCLIManager(logger, registry, nil, nil, nil, &buf)
+		require.NoError(t, mgr.Vendor(context.Background(), "https://geojson.org/schema/Point.json", src))
+		assert.Contains(t, buf.String(),
+			"Vendored https://geojson.org/schema/Point.json as vendor/geojson.org/schema/Point.json")
+		assert.FileExists(t, filepath.Join(registry.RootDirectory(), schema.VendorDir, schema.VendorLockFile))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mgr := NewCLIManager(logger, setupTestRegistry(t), nil, nil, nil, io.Discard)
+		var target *schema.InvalidVendorURLError
+		require.ErrorAs(t, mgr.Vendor(context.Background(), "not a url", "Point.json"), &target)
+	})
+}
@@ -2,11 +2,15 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
@@ -33,6 +37,43 @@ const DefaultConfigContent = `# Public Schema Registry Configuration
 # - http://json-schema.org/draft/2020-12/schema
 defaultJsonSchemaVersion: "http://json-schema.org/draft-07/schema#"
 
+# GIT CONFIGURATION
+#
+# By default, JSM runs the git binary to find deployment tags and changed schemas, and records each
+# successful deployment with an unsigned tag named jsm-deploy/<env>/<UTC timestamp>, pushed to origin.
+# jsm rollback records the rollback of a deployment with a tag named jsm-rollback/<env>/<UTC timestamp>.
+# Uncomment to change these defaults. The go backend is a built-in pure Go git implementation, for CI
+# containers which do not have git installed, but it cannot sign tags.
+# git:
+#   backend: "go"
+#   remote: "origin"
+#   deployTags:
+#     prefix: "jsm-deploy"
+#     rollbackPrefix: "jsm-rollback"
+#     timestampFormat: "20060102-150405"   # A Go time layout, which must include the seconds
+#     sign: "ssh"                          # gpg or ssh
+#     signingKey: "~/.ssh/deploy.pub"      # Defaults to git's user.signingKey
+
+# PROMOTION ORDER
+#
+# Uncomment to require schemas to be deployed to each environment before the next. build-dist then refuses
+# to build schemas which have not been deployed to the previous stage, and jsm promote <from> <to> builds
+# the schemas deployed to one stage which have not yet been deployed to the next.
+# promotionOrder: ["dev", "prod"]
+
+# EXTERNAL REGISTRIES
+#
+# Uncomment to reference the schemas of other teams' registries with {{ JSM "<name>:<key>" }}, e.g.
+# {{ JSM "payments:payments_refund_1_0_0" }}. Each registry is either a checkout of its repository (path), or
+# a distribution built from it with build-dist --all (dist), which holds a directory per environment. Relative
+# paths are relative to this file. References resolve to the registry's canonical ID for the environment
+# with the same name as the one being rendered.
+# externalRegistries:
+#   payments:
+#     path: "../../payments-schemas/registry"
+#   identity:
+#     dist: "/opt/schemas/identity/dist"
+
 # ENVIRONMENT CONFIGURATION
 #
 # This section defines properties relating to the publication of schemas to an environment.
@@ -51,28 +92,105 @@ environments:
     # You can also use a path for UrlRoots - e.g. "https://internal.example.com/schemas/"
 
     allowSchemaMutation: true # Permits developers to change schemas after publication: DO NOT DO THIS IN PRODUCTION!
+
+    # Uncomment to use environment-specific values in schemas with {{ Var "<name>" }}. Strings are
+    # JSON-escaped without their surrounding quotes, so should be used within a JSON string in the
+    # schema (e.g. "{{ Var "apiHost" }}"). Any other value is inserted as JSON (e.g. "enum": {{ Var "statuses" }}).
+    # variables:
+    #   apiHost: "api.dev.example.com"
+    #   statuses: ["active", "inactive", "test"]
   prod:
     privateUrlRoot: "https://json-schemas.internal.example.com/"
     publicUrlRoot: "https://json-schemas.example.com/"
     isProduction: true # This environment is the production environment.
+
+    # Uncomment to write schemas to the distribution in canonical JSON form (RFC 8785), so that the
+    # output of build-dist does not depend on the whitespace or property order of the source files.
+    # canonicalOutput: true
+
+    # By default, check-changes treats any edit to the source of a deployed schema as a mutation.
+    # Uncomment to treat only edits which change the canonical form of the rendered schema as
+    # mutations, so reformatting or reordering the source of a deployed schema is permitted.
+    # mutationCheck: "rendered"
+
+    # By default, no change to a deployed schema is permitted. Uncomment to permit changes which are
+    # confined to annotations (title, description, examples, $comment and the x- keywords listed in
+    # annotationKeys), such as fixing a typo in a description. Rendered schemas are compared.
+    # mutationPolicy: "annotations-allowed"
+    # annotationKeys: ["x-owner"]
+
+    # Uncomment to upload the distribution built by build-dist to an S3 bucket with jsm publish. Credentials
+    # are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and (optionally) AWS_SESSION_TOKEN. Set endpoint
+    # and pathStyle to use an S3-compatible object store such as MinIO.
+    # publish:
+    #   s3:
+    #     bucket: "json-schemas"
+    #     prefix: "schemas"
+    #     region: "eu-west-2"
+    #     # endpoint: "http://localhost:9000"
+    #     # pathStyle: true
+    # Or, to register each schema with a Confluent-compatible Schema Registry. Basic authentication
+    # credentials are read from SCHEMA_REGISTRY_USERNAME and SCHEMA_REGISTRY_PASSWORD, if set.
+    # publish:
+    #   schemaRegistry:
+    #     url: "https://schema-registry.internal.myorg.io"
+    #     subjectNaming: "major"      # Or "family" or "key"
+    #     compatibility: "BACKWARD"   # Experimental families always use NONE
+
+    # Uncomment to set the values used by {{ Var "<name>" }} in this environment.
+    # variables:
+    #   apiHost: "api.example.com"
+    #   statuses: ["active", "inactive"]
 `
 
 // Env represents a JSM environment name.
 type Env string
 
+// MutationCheck identifies how check-changes decides whether a deployed schema has been mutated.
+type MutationCheck string
+
+const (
+	// MutationCheckSource treats any change to a deployed schema's source file as a mutation. This is the default.
+	MutationCheckSource MutationCheck = "source"
+	// MutationCheckRendered treats a deployed schema as mutated only if the canonical form of its rendered
+	// output for the environment has changed, so cosmetic edits to the source are permitted.
+	MutationCheckRendered MutationCheck = "rendered"
+)
+
+// MutationPolicy identifies which changes to a deployed schema are permitted when schema mutation is not allowed.
+type MutationPolicy string
+
+const (
+	// MutationPolicyStrict permits no changes to a deployed schema. This is the default.
+	MutationPolicyStrict MutationPolicy = "strict"
+	// MutationPolicyAnnotationsAllowed permits changes to a deployed schema which are confined to annotation
+	// keywords (title, description, examples, $comment and the environment's annotationKeys), such as fixing
+	// a typo in a description.
+	MutationPolicyAnnotationsAllowed MutationPolicy = "annotations-allowed"
+)
+
 // EnvConfig contains configuration for a specific JSM environment.
 type EnvConfig struct {
-	PublicURLRoot       string `yaml:"publicUrlRoot"`
-	PrivateURLRoot      string `yaml:"privateUrlRoot"`
-	AllowSchemaMutation bool   `yaml:"allowSchemaMutation"`
-	IsProduction        bool   `yaml:"isProduction"`
-	Env                 Env    // this is set for convenience when the environments are read in.
+	PublicURLRoot       string         `yaml:"publicUrlRoot"`
+	PrivateURLRoot      string         `yaml:"privateUrlRoot"`
+	AllowSchemaMutation bool           `yaml:"allowSchemaMutation"`
+	IsProduction        bool           `yaml:"isProduction"`
+	Variables           map[string]any `yaml:"variables"`       // Values exposed to schema templates via {{ Var "name" }}
+	CanonicalOutput     bool           `yaml:"canonicalOutput"` // Write dist schemas as RFC 8785 canonical JSON
+	MutationCheck       MutationCheck  `yaml:"mutationCheck"`
+	MutationPolicy      MutationPolicy `yaml:"mutationPolicy"`
+	AnnotationKeys      []string       `yaml:"annotationKeys"` // x- keywords treated as annotations by the policy
+	Publish             *PublishConfig `yaml:"publish"`        // Where jsm publish uploads the distribution to
+	Env                 Env            // this is set for convenience when the environments are read in.
 }
 
 // Config represents the root JSM configuration.
 type Config struct {
 	Environments             map[Env]*EnvConfig `yaml:"environments"`
 	DefaultJSONSchemaVersion validator.Draft    `yaml:"defaultJsonSchemaVersion"`
+	Git                      GitConfig          `yaml:"git"`
+	PromotionOrder           []Env              `yaml:"promotionOrder"` // The stages schemas are deployed through
+	ExternalRegistries       ExternalRegistries `yaml:"externalRegistries"`
 	ProductionEnv            Env                // this is set for convenience when the environments are read in.
 }
 
@@ -91,9 +209,58 @@ func (e *EnvConfig) Validate(pathPrefix string) error {
 	if err := validateHTTPURL(fmt.Sprintf("%s.privateUrlRoot", pathPrefix), e.PrivateURLRoot); err != nil {
 		return err
 	}
+
+	switch e.MutationCheck {
+	case "":
+		e.MutationCheck = MutationCheckSource
+	case MutationCheckSource, MutationCheckRendered:
+	default:
+		return &InvalidMutationCheckError{Property: fmt.Sprintf("%s.mutationCheck", pathPrefix), Value: e.MutationCheck}
+	}
+
+	if e.Publish != nil {
+		if err := e.Publish.Validate(fmt.Sprintf("%s.publish", pathPrefix)); err != nil {
+			return err
+		}
+	}
+
+	return e.validateMutationPolicy(pathPrefix)
+}
+
+// validateMutationPolicy validates the mutationPolicy and annotationKeys properties of an EnvConfig.
+func (e *EnvConfig) validateMutationPolicy(pathPrefix string) error {
+	switch e.MutationPolicy {
+	case "":
+		e.MutationPolicy = MutationPolicyStrict
+	case MutationPolicyStrict, MutationPolicyAnnotationsAllowed:
+	default:
+		return &InvalidMutationPolicyError{
+			Property: fmt.Sprintf("%s.mutationPolicy", pathPrefix),
+			Value:    e.MutationPolicy,
+		}
+	}
+
+	for i, k := range e.AnnotationKeys {
+		if !strings.HasPrefix(k, "x-") {
+			return &InvalidAnnotationKeyError{Property: fmt.Sprintf("%s.annotationKeys[%d]", pathPrefix, i), Value: k}
+		}
+	}
 	return nil
 }
 
+// ComparesRenderedOutput returns true if mutations in the environment are detected by comparing rendered
+// schemas, rather than their source files.
+func (e *EnvConfig) ComparesRenderedOutput() bool {
+	return e.MutationCheck == MutationCheckRendered || e.MutationPolicy == MutationPolicyAnnotationsAllowed
+}
+
+// Variable returns the value of the named template variable for the environment.
+// ok is false if the variable is not defined.
+func (e *EnvConfig) Variable(name string) (v any, ok bool) {
+	v, ok = e.Variables[name]
+	return v, ok
+}
+
 // URLRoot returns the URL root for the environment.
 func (e *EnvConfig) URLRoot(isPublic bool) string {
 	if isPublic {
@@ -115,8 +282,27 @@ func New(registryRootDir string, compiler validator.Compiler) (*Config, error) {
 		return nil, err
 	}
 
+	return parse(data, compiler)
+}
+
+// NewFS creates a new Config by reading from the root of fsys, which holds the registry whose root
+// directory is given.
+func NewFS(fsys fs.FS, registryRootDir string, compiler validator.Compiler) (*Config, error) {
+	data, err := fs.ReadFile(fsys, JsmRegistryConfigFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &MissingConfigError{Path: registryRootDir}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(data, compiler)
+}
+
+// parse creates a new Config from the content of a registry configuration file.
+func parse(data []byte, compiler validator.Compiler) (*Config, error) {
 	var config Config
-	if err = yaml.Unmarshal(data, &config); err != nil {
+	if err := yaml.Unmarshal(data, &config); err != nil {
 		// return a wrapped error or the raw yaml error depending on preference.
 		// For now, we wrap it to match previous behaviour partially, but strictly speaking
 		// the yaml library returns nice errors on its own.
@@ -148,6 +334,70 @@ func (c *Config) EnvConfig(env Env) (*EnvConfig, error) {
 	return ec, nil
 }
 
+// Envs returns the names of the configured environments, sorted by name.
+func (c *Config) Envs() []Env {
+	return slices.Sorted(maps.Keys(c.Environments))
+}
+
+// PreviousStage returns the environment which precedes env in the promotion order. ok is false if no
+// promotion order is configured, or env is not in it, or env is its first stage.
+func (c *Config) PreviousStage(env Env) (stage Env, ok bool) {
+	i := slices.Index(c.PromotionOrder, env)
+	if i < 1 {
+		return "", false
+	}
+	return c.PromotionOrder[i-1], true
+}
+
+// ValidatePromotion returns an error if schemas may not be promoted from one environment to another. If a
+// promotion order is configured, schemas may only be promoted to an environment from its previous stage.
+func (c *Config) ValidatePromotion(from, to Env) error {
+	for _, env := range []Env{from, to} {
+		if _, err := c.EnvConfig(env); err != nil {
+			return err
+		}
+	}
+	if from == to {
+		return &InvalidPromotionError{From: from, To: to, Reason: "schemas cannot be promoted to the same environment"}
+	}
+	if len(c.PromotionOrder) == 0 {
+		return nil
+	}
+
+	stage, ok := c.PreviousStage(to)
+	switch {
+	case !ok && slices.Contains(c.PromotionOrder, to):
+		return &InvalidPromotionError{
+			From:   from,
+			To:     to,
+			Reason: fmt.Sprintf("%s is the first stage of the promotion order", to),
+		}
+	case !ok:
+		return &InvalidPromotionError{From: from, To: to, Reason: fmt.Sprintf("%s is not in the promotion order", to)}
+	case stage != from:
+		return &InvalidPromotionError{
+			From:   from,
+			To:     to,
+			Reason: fmt.Sprintf("%s is the previous stage of %s in the promotion order", stage, to),
+		}
+	}
+	return nil
+}
+
+// validatePromotionOrder checks that the promotion order lists configured environments, each at most once.
+func (c *Config) validatePromotionOrder() error {
+	for i, env := range c.PromotionOrder {
+		prop := fmt.Sprintf("promotionOrder[%d]", i)
+		if _, ok := c.Environments[env]; !ok {
+			return &InvalidPromotionOrderError{Property: prop, Value: env, Reason: "It is not a configured environment"}
+		}
+		if slices.Index(c.PromotionOrder, env) != i {
+			return &InvalidPromotionOrderError{Property: prop, Value: env, Reason: "Each environment may only appear once"}
+		}
+	}
+	return nil
+}
+
 // Validate validates the Config.
 func (c *Config) Validate(compiler validator.Compiler) error {
 	if c.DefaultJSONSchemaVersion == "" {
@@ -166,6 +416,18 @@ func (c *Config) Validate(compiler validator.Compiler) error {
 		return &MissingPropertyError{Property: "environments"}
 	}
 
+	if err := c.Git.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.validatePromotionOrder(); err != nil {
+		return err
+	}
+
+	if err := c.ExternalRegistries.Validate(); err != nil {
+		return err
+	}
+
 	prodCount := 0
 	for envName, envCfg := range c.Environments {
 		if err := envCfg.Validate(fmt.Sprintf("environments.%s", envName)); err != nil {
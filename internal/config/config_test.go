@@ -1,9 +1,11 @@
 package config
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -207,6 +209,381 @@ environments:
 	}
 }
 
+func TestNewConfig_Variables(t *testing.T) {
+	t.Parallel()
+
+	regDir := t.TempDir()
+	content := `
+environments:
+  dev:
+    publicUrlRoot: "https://dev.example.com"
+    privateUrlRoot: "https://dev.internal.example.com"
+    variables:
+      host: api.dev.example.com
+      statuses: [active, test]
+      maxItems: 10
+  prod:
+    publicUrlRoot: "https://example.com"
+    privateUrlRoot: "https://internal.example.com"
+    isProduction: true
+`
+	require.NoError(t, os.WriteFile(filepath.Join(regDir, JsmRegistryConfigFile), []byte(content), 0o600))
+
+	cfg, err := New(regDir, &mockCompiler{supported: []validator.Draft{validator.Draft7}})
+	require.NoError(t, err)
+
+	dev, err := cfg.EnvConfig("dev")
+	require.NoError(t, err)
+
+	v, ok := dev.Variable("host")
+	assert.True(t, ok)
+	assert.Equal(t, "api.dev.example.com", v)
+
+	v, ok = dev.Variable("statuses")
+	assert.True(t, ok)
+	assert.Equal(t, []any{"active", "test"}, v)
+
+	v, ok = dev.Variable("maxItems")
+	assert.True(t, ok)
+	assert.Equal(t, 10, v)
+
+	_, ok = dev.Variable("missing")
+	assert.False(t, ok)
+
+	// An environment without variables has none defined
+	_, ok = cfg.ProductionEnvConfig().Variable("host")
+	assert.False(t, ok)
+}
+
+func TestNewConfig_MutationCheck(t *testing.T) {
+	t.Parallel()
+
+	mc := &mockCompiler{supported: []validator.Draft{validator.Draft7}}
+	write := func(t *testing.T, prodExtra string) string {
+		t.Helper()
+		regDir := t.TempDir()
+		content := `
+environments:
+  dev:
+    publicUrlRoot: "https://dev.example.com"
+    privateUrlRoot: "https://dev.internal.example.com"
+  prod:
+    publicUrlRoot: "https://example.com"
+    privateUrlRoot: "https://internal.example.com"
+    isProduction: true
+` + prodExtra
+		require.NoError(t, os.WriteFile(filepath.Join(regDir, JsmRegistryConfigFile), []byte(content), 0o600))
+		return regDir
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := New(write(t, ""), mc)
+		require.NoError(t, err)
+
+		prod := cfg.ProductionEnvConfig()
+		assert.Equal(t, MutationCheckSource, prod.MutationCheck)
+		assert.False(t, prod.CanonicalOutput)
+	})
+
+	t.Run("rendered with canonical output", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := New(write(t, "    mutationCheck: rendered\n    canonicalOutput: true\n"), mc)
+		require.NoError(t, err)
+
+		prod := cfg.ProductionEnvConfig()
+		assert.Equal(t, MutationCheckRendered, prod.MutationCheck)
+		assert.True(t, prod.CanonicalOutput)
+
+		dev, err := cfg.EnvConfig("dev")
+		require.NoError(t, err)
+		assert.Equal(t, MutationCheckSource, dev.MutationCheck)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := New(write(t, "    mutationCheck: semantic\n"), mc)
+		var target *InvalidMutationCheckError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, "environments.prod.mutationCheck", target.Property)
+		assert.EqualError(t, err, "json-schema-manager-config.yml property environments.prod.mutationCheck "+
+			"has invalid value 'semantic'. Supported values are: source, rendered")
+	})
+}
+
+func TestNewConfig_Git(t *testing.T) {
+	t.Parallel()
+
+	mc := &mockCompiler{supported: []validator.Draft{validator.Draft7}}
+	load := func(t *testing.T, extra string) (*Config, error) {
+		t.Helper()
+		regDir := t.TempDir()
+		content := `
+environments:
+  prod:
+    publicUrlRoot: "https://example.com"
+    privateUrlRoot: "https://internal.example.com"
+    isProduction: true
+` + extra
+		require.NoError(t, os.WriteFile(filepath.Join(regDir, JsmRegistryConfigFile), []byte(content), 0o600))
+		return New(regDir, mc)
+	}
+
+	t.Run("defaults to cli", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, GitBackendCLI, cfg.Git.Backend)
+	})
+
+	t.Run("go backend", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, "git:\n  backend: go\n")
+		require.NoError(t, err)
+		assert.Equal(t, GitBackendGo, cfg.Git.Backend)
+	})
+
+	t.Run("backend selected for a command", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, "git:\n  deployTags:\n    sign: ssh\n")
+		require.NoError(t, err)
+		require.NoError(t, cfg.Git.ValidateBackend(GitBackendCLI))
+		var target *InvalidGitPropertyError
+		require.ErrorAs(t, cfg.Git.ValidateBackend(GitBackendGo), &target)
+		assert.Equal(t, "git.deployTags.sign", target.Property)
+	})
+
+	t.Run("invalid backend", func(t *testing.T) {
+		t.Parallel()
+		_, err := load(t, "git:\n  backend: svn\n")
+		var target *InvalidGitBackendError
+		require.ErrorAs(t, err, &target)
+		assert.EqualError(t, err, "json-schema-manager-config.yml property git.backend "+
+			"has invalid value 'svn'. Supported values are: cli, go")
+	})
+
+	t.Run("deployment tag defaults", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultGitRemote, cfg.Git.Remote)
+		assert.Equal(t, DefaultDeployTagPrefix, cfg.Git.DeployTags.Prefix)
+		assert.Equal(t, DefaultRollbackTagPrefix, cfg.Git.DeployTags.RollbackPrefix)
+		assert.Equal(t, DefaultDeployTagTimestampFormat, cfg.Git.DeployTags.TimestampFormat)
+		assert.Equal(t, TagSigningNone, cfg.Git.DeployTags.Sign)
+	})
+
+	t.Run("deployment tags configured", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, `git:
+  remote: upstream
+  deployTags:
+    prefix: releases/schemas
+    rollbackPrefix: releases/rollbacks
+    timestampFormat: "2006-01-02T15.04.05Z"
+    sign: ssh
+    signingKey: ~/.ssh/deploy.pub
+`)
+		require.NoError(t, err)
+		assert.Equal(t, GitConfig{
+			Backend: GitBackendCLI,
+			Remote:  "upstream",
+			DeployTags: DeployTagConfig{
+				Prefix:          "releases/schemas",
+				RollbackPrefix:  "releases/rollbacks",
+				TimestampFormat: "2006-01-02T15.04.05Z",
+				Sign:            TagSigningSSH,
+				SigningKey:      "~/.ssh/deploy.pub",
+			},
+		}, cfg.Git)
+	})
+
+	invalid := []struct {
+		name    string
+		yaml    string
+		message string
+	}{
+		{
+			name:    "remote",
+			yaml:    "git:\n  remote: \"my remote\"\n",
+			message: "property git.remote has invalid value 'my remote'. It is not a valid remote name",
+		},
+		{
+			name:    "prefix",
+			yaml:    "git:\n  deployTags:\n    prefix: \"deploy..tags\"\n",
+			message: "property git.deployTags.prefix has invalid value 'deploy..tags'. It cannot be used in a git tag name",
+		},
+		{
+			name:    "prefix with an empty component",
+			yaml:    "git:\n  deployTags:\n    prefix: \"deploy//tags\"\n",
+			message: "property git.deployTags.prefix has invalid value 'deploy//tags'. It cannot be used in a git tag name",
+		},
+		{
+			name: "rollback prefix",
+			yaml: "git:\n  deployTags:\n    rollbackPrefix: \"roll back\"\n",
+			message: "property git.deployTags.rollbackPrefix has invalid value 'roll back'. " +
+				"It cannot be used in a git tag name",
+		},
+		{
+			name: "rollback prefix the same as the prefix",
+			yaml: "git:\n  deployTags:\n    prefix: releases\n    rollbackPrefix: releases\n",
+			message: "property git.deployTags.rollbackPrefix has invalid value 'releases'. " +
+				"It must differ from git.deployTags.prefix",
+		},
+		{
+			name: "timestamp format with a colon",
+			yaml: "git:\n  deployTags:\n    timestampFormat: \"2006-01-02T15:04:05\"\n",
+			message: "property git.deployTags.timestampFormat has invalid value '2006-01-02T15:04:05'. " +
+				"It cannot be used in a git tag name",
+		},
+		{
+			name: "timestamp format without seconds",
+			yaml: "git:\n  deployTags:\n    timestampFormat: \"20060102-1504\"\n",
+			message: "property git.deployTags.timestampFormat has invalid value '20060102-1504'. " +
+				"It must include the year, month, day, hour, minute and second",
+		},
+		{
+			name:    "signing",
+			yaml:    "git:\n  deployTags:\n    sign: x509\n",
+			message: "property git.deployTags.sign has invalid value 'x509'. Supported values are: gpg, ssh",
+		},
+		{
+			name: "signing with the go backend",
+			yaml: "git:\n  backend: go\n  deployTags:\n    sign: gpg\n",
+			message: "property git.deployTags.sign has invalid value 'gpg'. " +
+				"Tags cannot be signed by the go git backend",
+		},
+		{
+			name: "signing key without signing",
+			yaml: "git:\n  deployTags:\n    signingKey: ABCDEF\n",
+			message: "property git.deployTags.signingKey has invalid value 'ABCDEF'. " +
+				"git.deployTags.sign must be set to use a signing key",
+		},
+	}
+	for _, tt := range invalid {
+		t.Run("invalid "+tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := load(t, tt.yaml)
+			var target *InvalidGitPropertyError
+			require.ErrorAs(t, err, &target)
+			assert.EqualError(t, err, "json-schema-manager-config.yml "+tt.message)
+		})
+	}
+}
+
+func TestNewConfig_MutationPolicy(t *testing.T) {
+	t.Parallel()
+
+	mc := &mockCompiler{supported: []validator.Draft{validator.Draft7}}
+	load := func(t *testing.T, prodExtra string) (*Config, error) {
+		t.Helper()
+		regDir := t.TempDir()
+		content := `
+environments:
+  prod:
+    publicUrlRoot: "https://example.com"
+    privateUrlRoot: "https://internal.example.com"
+    isProduction: true
+` + prodExtra
+		require.NoError(t, os.WriteFile(filepath.Join(regDir, JsmRegistryConfigFile), []byte(content), 0o600))
+		return New(regDir, mc)
+	}
+
+	t.Run("defaults to strict", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, MutationPolicyStrict, cfg.ProductionEnvConfig().MutationPolicy)
+		assert.Empty(t, cfg.ProductionEnvConfig().AnnotationKeys)
+	})
+
+	t.Run("annotations allowed", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, "    mutationPolicy: annotations-allowed\n    annotationKeys: [x-owner, x-docs-url]\n")
+		require.NoError(t, err)
+		prod := cfg.ProductionEnvConfig()
+		assert.Equal(t, MutationPolicyAnnotationsAllowed, prod.MutationPolicy)
+		assert.Equal(t, []string{"x-owner", "x-docs-url"}, prod.AnnotationKeys)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		t.Parallel()
+		_, err := load(t, "    mutationPolicy: lenient\n")
+		var target *InvalidMutationPolicyError
+		require.ErrorAs(t, err, &target)
+		assert.EqualError(t, err, "json-schema-manager-config.yml property environments.prod.mutationPolicy "+
+			"has invalid value 'lenient'. Supported values are: strict, annotations-allowed")
+	})
+
+	t.Run("invalid annotation key", func(t *testing.T) {
+		t.Parallel()
+		_, err := load(t, "    annotationKeys: [x-owner, format]\n")
+		var target *InvalidAnnotationKeyError
+		require.ErrorAs(t, err, &target)
+		assert.EqualError(t, err, "json-schema-manager-config.yml property environments.prod.annotationKeys[1] "+
+			"has invalid value 'format'. Annotation keys must start with 'x-'")
+	})
+}
+
+func TestEnvConfig_ComparesRenderedOutput(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, (&EnvConfig{MutationCheck: MutationCheckSource, MutationPolicy: MutationPolicyStrict}).
+		ComparesRenderedOutput())
+	assert.True(t, (&EnvConfig{MutationCheck: MutationCheckRendered, MutationPolicy: MutationPolicyStrict}).
+		ComparesRenderedOutput())
+	assert.True(t, (&EnvConfig{MutationCheck: MutationCheckSource, MutationPolicy: MutationPolicyAnnotationsAllowed}).
+		ComparesRenderedOutput())
+}
+
+func TestNewFS(t *testing.T) {
+	t.Parallel()
+
+	mc := &mockCompiler{supported: []validator.Draft{validator.Draft7}}
+	content := `
+environments:
+  prod:
+      publicUrlRoot: "https://prod.public.io/"
+      privateUrlRoot: "https://prod.private.io/"
+      isProduction: true
+`
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		fsys := fstest.MapFS{JsmRegistryConfigFile: {Data: []byte(content)}}
+
+		cfg, err := NewFS(fsys, "/registry", mc)
+		require.NoError(t, err)
+		assert.Equal(t, Env("prod"), cfg.ProductionEnvConfig().Env)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewFS(fstest.MapFS{}, "/registry", mc)
+		var target *MissingConfigError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, "/registry", target.Path)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		fsys := fstest.MapFS{JsmRegistryConfigFile: {Data: []byte("invalid: yaml: :")}}
+
+		_, err := NewFS(fsys, "/registry", mc)
+		var target *InvalidYAMLError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("unreadable", func(t *testing.T) {
+		t.Parallel()
+		fsys := fstest.MapFS{JsmRegistryConfigFile: {Mode: fs.ModeDir}}
+
+		_, err := NewFS(fsys, "/registry", mc)
+		require.Error(t, err)
+	})
+}
+
 func TestProductionEnvConfig(t *testing.T) {
 	t.Parallel()
 	tmpDir := t.TempDir()
@@ -294,3 +671,148 @@ func TestEnvConfig(t *testing.T) {
 		assert.EqualError(t, err, "json-schema-manager-config.yml does not define environment 'invalid-env'")
 	})
 }
+
+func TestConfig_Envs(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Environments: map[Env]*EnvConfig{"prod": {}, "dev": {}, "staging": {}}}
+	assert.Equal(t, []Env{"dev", "prod", "staging"}, cfg.Envs())
+}
+
+func TestNewConfig_PromotionOrder(t *testing.T) {
+	t.Parallel()
+
+	mc := &mockCompiler{supported: []validator.Draft{validator.Draft7}}
+	load := func(t *testing.T, extra string) (*Config, error) {
+		t.Helper()
+		regDir := t.TempDir()
+		content := `
+environments:
+  dev:
+    publicUrlRoot: "https://dev.example.com"
+    privateUrlRoot: "https://dev.internal.example.com"
+  prod:
+    publicUrlRoot: "https://example.com"
+    privateUrlRoot: "https://internal.example.com"
+    isProduction: true
+` + extra
+		require.NoError(t, os.WriteFile(filepath.Join(regDir, JsmRegistryConfigFile), []byte(content), 0o600))
+		return New(regDir, mc)
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, "")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.PromotionOrder)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load(t, "promotionOrder: [dev, prod]\n")
+		require.NoError(t, err)
+		assert.Equal(t, []Env{"dev", "prod"}, cfg.PromotionOrder)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		t.Parallel()
+		_, err := load(t, "promotionOrder: [dev, staging, prod]\n")
+		var target *InvalidPromotionOrderError
+		require.ErrorAs(t, err, &target)
+		assert.EqualError(t, err, "json-schema-manager-config.yml property promotionOrder[1] "+
+			"has invalid value 'staging'. It is not a configured environment")
+	})
+
+	t.Run("repeated environment", func(t *testing.T) {
+		t.Parallel()
+		_, err := load(t, "promotionOrder: [dev, prod, dev]\n")
+		var target *InvalidPromotionOrderError
+		require.ErrorAs(t, err, &target)
+		assert.EqualError(t, err, "json-schema-manager-config.yml property promotionOrder[2] "+
+			"has invalid value 'dev'. Each environment may only appear once")
+	})
+}
+
+func TestConfig_PreviousStage(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{PromotionOrder: []Env{"dev", "staging", "prod"}}
+
+	stage, ok := cfg.PreviousStage("prod")
+	assert.True(t, ok)
+	assert.Equal(t, Env("staging"), stage)
+
+	for _, env := range []Env{"dev", "sandbox"} {
+		_, ok = cfg.PreviousStage(env)
+		assert.False(t, ok, env)
+	}
+
+	_, ok = (&Config{}).PreviousStage("prod")
+	assert.False(t, ok)
+}
+
+func TestConfig_ValidatePromotion(t *testing.T) {
+	t.Parallel()
+
+	envs := map[Env]*EnvConfig{"dev": {}, "staging": {}, "prod": {}, "sandbox": {}}
+	ordered := &Config{Environments: envs, PromotionOrder: []Env{"dev", "staging", "prod"}}
+	unordered := &Config{Environments: envs}
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		from    Env
+		to      Env
+		wantErr string
+	}{
+		{name: "previous stage", cfg: ordered, from: "staging", to: "prod"},
+		{name: "any environment without a promotion order", cfg: unordered, from: "dev", to: "prod"},
+		{
+			name:    "skipped stage",
+			cfg:     ordered,
+			from:    "dev",
+			to:      "prod",
+			wantErr: "cannot promote schemas from dev to prod: staging is the previous stage of prod in the promotion order",
+		},
+		{
+			name:    "first stage",
+			cfg:     ordered,
+			from:    "staging",
+			to:      "dev",
+			wantErr: "cannot promote schemas from staging to dev: dev is the first stage of the promotion order",
+		},
+		{
+			name:    "not in the promotion order",
+			cfg:     ordered,
+			from:    "prod",
+			to:      "sandbox",
+			wantErr: "cannot promote schemas from prod to sandbox: sandbox is not in the promotion order",
+		},
+		{
+			name:    "same environment",
+			cfg:     unordered,
+			from:    "dev",
+			to:      "dev",
+			wantErr: "cannot promote schemas from dev to dev: schemas cannot be promoted to the same environment",
+		},
+		{
+			name:    "unknown environment",
+			cfg:     unordered,
+			from:    "qa",
+			to:      "dev",
+			wantErr: "does not define environment 'qa'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.cfg.ValidatePromotion(tt.from, tt.to)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -84,3 +84,181 @@ func (e *InvalidDefaultJSONSchemaVersionError) Error() string {
 		e.Supported,
 	)
 }
+
+// InvalidMutationCheckError is returned when an environment's mutationCheck property has an unsupported value.
+type InvalidMutationCheckError struct {
+	Property string
+	Value    MutationCheck
+}
+
+func (e *InvalidMutationCheckError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has invalid value '%s'. Supported values are: %s, %s",
+		e.Property,
+		e.Value,
+		MutationCheckSource,
+		MutationCheckRendered,
+	)
+}
+
+// InvalidGitBackendError is returned when the git backend property has an unsupported value.
+type InvalidGitBackendError struct {
+	Property string
+	Value    GitBackend
+}
+
+func (e *InvalidGitBackendError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has invalid value '%s'. Supported values are: %s, %s",
+		e.Property,
+		e.Value,
+		GitBackendCLI,
+		GitBackendGo,
+	)
+}
+
+// InvalidGitPropertyError is returned when a property of the git configuration has an invalid value.
+type InvalidGitPropertyError struct {
+	Property string
+	Value    string
+	Reason   string
+}
+
+func (e *InvalidGitPropertyError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has invalid value '%s'. %s",
+		e.Property,
+		e.Value,
+		e.Reason,
+	)
+}
+
+// InvalidPromotionOrderError is returned when the promotion order lists an environment which is not configured,
+// or lists an environment more than once.
+type InvalidPromotionOrderError struct {
+	Property string
+	Value    Env
+	Reason   string
+}
+
+func (e *InvalidPromotionOrderError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has invalid value '%s'. %s",
+		e.Property,
+		e.Value,
+		e.Reason,
+	)
+}
+
+// InvalidPromotionError is returned when schemas may not be promoted from one environment to another.
+type InvalidPromotionError struct {
+	From   Env
+	To     Env
+	Reason string
+}
+
+func (e *InvalidPromotionError) Error() string {
+	return fmt.Sprintf("cannot promote schemas from %s to %s: %s", e.From, e.To, e.Reason)
+}
+
+// InvalidMutationPolicyError is returned when an environment's mutationPolicy property has an unsupported value.
+type InvalidMutationPolicyError struct {
+	Property string
+	Value    MutationPolicy
+}
+
+func (e *InvalidMutationPolicyError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has invalid value '%s'. Supported values are: %s, %s",
+		e.Property,
+		e.Value,
+		MutationPolicyStrict,
+		MutationPolicyAnnotationsAllowed,
+	)
+}
+
+// InvalidAnnotationKeyError is returned when an environment's annotationKeys property contains a keyword which is
+// not an x- extension keyword.
+type InvalidAnnotationKeyError struct {
+	Property string
+	Value    string
+}
+
+func (e *InvalidAnnotationKeyError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has invalid value '%s'. Annotation keys must start with 'x-'",
+		e.Property,
+		e.Value,
+	)
+}
+
+// MultiplePublishStoresError is returned when an environment's publish property configures more than one store.
+type MultiplePublishStoresError struct {
+	Property string
+}
+
+func (e *MultiplePublishStoresError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s must configure exactly one of s3 and schemaRegistry",
+		e.Property,
+	)
+}
+
+// InvalidSubjectNamingError is returned when a Schema Registry's subjectNaming property has an unsupported value.
+type InvalidSubjectNamingError struct {
+	Property string
+	Value    SubjectNaming
+}
+
+func (e *InvalidSubjectNamingError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has invalid value '%s'. Supported values are: %s, %s, %s",
+		e.Property,
+		e.Value,
+		SubjectNamingMajor,
+		SubjectNamingFamily,
+		SubjectNamingKey,
+	)
+}
+
+// InvalidCompatibilityError is returned when a Schema Registry's compatibility property has an unsupported value.
+type InvalidCompatibilityError struct {
+	Property string
+	Value    Compatibility
+}
+
+func (e *InvalidCompatibilityError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has invalid value '%s'. Supported values are: %v",
+		e.Property,
+		e.Value,
+		compatibilities,
+	)
+}
+
+// InvalidExternalRegistryNameError is returned when the name of an external registry is not a lowercase
+// letter followed by lowercase letters, digits and hyphens.
+type InvalidExternalRegistryNameError struct {
+	Property string
+}
+
+func (e *InvalidExternalRegistryNameError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s has an invalid name. "+
+			"Names must start with a lowercase letter, followed by lowercase letters, digits and hyphens",
+		e.Property,
+	)
+}
+
+// MultipleExternalRegistrySourcesError is returned when an external registry configures both a path and a
+// dist directory.
+type MultipleExternalRegistrySourcesError struct {
+	Property string
+}
+
+func (e *MultipleExternalRegistrySourcesError) Error() string {
+	return fmt.Sprintf(
+		"json-schema-manager-config.yml property %s must configure exactly one of path and dist",
+		e.Property,
+	)
+}
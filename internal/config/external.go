@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ExternalRegistryConfig locates the registry of another team, whose schemas can be referenced with
+// {{ JSM "<name>:<key>" }}. Exactly one of Path and Dist must be set. Relative paths are relative to the
+// registry root directory.
+type ExternalRegistryConfig struct {
+	Path string `yaml:"path"` // The root directory of a checkout of the registry
+	Dist string `yaml:"dist"` // A dist directory built from the registry, holding a directory per environment
+}
+
+// externalRegistryNameRegex matches the name of an external registry, which prefixes keys in {{ JSM }}
+// arguments, so cannot contain the colon which separates it from the key.
+var externalRegistryNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// Validate validates an ExternalRegistryConfig.
+func (e *ExternalRegistryConfig) Validate(pathPrefix string) error {
+	switch {
+	case e.Path != "" && e.Dist != "":
+		return &MultipleExternalRegistrySourcesError{Property: pathPrefix}
+	case e.Path == "" && e.Dist == "":
+		return &MissingPropertyError{Property: fmt.Sprintf("%s.path", pathPrefix)}
+	}
+	return nil
+}
+
+// ExternalRegistries are the registries of other teams, by the name which prefixes their keys in {{ JSM }}
+// arguments.
+type ExternalRegistries map[string]*ExternalRegistryConfig
+
+// Validate checks that each external registry has a valid name and exactly one source.
+func (e ExternalRegistries) Validate() error {
+	for name, er := range e {
+		prop := fmt.Sprintf("externalRegistries.%s", name)
+		if !externalRegistryNameRegex.MatchString(name) {
+			return &InvalidExternalRegistryNameError{Property: prop}
+		}
+		if er == nil {
+			return &MissingPropertyError{Property: fmt.Sprintf("%s.path", prop)}
+		}
+		if err := er.Validate(prop); err != nil {
+			return err
+		}
+	}
+	return nil
+}
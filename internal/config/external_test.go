@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+func TestNewConfig_ExternalRegistries(t *testing.T) {
+	t.Parallel()
+
+	mc := &mockCompiler{supported: []validator.Draft{validator.Draft7}}
+	load := func(external string) (*Config, error) {
+		content := `
+environments:
+  prod:
+    publicUrlRoot: "https://example.com"
+    privateUrlRoot: "https://internal.example.com"
+    isProduction: true
+` + external
+		return NewFS(fstest.MapFS{JsmRegistryConfigFile: {Data: []byte(content)}}, "/registry", mc)
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load("")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.ExternalRegistries)
+	})
+
+	t.Run("checkout and dist", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load("externalRegistries:\n  payments:\n    path: ../payments/registry\n" +
+			"  identity-2:\n    dist: /opt/identity/dist\n")
+		require.NoError(t, err)
+		assert.Equal(t, ExternalRegistries{
+			"payments":   {Path: "../payments/registry"},
+			"identity-2": {Dist: "/opt/identity/dist"},
+		}, cfg.ExternalRegistries)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := load("externalRegistries:\n  Payments:\n    path: ../payments\n")
+		var nameErr *InvalidExternalRegistryNameError
+		require.ErrorAs(t, err, &nameErr)
+		assert.Equal(t, "externalRegistries.Payments", nameErr.Property)
+
+		_, err = load("externalRegistries:\n  payments:\n    path: ../payments\n    dist: ../payments/dist\n")
+		var multipleErr *MultipleExternalRegistrySourcesError
+		require.ErrorAs(t, err, &multipleErr)
+		assert.Equal(t, "externalRegistries.payments", multipleErr.Property)
+
+		for _, external := range []string{"externalRegistries:\n  payments: {}\n", "externalRegistries:\n  payments:\n"} {
+			_, err = load(external)
+			var missingErr *MissingPropertyError
+			require.ErrorAs(t, err, &missingErr)
+			assert.Equal(t, "externalRegistries.payments.path", missingErr.Property)
+		}
+	})
+}
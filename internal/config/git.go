@@ -0,0 +1,198 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// GitBackend identifies the implementation JSM uses to work with the git repository holding the registry.
+type GitBackend string
+
+const (
+	// GitBackendCLI runs the git binary. This is the default.
+	GitBackendCLI GitBackend = "cli"
+	// GitBackendGo reads and writes the repository with a pure Go git library, so the git binary is not needed.
+	GitBackendGo GitBackend = "go"
+)
+
+// GitBackends lists the supported git backends.
+var GitBackends = []GitBackend{GitBackendCLI, GitBackendGo}
+
+// TagSigning identifies how deployment tags are signed.
+type TagSigning string
+
+const (
+	// TagSigningNone creates unsigned tags. This is the default.
+	TagSigningNone TagSigning = ""
+	// TagSigningGPG signs tags with a GPG key.
+	TagSigningGPG TagSigning = "gpg"
+	// TagSigningSSH signs tags with an SSH key.
+	TagSigningSSH TagSigning = "ssh"
+)
+
+const (
+	// DefaultDeployTagPrefix is the default prefix of deployment tags.
+	DefaultDeployTagPrefix = "jsm-deploy"
+	// DefaultRollbackTagPrefix is the default prefix of rollback tags.
+	DefaultRollbackTagPrefix = "jsm-rollback"
+	// DefaultDeployTagTimestampFormat is the default layout, as used by time.Format, of the UTC timestamp in
+	// the names of deployment tags.
+	DefaultDeployTagTimestampFormat = "20060102-150405"
+	// DefaultGitRemote is the default remote deployment tags are pushed to.
+	DefaultGitRemote = "origin"
+)
+
+// GitConfig contains configuration for the git repository holding the registry.
+type GitConfig struct {
+	Backend    GitBackend      `yaml:"backend"`    // Defaults to cli
+	Remote     string          `yaml:"remote"`     // The remote deployment tags are pushed to. Defaults to origin
+	DeployTags DeployTagConfig `yaml:"deployTags"` // How deployment tags are named and signed
+}
+
+// DeployTagConfig contains configuration for the tags which record successful deployments. A deployment tag
+// is named <prefix>/<env>/<timestamp>, and a tag recording the rollback of a deployment is named
+// <rollbackPrefix>/<env>/<timestamp>.
+type DeployTagConfig struct {
+	Prefix          string     `yaml:"prefix"`          // Defaults to jsm-deploy
+	RollbackPrefix  string     `yaml:"rollbackPrefix"`  // Defaults to jsm-rollback
+	TimestampFormat string     `yaml:"timestampFormat"` // A time.Format layout. Defaults to 20060102-150405
+	Sign            TagSigning `yaml:"sign"`            // gpg or ssh. Tags are unsigned by default
+	SigningKey      string     `yaml:"signingKey"`      // Defaults to git's user.signingKey configuration
+}
+
+// Validate validates a GitConfig, setting defaults for any properties which are not configured.
+func (g *GitConfig) Validate() error {
+	if g.Backend == "" {
+		g.Backend = GitBackendCLI
+	}
+	if !slices.Contains(GitBackends, g.Backend) {
+		return &InvalidGitBackendError{Property: "git.backend", Value: g.Backend}
+	}
+
+	if g.Remote == "" {
+		g.Remote = DefaultGitRemote
+	}
+	if !validRefComponent(g.Remote) {
+		return &InvalidGitPropertyError{Property: "git.remote", Value: g.Remote, Reason: "It is not a valid remote name"}
+	}
+
+	if err := g.DeployTags.Validate(); err != nil {
+		return err
+	}
+	return g.ValidateBackend(g.Backend)
+}
+
+// ValidateBackend checks that the deployment tags can be created by the git backend, which may be selected
+// for a single command rather than configured. The go backend cannot sign tags, so signing is rejected before
+// anything is deployed, rather than when the deployment is tagged.
+func (g *GitConfig) ValidateBackend(b GitBackend) error {
+	if b == GitBackendGo && g.DeployTags.Sign != TagSigningNone {
+		return &InvalidGitPropertyError{
+			Property: "git.deployTags.sign",
+			Value:    string(g.DeployTags.Sign),
+			Reason:   "Tags cannot be signed by the go git backend",
+		}
+	}
+	return nil
+}
+
+// Validate validates a DeployTagConfig, setting defaults for any properties which are not configured.
+func (d *DeployTagConfig) Validate() error {
+	if d.Prefix == "" {
+		d.Prefix = DefaultDeployTagPrefix
+	}
+	if err := validateTagPrefix("git.deployTags.prefix", d.Prefix); err != nil {
+		return err
+	}
+	if d.RollbackPrefix == "" {
+		d.RollbackPrefix = DefaultRollbackTagPrefix
+	}
+	if err := validateTagPrefix("git.deployTags.rollbackPrefix", d.RollbackPrefix); err != nil {
+		return err
+	}
+	if d.RollbackPrefix == d.Prefix {
+		return &InvalidGitPropertyError{
+			Property: "git.deployTags.rollbackPrefix",
+			Value:    d.RollbackPrefix,
+			Reason:   "It must differ from git.deployTags.prefix",
+		}
+	}
+
+	if d.TimestampFormat == "" {
+		d.TimestampFormat = DefaultDeployTagTimestampFormat
+	}
+	if err := validateTimestampFormat(d.TimestampFormat); err != nil {
+		return err
+	}
+
+	switch d.Sign {
+	case TagSigningNone, TagSigningGPG, TagSigningSSH:
+	default:
+		return &InvalidGitPropertyError{
+			Property: "git.deployTags.sign",
+			Value:    string(d.Sign),
+			Reason:   "Supported values are: gpg, ssh",
+		}
+	}
+	if d.SigningKey != "" && d.Sign == TagSigningNone {
+		return &InvalidGitPropertyError{
+			Property: "git.deployTags.signingKey",
+			Value:    d.SigningKey,
+			Reason:   "git.deployTags.sign must be set to use a signing key",
+		}
+	}
+	return nil
+}
+
+// validateTagPrefix checks that every component of a tag prefix can be used in a git tag name.
+func validateTagPrefix(property, prefix string) error {
+	for _, c := range strings.Split(prefix, "/") {
+		if !validRefComponent(c) {
+			return &InvalidGitPropertyError{
+				Property: property,
+				Value:    prefix,
+				Reason:   "It cannot be used in a git tag name",
+			}
+		}
+	}
+	return nil
+}
+
+// validateTimestampFormat checks that a timestamp format can be used in a git tag name, and records the
+// date and time to the second, so that the timestamps of deployments made in different seconds never
+// collide.
+func validateTimestampFormat(format string) error {
+	// A time with a distinct value in every field, so that any field missing from the format is detected.
+	ref := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	formatted := ref.Format(format)
+	if strings.Contains(formatted, "/") || !validRefComponent(formatted) {
+		return &InvalidGitPropertyError{
+			Property: "git.deployTags.timestampFormat",
+			Value:    format,
+			Reason:   "It cannot be used in a git tag name",
+		}
+	}
+	if parsed, err := time.Parse(format, formatted); err != nil || !parsed.Equal(ref) {
+		return &InvalidGitPropertyError{
+			Property: "git.deployTags.timestampFormat",
+			Value:    format,
+			Reason:   "It must include the year, month, day, hour, minute and second",
+		}
+	}
+	return nil
+}
+
+// validRefComponent returns true if s can be used as a component of a git reference name, as described by
+// git check-ref-format.
+func validRefComponent(s string) bool {
+	if s == "" || strings.HasPrefix(s, ".") || strings.HasSuffix(s, ".") || strings.HasSuffix(s, ".lock") {
+		return false
+	}
+	if strings.Contains(s, "..") || strings.Contains(s, "@{") || s == "@" {
+		return false
+	}
+	return !strings.ContainsFunc(s, func(r rune) bool {
+		return r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\/", r)
+	})
+}
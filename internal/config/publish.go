@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// PublishConfig identifies where jsm publish uploads the distribution of an environment to. Exactly one
+// store must be configured.
+type PublishConfig struct {
+	S3             *S3PublishConfig             `yaml:"s3"`
+	SchemaRegistry *SchemaRegistryPublishConfig `yaml:"schemaRegistry"`
+}
+
+// S3PublishConfig identifies a bucket in Amazon S3, or in an S3-compatible object store such as MinIO.
+type S3PublishConfig struct {
+	Bucket    string `yaml:"bucket"`
+	Prefix    string `yaml:"prefix"`    // Prepended to the key of every object, e.g. "schemas"
+	Region    string `yaml:"region"`    // Defaults to us-east-1
+	Endpoint  string `yaml:"endpoint"`  // Defaults to the Amazon S3 endpoint for the region
+	PathStyle bool   `yaml:"pathStyle"` // Address the bucket in the URL path rather than the host name
+}
+
+// DefaultS3Region is the region used for an S3 bucket whose region is not configured.
+const DefaultS3Region = "us-east-1"
+
+// SchemaRegistryPublishConfig identifies a Confluent-compatible Schema Registry, and how schemas are
+// registered with it.
+type SchemaRegistryPublishConfig struct {
+	URL           string        `yaml:"url"`
+	SubjectNaming SubjectNaming `yaml:"subjectNaming"` // Defaults to major
+	Compatibility Compatibility `yaml:"compatibility"` // Of families which are not experimental; defaults to BACKWARD
+}
+
+// SubjectNaming identifies how the Schema Registry subject of a schema is derived from its key.
+type SubjectNaming string
+
+const (
+	// SubjectNamingMajor registers each major version of a family under its own subject,
+	// e.g. domain_family_1.
+	SubjectNamingMajor SubjectNaming = "major"
+	// SubjectNamingFamily registers every version of a family under one subject, e.g. domain_family.
+	// The compatibility level of the subject is NONE, as it spans breaking changes between major versions.
+	SubjectNamingFamily SubjectNaming = "family"
+	// SubjectNamingKey registers each schema under its own subject, e.g. domain_family_1_0_0.
+	SubjectNamingKey SubjectNaming = "key"
+)
+
+// Compatibility is a Schema Registry compatibility level.
+type Compatibility string
+
+// The compatibility levels supported by a Confluent-compatible Schema Registry.
+const (
+	CompatibilityBackward           Compatibility = "BACKWARD"
+	CompatibilityBackwardTransitive Compatibility = "BACKWARD_TRANSITIVE"
+	CompatibilityForward            Compatibility = "FORWARD"
+	CompatibilityForwardTransitive  Compatibility = "FORWARD_TRANSITIVE"
+	CompatibilityFull               Compatibility = "FULL"
+	CompatibilityFullTransitive     Compatibility = "FULL_TRANSITIVE"
+	CompatibilityNone               Compatibility = "NONE"
+)
+
+// compatibilities lists the supported compatibility levels.
+var compatibilities = []Compatibility{
+	CompatibilityBackward,
+	CompatibilityBackwardTransitive,
+	CompatibilityForward,
+	CompatibilityForwardTransitive,
+	CompatibilityFull,
+	CompatibilityFullTransitive,
+	CompatibilityNone,
+}
+
+// Validate validates a PublishConfig.
+func (p *PublishConfig) Validate(pathPrefix string) error {
+	switch {
+	case p.S3 != nil && p.SchemaRegistry != nil:
+		return &MultiplePublishStoresError{Property: pathPrefix}
+	case p.S3 != nil:
+		return p.S3.Validate(fmt.Sprintf("%s.s3", pathPrefix))
+	case p.SchemaRegistry != nil:
+		return p.SchemaRegistry.Validate(fmt.Sprintf("%s.schemaRegistry", pathPrefix))
+	default:
+		return &MissingPropertyError{Property: fmt.Sprintf("%s.s3", pathPrefix)}
+	}
+}
+
+// Validate validates an S3PublishConfig.
+func (s *S3PublishConfig) Validate(pathPrefix string) error {
+	if s.Bucket == "" {
+		return &MissingPropertyError{Property: fmt.Sprintf("%s.bucket", pathPrefix)}
+	}
+	if s.Region == "" {
+		s.Region = DefaultS3Region
+	}
+	if s.Endpoint == "" {
+		return nil
+	}
+
+	return validateEndpointURL(fmt.Sprintf("%s.endpoint", pathPrefix), s.Endpoint)
+}
+
+// Validate validates a SchemaRegistryPublishConfig.
+func (s *SchemaRegistryPublishConfig) Validate(pathPrefix string) error {
+	if s.URL == "" {
+		return &MissingPropertyError{Property: fmt.Sprintf("%s.url", pathPrefix)}
+	}
+	if err := validateEndpointURL(fmt.Sprintf("%s.url", pathPrefix), s.URL); err != nil {
+		return err
+	}
+
+	switch s.SubjectNaming {
+	case "":
+		s.SubjectNaming = SubjectNamingMajor
+	case SubjectNamingMajor, SubjectNamingFamily, SubjectNamingKey:
+	default:
+		return &InvalidSubjectNamingError{Property: fmt.Sprintf("%s.subjectNaming", pathPrefix), Value: s.SubjectNaming}
+	}
+
+	if s.Compatibility == "" {
+		s.Compatibility = CompatibilityBackward
+	}
+	if !slices.Contains(compatibilities, s.Compatibility) {
+		return &InvalidCompatibilityError{Property: fmt.Sprintf("%s.compatibility", pathPrefix), Value: s.Compatibility}
+	}
+	return nil
+}
+
+// validateEndpointURL returns an InvalidURLError if the value of a property is not an absolute http(s) URL.
+// Unlike the URL roots of environments, plain http is permitted, so that local stores can be used.
+func validateEndpointURL(prop, value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return &InvalidURLError{Property: prop, Value: value, Wrapped: err}
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return &InvalidURLError{Property: prop, Value: value, Wrapped: errors.New("must be an http(s) URL")}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+func TestNewConfig_Publish(t *testing.T) {
+	t.Parallel()
+
+	mc := &mockCompiler{supported: []validator.Draft{validator.Draft7}}
+	load := func(publish string) (*Config, error) {
+		content := `
+environments:
+  prod:
+    publicUrlRoot: "https://example.com"
+    privateUrlRoot: "https://internal.example.com"
+    isProduction: true
+` + publish
+		return NewFS(fstest.MapFS{JsmRegistryConfigFile: {Data: []byte(content)}}, "/registry", mc)
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load("")
+		require.NoError(t, err)
+		assert.Nil(t, cfg.ProductionEnvConfig().Publish)
+	})
+
+	t.Run("s3 with defaults", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load("    publish:\n      s3:\n        bucket: schemas\n")
+		require.NoError(t, err)
+		assert.Equal(t, &S3PublishConfig{Bucket: "schemas", Region: DefaultS3Region},
+			cfg.ProductionEnvConfig().Publish.S3)
+	})
+
+	t.Run("s3 compatible store", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load("    publish:\n      s3:\n        bucket: schemas\n        prefix: prod\n" +
+			"        region: eu-west-2\n        endpoint: http://localhost:9000\n        pathStyle: true\n")
+		require.NoError(t, err)
+		assert.Equal(t, &S3PublishConfig{
+			Bucket:    "schemas",
+			Prefix:    "prod",
+			Region:    "eu-west-2",
+			Endpoint:  "http://localhost:9000",
+			PathStyle: true,
+		}, cfg.ProductionEnvConfig().Publish.S3)
+	})
+
+	t.Run("schema registry with defaults", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load("    publish:\n      schemaRegistry:\n        url: http://localhost:8081\n")
+		require.NoError(t, err)
+		assert.Equal(t, &SchemaRegistryPublishConfig{
+			URL:           "http://localhost:8081",
+			SubjectNaming: SubjectNamingMajor,
+			Compatibility: CompatibilityBackward,
+		}, cfg.ProductionEnvConfig().Publish.SchemaRegistry)
+	})
+
+	t.Run("schema registry", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := load("    publish:\n      schemaRegistry:\n        url: https://registry.example.com\n" +
+			"        subjectNaming: family\n        compatibility: FULL_TRANSITIVE\n")
+		require.NoError(t, err)
+		assert.Equal(t, &SchemaRegistryPublishConfig{
+			URL:           "https://registry.example.com",
+			SubjectNaming: SubjectNamingFamily,
+			Compatibility: CompatibilityFullTransitive,
+		}, cfg.ProductionEnvConfig().Publish.SchemaRegistry)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		tests := map[string]string{
+			"    publish: {}\n":                                           "environments.prod.publish.s3",
+			"    publish:\n      s3:\n        prefix: p\n":                "environments.prod.publish.s3.bucket",
+			"    publish:\n      s3: {bucket: b, endpoint: 'ftp://x'}\n":  "environments.prod.publish.s3.endpoint",
+			"    publish:\n      s3: {bucket: b, endpoint: 'http://'}\n":  "environments.prod.publish.s3.endpoint",
+			"    publish:\n      s3: {bucket: b, endpoint: ':invalid'}\n": "environments.prod.publish.s3.endpoint",
+			"    publish:\n      schemaRegistry: {}\n":                    "environments.prod.publish.schemaRegistry.url",
+			"    publish:\n      schemaRegistry: {url: 'ftp://x'}\n":      "environments.prod.publish.schemaRegistry.url",
+			"    publish:\n      schemaRegistry: {url: 'http://x', subjectNaming: topic}\n": "environments.prod.publish." +
+				"schemaRegistry.subjectNaming",
+			"    publish:\n      schemaRegistry: {url: 'http://x', compatibility: backward}\n": "environments.prod.publish." +
+				"schemaRegistry.compatibility",
+			"    publish:\n      s3: {bucket: b}\n      schemaRegistry: {url: 'http://x'}\n": "environments.prod.publish " +
+				"must configure exactly one",
+		}
+		for publish, prop := range tests {
+			_, err := load(publish)
+			require.Error(t, err, publish)
+			assert.Contains(t, err.Error(), prop, publish)
+		}
+	})
+}
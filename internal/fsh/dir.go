@@ -2,6 +2,7 @@
 package fsh
 
 import (
+	"io/fs"
 	"os"
 	"slices"
 	"strconv"
@@ -14,7 +15,20 @@ func GetUintSubdirectories(dirPath string) ([]uint64, error) {
 	if err != nil {
 		return nil, err
 	}
+	return uintDirNames(entries), nil
+}
+
+// GetUintSubdirectoriesFS is GetUintSubdirectories for the directory with the given name in fsys.
+func GetUintSubdirectoriesFS(fsys fs.FS, name string) ([]uint64, error) {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return uintDirNames(entries), nil
+}
 
+// uintDirNames returns the names of the directories in entries which are uint64s, sorted in ascending order.
+func uintDirNames(entries []fs.DirEntry) []uint64 {
 	var nums []uint64
 	for _, entry := range entries {
 		if !entry.IsDir() {
@@ -25,5 +39,5 @@ func GetUintSubdirectories(dirPath string) ([]uint64, error) {
 		}
 	}
 	slices.Sort(nums)
-	return nums, nil
+	return nums
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -100,3 +101,21 @@ func TestGetUintSubdirectories(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUintSubdirectoriesFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"dir/10/a":  {Data: []byte("a")},
+		"dir/2/a":   {Data: []byte("a")},
+		"dir/abc/a": {Data: []byte("a")},
+		"dir/3":     {Data: []byte("file")},
+	}
+
+	nums, err := GetUintSubdirectoriesFS(fsys, "dir")
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2, 10}, nums)
+
+	_, err = GetUintSubdirectoriesFS(fsys, "missing")
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package fsh
 
 import (
+	"io/fs"
 	"path/filepath"
 )
 
@@ -43,3 +44,48 @@ func (r *StandardPathResolver) Abs(path string) (string, error) {
 func (r *StandardPathResolver) GetUintSubdirectories(dirPath string) ([]uint64, error) {
 	return GetUintSubdirectories(dirPath)
 }
+
+// FSPathResolver is a PathResolver which reads directories from an fs.FS holding the tree under a root
+// directory, rather than from the operating system. Paths are resolved by the base PathResolver.
+type FSPathResolver struct {
+	PathResolver
+	fsys fs.FS
+	root string
+}
+
+// NewFSPathResolver creates a new FSPathResolver which reads the directories under root from fsys.
+func NewFSPathResolver(base PathResolver, fsys fs.FS, root string) *FSPathResolver {
+	return &FSPathResolver{PathResolver: base, fsys: fsys, root: root}
+}
+
+// CanonicalPath returns the absolute path of a path under root, whose files are not on disk and so have no
+// symlinks to resolve. Other paths are resolved by the base PathResolver.
+func (r *FSPathResolver) CanonicalPath(path string) (string, error) {
+	abs, err := r.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := r.name(abs); ok {
+		return abs, nil
+	}
+	return r.PathResolver.CanonicalPath(path)
+}
+
+// GetUintSubdirectories returns a slice of uint64s corresponding to subdirectories
+// of the given path that are compatible with uint64, sorted in ascending order.
+func (r *FSPathResolver) GetUintSubdirectories(dirPath string) ([]uint64, error) {
+	name, ok := r.name(dirPath)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: dirPath, Err: fs.ErrNotExist}
+	}
+	return GetUintSubdirectoriesFS(r.fsys, name)
+}
+
+// name returns the name in fsys of a path, and whether the path is under root.
+func (r *FSPathResolver) name(path string) (string, bool) {
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
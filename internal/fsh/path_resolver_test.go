@@ -1,9 +1,11 @@
 package fsh
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -166,3 +168,56 @@ func TestMockPathResolver(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestFSPathResolver(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(string(filepath.Separator), "registry")
+	fsys := fstest.MapFS{
+		"domain/family/1/file": {Data: []byte("{}")},
+		"domain/family/2/x":    {Data: []byte("{}")},
+		"domain/family/abc/x":  {Data: []byte("{}")},
+	}
+	resolver := NewFSPathResolver(&mockPathResolver{}, fsys, root)
+
+	t.Run("GetUintSubdirectories reads fsys", func(t *testing.T) {
+		t.Parallel()
+		got, err := resolver.GetUintSubdirectories(filepath.Join(root, "domain", "family"))
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2}, got)
+	})
+
+	t.Run("GetUintSubdirectories of missing directory", func(t *testing.T) {
+		t.Parallel()
+		_, err := resolver.GetUintSubdirectories(filepath.Join(root, "missing"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("GetUintSubdirectories outside root", func(t *testing.T) {
+		t.Parallel()
+		_, err := resolver.GetUintSubdirectories(filepath.Join(root, "..", "other"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("CanonicalPath under root", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(root, "domain", "family", "1", "file")
+		got, err := resolver.CanonicalPath(filepath.Join(root, "domain", "..", "domain", "family", "1", "file"))
+		require.NoError(t, err)
+		assert.Equal(t, path, got)
+	})
+
+	t.Run("CanonicalPath outside root is resolved by the base resolver", func(t *testing.T) {
+		t.Parallel()
+		base := &mockPathResolver{canonicalPathFn: func(_ string) (string, error) { return "", os.ErrNotExist }}
+		_, err := NewFSPathResolver(base, fsys, root).CanonicalPath(filepath.Join(root, "..", "other"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("CanonicalPath Abs error", func(t *testing.T) {
+		t.Parallel()
+		base := &mockPathResolver{absFn: func(_ string) (string, error) { return "", os.ErrInvalid }}
+		_, err := NewFSPathResolver(base, fsys, root).CanonicalPath("relative")
+		require.ErrorIs(t, err, os.ErrInvalid)
+	})
+}
@@ -0,0 +1,209 @@
+// Package jcs serialises JSON documents using the JSON Canonicalization Scheme (RFC 8785), so that
+// documents which differ only in whitespace, property order or number and string formatting
+// have identical bytes.
+package jcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Transform returns the canonical serialisation of the JSON document in data.
+func Transform(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+	return Marshal(v)
+}
+
+// Marshal returns the canonical serialisation of an unmarshalled JSON value. Numbers may be
+// json.Number or any Go numeric type, and objects must be map[string]any.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := write(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func write(buf *bytes.Buffer, v any) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(val))
+	case string:
+		writeString(buf, val)
+	case json.Number:
+		f, err := val.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid number %s: %w", val, err)
+		}
+		return writeNumber(buf, f)
+	case float64:
+		return writeNumber(buf, val)
+	case float32:
+		return writeNumber(buf, float64(val))
+	case int:
+		return writeNumber(buf, float64(val))
+	case int8:
+		return writeNumber(buf, float64(val))
+	case int16:
+		return writeNumber(buf, float64(val))
+	case int32:
+		return writeNumber(buf, float64(val))
+	case int64:
+		return writeNumber(buf, float64(val))
+	case uint:
+		return writeNumber(buf, float64(val))
+	case uint8:
+		return writeNumber(buf, float64(val))
+	case uint16:
+		return writeNumber(buf, float64(val))
+	case uint32:
+		return writeNumber(buf, float64(val))
+	case uint64:
+		return writeNumber(buf, float64(val))
+	case []any:
+		return writeArray(buf, val)
+	case map[string]any:
+		return writeObject(buf, val)
+	default:
+		return fmt.Errorf("cannot canonicalise a value of type %T", v)
+	}
+	return nil
+}
+
+func writeArray(buf *bytes.Buffer, a []any) error {
+	buf.WriteByte('[')
+	for i, e := range a {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := write(buf, e); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+func writeObject(buf *bytes.Buffer, o map[string]any) error {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	// Properties are sorted by the UTF-16 code units of their names.
+	slices.SortFunc(keys, func(a, b string) int {
+		return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+	})
+
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(buf, k)
+		buf.WriteByte(':')
+		if err := write(buf, o[k]); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeString writes a JSON string, escaping only the characters which must be escaped.
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// writeNumber writes a number in the format of the ECMAScript Number.prototype.toString() method.
+func writeNumber(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("cannot canonicalise the number %v", f)
+	}
+	if f == 0 {
+		// Includes negative zero.
+		buf.WriteByte('0')
+		return nil
+	}
+	if f < 0 {
+		buf.WriteByte('-')
+		f = -f
+	}
+
+	// The shortest decimal digits which round-trip, and the exponent n such that the
+	// value is 0.<digits> x 10^n.
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	k, n := len(digits), e+1
+
+	switch {
+	case k <= n && n <= 21:
+		buf.WriteString(digits)
+		buf.WriteString(strings.Repeat("0", n-k))
+	case 0 < n && n <= 21:
+		buf.WriteString(digits[:n])
+		buf.WriteByte('.')
+		buf.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		buf.WriteString("0.")
+		buf.WriteString(strings.Repeat("0", -n))
+		buf.WriteString(digits)
+	default:
+		buf.WriteString(digits[:1])
+		if k > 1 {
+			buf.WriteByte('.')
+			buf.WriteString(digits[1:])
+		}
+		buf.WriteByte('e')
+		if n-1 > 0 {
+			buf.WriteByte('+')
+		}
+		buf.WriteString(strconv.Itoa(n - 1))
+	}
+	return nil
+}
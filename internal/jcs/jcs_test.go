@@ -0,0 +1,128 @@
+package jcs
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "whitespace and property order",
+			in:   "{\n  \"b\": [1, 2, {\"z\": true, \"a\": null}],\n  \"a\": \"x\"\n}",
+			want: `{"a":"x","b":[1,2,{"a":null,"z":true}]}`,
+		},
+		{
+			// The example in RFC 8785 section 3.2.2.
+			name: "RFC 8785 example",
+			in: `{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],` +
+				`"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","literals": [null, true, false]}`,
+			want: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],` +
+				`"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// The example in RFC 8785 section 3.2.3.
+			name: "properties are sorted by UTF-16 code units",
+			in: `{"\u20ac": "Euro", "\r": "CR", "\ufb33": "Dalet", "1": "One", ` +
+				`"\ud83d\ude00": "Emoji", "\u0080": "Control", "\u00f6": "O Diaeresis"}`,
+			want: "{\"\\r\":\"CR\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"O Diaeresis\"," +
+				"\"\u20ac\":\"Euro\",\"\U0001F600\":\"Emoji\",\"\ufb33\":\"Dalet\"}",
+		},
+		{
+			name: "control characters",
+			in:   `"\u0001\b\f\t\r"`,
+			want: `"\u0001\b\f\t\r"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Transform([]byte(tt.in))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestTransform_Errors(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{`{"a": }`, `{} {}`, `1e400`} {
+		_, err := Transform([]byte(in))
+		require.Error(t, err, in)
+	}
+}
+
+func TestMarshal_Numbers(t *testing.T) {
+	t.Parallel()
+
+	// Test values from RFC 8785 appendix B.
+	tests := map[float64]string{
+		0:                       "0",
+		math.Copysign(0, -1):    "0",
+		5e-324:                  "5e-324",
+		-5e-324:                 "-5e-324",
+		1.7976931348623157e308:  "1.7976931348623157e+308",
+		-1.7976931348623157e308: "-1.7976931348623157e+308",
+		9007199254740992:        "9007199254740992",
+		-9007199254740992:       "-9007199254740992",
+		295147905179352830000:   "295147905179352830000",
+		1e21:                    "1e+21",
+		1e20:                    "100000000000000000000",
+		0.000001:                "0.000001",
+		1e-7:                    "1e-7",
+		1.5:                     "1.5",
+		-1.5:                    "-1.5",
+		333333333.3333333:       "333333333.3333333",
+		1e23:                    "1e+23",
+		100:                     "100",
+		123.456:                 "123.456",
+	}
+
+	for f, want := range tests {
+		got, err := Marshal(f)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got), "%v", f)
+	}
+}
+
+func TestMarshal_Types(t *testing.T) {
+	t.Parallel()
+
+	got, err := Marshal(map[string]any{
+		"f32": float32(0.5),
+		"i":   3,
+		"i64": int64(-4),
+		"i8":  int8(-8),
+		"i16": int16(16),
+		"i32": int32(-32),
+		"n":   json.Number("1.0"),
+		"u":   uint(1),
+		"u8":  uint8(8),
+		"u16": uint16(16),
+		"u32": uint32(32),
+		"u64": uint64(64),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"f32":0.5,"i":3,"i16":16,"i32":-32,"i64":-4,"i8":-8,"n":1,`+
+		`"u":1,"u16":16,"u32":32,"u64":64,"u8":8}`, string(got))
+
+	invalid := []any{
+		math.NaN(), math.Inf(1), json.Number("x"), struct{}{}, []any{struct{}{}}, map[string]any{"a": struct{}{}},
+	}
+	for _, v := range invalid {
+		_, err = Marshal(v)
+		require.Error(t, err)
+	}
+}
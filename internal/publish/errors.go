@@ -0,0 +1,106 @@
+package publish
+
+import (
+	"fmt"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// NotConfiguredError is returned when publishing to an environment which has no publish configuration.
+type NotConfiguredError struct {
+	Env config.Env
+}
+
+func (e *NotConfiguredError) Error() string {
+	return fmt.Sprintf("environment %s has no publish configuration in json-schema-manager-config.yml", e.Env)
+}
+
+// MissingDistributionError is returned when an environment's distribution has not been built.
+type MissingDistributionError struct {
+	Env config.Env
+	Dir string
+}
+
+func (e *MissingDistributionError) Error() string {
+	return fmt.Sprintf("no distribution found for environment %s in %s: run jsm build-dist %s first",
+		e.Env, e.Dir, e.Env)
+}
+
+// InvalidManifestError is returned when a distribution's manifest cannot be read.
+type InvalidManifestError struct {
+	Path    string
+	Wrapped error
+}
+
+func (e *InvalidManifestError) Error() string {
+	return fmt.Sprintf("invalid distribution manifest %s: %v", e.Path, e.Wrapped)
+}
+
+func (e *InvalidManifestError) Unwrap() error {
+	return e.Wrapped
+}
+
+// MissingCredentialsError is returned when the credentials for a store are not set in the environment.
+type MissingCredentialsError struct {
+	Vars []string
+}
+
+func (e *MissingCredentialsError) Error() string {
+	return fmt.Sprintf("missing credentials: set the environment variables %v", e.Vars)
+}
+
+// S3RequestError is returned when a request to an S3 bucket fails.
+type S3RequestError struct {
+	Method     string
+	Key        string
+	StatusCode int
+	Body       string
+}
+
+func (e *S3RequestError) Error() string {
+	return fmt.Sprintf("S3 %s %s failed with status %d: %s", e.Method, e.Key, e.StatusCode, e.Body)
+}
+
+// SchemaRegistryRequestError is returned when a request to a Schema Registry fails.
+type SchemaRegistryRequestError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	ErrorCode  int // The Schema Registry error code, if the response had one
+	Message    string
+}
+
+func (e *SchemaRegistryRequestError) Error() string {
+	if e.ErrorCode != 0 {
+		return fmt.Sprintf("schema registry %s %s failed with status %d (error %d): %s",
+			e.Method, e.Path, e.StatusCode, e.ErrorCode, e.Message)
+	}
+	return fmt.Sprintf("schema registry %s %s failed with status %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// InvalidSchemaError is returned when a schema in a distribution is not valid JSON.
+type InvalidSchemaError struct {
+	Key     schema.Key
+	Wrapped error
+}
+
+func (e *InvalidSchemaError) Error() string {
+	return fmt.Sprintf("invalid schema %s in distribution: %v", e.Key, e.Wrapped)
+}
+
+func (e *InvalidSchemaError) Unwrap() error {
+	return e.Wrapped
+}
+
+// UnregisteredDependencyError is returned when a schema depends on a schema which is neither in the
+// distribution nor registered in the Schema Registry.
+type UnregisteredDependencyError struct {
+	Key        schema.Key
+	Dependency schema.Key
+}
+
+func (e *UnregisteredDependencyError) Error() string {
+	return fmt.Sprintf("schema %s depends on %s, which is not in the distribution and has not been registered",
+		e.Key, e.Dependency)
+}
@@ -0,0 +1,79 @@
+// Package publish uploads the distributions built by build-dist to the stores they are served from.
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// Distribution is the output of build-dist for an environment.
+type Distribution struct {
+	Env      config.Env
+	Dir      string // The environment's dist directory
+	Manifest *schema.Manifest
+}
+
+// Result summarises a publication.
+type Result struct {
+	Uploaded  int // The number of files uploaded
+	Unchanged int // The number of files skipped because the store already holds them
+	Deleted   int // The number of schemas deleted because a rollback removes them
+}
+
+// Publisher uploads a distribution to a store.
+type Publisher interface {
+	// Publish uploads the files of the distribution which the store does not already hold, and deletes the
+	// schemas listed as removed by the manifest of a rollback distribution.
+	Publish(ctx context.Context, dist *Distribution) (Result, error)
+}
+
+// Factory creates the Publisher configured for an environment.
+type Factory func(ec *config.EnvConfig) (Publisher, error)
+
+// NewFactory returns a Factory which creates the publishers configured for environments, reading any
+// credentials they need from envProvider and making requests with client.
+func NewFactory(envProvider fsh.EnvProvider, client *http.Client) Factory {
+	return func(ec *config.EnvConfig) (Publisher, error) {
+		switch {
+		case ec.Publish == nil:
+			return nil, &NotConfiguredError{Env: ec.Env}
+		case ec.Publish.SchemaRegistry != nil:
+			return NewSchemaRegistryPublisher(ec, envProvider, client), nil
+		case ec.Publish.S3 != nil:
+			p, err := NewS3Publisher(ec, envProvider, client)
+			if err != nil {
+				return nil, err
+			}
+			return p, nil
+		default:
+			return nil, &NotConfiguredError{Env: ec.Env}
+		}
+	}
+}
+
+// LoadDistribution reads the distribution built for an environment into dir.
+func LoadDistribution(env config.Env, dir string) (*Distribution, error) {
+	//nolint:gosec // Path is constructed from internal dist logic
+	data, err := os.ReadFile(filepath.Join(dir, schema.ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &MissingDistributionError{Env: env, Dir: dir}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m schema.Manifest
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, &InvalidManifestError{Path: filepath.Join(dir, schema.ManifestFile), Wrapped: err}
+	}
+	return &Distribution{Env: env, Dir: dir, Manifest: &m}, nil
+}
@@ -0,0 +1,103 @@
+package publish
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func TestNewFactory(t *testing.T) {
+	t.Parallel()
+
+	s3Config := &config.PublishConfig{S3: &config.S3PublishConfig{Bucket: "schemas"}}
+
+	t.Run("s3", func(t *testing.T) {
+		t.Parallel()
+		p, err := NewFactory(testCredentials(), http.DefaultClient)(&config.EnvConfig{Env: "prod", Publish: s3Config})
+		require.NoError(t, err)
+		assert.IsType(t, &S3Publisher{}, p)
+	})
+
+	t.Run("schema registry", func(t *testing.T) {
+		t.Parallel()
+		ec := &config.EnvConfig{Env: "prod", Publish: &config.PublishConfig{
+			SchemaRegistry: &config.SchemaRegistryPublishConfig{URL: "http://localhost:8081"},
+		}}
+		p, err := NewFactory(&mockEnvProvider{}, http.DefaultClient)(ec)
+		require.NoError(t, err)
+		assert.IsType(t, &SchemaRegistryPublisher{}, p)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		t.Parallel()
+		for _, ec := range []*config.EnvConfig{
+			{Env: "prod"},
+			{Env: "prod", Publish: &config.PublishConfig{}},
+		} {
+			_, err := NewFactory(testCredentials(), http.DefaultClient)(ec)
+			var target *NotConfiguredError
+			require.ErrorAs(t, err, &target)
+			assert.Equal(t, config.Env("prod"), target.Env)
+		}
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewFactory(&mockEnvProvider{}, http.DefaultClient)(&config.EnvConfig{Env: "prod", Publish: s3Config})
+		var target *MissingCredentialsError
+		require.ErrorAs(t, err, &target)
+	})
+}
+
+func TestLoadDistribution(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		manifest := `{"env":"prod","schemas":[{"key":"domain_family_1_0_0"}]}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, schema.ManifestFile), []byte(manifest), 0o600))
+
+		dist, err := LoadDistribution("prod", dir)
+		require.NoError(t, err)
+		assert.Equal(t, dir, dist.Dir)
+		assert.Equal(t, config.Env("prod"), dist.Env)
+		require.Len(t, dist.Manifest.Schemas, 1)
+		assert.Equal(t, schema.Key("domain_family_1_0_0"), dist.Manifest.Schemas[0].Key)
+	})
+
+	t.Run("not built", func(t *testing.T) {
+		t.Parallel()
+		_, err := LoadDistribution("prod", t.TempDir())
+		var target *MissingDistributionError
+		require.ErrorAs(t, err, &target)
+		assert.Contains(t, err.Error(), "run jsm build-dist prod first")
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, schema.ManifestFile), []byte("{"), 0o600))
+
+		_, err := LoadDistribution("prod", dir)
+		var target *InvalidManifestError
+		require.ErrorAs(t, err, &target)
+		require.Error(t, target.Unwrap())
+	})
+
+	t.Run("unreadable manifest", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, schema.ManifestFile), 0o750))
+
+		_, err := LoadDistribution("prod", dir)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,319 @@
+package publish
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// The environment variables the credentials of an S3Publisher are read from.
+const (
+	AccessKeyIDEnvVar     = "AWS_ACCESS_KEY_ID"
+	SecretAccessKeyEnvVar = "AWS_SECRET_ACCESS_KEY"
+	SessionTokenEnvVar    = "AWS_SESSION_TOKEN" //nolint:gosec // The name of the variable, not a credential
+)
+
+// The Cache-Control headers of published objects. Schemas in an environment which does not allow mutation
+// never change once published, so may be cached indefinitely. Manifests and indexes change with every
+// publication, so must be revalidated.
+const (
+	immutableCacheControl  = "max-age=31536000, immutable"
+	revalidateCacheControl = "no-cache"
+)
+
+// digestMetadataHeader is the header of the user-defined object metadata holding the hex encoded SHA-256
+// digest of a published object, which is used to skip unchanged objects.
+const digestMetadataHeader = "X-Amz-Meta-Sha256"
+
+// maxErrorBodySize is the maximum number of bytes of an S3 error response included in an S3RequestError.
+const maxErrorBodySize = 1024
+
+// visibilities are the dist subdirectories a schema may be published in.
+var visibilities = []string{"public", "private"}
+
+// S3Publisher publishes distributions to a bucket in Amazon S3, or in an S3-compatible object store.
+type S3Publisher struct {
+	cfg     *config.S3PublishConfig
+	mutable bool // Whether published schemas may change, so cannot be cached indefinitely
+	creds   credentials
+	client  *http.Client
+	now     func() time.Time
+}
+
+// NewS3Publisher creates a new S3Publisher for the environment, whose publish configuration must have an s3
+// bucket. The credentials are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// environment variables.
+func NewS3Publisher(ec *config.EnvConfig, envProvider fsh.EnvProvider, client *http.Client) (*S3Publisher, error) {
+	creds := credentials{
+		accessKeyID:     envProvider.Get(AccessKeyIDEnvVar),
+		secretAccessKey: envProvider.Get(SecretAccessKeyEnvVar),
+		sessionToken:    envProvider.Get(SessionTokenEnvVar),
+	}
+	if creds.accessKeyID == "" || creds.secretAccessKey == "" {
+		return nil, &MissingCredentialsError{Vars: []string{AccessKeyIDEnvVar, SecretAccessKeyEnvVar}}
+	}
+
+	return &S3Publisher{
+		cfg:     ec.Publish.S3,
+		mutable: ec.AllowSchemaMutation,
+		creds:   creds,
+		client:  client,
+		now:     time.Now,
+	}, nil
+}
+
+// Publish uploads the files of the distribution whose digest differs from that of the object in the bucket.
+// Schemas are uploaded first, then family indexes, and the manifest last, so that the published manifest and
+// indexes never refer to schemas which have not been uploaded. The objects of the schemas a rollback removes
+// are deleted before the manifest is uploaded.
+func (p *S3Publisher) Publish(ctx context.Context, dist *Distribution) (Result, error) {
+	var res Result
+
+	files, err := distFiles(dist.Dir)
+	if err != nil {
+		return res, err
+	}
+
+	for _, f := range files {
+		if f == schema.ManifestFile {
+			if dErr := p.deleteRemoved(ctx, dist.Manifest, &res); dErr != nil {
+				return res, dErr
+			}
+		}
+
+		//nolint:gosec // Path is constructed from internal dist logic
+		data, rErr := os.ReadFile(filepath.Join(dist.Dir, filepath.FromSlash(f)))
+		if rErr != nil {
+			return res, rErr
+		}
+
+		key := p.objectKey(f)
+		digest := hexSHA256(data)
+		current, _, hErr := p.head(ctx, key)
+		if hErr != nil {
+			return res, hErr
+		}
+		if current == digest {
+			res.Unchanged++
+			continue
+		}
+
+		if pErr := p.put(ctx, key, data, p.objectHeader(f, digest)); pErr != nil {
+			return res, pErr
+		}
+		res.Uploaded++
+	}
+	return res, nil
+}
+
+// deleteRemoved deletes the objects of the schemas the manifest lists as removed, at whichever visibility
+// they were published.
+func (p *S3Publisher) deleteRemoved(ctx context.Context, m *schema.Manifest, res *Result) error {
+	if m == nil {
+		return nil
+	}
+
+	for _, k := range m.Removed {
+		for _, vis := range visibilities {
+			key := p.objectKey(vis + "/" + string(k) + schema.SchemaSuffix)
+			_, found, err := p.head(ctx, key)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			if err = p.delete(ctx, key); err != nil {
+				return err
+			}
+			res.Deleted++
+		}
+	}
+	return nil
+}
+
+// distFiles returns the paths of the files in a dist directory, relative to it and using forward slashes,
+// in the order they are published.
+func distFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, rErr := filepath.Rel(dir, p)
+		if rErr != nil {
+			return rErr
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(files, func(a, b string) int {
+		return cmp.Or(cmp.Compare(publishRank(a), publishRank(b)), strings.Compare(a, b))
+	})
+	return files, nil
+}
+
+// publishRank returns the position of a dist file in the publication order: schemas, then family indexes,
+// then the manifest.
+func publishRank(f string) int {
+	switch {
+	case f == schema.ManifestFile:
+		return 2
+	case strings.HasPrefix(f, schema.FamilyIndexDir+"/"):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// objectHeader returns the headers of the object for a dist file with the given digest.
+func (p *S3Publisher) objectHeader(f, digest string) http.Header {
+	header := http.Header{}
+	header.Set("Content-Type", contentType(f))
+	header.Set("Cache-Control", p.cacheControl(f))
+	header.Set(digestMetadataHeader, digest)
+	return header
+}
+
+// cacheControl returns the Cache-Control header of the object for a dist file.
+func (p *S3Publisher) cacheControl(f string) string {
+	if publishRank(f) == 0 && !p.mutable {
+		return immutableCacheControl
+	}
+	return revalidateCacheControl
+}
+
+// contentType returns the Content-Type header of the object for a dist file.
+func contentType(f string) string {
+	switch {
+	case strings.HasSuffix(f, schema.SchemaSuffix):
+		return "application/schema+json"
+	case path.Ext(f) == ".json":
+		return "application/json"
+	}
+	if t := mime.TypeByExtension(path.Ext(f)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// objectKey returns the key of the object for a dist file.
+func (p *S3Publisher) objectKey(f string) string {
+	prefix := strings.Trim(p.cfg.Prefix, "/")
+	if prefix == "" {
+		return f
+	}
+	return prefix + "/" + f
+}
+
+// objectURL returns the URL of the object with the given key.
+func (p *S3Publisher) objectURL(key string) *url.URL {
+	endpoint := p.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + p.cfg.Region + ".amazonaws.com"
+	}
+	// The endpoint is validated with the configuration.
+	u, _ := url.Parse(endpoint)
+
+	objectPath := strings.TrimSuffix(u.Path, "/") + "/" + key
+	if p.cfg.PathStyle {
+		objectPath = strings.TrimSuffix(u.Path, "/") + "/" + p.cfg.Bucket + "/" + key
+	} else {
+		u.Host = p.cfg.Bucket + "." + u.Host
+	}
+	u.Path = objectPath
+	u.RawPath = uriEncode(objectPath, false)
+	return u
+}
+
+// head returns the digest recorded in the metadata of the object with the given key, and whether there is
+// such an object.
+func (p *S3Publisher) head(ctx context.Context, key string) (string, bool, error) {
+	resp, err := p.do(ctx, http.MethodHead, key, nil, emptyPayloadHash, nil)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get(digestMetadataHeader), true, nil
+	case http.StatusNotFound:
+		return "", false, nil
+	default:
+		return "", false, &S3RequestError{
+			Method:     http.MethodHead,
+			Key:        key,
+			StatusCode: resp.StatusCode,
+			Body:       http.StatusText(resp.StatusCode),
+		}
+	}
+}
+
+// put uploads an object with the given headers, which must include its digest.
+func (p *S3Publisher) put(ctx context.Context, key string, data []byte, header http.Header) error {
+	resp, err := p.do(ctx, http.MethodPut, key, data, header.Get(digestMetadataHeader), header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return &S3RequestError{Method: http.MethodPut, Key: key, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// delete deletes the object with the given key.
+func (p *S3Publisher) delete(ctx context.Context, key string) error {
+	resp, err := p.do(ctx, http.MethodDelete, key, nil, emptyPayloadHash, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return &S3RequestError{Method: http.MethodDelete, Key: key, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// do sends a signed request for the object with the given key.
+func (p *S3Publisher) do(
+	ctx context.Context,
+	method, key string,
+	body []byte,
+	payloadHash string,
+	header http.Header,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.objectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, vals := range header {
+		req.Header[name] = vals
+	}
+	signV4(req, payloadHash, p.creds, p.cfg.Region, "s3", p.now())
+	return p.client.Do(req)
+}
@@ -0,0 +1,424 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// The environment variables the basic authentication credentials of a SchemaRegistryPublisher are read from.
+// They are optional, as a Schema Registry may not require authentication.
+const (
+	SchemaRegistryUsernameEnvVar = "SCHEMA_REGISTRY_USERNAME"
+	SchemaRegistryPasswordEnvVar = "SCHEMA_REGISTRY_PASSWORD" //nolint:gosec // The name of the variable
+)
+
+// schemaRegistryContentType is the content type of Schema Registry REST API requests.
+const schemaRegistryContentType = "application/vnd.schemaregistry.v1+json"
+
+// srSchema is a schema, as registered with a Schema Registry.
+type srSchema struct {
+	Schema     string        `json:"schema"`
+	SchemaType string        `json:"schemaType,omitempty"`
+	References []srReference `json:"references,omitempty"`
+}
+
+// srReference is a reference from a registered schema to another, identified by the $ref it is referenced with.
+type srReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// srVersion is a version of a subject in a Schema Registry.
+type srVersion struct {
+	Version int    `json:"version"`
+	Schema  string `json:"schema"`
+}
+
+// srError is the body of an error response from a Schema Registry.
+type srError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// SchemaRegistryPublisher publishes distributions to a Confluent-compatible Schema Registry, registering
+// each schema under a subject derived from its key. The schemas referenced with {{ JSM }} are registered
+// as references of the schema.
+type SchemaRegistryPublisher struct {
+	cfg      *config.SchemaRegistryPublishConfig
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewSchemaRegistryPublisher creates a new SchemaRegistryPublisher for the environment, whose publish
+// configuration must have a schemaRegistry. Any basic authentication credentials are read from the
+// SCHEMA_REGISTRY_USERNAME and SCHEMA_REGISTRY_PASSWORD environment variables.
+func NewSchemaRegistryPublisher(
+	ec *config.EnvConfig,
+	envProvider fsh.EnvProvider,
+	client *http.Client,
+) *SchemaRegistryPublisher {
+	return &SchemaRegistryPublisher{
+		cfg:      ec.Publish.SchemaRegistry,
+		username: envProvider.Get(SchemaRegistryUsernameEnvVar),
+		password: envProvider.Get(SchemaRegistryPasswordEnvVar),
+		client:   client,
+	}
+}
+
+// srPublication holds the state of a single publication to a Schema Registry.
+type srPublication struct {
+	dist     *Distribution
+	ids      map[schema.Key]schema.ID // The IDs of the schemas in the distribution
+	versions map[schema.Key]int       // The subject versions of the schemas registered, or found, so far
+}
+
+// Publish registers the schemas of the distribution which are not already registered. A schema's
+// dependencies in the distribution are registered before it. Dependencies which are not in the
+// distribution must already be registered. The subject versions of the schemas a rollback removes are
+// then deleted.
+func (p *SchemaRegistryPublisher) Publish(ctx context.Context, dist *Distribution) (Result, error) {
+	var res Result
+
+	pub := &srPublication{dist: dist, ids: map[schema.Key]schema.ID{}, versions: map[schema.Key]int{}}
+	for _, e := range dist.Manifest.Schemas {
+		pub.ids[e.Key] = e.ID
+	}
+
+	for _, e := range publicationOrder(dist.Manifest.Schemas) {
+		registered, err := p.publishEntry(ctx, pub, e)
+		if err != nil {
+			return res, err
+		}
+		if registered {
+			res.Uploaded++
+		} else {
+			res.Unchanged++
+		}
+	}
+	return res, p.deleteRemoved(ctx, dist.Manifest.Removed, &res)
+}
+
+// deleteRemoved deletes the subject versions under which the removed schemas are registered. The versions
+// are soft deleted, so they can be restored if the schemas are deployed again.
+func (p *SchemaRegistryPublisher) deleteRemoved(ctx context.Context, removed []schema.Key, res *Result) error {
+	for _, k := range removed {
+		subject := p.subject(k)
+		version, err := p.findVersion(ctx, subject, func(id schema.ID) bool {
+			return strings.HasSuffix(string(id), "/"+string(k)+schema.SchemaSuffix)
+		})
+		if err != nil {
+			return err
+		}
+		if version == 0 {
+			continue
+		}
+
+		path := "/subjects/" + url.PathEscape(subject) + "/versions/" + strconv.Itoa(version)
+		if _, err = p.do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+			return err
+		}
+		res.Deleted++
+	}
+	return nil
+}
+
+// publicationOrder returns the manifest entries ordered so that each schema follows the schemas in the
+// manifest which it depends on.
+func publicationOrder(entries []schema.ManifestEntry) []schema.ManifestEntry {
+	byKey := make(map[schema.Key]schema.ManifestEntry, len(entries))
+	for _, e := range entries {
+		byKey[e.Key] = e
+	}
+
+	order := make([]schema.ManifestEntry, 0, len(entries))
+	visited := make(map[schema.Key]bool, len(entries))
+	var visit func(e schema.ManifestEntry)
+	visit = func(e schema.ManifestEntry) {
+		if visited[e.Key] {
+			return
+		}
+		visited[e.Key] = true
+		for _, dep := range e.Dependencies {
+			if d, ok := byKey[dep]; ok {
+				visit(d)
+			}
+		}
+		order = append(order, e)
+	}
+
+	for _, e := range entries {
+		visit(e)
+	}
+	return order
+}
+
+// publishEntry registers the schema described by a manifest entry, unless it is already registered.
+// It returns true if the schema was registered.
+func (p *SchemaRegistryPublisher) publishEntry(
+	ctx context.Context,
+	pub *srPublication,
+	e schema.ManifestEntry,
+) (bool, error) {
+	//nolint:gosec // Path is constructed from internal dist logic
+	data, err := os.ReadFile(filepath.Join(pub.dist.Dir, filepath.FromSlash(e.DistPath)))
+	if err != nil {
+		return false, err
+	}
+
+	refs, err := p.references(ctx, pub, e, data)
+	if err != nil {
+		return false, err
+	}
+
+	subject := p.subject(e.Key)
+	s := srSchema{Schema: string(data), SchemaType: "JSON", References: refs}
+	version, found, err := p.lookup(ctx, subject, s)
+	if err != nil || found {
+		pub.versions[e.Key] = version
+		return false, err
+	}
+
+	if err = p.setCompatibility(ctx, subject, p.compatibility(e)); err != nil {
+		return false, err
+	}
+
+	// The registration response only holds the schema's ID, so the version it was registered as is looked up.
+	if _, err = p.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", s, nil); err != nil {
+		return false, err
+	}
+	version, found, err = p.lookup(ctx, subject, s)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, &SchemaRegistryRequestError{
+			Method:     http.MethodPost,
+			Path:       "/subjects/" + url.PathEscape(subject),
+			StatusCode: http.StatusNotFound,
+			Message:    "schema " + string(e.Key) + " not found after registration",
+		}
+	}
+	pub.versions[e.Key] = version
+	return true, nil
+}
+
+// subject returns the subject a schema is registered under.
+func (p *SchemaRegistryPublisher) subject(k schema.Key) string {
+	family := strings.ReplaceAll(string(k.FamilyScope()), schema.SearchSeparatorString, schema.KeySeparatorString)
+	switch p.cfg.SubjectNaming {
+	case config.SubjectNamingKey:
+		return string(k)
+	case config.SubjectNamingFamily:
+		return family
+	default:
+		return family + schema.KeySeparatorString + strconv.FormatUint(k.Major(), 10)
+	}
+}
+
+// compatibility returns the compatibility level of the subject of a schema. Experimental families may
+// still change significantly, so breaking changes are allowed. So are they when every major version of a
+// family shares a subject, as a new major version is expected to break compatibility with the last.
+func (p *SchemaRegistryPublisher) compatibility(e schema.ManifestEntry) config.Compatibility {
+	if e.Status == schema.StatusExperimental || p.cfg.SubjectNaming == config.SubjectNamingFamily {
+		return config.CompatibilityNone
+	}
+	return p.cfg.Compatibility
+}
+
+// references returns the Schema Registry references of a rendered schema to its dependencies.
+func (p *SchemaRegistryPublisher) references(
+	ctx context.Context,
+	pub *srPublication,
+	e schema.ManifestEntry,
+	data []byte,
+) ([]srReference, error) {
+	if len(e.Dependencies) == 0 {
+		return nil, nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, &InvalidSchemaError{Key: e.Key, Wrapped: err}
+	}
+	refs := collectRefs(doc, nil)
+
+	out := make([]srReference, 0, len(e.Dependencies))
+	for _, dep := range e.Dependencies {
+		id, ok := pub.ids[dep]
+		if !ok {
+			if id, ok = refTo(refs, dep); !ok {
+				return nil, &UnregisteredDependencyError{Key: e.Key, Dependency: dep}
+			}
+		}
+
+		subject := p.subject(dep)
+		version, ok := pub.versions[dep]
+		if !ok {
+			var err error
+			if version, err = p.findVersion(ctx, subject, func(v schema.ID) bool { return v == id }); err != nil {
+				return nil, err
+			}
+			if version == 0 {
+				return nil, &UnregisteredDependencyError{Key: e.Key, Dependency: dep}
+			}
+			pub.versions[dep] = version
+		}
+		out = append(out, srReference{Name: string(id), Subject: subject, Version: version})
+	}
+	return out, nil
+}
+
+// collectRefs appends the values of the $ref keywords in a JSON document, without any fragment, to refs.
+func collectRefs(doc any, refs []string) []string {
+	switch v := doc.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			base, _, _ := strings.Cut(ref, "#")
+			refs = append(refs, base)
+		}
+		for _, child := range v {
+			refs = collectRefs(child, refs)
+		}
+	case []any:
+		for _, child := range v {
+			refs = collectRefs(child, refs)
+		}
+	}
+	return refs
+}
+
+// refTo returns the ID of the schema with the given key, as referenced by one of refs.
+func refTo(refs []string, k schema.Key) (schema.ID, bool) {
+	i := slices.IndexFunc(refs, func(ref string) bool {
+		return strings.HasSuffix(ref, "/"+string(k)+schema.SchemaSuffix)
+	})
+	if i < 0 {
+		return "", false
+	}
+	return schema.ID(refs[i]), true
+}
+
+// setCompatibility sets the compatibility level of a subject.
+func (p *SchemaRegistryPublisher) setCompatibility(ctx context.Context, subject string, c config.Compatibility) error {
+	body := map[string]config.Compatibility{"compatibility": c}
+	_, err := p.do(ctx, http.MethodPut, "/config/"+url.PathEscape(subject), body, nil)
+	return err
+}
+
+// lookup returns the version of a subject under which a schema is registered, and whether it is registered.
+func (p *SchemaRegistryPublisher) lookup(ctx context.Context, subject string, s srSchema) (int, bool, error) {
+	var v srVersion
+	status, err := p.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject), s, &v)
+	if status == http.StatusNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return v.Version, true, nil
+}
+
+// findVersion returns the version of a subject whose schema has an $id which matches, or 0 if there is none.
+// The latest versions are searched first.
+func (p *SchemaRegistryPublisher) findVersion(
+	ctx context.Context,
+	subject string,
+	match func(id schema.ID) bool,
+) (int, error) {
+	var versions []int
+	status, err := p.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions", nil, &versions)
+	if status == http.StatusNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	slices.Reverse(versions)
+	for _, n := range versions {
+		var v srVersion
+		path := "/subjects/" + url.PathEscape(subject) + "/versions/" + strconv.Itoa(n)
+		if _, err = p.do(ctx, http.MethodGet, path, nil, &v); err != nil {
+			return 0, err
+		}
+		var doc struct {
+			ID schema.ID `json:"$id"`
+		}
+		if json.Unmarshal([]byte(v.Schema), &doc) == nil && match(doc.ID) {
+			return n, nil
+		}
+	}
+	return 0, nil
+}
+
+// do sends a request to the Schema Registry, encoding in as the request body and decoding the response
+// body into out. It returns the status of the response, and a SchemaRegistryRequestError if it is not a
+// success.
+func (p *SchemaRegistryPublisher) do(ctx context.Context, method, path string, in, out any) (int, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(p.cfg.URL, "/")+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", schemaRegistryContentType)
+	if in != nil {
+		req.Header.Set("Content-Type", schemaRegistryContentType)
+	}
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e srError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&e)
+		return resp.StatusCode, &SchemaRegistryRequestError{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			ErrorCode:  e.ErrorCode,
+			Message:    e.Message,
+		}
+	}
+
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, &SchemaRegistryRequestError{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Message:    "invalid response: " + err.Error(),
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,403 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// fakeSchemaRegistry is an in-memory stand-in for the subset of the Confluent Schema Registry REST API
+// used by the SchemaRegistryPublisher.
+type fakeSchemaRegistry struct {
+	mu            sync.Mutex
+	subjects      map[string][]srSchema
+	compatibility map[string]string
+	configured    []string       // The subjects whose compatibility level was set, in order
+	registered    []string       // The subjects registered to, in order
+	deleted       []string       // The subject versions deleted, as subject/version, in order
+	reject        map[string]int // Status codes to reject registrations to subjects with
+	auth          string         // The required basic authentication credentials, as user:password
+}
+
+func newFakeSchemaRegistry(t *testing.T) (*fakeSchemaRegistry, *httptest.Server) {
+	t.Helper()
+	f := &fakeSchemaRegistry{
+		subjects:      map[string][]srSchema{},
+		compatibility: map[string]string{},
+		reject:        map[string]int{},
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeSchemaRegistry) writeError(w http.ResponseWriter, status, code int, msg string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(srError{ErrorCode: code, Message: msg})
+}
+
+func (f *fakeSchemaRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if user, pass, _ := r.BasicAuth(); f.auth != "" && user+":"+pass != f.auth {
+		f.writeError(w, http.StatusUnauthorized, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodPut && len(parts) == 2 && parts[0] == "config":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.compatibility[parts[1]] = body["compatibility"]
+		f.configured = append(f.configured, parts[1])
+		_ = json.NewEncoder(w).Encode(body)
+	case r.Method == http.MethodPost && len(parts) == 2:
+		f.lookup(w, r, parts[1])
+	case r.Method == http.MethodPost && len(parts) == 3:
+		f.register(w, r, parts[1])
+	case r.Method == http.MethodGet && len(parts) == 3:
+		versions, ok := f.subjects[parts[1]]
+		if !ok {
+			f.writeError(w, http.StatusNotFound, 40401, "Subject not found.")
+			return
+		}
+		list := make([]int, 0, len(versions))
+		for i := range versions {
+			if !f.isDeleted(parts[1], i+1) {
+				list = append(list, i+1)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodGet && len(parts) == 4:
+		n, _ := strconv.Atoi(parts[3])
+		_ = json.NewEncoder(w).Encode(srVersion{Version: n, Schema: f.subjects[parts[1]][n-1].Schema})
+	case r.Method == http.MethodDelete && len(parts) == 4:
+		n, _ := strconv.Atoi(parts[3])
+		if n < 1 || n > len(f.subjects[parts[1]]) || f.isDeleted(parts[1], n) {
+			f.writeError(w, http.StatusNotFound, 40402, "Version not found.")
+			return
+		}
+		f.deleted = append(f.deleted, parts[1]+"/"+parts[3])
+		_ = json.NewEncoder(w).Encode(n)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeSchemaRegistry) lookup(w http.ResponseWriter, r *http.Request, subject string) {
+	var s srSchema
+	_ = json.NewDecoder(r.Body).Decode(&s)
+	versions, ok := f.subjects[subject]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, 40401, "Subject not found.")
+		return
+	}
+	for i, v := range versions {
+		if reflect.DeepEqual(v, s) && !f.isDeleted(subject, i+1) {
+			_ = json.NewEncoder(w).Encode(srVersion{Version: i + 1, Schema: v.Schema})
+			return
+		}
+	}
+	f.writeError(w, http.StatusNotFound, 40403, "Schema not found")
+}
+
+func (f *fakeSchemaRegistry) isDeleted(subject string, version int) bool {
+	return slices.Contains(f.deleted, subject+"/"+strconv.Itoa(version))
+}
+
+func (f *fakeSchemaRegistry) register(w http.ResponseWriter, r *http.Request, subject string) {
+	if status := f.reject[subject]; status != 0 {
+		f.writeError(w, status, status, "Schema being registered is incompatible with an earlier schema")
+		return
+	}
+	var s srSchema
+	_ = json.NewDecoder(r.Body).Decode(&s)
+	for _, ref := range s.References {
+		if ref.Version < 1 || ref.Version > len(f.subjects[ref.Subject]) {
+			f.writeError(w, http.StatusUnprocessableEntity, 42201, "Invalid reference "+ref.Name)
+			return
+		}
+	}
+	f.subjects[subject] = append(f.subjects[subject], s)
+	f.registered = append(f.registered, subject)
+	_ = json.NewEncoder(w).Encode(map[string]int{"id": len(f.registered)})
+}
+
+// registryDist writes a distribution holding a schema which depends on an experimental family.
+func registryDist(t *testing.T) *Distribution {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"private/domain_order_1_0_0.schema.json": `{"$id":"https://example.com/domain_order_1_0_0.schema.json",` +
+			`"properties":{"customer":{"$ref":"https://example.com/domain_person_1_0_0.schema.json#/$defs/name"}}}`,
+		"public/domain_person_1_0_0.schema.json": `{"$id":"https://example.com/domain_person_1_0_0.schema.json"}`,
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+
+	return &Distribution{Env: "prod", Dir: dir, Manifest: &schema.Manifest{Env: "prod", Schemas: []schema.ManifestEntry{
+		{
+			Key:          "domain_order_1_0_0",
+			ID:           "https://example.com/domain_order_1_0_0.schema.json",
+			DistPath:     "private/domain_order_1_0_0.schema.json",
+			Dependencies: []schema.Key{"domain_person_1_0_0"},
+		},
+		{
+			Key:      "domain_person_1_0_0",
+			ID:       "https://example.com/domain_person_1_0_0.schema.json",
+			DistPath: "public/domain_person_1_0_0.schema.json",
+			Status:   schema.StatusExperimental,
+		},
+	}}}
+}
+
+func newTestSchemaRegistryPublisher(
+	srvURL string,
+	naming config.SubjectNaming,
+	env *mockEnvProvider,
+) *SchemaRegistryPublisher {
+	sr := &config.SchemaRegistryPublishConfig{
+		URL:           srvURL + "/",
+		SubjectNaming: naming,
+		Compatibility: config.CompatibilityBackward,
+	}
+	ec := &config.EnvConfig{Env: "prod", Publish: &config.PublishConfig{SchemaRegistry: sr}}
+	return NewSchemaRegistryPublisher(ec, env, http.DefaultClient)
+}
+
+func TestSchemaRegistryPublisher_subject(t *testing.T) {
+	t.Parallel()
+
+	tests := map[config.SubjectNaming]string{
+		config.SubjectNamingMajor:  "logistics_data_shipment_2",
+		config.SubjectNamingFamily: "logistics_data_shipment",
+		config.SubjectNamingKey:    "logistics_data_shipment_2_1_0",
+	}
+	for naming, want := range tests {
+		p := newTestSchemaRegistryPublisher("http://localhost", naming, &mockEnvProvider{})
+		assert.Equal(t, want, p.subject("logistics_data_shipment_2_1_0"), naming)
+	}
+}
+
+func TestCollectRefs(t *testing.T) {
+	t.Parallel()
+
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{"allOf":[{"$ref":"https://a/x.schema.json"}],`+
+		`"properties":{"b":{"$ref":"https://a/y.schema.json#/$defs/b"}}}`), &doc))
+	refs := collectRefs(doc, nil)
+	assert.ElementsMatch(t, []string{"https://a/x.schema.json", "https://a/y.schema.json"}, refs)
+
+	id, ok := refTo(refs, "y")
+	assert.True(t, ok)
+	assert.Equal(t, schema.ID("https://a/y.schema.json"), id)
+	_, ok = refTo(refs, "z")
+	assert.False(t, ok)
+}
+
+func TestSchemaRegistryPublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	personID := "https://example.com/domain_person_1_0_0.schema.json"
+
+	t.Run("registers dependencies first", func(t *testing.T) {
+		t.Parallel()
+		reg, srv := newFakeSchemaRegistry(t)
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{})
+		dist := registryDist(t)
+
+		res, err := p.Publish(context.Background(), dist)
+		require.NoError(t, err)
+		assert.Equal(t, Result{Uploaded: 2}, res)
+		assert.Equal(t, []string{"domain_person_1", "domain_order_1"}, reg.registered)
+		assert.Equal(t, []srReference{{Name: personID, Subject: "domain_person_1", Version: 1}},
+			reg.subjects["domain_order_1"][0].References)
+		assert.Equal(t, "JSON", reg.subjects["domain_order_1"][0].SchemaType)
+		assert.Equal(t, "BACKWARD", reg.compatibility["domain_order_1"])
+		assert.Equal(t, "NONE", reg.compatibility["domain_person_1"])
+
+		// Publishing again registers nothing.
+		res, err = p.Publish(context.Background(), dist)
+		require.NoError(t, err)
+		assert.Equal(t, Result{Unchanged: 2}, res)
+		assert.Len(t, reg.registered, 2)
+		assert.Equal(t, []string{"domain_person_1", "domain_order_1"}, reg.configured)
+	})
+
+	t.Run("finds dependencies outside the distribution", func(t *testing.T) {
+		t.Parallel()
+		reg, srv := newFakeSchemaRegistry(t)
+		reg.subjects["domain_person"] = []srSchema{
+			{Schema: `{"$id":"` + personID + `"}`, SchemaType: "JSON"},
+			{Schema: `{"$id":"https://example.com/domain_person_1_1_0.schema.json"}`, SchemaType: "JSON"},
+		}
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingFamily, &mockEnvProvider{})
+		dist := registryDist(t)
+		dist.Manifest.Schemas = dist.Manifest.Schemas[:1]
+
+		res, err := p.Publish(context.Background(), dist)
+		require.NoError(t, err)
+		assert.Equal(t, Result{Uploaded: 1}, res)
+		assert.Equal(t, []srReference{{Name: personID, Subject: "domain_person", Version: 1}},
+			reg.subjects["domain_order"][0].References)
+		// Every major version of the family shares the subject, so breaking changes are allowed.
+		assert.Equal(t, "NONE", reg.compatibility["domain_order"])
+	})
+
+	t.Run("deletes the schemas a rollback removes", func(t *testing.T) {
+		t.Parallel()
+		reg, srv := newFakeSchemaRegistry(t)
+		reg.subjects["domain_person"] = []srSchema{
+			{Schema: `{"$id":"` + personID + `"}`, SchemaType: "JSON"},
+			{Schema: `{"$id":"https://example.com/domain_person_1_1_0.schema.json"}`, SchemaType: "JSON"},
+		}
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingFamily, &mockEnvProvider{})
+		dist := registryDist(t)
+		dist.Manifest.Schemas = dist.Manifest.Schemas[1:]
+		dist.Manifest.Removed = []schema.Key{"domain_person_1_1_0", "domain_order_1_0_0"}
+
+		res, err := p.Publish(context.Background(), dist)
+		require.NoError(t, err)
+		assert.Equal(t, Result{Unchanged: 1, Deleted: 1}, res)
+		assert.Equal(t, []string{"domain_person/2"}, reg.deleted)
+
+		// Publishing again has nothing left to delete.
+		res, err = p.Publish(context.Background(), dist)
+		require.NoError(t, err)
+		assert.Equal(t, Result{Unchanged: 1}, res)
+	})
+
+	t.Run("unregistered dependency", func(t *testing.T) {
+		t.Parallel()
+		reg, srv := newFakeSchemaRegistry(t)
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingKey, &mockEnvProvider{})
+		dist := registryDist(t)
+		dist.Manifest.Schemas = dist.Manifest.Schemas[:1]
+
+		_, err := p.Publish(context.Background(), dist)
+		var target *UnregisteredDependencyError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, schema.Key("domain_person_1_0_0"), target.Dependency)
+
+		reg.subjects["domain_person_1_0_0"] = []srSchema{{Schema: `{"$id":"https://example.com/other.schema.json"}`}}
+		_, err = p.Publish(context.Background(), dist)
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("dependency not referenced", func(t *testing.T) {
+		t.Parallel()
+		_, srv := newFakeSchemaRegistry(t)
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{})
+		dist := registryDist(t)
+		dist.Manifest.Schemas = dist.Manifest.Schemas[:1]
+		dist.Manifest.Schemas[0].Dependencies = []schema.Key{"domain_address_1_0_0"}
+
+		_, err := p.Publish(context.Background(), dist)
+		var target *UnregisteredDependencyError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		t.Parallel()
+		_, srv := newFakeSchemaRegistry(t)
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{})
+		dist := registryDist(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dist.Dir, "private", "domain_order_1_0_0.schema.json"),
+			[]byte("{"), 0o600))
+
+		_, err := p.Publish(context.Background(), dist)
+		var target *InvalidSchemaError
+		require.ErrorAs(t, err, &target)
+		require.Error(t, target.Unwrap())
+	})
+
+	t.Run("registration rejected", func(t *testing.T) {
+		t.Parallel()
+		reg, srv := newFakeSchemaRegistry(t)
+		reg.reject["domain_order_1"] = http.StatusConflict
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{})
+
+		res, err := p.Publish(context.Background(), registryDist(t))
+		var target *SchemaRegistryRequestError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, http.StatusConflict, target.StatusCode)
+		assert.Contains(t, err.Error(), "incompatible")
+		assert.Equal(t, 1, res.Uploaded)
+	})
+
+	t.Run("basic authentication", func(t *testing.T) {
+		t.Parallel()
+		reg, srv := newFakeSchemaRegistry(t)
+		reg.auth = "key:secret"
+
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{values: map[string]string{
+			SchemaRegistryUsernameEnvVar: "key",
+			SchemaRegistryPasswordEnvVar: "secret",
+		}})
+		_, err := p.Publish(context.Background(), registryDist(t))
+		require.NoError(t, err)
+
+		p = newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{})
+		_, err = p.Publish(context.Background(), registryDist(t))
+		var target *SchemaRegistryRequestError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, http.StatusUnauthorized, target.StatusCode)
+		assert.Contains(t, err.Error(), "(error 401): Unauthorized")
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("not json"))
+		}))
+		t.Cleanup(srv.Close)
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{})
+
+		_, err := p.Publish(context.Background(), registryDist(t))
+		var target *SchemaRegistryRequestError
+		require.ErrorAs(t, err, &target)
+		assert.Contains(t, err.Error(), "invalid response")
+	})
+
+	t.Run("missing schema file", func(t *testing.T) {
+		t.Parallel()
+		_, srv := newFakeSchemaRegistry(t)
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{})
+		dist := registryDist(t)
+		dist.Dir = t.TempDir()
+
+		_, err := p.Publish(context.Background(), dist)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("request error", func(t *testing.T) {
+		t.Parallel()
+		_, srv := newFakeSchemaRegistry(t)
+		p := newTestSchemaRegistryPublisher(srv.URL, config.SubjectNamingMajor, &mockEnvProvider{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := p.Publish(ctx, registryDist(t))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
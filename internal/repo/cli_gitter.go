@@ -3,10 +3,14 @@ package repo
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -44,38 +48,40 @@ func (g *CLIGitter) getEnvConfig(env config.Env) (*config.EnvConfig, error) {
 
 // tagPrefix returns the tag prefix for the given environment.
 func (g *CLIGitter) tagPrefix(env config.Env) string {
-	return fmt.Sprintf("%s/%s", JSMDeployTagPrefix, env)
+	return deployTagPrefix(deployTagConfig(g.cfg), env)
 }
 
-// GetLatestAnchor finds the latest deployment tag for an environment.
-// If no tag is found, it returns the repository's initial commit.
+// GetLatestAnchor finds the latest deployment tag for an environment: the newest deployment tag reachable from
+// HEAD. This is not necessarily the nearest, as a promotion is tagged at the older revision it was promoted
+// from. If no tag is found, it returns the repository's initial commit.
 func (g *CLIGitter) GetLatestAnchor(ctx context.Context, env config.Env) (Revision, error) {
 	if _, err := g.getEnvConfig(env); err != nil {
 		return "", err
 	}
 
-	tagPattern := fmt.Sprintf("%s/*", g.tagPrefix(env))
-
-	// --abbrev=0 finds the closest reachable tag matching the pattern
-	//nolint:gosec // CMD arguments are internal
-	cmd := exec.CommandContext(ctx, g.gitBinary, "describe", "--tags", "--match", tagPattern, "--abbrev=0")
-	cmd.Dir = g.repoRoot
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	if err := cmd.Run(); err != nil {
+	tags, err := g.listTags(ctx, g.tagPrefix(env), "--merged", "HEAD")
+	if err != nil || len(tags) == 0 {
 		// Fallback: Get the root commit (Day Zero)
-		//nolint:gosec // CMD arguments are internal
-		revCmd := exec.CommandContext(ctx, g.gitBinary, "rev-list", "--max-parents=0", "HEAD")
-		revCmd.Dir = g.repoRoot
-		revOut, rErr := revCmd.Output()
-		if rErr != nil {
-			return "", fmt.Errorf("could not find git history: %w", rErr)
-		}
-		return Revision(strings.TrimSpace(string(revOut))), nil
+		return g.rootCommit(ctx)
+	}
+
+	rollbacks, err := g.Rollbacks(ctx, env)
+	if err != nil {
+		return "", err
 	}
+	return rollbackAnchor(newestTag(tags), rollbacks), nil
+}
 
-	return Revision(strings.TrimSpace(out.String())), nil
+// rootCommit returns the repository's initial commit.
+func (g *CLIGitter) rootCommit(ctx context.Context) (Revision, error) {
+	//nolint:gosec // CMD arguments are internal
+	revCmd := exec.CommandContext(ctx, g.gitBinary, "rev-list", "--max-parents=0", "HEAD")
+	revCmd.Dir = g.repoRoot
+	revOut, err := revCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("could not find git history: %w", err)
+	}
+	return Revision(strings.TrimSpace(string(revOut))), nil
 }
 
 // getGitRoot finds the top-level directory of the git repository.
@@ -90,44 +96,134 @@ func (g *CLIGitter) getGitRoot(ctx context.Context) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-// TagDeploymentSuccess creates and pushes a new environment-specific deployment tag.
-func (g *CLIGitter) TagDeploymentSuccess(ctx context.Context, env config.Env) (string, error) {
+// TagDeploymentSuccess creates and pushes a new environment-specific deployment tag, whose message records
+// the schemas deployed. The tag points to the commit of at, or to HEAD if at is empty. The tag is signed if
+// signing is configured.
+func (g *CLIGitter) TagDeploymentSuccess(
+	ctx context.Context,
+	env config.Env,
+	at Revision,
+	schemas []DeployedSchema,
+) (string, error) {
+	if _, err := g.getEnvConfig(env); err != nil {
+		return "", err
+	}
+
+	tc := deployTagConfig(g.cfg)
+	tagName, err := deployTagName(tc, env, time.Now(), func(name string) (bool, error) {
+		return g.tagExists(ctx, name)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create git tag: %w", err)
+	}
+
+	args := tagArgs(tc, tagName, deployTagMessage(env, schemas))
+	if at != "" {
+		args = append(args, (at + "^{commit}").String())
+	}
+	return g.createAndPushTag(ctx, args, tagName)
+}
+
+// TagRollback creates and pushes a rollback tag, which records that the deployment rolledBack has been rolled
+// back to the deployment restored. The tag points to the commit of restored, or to the repository's initial
+// commit if restored is empty. The tag is signed if signing is configured.
+func (g *CLIGitter) TagRollback(ctx context.Context, env config.Env, rolledBack, restored Revision) (string, error) {
 	if _, err := g.getEnvConfig(env); err != nil {
 		return "", err
 	}
 
-	timestamp := time.Now().Format("20060102-150405")
-	tagName := fmt.Sprintf("%s/%s", g.tagPrefix(env), timestamp)
+	target := restored + "^{commit}"
+	if restored == "" {
+		root, err := g.rootCommit(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to create git tag: %w", err)
+		}
+		target = root
+	}
+
+	tc := rollbackTagConfig(g.cfg)
+	tagName, err := deployTagName(tc, env, time.Now(), func(name string) (bool, error) {
+		return g.tagExists(ctx, name)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create git tag: %w", err)
+	}
+
+	args := append(tagArgs(tc, tagName, rollbackTagMessage(env, rolledBack, restored)), target.String())
+	return g.createAndPushTag(ctx, args, tagName)
+}
 
+// createAndPushTag runs git with the given arguments to create the local annotated tag tagName, then pushes
+// the tag to the remote.
+func (g *CLIGitter) createAndPushTag(ctx context.Context, args []string, tagName string) (string, error) {
 	// 1. Create the local annotated tag
 	//nolint:gosec // CMD arguments are internal
-	tagCmd := exec.CommandContext(
-		ctx,
-		g.gitBinary,
-		"tag",
-		"-a",
-		tagName,
-		"-m",
-		fmt.Sprintf("Successful JSM deployment to %s", env),
-	)
+	tagCmd := exec.CommandContext(ctx, g.gitBinary, args...)
 	tagCmd.Dir = g.repoRoot
 	if err := tagCmd.Run(); err != nil {
 		return "", fmt.Errorf("failed to create git tag: %w", err)
 	}
 
-	// 2. Push the tag to origin
+	// 2. Push the tag to the remote
+	remote := gitRemote(g.cfg)
 	//nolint:gosec // CMD arguments are internal
-	pushCmd := exec.CommandContext(ctx, g.gitBinary, "push", "origin", tagName)
+	pushCmd := exec.CommandContext(ctx, g.gitBinary, "push", remote, "refs/tags/"+tagName)
 	pushCmd.Dir = g.repoRoot
 	if err := pushCmd.Run(); err != nil {
-		return tagName, fmt.Errorf("failed to push git tag to origin: %w", err)
+		return tagName, fmt.Errorf("failed to push git tag to %s: %w", remote, err)
 	}
 
 	return tagName, nil
 }
 
+// tagArgs returns the arguments to git which create the deployment tag, signing it as configured.
+func tagArgs(tc config.DeployTagConfig, tagName, message string) []string {
+	var args []string
+	switch tc.Sign {
+	case config.TagSigningGPG:
+		args = []string{"-c", "gpg.format=openpgp", "tag", "-s"}
+	case config.TagSigningSSH:
+		args = []string{"-c", "gpg.format=ssh", "tag", "-s"}
+	default:
+		args = []string{"tag", "-a"}
+	}
+	if tc.SigningKey != "" {
+		args = append(args, "-u", tc.SigningKey)
+	}
+	return append(args, tagName, "-m", message)
+}
+
+// tagExists returns true if a tag with the given name exists in the repository.
+func (g *CLIGitter) tagExists(ctx context.Context, name string) (bool, error) {
+	//nolint:gosec // CMD arguments are internal
+	cmd := exec.CommandContext(ctx, g.gitBinary, "rev-parse", "--quiet", "--verify", "refs/tags/"+name)
+	cmd.Dir = g.repoRoot
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // GetSchemaChanges identifies files with the given suffix changed between the anchor and HEAD.
 func (g *CLIGitter) GetSchemaChanges(ctx context.Context, anchor Revision, sourceDir, suffix string) ([]Change, error) {
+	return g.diff(ctx, []string{anchor.String()}, sourceDir, suffix)
+}
+
+// GetSchemaChangesBetween identifies files with the given suffix changed between two revisions. If from is
+// empty, every file at the revision to is reported as added.
+func (g *CLIGitter) GetSchemaChangesBetween(
+	ctx context.Context,
+	from, to Revision,
+	sourceDir, suffix string,
+) ([]Change, error) {
+	return g.diff(ctx, []string{cmp.Or(from.String(), emptyTree), to.String()}, sourceDir, suffix)
+}
+
+// diff identifies files with the given suffix in sourceDir changed between the given revisions, or between
+// a revision and the working tree.
+func (g *CLIGitter) diff(ctx context.Context, revs []string, sourceDir, suffix string) ([]Change, error) {
 	if !filepath.IsAbs(sourceDir) {
 		sourceDir = filepath.Join(g.repoRoot, sourceDir)
 	}
@@ -141,36 +237,231 @@ func (g *CLIGitter) GetSchemaChanges(ctx context.Context, anchor Revision, sourc
 		return nil, err
 	}
 
+	// Renames are detected explicitly, whatever the user's diff.renames setting, and -z stops git quoting
+	// unusual paths.
+	args := append([]string{"diff", "--name-status", "--find-renames", "-z"}, revs...)
 	//nolint:gosec // CMD arguments are internal and path is absolute
-	cmd := exec.CommandContext(ctx, g.gitBinary, "diff", "--name-status", anchor.String(), "--", absSourceDir)
+	cmd := exec.CommandContext(ctx, g.gitBinary, append(args, "--", absSourceDir)...)
 	cmd.Dir = g.repoRoot
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("git diff failed: %w (output: %s)", err, string(out))
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	changes := make([]Change, 0, len(lines))
+	// git diff returns paths relative to the repo root.
+	// Resolve these to absolute paths so they are correctly handled regardless of CWD.
+	return resolveChanges(root, parseNameStatus(string(out)), suffix), nil
+}
+
+// Deployments returns the deployments to an environment recorded by its deployment tags, oldest first.
+func (g *CLIGitter) Deployments(ctx context.Context, env config.Env) ([]Deployment, error) {
+	if _, err := g.getEnvConfig(env); err != nil {
+		return nil, err
+	}
+
+	tags, err := g.listTags(ctx, g.tagPrefix(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployment tags: %w", err)
+	}
+
+	deployments := make([]Deployment, 0, len(tags))
+	for _, t := range tags {
+		deployments = append(deployments, Deployment{
+			Env:     env,
+			Tag:     t.name,
+			Time:    t.created,
+			Schemas: ParseDeployTagMessage(t.message),
+		})
+	}
+	sortDeployments(deployments)
+	return deployments, nil
+}
+
+// Rollbacks returns the rollbacks of deployments to an environment recorded by its rollback tags, oldest
+// first.
+func (g *CLIGitter) Rollbacks(ctx context.Context, env config.Env) ([]Rollback, error) {
+	if _, err := g.getEnvConfig(env); err != nil {
+		return nil, err
+	}
+
+	tags, err := g.listTags(ctx, deployTagPrefix(rollbackTagConfig(g.cfg), env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rollback tags: %w", err)
+	}
+
+	rollbacks := make([]Rollback, 0, len(tags))
+	for _, t := range tags {
+		rb := Rollback{Env: env, Tag: t.name, Time: t.created}
+		rb.RolledBack, rb.Restored = ParseRollbackTagMessage(t.message)
+		rollbacks = append(rollbacks, rb)
+	}
+	sortRollbacks(rollbacks)
+	return rollbacks, nil
+}
+
+// listTags returns the tags of commits whose names have the given prefix. Tags of other objects are skipped.
+// Any filter options, such as --merged, are passed to git for-each-ref.
+func (g *CLIGitter) listTags(ctx context.Context, prefix string, filters ...string) ([]commitTag, error) {
+	// Each tag is written as NUL separated fields, terminated by a record separator, as its message may
+	// span several lines.
+	const format = "%(refname:strip=2)%00%(objecttype)%00%(*objecttype)%00%(creatordate:unix)%00%(contents)%1e"
+	args := append([]string{"for-each-ref", "--format=" + format}, filters...)
+	//nolint:gosec // CMD arguments are internal
+	cmd := exec.CommandContext(ctx, g.gitBinary, append(args, "refs/tags/"+prefix+"/")...)
+	cmd.Dir = g.repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
 
-	for _, line := range lines {
-		fields := strings.Fields(line)
-		if len(fields) < 2 {
+	var tags []commitTag
+	for record := range strings.SplitSeq(string(out), "\x1e") {
+		fields := strings.SplitN(strings.TrimPrefix(record, "\n"), "\x00", 5)
+		if len(fields) < 5 {
 			continue
 		}
-
-		path := fields[1]
-		if !strings.HasSuffix(path, suffix) {
+		name, objType, targetType, created, contents := fields[0], fields[1], fields[2], fields[3], fields[4]
+		if objType != "commit" && (objType != "tag" || targetType != "commit") {
+			// Tags of anything other than a commit are not deployment tags.
 			continue
 		}
+		secs, pErr := strconv.ParseInt(created, 10, 64)
+		if pErr != nil {
+			return nil, pErr
+		}
+
+		t := commitTag{name: Revision(name), created: time.Unix(secs, 0).UTC()}
+		if objType == "tag" {
+			t.message = contents
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// parseNameStatus parses the output of git diff --name-status -z, in which each field is terminated by
+// a NUL. Each status is followed by a path, or by the old and new paths of a rename or copy. Statuses
+// other than those of a ChangeStatus, such as unmerged files, are reported as modifications.
+func parseNameStatus(out string) []Change {
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	var changes []Change
+	for i := 0; i+1 < len(fields); i += 2 {
+		// Renames and copies carry a similarity score, such as R100.
+		status := ChangeStatus(fields[i][:1])
+		c := Change{Path: fields[i+1], Status: status}
+		switch status {
+		case ChangeAdded, ChangeModified, ChangeDeleted, ChangeTypeChanged:
+		case ChangeRenamed:
+			if i+2 >= len(fields) {
+				return changes
+			}
+			c.OldPath, c.Path = fields[i+1], fields[i+2]
+			i++
+		case "C":
+			// Copies are only detected if diff.renames is set to copies. A copy leaves the original in
+			// place, so only adds a file.
+			if i+2 >= len(fields) {
+				return changes
+			}
+			c.Path, c.Status = fields[i+2], ChangeAdded
+			i++
+		default:
+			c.Status = ChangeModified
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
 
-		// git diff returns paths relative to the repo root.
-		// Resolve these to absolute paths so they are correctly handled regardless of CWD.
-		absPath := filepath.Join(root, path)
+// GetFileAtRevision returns the content of the file at path as it was at the given revision.
+// If the file did not exist at the revision, the error wraps fs.ErrNotExist.
+func (g *CLIGitter) GetFileAtRevision(ctx context.Context, rev Revision, path string) ([]byte, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(g.repoRoot, path)
+	}
+	absPath, err := g.pathResolver.Abs(path)
+	if err != nil {
+		return nil, err
+	}
 
-		changes = append(changes, Change{
-			Path:  absPath,
-			IsNew: fields[0] == "A",
-		})
+	root, err := g.getGitRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return nil, err
+	}
+	object := fmt.Sprintf("%s:%s", rev, filepath.ToSlash(rel))
+
+	// ls-tree succeeds with no output when the revision exists but the file does not.
+	//nolint:gosec // CMD arguments are internal
+	lsCmd := exec.CommandContext(ctx, g.gitBinary, "ls-tree", rev.String(), "--", filepath.ToSlash(rel))
+	lsCmd.Dir = root
+	lsOut, err := lsCmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree failed: %w (output: %s)", err, string(lsOut))
+	}
+	if len(bytes.TrimSpace(lsOut)) == 0 {
+		return nil, fmt.Errorf("%s does not exist at %s: %w", path, rev, fs.ErrNotExist)
+	}
+
+	//nolint:gosec // CMD arguments are internal
+	showCmd := exec.CommandContext(ctx, g.gitBinary, "show", object)
+	showCmd.Dir = root
+	var stderr bytes.Buffer
+	showCmd.Stderr = &stderr
+	out, err := showCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show failed: %w (output: %s)", err, stderr.String())
+	}
+	return out, nil
+}
+
+// RevisionFS returns a read-only filesystem holding the tree of the directory dir as it was at the given
+// revision. If the directory did not exist at the revision, the error wraps fs.ErrNotExist.
+func (g *CLIGitter) RevisionFS(ctx context.Context, rev Revision, dir string) (fs.FS, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.repoRoot, dir)
+	}
+	absDir, err := g.pathResolver.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := g.getGitRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := rev.String()
+	if rel != "." {
+		// ls-tree succeeds with no output when the revision exists but the directory does not.
+		//nolint:gosec // CMD arguments are internal
+		lsCmd := exec.CommandContext(ctx, g.gitBinary, "ls-tree", "-d", tree, "--", filepath.ToSlash(rel))
+		lsCmd.Dir = root
+		lsOut, lsErr := lsCmd.CombinedOutput()
+		if lsErr != nil {
+			return nil, fmt.Errorf("git ls-tree failed: %w (output: %s)", lsErr, string(lsOut))
+		}
+		if len(bytes.TrimSpace(lsOut)) == 0 {
+			return nil, fmt.Errorf("%s does not exist at %s: %w", dir, rev, fs.ErrNotExist)
+		}
+		tree = fmt.Sprintf("%s:%s", rev, filepath.ToSlash(rel))
+	}
+
+	//nolint:gosec // CMD arguments are internal
+	archiveCmd := exec.CommandContext(ctx, g.gitBinary, "archive", "--format=tar", tree)
+	archiveCmd.Dir = root
+	var stderr bytes.Buffer
+	archiveCmd.Stderr = &stderr
+	out, err := archiveCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git archive failed: %w (output: %s)", err, stderr.String())
 	}
-	return changes, nil
+	return newTreeFS(bytes.NewReader(out))
 }
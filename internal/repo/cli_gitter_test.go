@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -142,7 +144,7 @@ func TestCLIGitter_GetSchemaChanges(t *testing.T) {
 		expected, _ := filepath.EvalSymlinks(f1)
 		actual, _ := filepath.EvalSymlinks(changes[0].Path)
 		assert.Equal(t, expected, actual)
-		assert.False(t, changes[0].IsNew)
+		assert.False(t, changes[0].IsNew())
 	})
 
 	t.Run("new file", func(t *testing.T) {
@@ -174,7 +176,53 @@ func TestCLIGitter_GetSchemaChanges(t *testing.T) {
 		expected, _ := filepath.EvalSymlinks(f2)
 		actual, _ := filepath.EvalSymlinks(changes[0].Path)
 		assert.Equal(t, expected, actual)
-		assert.True(t, changes[0].IsNew)
+		assert.True(t, changes[0].IsNew())
+	})
+
+	t.Run("deleted and renamed files", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupTestRepo(t)
+		g := NewCLIGitter(cfg, pathResolver, tmpDir)
+		git := func(args ...string) {
+			t.Helper()
+			cmd := exec.CommandContext(context.Background(), "git", args...)
+			cmd.Dir = tmpDir
+			require.NoError(t, cmd.Run())
+		}
+
+		srcDir := filepath.Join(tmpDir, "src", "schemas")
+		require.NoError(t, os.MkdirAll(srcDir, 0o755))
+		for name, content := range map[string]string{
+			"deleted.schema.json": `{"title": "deleted"}`,
+			"old.schema.json":     `{"title": "renamed"}`,
+			"schema.txt":          `{"title": "becomes a schema"}`,
+			"drafts.schema.json":  `{"title": "stops being a schema"}`,
+		} {
+			require.NoError(t, os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0o600))
+		}
+		git("add", ".")
+		git("commit", "-m", "1")
+		git("tag", "jsm-deploy/prod/v1")
+
+		git("rm", "-q", filepath.Join(srcDir, "deleted.schema.json"))
+		git("mv", filepath.Join(srcDir, "old.schema.json"), filepath.Join(srcDir, "new.schema.json"))
+		git("mv", filepath.Join(srcDir, "schema.txt"), filepath.Join(srcDir, "text.schema.json"))
+		git("mv", filepath.Join(srcDir, "drafts.schema.json"), filepath.Join(srcDir, "drafts.txt"))
+		git("commit", "-m", "2")
+
+		changes, err := g.GetSchemaChanges(context.Background(), "jsm-deploy/prod/v1", srcDir, ".schema.json")
+		require.NoError(t, err)
+
+		root, err := filepath.EvalSymlinks(tmpDir)
+		require.NoError(t, err)
+		src := filepath.Join(root, "src", "schemas")
+		assert.ElementsMatch(t, []Change{
+			{Path: filepath.Join(src, "deleted.schema.json"), Status: ChangeDeleted},
+			{Path: filepath.Join(src, "new.schema.json"), OldPath: filepath.Join(src, "old.schema.json"),
+				Status: ChangeRenamed},
+			{Path: filepath.Join(src, "text.schema.json"), Status: ChangeAdded},
+			{Path: filepath.Join(src, "drafts.schema.json"), Status: ChangeDeleted},
+		}, changes)
 	})
 
 	t.Run("ignore non-schema files", func(t *testing.T) {
@@ -273,6 +321,143 @@ func TestCLIGitter_GetSchemaChanges(t *testing.T) {
 	})
 }
 
+func TestParseNameStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		out  string
+		want []Change
+	}{
+		{
+			name: "empty",
+			out:  "",
+			want: nil,
+		},
+		{
+			name: "added, modified, deleted and type-changed files",
+			out:  "A\x00a.json\x00M\x00m.json\x00D\x00d.json\x00T\x00t.json\x00",
+			want: []Change{
+				{Path: "a.json", Status: ChangeAdded},
+				{Path: "m.json", Status: ChangeModified},
+				{Path: "d.json", Status: ChangeDeleted},
+				{Path: "t.json", Status: ChangeTypeChanged},
+			},
+		},
+		{
+			name: "renamed and copied files",
+			out:  "R087\x00old.json\x00new.json\x00C100\x00orig.json\x00copy.json\x00M\x00m.json\x00",
+			want: []Change{
+				{Path: "new.json", OldPath: "old.json", Status: ChangeRenamed},
+				{Path: "copy.json", Status: ChangeAdded},
+				{Path: "m.json", Status: ChangeModified},
+			},
+		},
+		{
+			name: "paths with spaces and tabs",
+			out:  "M\x00a b\tc.json\x00",
+			want: []Change{{Path: "a b\tc.json", Status: ChangeModified}},
+		},
+		{
+			name: "unmerged files are modifications",
+			out:  "U\x00u.json\x00",
+			want: []Change{{Path: "u.json", Status: ChangeModified}},
+		},
+		{
+			name: "truncated rename",
+			out:  "M\x00m.json\x00R100\x00old.json\x00",
+			want: []Change{{Path: "m.json", Status: ChangeModified}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, parseNameStatus(tt.out))
+		})
+	}
+}
+
+func TestCLIGitter_GetFileAtRevision(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(t)
+	pathResolver := fsh.NewPathResolver()
+
+	commitFile := func(t *testing.T, dir, fp, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(fp), 0o755))
+		require.NoError(t, os.WriteFile(fp, []byte(content), 0o600))
+		require.NoError(t, exec.CommandContext(context.Background(), "git", "-C", dir, "add", ".").Run())
+		require.NoError(t, exec.CommandContext(context.Background(), "git", "-C", dir, "commit", "-m", "c").Run())
+	}
+
+	t.Run("file at an earlier revision", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupTestRepo(t)
+		g := NewCLIGitter(cfg, pathResolver, tmpDir)
+
+		f1 := filepath.Join(tmpDir, "src", "user.schema.json")
+		commitFile(t, tmpDir, f1, `{"type": "object"}`)
+		require.NoError(
+			t,
+			exec.CommandContext(context.Background(), "git", "-C", tmpDir, "tag", "jsm-deploy/prod/v1").Run(),
+		)
+		commitFile(t, tmpDir, f1, `{"type": "string"}`)
+
+		data, err := g.GetFileAtRevision(context.Background(), "jsm-deploy/prod/v1", f1)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type": "object"}`, string(data))
+
+		// Relative paths are relative to the repository root given to the gitter.
+		data, err = g.GetFileAtRevision(context.Background(), "HEAD", filepath.Join("src", "user.schema.json"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type": "string"}`, string(data))
+	})
+
+	t.Run("file did not exist at the revision", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupTestRepo(t)
+		g := NewCLIGitter(cfg, pathResolver, tmpDir)
+
+		f1 := filepath.Join(tmpDir, "new.schema.json")
+		commitFile(t, tmpDir, f1, "{}")
+
+		_, err := g.GetFileAtRevision(context.Background(), "HEAD~1", f1)
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("invalid revision", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupTestRepo(t)
+		g := NewCLIGitter(cfg, pathResolver, tmpDir)
+
+		_, err := g.GetFileAtRevision(context.Background(), "invalid-rev", "a.schema.json")
+		require.ErrorContains(t, err, "git ls-tree failed")
+		assert.NotErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("absPath error", func(t *testing.T) {
+		t.Parallel()
+		mockResolver := &mockPathResolver{
+			absFn: func(_ string) (string, error) {
+				return "", errors.New("absPath failure")
+			},
+		}
+		g := NewCLIGitter(cfg, mockResolver, "")
+
+		_, err := g.GetFileAtRevision(context.Background(), "HEAD", "a.schema.json")
+		require.ErrorContains(t, err, "absPath failure")
+	})
+
+	t.Run("getGitRoot error", func(t *testing.T) {
+		t.Parallel()
+		g := NewCLIGitter(cfg, pathResolver, t.TempDir())
+
+		_, err := g.GetFileAtRevision(context.Background(), "HEAD", "a.schema.json")
+		require.ErrorContains(t, err, "failed to find git root")
+	})
+}
+
 func TestCLIGitter_getGitRoot(t *testing.T) {
 	t.Parallel()
 	t.Run("success", func(t *testing.T) {
@@ -307,7 +492,7 @@ func TestCLIGitter_TagDeploymentSuccess(t *testing.T) {
 		tmpDir := setupTestRepo(t)
 		g := NewCLIGitter(cfg, pathResolver, tmpDir)
 
-		tagName, err := g.TagDeploymentSuccess(context.Background(), "prod")
+		tagName, err := g.TagDeploymentSuccess(context.Background(), "prod", "", nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to push git tag to origin")
 		assert.NotEmpty(t, tagName)
@@ -343,13 +528,32 @@ func TestCLIGitter_TagDeploymentSuccess(t *testing.T) {
 		)
 
 		g := NewCLIGitter(cfg, pathResolver, repoDir)
-		tagName, err := g.TagDeploymentSuccess(context.Background(), "prod")
+		tagName, err := g.TagDeploymentSuccess(context.Background(), "prod", "", nil)
 		require.NoError(t, err)
 
 		cmd := exec.CommandContext(context.Background(), "git", "-C", remoteDir, "rev-parse", tagName)
 		require.NoError(t, cmd.Run())
 	})
 
+	t.Run("signed with an ssh key", func(t *testing.T) {
+		t.Parallel()
+		if _, err := exec.LookPath("ssh-keygen"); err != nil {
+			t.Skip("ssh-keygen is not installed")
+		}
+		tmpDir := setupTestRepo(t)
+		key := filepath.Join(t.TempDir(), "deploy")
+		keygen := exec.CommandContext(context.Background(), "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key)
+		require.NoError(t, keygen.Run())
+
+		signed := newTestConfig(t)
+		signed.Git.DeployTags = config.DeployTagConfig{Sign: config.TagSigningSSH, SigningKey: key}
+		g := NewCLIGitter(signed, pathResolver, tmpDir)
+
+		tagName, err := g.TagDeploymentSuccess(context.Background(), "prod", "", nil)
+		require.ErrorContains(t, err, "failed to push git tag to origin")
+		assert.Contains(t, runGit(t, tmpDir, "cat-file", "-p", tagName), "-----BEGIN SSH SIGNATURE-----")
+	})
+
 	t.Run("tag failure", func(t *testing.T) {
 		t.Parallel()
 		tmpDir := setupTestRepo(t)
@@ -368,7 +572,7 @@ exec %s "$@"
 		g := NewCLIGitter(cfg, pathResolver, tmpDir)
 		g.SetGitBinary(gitPath)
 
-		_, err := g.TagDeploymentSuccess(context.Background(), "prod")
+		_, err := g.TagDeploymentSuccess(context.Background(), "prod", "", nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to create git tag")
 	})
@@ -376,7 +580,7 @@ exec %s "$@"
 	t.Run("error - invalid env", func(t *testing.T) {
 		t.Parallel()
 		g := NewCLIGitter(cfg, pathResolver, "")
-		_, err := g.TagDeploymentSuccess(context.Background(), "invalid-env")
+		_, err := g.TagDeploymentSuccess(context.Background(), "invalid-env", "", nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "does not define environment")
 	})
@@ -386,6 +590,43 @@ func TestCLIGitter_tagPrefix(t *testing.T) {
 	t.Parallel()
 	g := NewCLIGitter(newTestConfig(t), fsh.NewPathResolver(), "")
 	assert.Equal(t, "jsm-deploy/prod", g.tagPrefix("prod"))
+
+	cfg := newTestConfig(t)
+	cfg.Git.DeployTags.Prefix = "releases/schemas"
+	g = NewCLIGitter(cfg, fsh.NewPathResolver(), "")
+	assert.Equal(t, "releases/schemas/prod", g.tagPrefix("prod"))
+}
+
+func TestTagArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tc   config.DeployTagConfig
+		want []string
+	}{
+		{
+			name: "unsigned",
+			want: []string{"tag", "-a", "t", "-m", "msg"},
+		},
+		{
+			name: "gpg with default key",
+			tc:   config.DeployTagConfig{Sign: config.TagSigningGPG},
+			want: []string{"-c", "gpg.format=openpgp", "tag", "-s", "t", "-m", "msg"},
+		},
+		{
+			name: "ssh with key",
+			tc:   config.DeployTagConfig{Sign: config.TagSigningSSH, SigningKey: "~/.ssh/deploy.pub"},
+			want: []string{"-c", "gpg.format=ssh", "tag", "-s", "-u", "~/.ssh/deploy.pub", "t", "-m", "msg"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tagArgs(tt.tc, "t", "msg"))
+		})
+	}
 }
 
 type mockPathResolver struct {
@@ -414,3 +655,86 @@ func (m *mockPathResolver) GetUintSubdirectories(dirPath string) ([]uint64, erro
 	}
 	return fsh.GetUintSubdirectories(dirPath)
 }
+
+func TestCLIGitter_RevisionFS(t *testing.T) {
+	t.Parallel()
+	cfg := newTestConfig(t)
+	pathResolver := fsh.NewPathResolver()
+
+	commitFile := func(t *testing.T, dir, fp, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(fp), 0o755))
+		require.NoError(t, os.WriteFile(fp, []byte(content), 0o600))
+		require.NoError(t, exec.CommandContext(context.Background(), "git", "-C", dir, "add", ".").Run())
+		require.NoError(t, exec.CommandContext(context.Background(), "git", "-C", dir, "commit", "-m", "c").Run())
+	}
+
+	t.Run("directory at an earlier revision", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupTestRepo(t)
+		g := NewCLIGitter(cfg, pathResolver, tmpDir)
+
+		f1 := filepath.Join(tmpDir, "registry", "domain", "user.schema.json")
+		commitFile(t, tmpDir, f1, `{"type": "object"}`)
+		commitFile(t, tmpDir, filepath.Join(tmpDir, "other.txt"), "other")
+		commitFile(t, tmpDir, f1, `{"type": "string"}`)
+		commitFile(t, tmpDir, filepath.Join(tmpDir, "registry", "new.json"), "{}")
+
+		fsys, err := g.RevisionFS(context.Background(), "HEAD~2", filepath.Join(tmpDir, "registry"))
+		require.NoError(t, err)
+		require.NoError(t, fstest.TestFS(fsys, "domain/user.schema.json"))
+		data, err := fs.ReadFile(fsys, "domain/user.schema.json")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type": "object"}`, string(data))
+		_, err = fs.Stat(fsys, "new.json")
+		require.ErrorIs(t, err, fs.ErrNotExist)
+
+		// Relative directories are relative to the repository root given to the gitter.
+		fsys, err = g.RevisionFS(context.Background(), "HEAD", ".")
+		require.NoError(t, err)
+		require.NoError(t, fstest.TestFS(fsys, "other.txt", "registry/domain/user.schema.json", "registry/new.json"))
+	})
+
+	t.Run("directory did not exist at the revision", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupTestRepo(t)
+		g := NewCLIGitter(cfg, pathResolver, tmpDir)
+		commitFile(t, tmpDir, filepath.Join(tmpDir, "registry", "a.json"), "{}")
+
+		_, err := g.RevisionFS(context.Background(), "HEAD~1", "registry")
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("invalid revision", func(t *testing.T) {
+		t.Parallel()
+		tmpDir := setupTestRepo(t)
+		g := NewCLIGitter(cfg, pathResolver, tmpDir)
+
+		_, err := g.RevisionFS(context.Background(), "invalid-rev", "registry")
+		require.ErrorContains(t, err, "git ls-tree failed")
+
+		_, err = g.RevisionFS(context.Background(), "invalid-rev", tmpDir)
+		require.ErrorContains(t, err, "git archive failed")
+	})
+
+	t.Run("absPath error", func(t *testing.T) {
+		t.Parallel()
+		mockResolver := &mockPathResolver{
+			absFn: func(_ string) (string, error) {
+				return "", errors.New("absPath failure")
+			},
+		}
+		g := NewCLIGitter(cfg, mockResolver, "")
+
+		_, err := g.RevisionFS(context.Background(), "HEAD", "registry")
+		require.ErrorContains(t, err, "absPath failure")
+	})
+
+	t.Run("getGitRoot error", func(t *testing.T) {
+		t.Parallel()
+		g := NewCLIGitter(cfg, pathResolver, t.TempDir())
+
+		_, err := g.RevisionFS(context.Background(), "HEAD", "registry")
+		require.ErrorContains(t, err, "failed to find git root")
+	})
+}
@@ -1,34 +1,320 @@
 package repo
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
 )
 
-// JSMDeployTagPrefix is the prefix used for deployment tags.
-var JSMDeployTagPrefix = "jsm-deploy"
+// DeployedSchema records a schema shipped by a deployment.
+type DeployedSchema struct {
+	Key    string
+	SHA256 string // The hex encoded digest of the rendered schema
+}
+
+// Deployment is a successful deployment to an environment, recorded by a deployment tag.
+type Deployment struct {
+	Env     config.Env
+	Tag     Revision
+	Time    time.Time        // When the tag was created, in UTC
+	Schemas []DeployedSchema // As recorded in the tag message. Lightweight and older tags record none
+}
+
+// Rollback is the rollback of a deployment to an environment, recorded by a rollback tag. The tag points to
+// the commit of the deployment restored, or to the repository's initial commit if there was none.
+type Rollback struct {
+	Env        config.Env
+	Tag        Revision
+	Time       time.Time // When the tag was created, in UTC
+	RolledBack Revision  // The tag of the deployment rolled back, as recorded in the tag message
+	Restored   Revision  // The tag of the deployment restored, or empty if there was none
+}
+
+// commitTag is a tag of a commit.
+type commitTag struct {
+	name    Revision
+	created time.Time // When an annotated tag was created, or the time of the commit a lightweight tag points to
+	message string    // The message of an annotated tag
+}
+
+// emptyTree is the hash of git's empty tree, with which the files at a revision are compared to report every
+// file as added.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// deployedSchemaLine matches the lines of a deployment tag message which record a deployed schema.
+var deployedSchemaLine = regexp.MustCompile(`^(\S+) sha256:([0-9a-f]+)$`)
+
+const (
+	rolledBackLabel = "Rolled back: "
+	restoredLabel   = "Restored: "
+)
+
+// deployTagConfig returns the deployment tag configuration, with defaults for any properties which are
+// not configured.
+func deployTagConfig(cfg *config.Config) config.DeployTagConfig {
+	var tc config.DeployTagConfig
+	if cfg != nil {
+		tc = cfg.Git.DeployTags
+	}
+	tc.Prefix = cmp.Or(tc.Prefix, config.DefaultDeployTagPrefix)
+	tc.RollbackPrefix = cmp.Or(tc.RollbackPrefix, config.DefaultRollbackTagPrefix)
+	tc.TimestampFormat = cmp.Or(tc.TimestampFormat, config.DefaultDeployTagTimestampFormat)
+	return tc
+}
+
+// rollbackTagConfig returns the configuration of rollback tags, which are named and signed as deployment tags
+// are, but with the rollback prefix.
+func rollbackTagConfig(cfg *config.Config) config.DeployTagConfig {
+	tc := deployTagConfig(cfg)
+	tc.Prefix = tc.RollbackPrefix
+	return tc
+}
+
+// gitRemote returns the remote deployment tags are pushed to.
+func gitRemote(cfg *config.Config) string {
+	if cfg == nil {
+		return config.DefaultGitRemote
+	}
+	return cmp.Or(cfg.Git.Remote, config.DefaultGitRemote)
+}
+
+// deployTagPrefix returns the prefix of the deployment tags of the given environment.
+func deployTagPrefix(tc config.DeployTagConfig, env config.Env) string {
+	return fmt.Sprintf("%s/%s", tc.Prefix, env)
+}
+
+// deployTagName returns the name of the deployment tag for a deployment to the given environment at t.
+// The timestamp is always in UTC. If a tag with the name already exists, because another deployment was
+// tagged in the same second, a counter is appended to the name.
+func deployTagName(
+	tc config.DeployTagConfig,
+	env config.Env,
+	t time.Time,
+	exists func(name string) (bool, error),
+) (string, error) {
+	name := fmt.Sprintf("%s/%s", deployTagPrefix(tc, env), t.UTC().Format(tc.TimestampFormat))
+	candidate := name
+	for i := 2; ; i++ {
+		taken, err := exists(candidate)
+		if err != nil || !taken {
+			return candidate, err
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// deployTagMessage returns the message of the deployment tag for a deployment of the given schemas to the
+// given environment. Each deployed schema is recorded on its own line, with the digest of the schema shipped.
+func deployTagMessage(env config.Env, schemas []DeployedSchema) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Successful JSM deployment to %s", env)
+	if len(schemas) > 0 {
+		b.WriteString("\n\nDeployed schemas:")
+	}
+	for _, s := range schemas {
+		fmt.Fprintf(&b, "\n%s sha256:%s", s.Key, s.SHA256)
+	}
+	return b.String()
+}
+
+// rollbackTagMessage returns the message of the rollback tag for the rollback of a deployment to the given
+// environment, which records the deployments rolled back and restored.
+func rollbackTagMessage(env config.Env, rolledBack, restored Revision) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "JSM rollback of %s\n\n%s%s", env, rolledBackLabel, rolledBack)
+	if restored != "" {
+		fmt.Fprintf(&b, "\n%s%s", restoredLabel, restored)
+	}
+	return b.String()
+}
+
+// ParseRollbackTagMessage returns the tags of the deployments rolled back and restored, as recorded in the
+// message of a rollback tag. Either is empty if it is not recorded.
+func ParseRollbackTagMessage(msg string) (rolledBack, restored Revision) {
+	for line := range strings.Lines(msg) {
+		line = strings.TrimSpace(line)
+		if v, ok := strings.CutPrefix(line, rolledBackLabel); ok {
+			rolledBack = Revision(v)
+		} else if v, ok = strings.CutPrefix(line, restoredLabel); ok {
+			restored = Revision(v)
+		}
+	}
+	return rolledBack, restored
+}
+
+// newestTag returns the name of the newest of the tags. Tags created in the same second are ordered by name, as
+// deployments are.
+func newestTag(tags []commitTag) Revision {
+	return slices.MaxFunc(tags, func(a, b commitTag) int {
+		return cmp.Or(a.created.Compare(b.created), strings.Compare(a.name.String(), b.name.String()))
+	}).name
+}
+
+// rollbackAnchor returns the anchor of an environment given its latest reachable deployment tag. If that
+// deployment has been rolled back, the latest rollback tag is the anchor, as it points to the deployment
+// restored. Rollbacks are ordered oldest first.
+func rollbackAnchor(anchor Revision, rollbacks []Rollback) Revision {
+	for _, rb := range rollbacks {
+		if rb.RolledBack == anchor {
+			return rollbacks[len(rollbacks)-1].Tag
+		}
+	}
+	return anchor
+}
+
+// sortRollbacks sorts rollbacks oldest first, as deployments are sorted.
+func sortRollbacks(rollbacks []Rollback) {
+	slices.SortStableFunc(rollbacks, func(a, b Rollback) int {
+		return cmp.Or(a.Time.Compare(b.Time), strings.Compare(a.Tag.String(), b.Tag.String()))
+	})
+}
+
+// sortDeployments sorts deployments oldest first. Deployments tagged in the same second are sorted by
+// tag name.
+func sortDeployments(deployments []Deployment) {
+	slices.SortStableFunc(deployments, func(a, b Deployment) int {
+		return cmp.Or(a.Time.Compare(b.Time), strings.Compare(a.Tag.String(), b.Tag.String()))
+	})
+}
+
+// ParseDeployTagMessage returns the schemas recorded in the message of a deployment tag. Deployment tags
+// created before deployed schemas were recorded, or for deployments with no schemas, record none.
+func ParseDeployTagMessage(msg string) []DeployedSchema {
+	var schemas []DeployedSchema
+	for line := range strings.Lines(msg) {
+		if m := deployedSchemaLine.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			schemas = append(schemas, DeployedSchema{Key: m[1], SHA256: m[2]})
+		}
+	}
+	return schemas
+}
 
 // Revision represents a specific git point-in-time (tag or hash).
 type Revision string
 
 func (r Revision) String() string { return string(r) }
 
+// ChangeStatus is the kind of change made to a file, as reported by git diff --name-status.
+type ChangeStatus string
+
+const (
+	// ChangeAdded is a file which has been added.
+	ChangeAdded ChangeStatus = "A"
+	// ChangeModified is a file whose content or mode has been modified.
+	ChangeModified ChangeStatus = "M"
+	// ChangeDeleted is a file which has been deleted.
+	ChangeDeleted ChangeStatus = "D"
+	// ChangeRenamed is a file which has been moved from OldPath to Path, possibly with changes.
+	ChangeRenamed ChangeStatus = "R"
+	// ChangeTypeChanged is a file whose type has changed, such as a regular file replaced by a symlink.
+	ChangeTypeChanged ChangeStatus = "T"
+)
+
 // Change represents a file status detected in the repository.
 type Change struct {
-	Path  string
-	IsNew bool // True if status is 'A' (Added), False if 'M' (Modified)
+	Path    string // The path of the file now, or of a deleted file before it was deleted
+	OldPath string // The path of a renamed file before it was renamed
+	Status  ChangeStatus
+}
+
+// IsNew returns true if there was no file at Path before the change, because the file has been added
+// or renamed.
+func (c Change) IsNew() bool {
+	return c.Status == ChangeAdded || c.Status == ChangeRenamed
 }
 
 // Gitter defines the interface for git repository operations.
 type Gitter interface {
-	// GetLatestAnchor finds the latest deployment tag for an environment.
-	// If no tag is found, it returns the repository's initial commit.
+	// GetLatestAnchor finds the latest deployment tag for an environment: the newest deployment tag reachable
+	// from HEAD. If that deployment has been rolled back, it returns the latest rollback tag instead. If no tag
+	// is found, it returns the repository's initial commit.
 	GetLatestAnchor(ctx context.Context, env config.Env) (Revision, error)
 
-	// TagDeploymentSuccess creates and pushes a new environment-specific deployment tag.
-	TagDeploymentSuccess(ctx context.Context, env config.Env) (string, error)
+	// TagDeploymentSuccess creates and pushes a new environment-specific deployment tag, whose message records
+	// the schemas deployed. The tag points to the commit of the revision at, or to HEAD if at is empty. If the
+	// tag is created but cannot be pushed, its name is returned with the error.
+	TagDeploymentSuccess(ctx context.Context, env config.Env, at Revision, schemas []DeployedSchema) (string, error)
 
 	// GetSchemaChanges identifies files with the given suffix changed between the anchor and HEAD.
+	// A file renamed from or to a path without the suffix is reported as deleted or added.
 	GetSchemaChanges(ctx context.Context, anchor Revision, sourceDir, suffix string) ([]Change, error)
+
+	// GetSchemaChangesBetween identifies files with the given suffix changed between two revisions, as
+	// GetSchemaChanges does. If from is empty, every file at the revision to is reported as added.
+	GetSchemaChangesBetween(ctx context.Context, from, to Revision, sourceDir, suffix string) ([]Change, error)
+
+	// TagRollback creates and pushes a rollback tag, which records that the deployment rolledBack has been
+	// rolled back to the deployment restored. The tag points to the commit of restored, or to the repository's
+	// initial commit if restored is empty. If the tag is created but cannot be pushed, its name is returned
+	// with the error.
+	TagRollback(ctx context.Context, env config.Env, rolledBack, restored Revision) (string, error)
+
+	// Deployments returns the deployments to an environment recorded by its deployment tags, oldest first.
+	Deployments(ctx context.Context, env config.Env) ([]Deployment, error)
+
+	// Rollbacks returns the rollbacks of deployments to an environment recorded by its rollback tags, oldest
+	// first.
+	Rollbacks(ctx context.Context, env config.Env) ([]Rollback, error)
+
+	// GetFileAtRevision returns the content of the file at path as it was at the given revision.
+	// If the file did not exist at the revision, the error wraps fs.ErrNotExist.
+	GetFileAtRevision(ctx context.Context, rev Revision, path string) ([]byte, error)
+
+	// RevisionFS returns a read-only filesystem holding the tree of the directory dir as it was at the
+	// given revision. If the directory did not exist at the revision, the error wraps fs.ErrNotExist.
+	RevisionFS(ctx context.Context, rev Revision, dir string) (fs.FS, error)
+}
+
+// NewGitter creates the Gitter for the given backend, for the repository containing repoRoot. If repoRoot is
+// empty, the repository containing the working directory is used.
+func NewGitter(backend config.GitBackend, cfg *config.Config, pathResolver fsh.PathResolver, repoRoot string) Gitter {
+	if backend == config.GitBackendGo {
+		return NewGoGitter(cfg, pathResolver, repoRoot)
+	}
+	return NewCLIGitter(cfg, pathResolver, repoRoot)
+}
+
+// resolveChanges resolves the paths of changes, which are relative to the root of the repository, to absolute
+// paths, and returns those which affect files with the given suffix.
+func resolveChanges(root string, changes []Change, suffix string) []Change {
+	var resolved []Change
+	for _, c := range changes {
+		c.Path = filepath.Join(root, c.Path)
+		if c.OldPath != "" {
+			c.OldPath = filepath.Join(root, c.OldPath)
+		}
+		if change, ok := filterRename(c, suffix); ok {
+			resolved = append(resolved, change)
+		}
+	}
+	return resolved
+}
+
+// filterRename reports whether a change affects a file with the given suffix. A rename which gives a file
+// the suffix adds a file, and one which takes it away deletes a file, so is reported as such.
+func filterRename(c Change, suffix string) (Change, bool) {
+	newMatch := strings.HasSuffix(c.Path, suffix)
+	if c.Status != ChangeRenamed {
+		return c, newMatch
+	}
+	oldMatch := strings.HasSuffix(c.OldPath, suffix)
+	switch {
+	case newMatch && oldMatch:
+		return c, true
+	case newMatch:
+		return Change{Path: c.Path, Status: ChangeAdded}, true
+	case oldMatch:
+		return Change{Path: c.OldPath, Status: ChangeDeleted}, true
+	default:
+		return c, false
+	}
 }
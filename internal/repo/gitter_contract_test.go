@@ -0,0 +1,640 @@
+package repo
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+)
+
+// newGitterFunc creates the Gitter under test for the repository at repoRoot.
+type newGitterFunc func(cfg *config.Config, repoRoot string) Gitter
+
+// TestGitter_Contract runs the same tests against every Gitter implementation, to ensure they behave
+// identically.
+func TestGitter_Contract(t *testing.T) {
+	t.Parallel()
+
+	backends := map[config.GitBackend]newGitterFunc{
+		config.GitBackendCLI: func(cfg *config.Config, repoRoot string) Gitter {
+			return NewCLIGitter(cfg, fsh.NewPathResolver(), repoRoot)
+		},
+		config.GitBackendGo: func(cfg *config.Config, repoRoot string) Gitter {
+			return NewGoGitter(cfg, fsh.NewPathResolver(), repoRoot)
+		},
+	}
+	for backend, newGitter := range backends {
+		t.Run(string(backend), func(t *testing.T) {
+			t.Parallel()
+			testGetLatestAnchorContract(t, newGitter)
+			testTagDeploymentSuccessContract(t, newGitter)
+			testGetSchemaChangesContract(t, newGitter)
+			testRevisionContract(t, newGitter)
+			testHistoryContract(t, newGitter)
+			testRollbackContract(t, newGitter)
+		})
+	}
+}
+
+// runGit runs git in dir, and returns its trimmed output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+	return strings.TrimSpace(string(out))
+}
+
+// commitFiles writes the given files, relative to dir, and commits every change in the working tree.
+func commitFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "commit")
+}
+
+func testGetLatestAnchorContract(t *testing.T, newGitter newGitterFunc) {
+	t.Helper()
+	cfg := newTestConfig(t)
+	cfg.Environments["dev"] = &config.EnvConfig{Env: "dev"}
+
+	t.Run("no deployment tags", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		commitFiles(t, dir, map[string]string{"a.schema.json": "{}"})
+
+		anchor, err := newGitter(cfg, dir).GetLatestAnchor(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Equal(t, runGit(t, dir, "rev-list", "--max-parents=0", "HEAD"), anchor.String())
+	})
+
+	t.Run("latest reachable tag of the environment", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		commitFiles(t, dir, map[string]string{"a.schema.json": "{}"})
+		runGit(t, dir, "tag", "-a", "jsm-deploy/prod/20260101-000000", "-m", "1")
+		commitFiles(t, dir, map[string]string{"b.schema.json": "{}"})
+		runGit(t, dir, "tag", "-a", "jsm-deploy/prod/20260102-000000", "-m", "2")
+		commitFiles(t, dir, map[string]string{"c.schema.json": "{}"})
+		runGit(t, dir, "tag", "-a", "jsm-deploy/dev/20260103-000000", "-m", "3")
+
+		// A deployment from a branch which has not been merged is not reachable.
+		runGit(t, dir, "checkout", "-q", "-b", "other")
+		commitFiles(t, dir, map[string]string{"d.schema.json": "{}"})
+		runGit(t, dir, "tag", "-a", "jsm-deploy/prod/20260104-000000", "-m", "4")
+		runGit(t, dir, "checkout", "-q", "-")
+
+		g := newGitter(cfg, dir)
+		anchor, err := g.GetLatestAnchor(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Equal(t, Revision("jsm-deploy/prod/20260102-000000"), anchor)
+
+		anchor, err = g.GetLatestAnchor(context.Background(), "dev")
+		require.NoError(t, err)
+		assert.Equal(t, Revision("jsm-deploy/dev/20260103-000000"), anchor)
+	})
+
+	t.Run("promotion tagged behind a later deployment", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		commitFiles(t, dir, map[string]string{"a.schema.json": "{}"})
+		runGit(t, dir, "tag", "-a", "jsm-deploy/staging/20260101-000000", "-m", "staging")
+		commitFiles(t, dir, map[string]string{"b.schema.json": "{}"})
+
+		// A hotfix is deployed from HEAD, and then the staging deployment is promoted, so is tagged behind it.
+		tagAt := func(name, rev, date string) {
+			cmd := exec.CommandContext(context.Background(), "git", "tag", "-a", name, "-m", name, rev)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date)
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+		tagAt("jsm-deploy/prod/20260102-000000", "HEAD", "2026-01-02T00:00:00Z")
+		tagAt("jsm-deploy/prod/20260103-000000", "jsm-deploy/staging/20260101-000000^{commit}",
+			"2026-01-03T00:00:00Z")
+
+		anchor, err := newGitter(cfg, dir).GetLatestAnchor(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Equal(t, Revision("jsm-deploy/prod/20260103-000000"), anchor)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		t.Parallel()
+		_, err := newGitter(cfg, setupTestRepo(t)).GetLatestAnchor(context.Background(), "staging")
+		require.Error(t, err)
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		t.Parallel()
+		_, err := newGitter(cfg, t.TempDir()).GetLatestAnchor(context.Background(), "prod")
+		require.ErrorContains(t, err, "could not find git history")
+	})
+}
+
+func testTagDeploymentSuccessContract(t *testing.T, newGitter newGitterFunc) {
+	t.Helper()
+	cfg := newTestConfig(t)
+
+	t.Run("tag is created and pushed", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		origin := t.TempDir()
+		runGit(t, origin, "init", "-q", "--bare")
+		runGit(t, dir, "remote", "add", "origin", origin)
+
+		g := newGitter(cfg, dir)
+		tagName, err := g.TagDeploymentSuccess(context.Background(), "prod", "", nil)
+		require.NoError(t, err)
+		assert.Regexp(t, `^jsm-deploy/prod/\d{8}-\d{6}$`, tagName)
+
+		assert.Equal(t, "tag", runGit(t, dir, "cat-file", "-t", tagName))
+		assert.Equal(t, "Successful JSM deployment to prod", runGit(t, dir, "tag", "-l", "--format=%(contents)", tagName))
+		assert.Equal(t, "Test User", runGit(t, dir, "tag", "-l", "--format=%(taggername)", tagName))
+		assert.Equal(t, tagName, runGit(t, origin, "tag", "-l"))
+
+		anchor, err := g.GetLatestAnchor(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Equal(t, Revision(tagName), anchor)
+	})
+
+	t.Run("configured naming and remote", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		upstream := t.TempDir()
+		runGit(t, upstream, "init", "-q", "--bare")
+		runGit(t, dir, "remote", "add", "upstream", upstream)
+		runGit(t, dir, "tag", "-a", "jsm-deploy/prod/20260101-000000", "-m", "default prefix")
+
+		custom := newTestConfig(t)
+		custom.Git = config.GitConfig{
+			Remote: "upstream",
+			DeployTags: config.DeployTagConfig{
+				Prefix:          "releases/schemas",
+				TimestampFormat: "2006-01-02T15.04.05Z",
+			},
+		}
+		schemas := []DeployedSchema{
+			{Key: "domain_a_1_0_0", SHA256: "9f86d081884c7d65"},
+			{Key: "domain_b_1_1_0", SHA256: "60303ae22b998861"},
+		}
+
+		g := newGitter(custom, dir)
+		tagName, err := g.TagDeploymentSuccess(context.Background(), "prod", "", schemas)
+		require.NoError(t, err)
+		assert.Regexp(t, `^releases/schemas/prod/\d{4}-\d{2}-\d{2}T\d{2}\.\d{2}\.\d{2}Z$`, tagName)
+		assert.Equal(t, tagName, runGit(t, upstream, "tag", "-l"))
+
+		message := runGit(t, dir, "tag", "-l", "--format=%(contents)", tagName)
+		assert.Equal(t, "Successful JSM deployment to prod\n\nDeployed schemas:\n"+
+			"domain_a_1_0_0 sha256:9f86d081884c7d65\ndomain_b_1_1_0 sha256:60303ae22b998861", message)
+		assert.Equal(t, schemas, ParseDeployTagMessage(message))
+
+		anchor, err := g.GetLatestAnchor(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Equal(t, Revision(tagName), anchor)
+	})
+
+	t.Run("tag points to the given revision", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		runGit(t, dir, "tag", "-a", "jsm-deploy/dev/20260101-000000", "-m", "dev deployment")
+		promoted := runGit(t, dir, "rev-parse", "HEAD")
+		commitFiles(t, dir, map[string]string{"a.schema.json": "{}"})
+
+		tagName, err := newGitter(cfg, dir).TagDeploymentSuccess(context.Background(), "prod",
+			"jsm-deploy/dev/20260101-000000", nil)
+		require.ErrorContains(t, err, "failed to push git tag")
+		assert.Equal(t, promoted, runGit(t, dir, "rev-parse", tagName+"^{commit}"))
+
+		_, err = newGitter(cfg, dir).TagDeploymentSuccess(context.Background(), "prod", "missing", nil)
+		require.ErrorContains(t, err, "failed to create git tag")
+	})
+
+	t.Run("tags in the same second do not collide", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+
+		// A timestamp with only the date collides on every deployment of the day.
+		daily := newTestConfig(t)
+		daily.Git.DeployTags.TimestampFormat = "20060102"
+		g := newGitter(daily, dir)
+
+		first, err := g.TagDeploymentSuccess(context.Background(), "prod", "", nil)
+		require.ErrorContains(t, err, "failed to push git tag")
+		second, err := g.TagDeploymentSuccess(context.Background(), "prod", "", nil)
+		require.ErrorContains(t, err, "failed to push git tag")
+		assert.Equal(t, first+"-2", second)
+	})
+
+	t.Run("push fails without origin", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+
+		tagName, err := newGitter(cfg, dir).TagDeploymentSuccess(context.Background(), "prod", "", nil)
+		require.ErrorContains(t, err, "failed to push git tag to origin")
+		assert.Equal(t, tagName, runGit(t, dir, "tag", "-l"))
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		t.Parallel()
+		_, err := newGitter(cfg, setupTestRepo(t)).TagDeploymentSuccess(context.Background(), "staging", "", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		t.Parallel()
+		_, err := newGitter(cfg, t.TempDir()).TagDeploymentSuccess(context.Background(), "prod", "", nil)
+		require.ErrorContains(t, err, "failed to create git tag")
+	})
+}
+
+func testGetSchemaChangesContract(t *testing.T, newGitter newGitterFunc) {
+	t.Helper()
+	cfg := newTestConfig(t)
+	const similar = "{\n  \"type\": \"object\",\n  \"properties\": {\n    \"name\": {\"type\": \"string\"},\n" +
+		"    \"age\": {\"type\": \"integer\"},\n    \"email\": {\"type\": \"string\"}\n  }\n}\n"
+
+	t.Run("changes since the anchor", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		commitFiles(t, dir, map[string]string{
+			"registry/modified.schema.json":    `{"title": "modified"}`,
+			"registry/deleted.schema.json":     `{"title": "deleted"}`,
+			"registry/renamed.schema.json":     `{"title": "renamed"}`,
+			"registry/edited.schema.json":      similar,
+			"registry/uncommitted.schema.json": `{"title": "uncommitted"}`,
+			"registry/unchanged.schema.json":   `{"title": "unchanged"}`,
+			"registry/notes.txt":               "notes",
+			"outside.schema.json":              `{"title": "outside"}`,
+		})
+		runGit(t, dir, "tag", "jsm-deploy/prod/v1")
+
+		runGit(t, dir, "rm", "-q", "registry/deleted.schema.json")
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "registry", "moved"), 0o755))
+		runGit(t, dir, "mv", "registry/renamed.schema.json", "registry/moved/renamed.schema.json")
+		runGit(t, dir, "mv", "registry/edited.schema.json", "registry/edited-renamed.schema.json")
+		commitFiles(t, dir, map[string]string{
+			"registry/modified.schema.json":       `{"title": "modified", "type": "object"}`,
+			"registry/edited-renamed.schema.json": strings.Replace(similar, "integer", "number", 1),
+			"registry/added.schema.json":          `{"title": "added"}`,
+			"registry/notes.txt":                  "more notes",
+			"outside.schema.json":                 `{"title": "outside", "type": "object"}`,
+		})
+
+		// Uncommitted changes to tracked files are included, but untracked files are not.
+		uncommitted := filepath.Join(dir, "registry", "uncommitted.schema.json")
+		require.NoError(t, os.WriteFile(uncommitted, []byte(`{"title": "changed"}`), 0o600))
+		untracked := filepath.Join(dir, "registry", "untracked.schema.json")
+		require.NoError(t, os.WriteFile(untracked, []byte(`{}`), 0o600))
+
+		changes, err := newGitter(cfg, dir).GetSchemaChanges(
+			context.Background(), "jsm-deploy/prod/v1", "registry", ".schema.json")
+		require.NoError(t, err)
+
+		root, err := filepath.EvalSymlinks(dir)
+		require.NoError(t, err)
+		reg := filepath.Join(root, "registry")
+		assert.ElementsMatch(t, []Change{
+			{Path: filepath.Join(reg, "added.schema.json"), Status: ChangeAdded},
+			{Path: filepath.Join(reg, "deleted.schema.json"), Status: ChangeDeleted},
+			{Path: filepath.Join(reg, "modified.schema.json"), Status: ChangeModified},
+			{Path: filepath.Join(reg, "uncommitted.schema.json"), Status: ChangeModified},
+			{
+				Path:    filepath.Join(reg, "moved", "renamed.schema.json"),
+				OldPath: filepath.Join(reg, "renamed.schema.json"),
+				Status:  ChangeRenamed,
+			},
+			{
+				Path:    filepath.Join(reg, "edited-renamed.schema.json"),
+				OldPath: filepath.Join(reg, "edited.schema.json"),
+				Status:  ChangeRenamed,
+			},
+		}, changes)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		commitFiles(t, dir, map[string]string{"registry/a.schema.json": "{}"})
+		runGit(t, dir, "tag", "jsm-deploy/prod/v1")
+
+		changes, err := newGitter(cfg, dir).GetSchemaChanges(
+			context.Background(), "jsm-deploy/prod/v1", "registry", ".schema.json")
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("unknown anchor", func(t *testing.T) {
+		t.Parallel()
+		_, err := newGitter(cfg, setupTestRepo(t)).GetSchemaChanges(
+			context.Background(), "invalid-anchor", ".", ".schema.json")
+		require.ErrorContains(t, err, "git diff failed")
+	})
+}
+
+func testRevisionContract(t *testing.T, newGitter newGitterFunc) {
+	t.Helper()
+	cfg := newTestConfig(t)
+
+	setup := func(t *testing.T) (Gitter, string) {
+		t.Helper()
+		dir := setupTestRepo(t)
+		commitFiles(t, dir, map[string]string{
+			"registry/domain/a.schema.json": `{"title": "a"}`,
+			"registry/partials/p.json":      `{"type": "string"}`,
+		})
+		runGit(t, dir, "tag", "jsm-deploy/prod/v1")
+		commitFiles(t, dir, map[string]string{"registry/domain/a.schema.json": `{"title": "changed"}`})
+		return newGitter(cfg, dir), dir
+	}
+
+	t.Run("file at revision", func(t *testing.T) {
+		t.Parallel()
+		g, dir := setup(t)
+		data, err := g.GetFileAtRevision(context.Background(), "jsm-deploy/prod/v1",
+			filepath.Join(dir, "registry", "domain", "a.schema.json"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"title": "a"}`, string(data))
+
+		_, err = g.GetFileAtRevision(context.Background(), "jsm-deploy/prod/v1",
+			filepath.Join(dir, "registry", "domain", "b.schema.json"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+
+		_, err = g.GetFileAtRevision(context.Background(), "invalid-anchor",
+			filepath.Join(dir, "registry", "domain", "a.schema.json"))
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("directory at revision", func(t *testing.T) {
+		t.Parallel()
+		g, dir := setup(t)
+		fsys, err := g.RevisionFS(context.Background(), "jsm-deploy/prod/v1", filepath.Join(dir, "registry"))
+		require.NoError(t, err)
+
+		data, err := fs.ReadFile(fsys, "domain/a.schema.json")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"title": "a"}`, string(data))
+
+		entries, err := fs.ReadDir(fsys, ".")
+		require.NoError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		assert.Equal(t, []string{"domain", "partials"}, names)
+
+		_, err = g.RevisionFS(context.Background(), "jsm-deploy/prod/v1", filepath.Join(dir, "missing"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
+
+func testHistoryContract(t *testing.T, newGitter newGitterFunc) {
+	t.Helper()
+	cfg := newTestConfig(t)
+	cfg.Environments["dev"] = &config.EnvConfig{Env: "dev"}
+
+	// tagAt creates an annotated tag of HEAD, created at the given time.
+	tagAt := func(t *testing.T, dir, name, message, when string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", "tag", "-a", name, "-m", message)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+when)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	t.Run("changes between revisions", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		commitFiles(t, dir, map[string]string{
+			"registry/kept.schema.json":    `{"title": "kept"}`,
+			"registry/deleted.schema.json": `{"title": "deleted"}`,
+			"registry/notes.txt":           "notes",
+		})
+		runGit(t, dir, "tag", "v1")
+		runGit(t, dir, "rm", "-q", "registry/deleted.schema.json")
+		commitFiles(t, dir, map[string]string{
+			"registry/kept.schema.json":  `{"title": "kept", "type": "object"}`,
+			"registry/added.schema.json": `{"title": "added"}`,
+		})
+		runGit(t, dir, "tag", "v2")
+
+		// Uncommitted changes are not included.
+		uncommitted := filepath.Join(dir, "registry", "kept.schema.json")
+		require.NoError(t, os.WriteFile(uncommitted, []byte(`{}`), 0o600))
+
+		root, err := filepath.EvalSymlinks(dir)
+		require.NoError(t, err)
+		reg := filepath.Join(root, "registry")
+		g := newGitter(cfg, dir)
+
+		changes, err := g.GetSchemaChangesBetween(context.Background(), "v1", "v2", "registry", ".schema.json")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []Change{
+			{Path: filepath.Join(reg, "added.schema.json"), Status: ChangeAdded},
+			{Path: filepath.Join(reg, "deleted.schema.json"), Status: ChangeDeleted},
+			{Path: filepath.Join(reg, "kept.schema.json"), Status: ChangeModified},
+		}, changes)
+
+		changes, err = g.GetSchemaChangesBetween(context.Background(), "", "v1", "registry", ".schema.json")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []Change{
+			{Path: filepath.Join(reg, "deleted.schema.json"), Status: ChangeAdded},
+			{Path: filepath.Join(reg, "kept.schema.json"), Status: ChangeAdded},
+		}, changes)
+
+		_, err = g.GetSchemaChangesBetween(context.Background(), "v1", "unknown", "registry", ".schema.json")
+		require.ErrorContains(t, err, "git diff failed")
+	})
+
+	t.Run("deployments", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		commitFiles(t, dir, map[string]string{"a.schema.json": "{}"})
+		tagAt(t, dir, "jsm-deploy/prod/2", deployTagMessage("prod", []DeployedSchema{{Key: "a_1_0_0", SHA256: "ab"}}),
+			"2020-01-02T00:00:00Z")
+		tagAt(t, dir, "jsm-deploy/prod/1", "Successful JSM deployment to prod", "2020-01-01T00:00:00Z")
+		tagAt(t, dir, "jsm-deploy/dev/1", "Successful JSM deployment to dev", "2020-01-01T00:00:00Z")
+		commitFiles(t, dir, map[string]string{"b.schema.json": "{}"})
+		runGit(t, dir, "tag", "jsm-deploy/prod/lightweight")
+		runGit(t, dir, "tag", "jsm-deploy/prod/blob", runGit(t, dir, "rev-parse", "HEAD:b.schema.json"))
+		commitTime := runGit(t, dir, "log", "-1", "--format=%ct")
+
+		deployments, err := newGitter(cfg, dir).Deployments(context.Background(), "prod")
+		require.NoError(t, err)
+		require.Len(t, deployments, 3)
+		assert.Equal(t, Deployment{
+			Env:  "prod",
+			Tag:  "jsm-deploy/prod/1",
+			Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, deployments[0])
+		assert.Equal(t, Deployment{
+			Env:     "prod",
+			Tag:     "jsm-deploy/prod/2",
+			Time:    time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			Schemas: []DeployedSchema{{Key: "a_1_0_0", SHA256: "ab"}},
+		}, deployments[1])
+		assert.Equal(t, Revision("jsm-deploy/prod/lightweight"), deployments[2].Tag)
+		assert.Equal(t, commitTime, strconv.FormatInt(deployments[2].Time.Unix(), 10))
+		assert.Empty(t, deployments[2].Schemas)
+	})
+
+	t.Run("no deployments", func(t *testing.T) {
+		t.Parallel()
+		deployments, err := newGitter(cfg, setupTestRepo(t)).Deployments(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Empty(t, deployments)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		t.Parallel()
+		_, err := newGitter(cfg, setupTestRepo(t)).Deployments(context.Background(), "staging")
+		require.Error(t, err)
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		t.Parallel()
+		_, err := newGitter(cfg, t.TempDir()).Deployments(context.Background(), "prod")
+		require.ErrorContains(t, err, "failed to list deployment tags")
+	})
+}
+
+func testRollbackContract(t *testing.T, newGitter newGitterFunc) {
+	t.Helper()
+	cfg := newTestConfig(t)
+
+	// setup creates a repository with two deployments to prod, and a remote to push tags to.
+	setup := func(t *testing.T) (string, string) {
+		t.Helper()
+		dir := setupTestRepo(t)
+		origin := t.TempDir()
+		runGit(t, origin, "init", "-q", "--bare")
+		runGit(t, dir, "remote", "add", "origin", origin)
+		commitFiles(t, dir, map[string]string{"a.schema.json": "{}"})
+		runGit(t, dir, "tag", "-a", "jsm-deploy/prod/1", "-m", "1")
+		commitFiles(t, dir, map[string]string{"b.schema.json": "{}"})
+		runGit(t, dir, "tag", "-a", "jsm-deploy/prod/2", "-m", "2")
+		return dir, origin
+	}
+
+	t.Run("rollback moves the anchor back", func(t *testing.T) {
+		t.Parallel()
+		dir, origin := setup(t)
+		g := newGitter(cfg, dir)
+
+		tagName, err := g.TagRollback(context.Background(), "prod", "jsm-deploy/prod/2", "jsm-deploy/prod/1")
+		require.NoError(t, err)
+		assert.Regexp(t, `^jsm-rollback/prod/\d{8}-\d{6}$`, tagName)
+		assert.Equal(t, "tag", runGit(t, dir, "cat-file", "-t", tagName))
+		assert.Equal(t, runGit(t, dir, "rev-parse", "jsm-deploy/prod/1^{commit}"),
+			runGit(t, dir, "rev-parse", tagName+"^{commit}"))
+		assert.Equal(t, tagName, runGit(t, origin, "tag", "-l"))
+
+		anchor, err := g.GetLatestAnchor(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Equal(t, Revision(tagName), anchor)
+
+		rollbacks, err := g.Rollbacks(context.Background(), "prod")
+		require.NoError(t, err)
+		require.Len(t, rollbacks, 1)
+		assert.Equal(t, Revision(tagName), rollbacks[0].Tag)
+		assert.Equal(t, Revision("jsm-deploy/prod/2"), rollbacks[0].RolledBack)
+		assert.Equal(t, Revision("jsm-deploy/prod/1"), rollbacks[0].Restored)
+		assert.WithinDuration(t, time.Now(), rollbacks[0].Time, time.Minute)
+
+		// A rollback tag is not a deployment.
+		deployments, err := g.Deployments(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Len(t, deployments, 2)
+
+		// A later deployment is the anchor once more.
+		commitFiles(t, dir, map[string]string{"c.schema.json": "{}"})
+		runGit(t, dir, "tag", "-a", "jsm-deploy/prod/3", "-m", "3")
+		anchor, err = g.GetLatestAnchor(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Equal(t, Revision("jsm-deploy/prod/3"), anchor)
+	})
+
+	t.Run("rollback of the first deployment", func(t *testing.T) {
+		t.Parallel()
+		dir, _ := setup(t)
+		g := newGitter(cfg, dir)
+
+		tagName, err := g.TagRollback(context.Background(), "prod", "jsm-deploy/prod/1", "")
+		require.NoError(t, err)
+		assert.Equal(t, runGit(t, dir, "rev-list", "--max-parents=0", "HEAD"),
+			runGit(t, dir, "rev-parse", tagName+"^{commit}"))
+		assert.Equal(t, "JSM rollback of prod\n\nRolled back: jsm-deploy/prod/1",
+			runGit(t, dir, "tag", "-l", "--format=%(contents)", tagName))
+	})
+
+	t.Run("configured prefix", func(t *testing.T) {
+		t.Parallel()
+		dir, _ := setup(t)
+		custom := newTestConfig(t)
+		custom.Git.DeployTags.RollbackPrefix = "releases/rollbacks"
+
+		tagName, err := newGitter(custom, dir).TagRollback(context.Background(), "prod", "jsm-deploy/prod/2",
+			"jsm-deploy/prod/1")
+		require.NoError(t, err)
+		assert.Regexp(t, `^releases/rollbacks/prod/\d{8}-\d{6}$`, tagName)
+	})
+
+	t.Run("push fails without origin", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+
+		tagName, err := newGitter(cfg, dir).TagRollback(context.Background(), "prod", "jsm-deploy/prod/1", "")
+		require.ErrorContains(t, err, "failed to push git tag to origin")
+		assert.Equal(t, tagName, runGit(t, dir, "tag", "-l"))
+	})
+
+	t.Run("unknown restored deployment", func(t *testing.T) {
+		t.Parallel()
+		dir, _ := setup(t)
+		_, err := newGitter(cfg, dir).TagRollback(context.Background(), "prod", "jsm-deploy/prod/2", "missing")
+		require.ErrorContains(t, err, "failed to create git tag")
+	})
+
+	t.Run("no rollbacks", func(t *testing.T) {
+		t.Parallel()
+		rollbacks, err := newGitter(cfg, setupTestRepo(t)).Rollbacks(context.Background(), "prod")
+		require.NoError(t, err)
+		assert.Empty(t, rollbacks)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		t.Parallel()
+		g := newGitter(cfg, setupTestRepo(t))
+		_, err := g.TagRollback(context.Background(), "staging", "jsm-deploy/staging/1", "")
+		require.Error(t, err)
+		_, err = g.Rollbacks(context.Background(), "staging")
+		require.Error(t, err)
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		t.Parallel()
+		g := newGitter(cfg, t.TempDir())
+		_, err := g.TagRollback(context.Background(), "prod", "jsm-deploy/prod/1", "")
+		require.ErrorContains(t, err, "failed to create git tag")
+		_, err = g.Rollbacks(context.Background(), "prod")
+		require.ErrorContains(t, err, "failed to list rollback tags")
+	})
+}
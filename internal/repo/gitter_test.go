@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+func TestDeployTagConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+		tc := deployTagConfig(nil)
+		assert.Equal(t, config.DefaultDeployTagPrefix, tc.Prefix)
+		assert.Equal(t, config.DefaultDeployTagTimestampFormat, tc.TimestampFormat)
+		assert.Equal(t, "jsm-rollback/prod", deployTagPrefix(rollbackTagConfig(nil), "prod"))
+		assert.Equal(t, config.DefaultGitRemote, gitRemote(nil))
+		assert.Equal(t, config.DefaultGitRemote, gitRemote(&config.Config{}))
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{Git: config.GitConfig{
+			Remote:     "upstream",
+			DeployTags: config.DeployTagConfig{Prefix: "deploys", TimestampFormat: "2006.01.02-15.04.05"},
+		}}
+		tc := deployTagConfig(cfg)
+		assert.Equal(t, "deploys/prod", deployTagPrefix(tc, "prod"))
+		assert.Equal(t, "2006.01.02-15.04.05", tc.TimestampFormat)
+		assert.Equal(t, "upstream", gitRemote(cfg))
+	})
+}
+
+func TestDeployTagName(t *testing.T) {
+	t.Parallel()
+
+	tc := deployTagConfig(nil)
+	// 01:02:03 in UTC, as seen from a timezone an hour ahead.
+	when := time.Date(2026, 1, 30, 2, 2, 3, 0, time.FixedZone("CET", 3600))
+
+	t.Run("timestamp is in UTC", func(t *testing.T) {
+		t.Parallel()
+		name, err := deployTagName(tc, "prod", when, func(string) (bool, error) { return false, nil })
+		require.NoError(t, err)
+		assert.Equal(t, "jsm-deploy/prod/20260130-010203", name)
+	})
+
+	t.Run("existing tags get a counter", func(t *testing.T) {
+		t.Parallel()
+		taken := map[string]bool{"jsm-deploy/prod/20260130-010203": true, "jsm-deploy/prod/20260130-010203-2": true}
+		name, err := deployTagName(tc, "prod", when, func(n string) (bool, error) { return taken[n], nil })
+		require.NoError(t, err)
+		assert.Equal(t, "jsm-deploy/prod/20260130-010203-3", name)
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		t.Parallel()
+		_, err := deployTagName(tc, "prod", when, func(string) (bool, error) { return false, errors.New("boom") })
+		require.EqualError(t, err, "boom")
+	})
+}
+
+func TestDeployTagMessage(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Successful JSM deployment to prod", deployTagMessage("prod", nil))
+	assert.Empty(t, ParseDeployTagMessage(deployTagMessage("prod", nil)))
+
+	schemas := []DeployedSchema{{Key: "domain_a_1_0_0", SHA256: "abc123"}, {Key: "domain_b_2_0_0", SHA256: "def456"}}
+	msg := deployTagMessage("prod", schemas)
+	assert.Equal(t, "Successful JSM deployment to prod\n\nDeployed schemas:\n"+
+		"domain_a_1_0_0 sha256:abc123\ndomain_b_2_0_0 sha256:def456", msg)
+	assert.Equal(t, schemas, ParseDeployTagMessage(msg))
+
+	// Signatures and other lines are ignored.
+	signed := msg + "\n-----BEGIN PGP SIGNATURE-----\nnot a schema\n-----END PGP SIGNATURE-----\n"
+	assert.Equal(t, schemas, ParseDeployTagMessage(signed))
+}
+
+func TestRollbackTagMessage(t *testing.T) {
+	t.Parallel()
+
+	msg := rollbackTagMessage("prod", "jsm-deploy/prod/2", "jsm-deploy/prod/1")
+	assert.Equal(t, "JSM rollback of prod\n\nRolled back: jsm-deploy/prod/2\nRestored: jsm-deploy/prod/1", msg)
+	rolledBack, restored := ParseRollbackTagMessage(msg)
+	assert.Equal(t, Revision("jsm-deploy/prod/2"), rolledBack)
+	assert.Equal(t, Revision("jsm-deploy/prod/1"), restored)
+
+	msg = rollbackTagMessage("prod", "jsm-deploy/prod/1", "")
+	assert.Equal(t, "JSM rollback of prod\n\nRolled back: jsm-deploy/prod/1", msg)
+	rolledBack, restored = ParseRollbackTagMessage(msg)
+	assert.Equal(t, Revision("jsm-deploy/prod/1"), rolledBack)
+	assert.Empty(t, restored)
+
+	rolledBack, restored = ParseRollbackTagMessage("")
+	assert.Empty(t, rolledBack)
+	assert.Empty(t, restored)
+}
+
+func TestRollbackAnchor(t *testing.T) {
+	t.Parallel()
+
+	rollbacks := []Rollback{
+		{Tag: "jsm-rollback/prod/1", RolledBack: "jsm-deploy/prod/3", Restored: "jsm-deploy/prod/2"},
+		{Tag: "jsm-rollback/prod/2", RolledBack: "jsm-deploy/prod/2", Restored: "jsm-deploy/prod/1"},
+	}
+
+	// Once rolled back, the latest reachable deployment tag stays the same, so the latest rollback is used.
+	assert.Equal(t, Revision("jsm-rollback/prod/2"), rollbackAnchor("jsm-deploy/prod/3", rollbacks))
+	assert.Equal(t, Revision("jsm-deploy/prod/4"), rollbackAnchor("jsm-deploy/prod/4", rollbacks))
+	assert.Equal(t, Revision("jsm-deploy/prod/3"), rollbackAnchor("jsm-deploy/prod/3", nil))
+}
+
+func TestNewestTag(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tags := []commitTag{
+		{name: "jsm-deploy/prod/b", created: now.Add(-time.Hour)},
+		{name: "jsm-deploy/prod/a", created: now},
+		{name: "jsm-deploy/prod/c", created: now.Add(-2 * time.Hour)},
+	}
+	assert.Equal(t, Revision("jsm-deploy/prod/a"), newestTag(tags))
+
+	// Tags created in the same second are ordered by name.
+	tags = append(tags, commitTag{name: "jsm-deploy/prod/a-2", created: now})
+	assert.Equal(t, Revision("jsm-deploy/prod/a-2"), newestTag(tags))
+}
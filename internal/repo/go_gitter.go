@@ -0,0 +1,805 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+)
+
+// renameThreshold is the percentage similarity at which a deleted and an added file are reported as a rename,
+// as with git diff --find-renames.
+const renameThreshold = 50
+
+// GoGitter is an implementation of Gitter which uses a pure Go git library, so does not need the git binary.
+type GoGitter struct {
+	cfg          *config.Config
+	pathResolver fsh.PathResolver
+	repoRoot     string
+	now          func() time.Time
+}
+
+// NewGoGitter creates a new GoGitter instance.
+func NewGoGitter(cfg *config.Config, pathResolver fsh.PathResolver, repoRoot string) *GoGitter {
+	return &GoGitter{
+		cfg:          cfg,
+		pathResolver: pathResolver,
+		repoRoot:     repoRoot,
+		now:          time.Now,
+	}
+}
+
+// TopLevel returns the top-level directory of the git repository containing dir, without running git. If dir
+// is not within a git repository with a working tree, it returns an empty string.
+func TopLevel(dir string) string {
+	r, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return ""
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		return ""
+	}
+	return wt.Filesystem.Root()
+}
+
+// open opens the repository containing the repo root, and returns it with the canonical path of its top-level
+// directory.
+func (g *GoGitter) open() (*git.Repository, string, error) {
+	dir, err := g.pathResolver.Abs(cmp.Or(g.repoRoot, "."))
+	if err != nil {
+		return nil, "", err
+	}
+	r, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find git root: %w", err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find git root: %w", err)
+	}
+	root, err := g.pathResolver.CanonicalPath(wt.Filesystem.Root())
+	if err != nil {
+		return nil, "", err
+	}
+	return r, root, nil
+}
+
+// relPath returns the path of p relative to the top-level directory root, using forward slashes. Relative
+// paths are relative to the repo root. The parent directory of p is resolved, so p need not exist.
+func (g *GoGitter) relPath(root, p string) (string, error) {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(g.repoRoot, p)
+	}
+	abs, err := g.pathResolver.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	if dir, dErr := g.pathResolver.CanonicalPath(filepath.Dir(abs)); dErr == nil {
+		abs = filepath.Join(dir, filepath.Base(abs))
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository %s", p, root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// GetLatestAnchor finds the latest deployment tag for an environment: the newest deployment tag reachable from
+// HEAD. If that deployment has been rolled back, it returns the latest rollback tag instead. If no tag is
+// found, it returns the repository's initial commit.
+func (g *GoGitter) GetLatestAnchor(ctx context.Context, env config.Env) (Revision, error) {
+	if _, err := g.cfg.EnvConfig(env); err != nil {
+		return "", err
+	}
+
+	r, _, err := g.open()
+	if err != nil {
+		return "", fmt.Errorf("could not find git history: %w", err)
+	}
+	head, err := r.Head()
+	if err != nil {
+		return "", fmt.Errorf("could not find git history: %w", err)
+	}
+	tags, err := deployTags(r, deployTagPrefix(deployTagConfig(g.cfg), env))
+	if err != nil {
+		return "", err
+	}
+
+	// Every commit reachable from HEAD is visited, as the newest deployment tag is not necessarily the nearest.
+	commits, err := r.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderBSF})
+	if err != nil {
+		return "", fmt.Errorf("could not find git history: %w", err)
+	}
+	var reachable []commitTag
+	var root plumbing.Hash
+	err = commits.ForEach(func(c *object.Commit) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		for _, t := range tags[c.Hash] {
+			if t.created.IsZero() {
+				t.created = c.Committer.When.Truncate(time.Second).UTC()
+			}
+			reachable = append(reachable, t)
+		}
+		if c.NumParents() == 0 && root.IsZero() {
+			root = c.Hash
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not find git history: %w", err)
+	}
+
+	if len(reachable) == 0 {
+		return Revision(root.String()), nil
+	}
+	rollbacks, err := g.Rollbacks(ctx, env)
+	if err != nil {
+		return "", err
+	}
+	return rollbackAnchor(newestTag(reachable), rollbacks), nil
+}
+
+// deployTags returns the deployment tags with the given prefix by the hash of the commit they point to. The
+// creation time of lightweight tags is left for the caller to take from their commit.
+func deployTags(r *git.Repository, prefix string) (map[plumbing.Hash][]commitTag, error) {
+	refs, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix += "/"
+	tags := make(map[plumbing.Hash][]commitTag)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		tag := commitTag{name: Revision(name)}
+		target := ref.Hash()
+		if obj, tErr := r.TagObject(ref.Hash()); tErr == nil {
+			c, cErr := obj.Commit()
+			if cErr != nil {
+				// Tags of anything other than a commit are not deployment tags.
+				return nil //nolint:nilerr // Skipped rather than failing
+			}
+			tag.created, target = obj.Tagger.When.Truncate(time.Second).UTC(), c.Hash
+		}
+		tags[target] = append(tags[target], tag)
+		return nil
+	})
+	return tags, err
+}
+
+// TagDeploymentSuccess creates and pushes a new environment-specific deployment tag, whose message records
+// the schemas deployed. The tag points to the commit of at, or to HEAD if at is empty. The tagger is read from
+// the user.name and user.email git configuration. Tags are never signed, as the configuration rejects signing
+// with the go backend.
+func (g *GoGitter) TagDeploymentSuccess(
+	ctx context.Context,
+	env config.Env,
+	at Revision,
+	schemas []DeployedSchema,
+) (string, error) {
+	if _, err := g.cfg.EnvConfig(env); err != nil {
+		return "", err
+	}
+	tc := deployTagConfig(g.cfg)
+
+	r, _, err := g.open()
+	if err != nil {
+		return "", fmt.Errorf("failed to create git tag: %w", err)
+	}
+	target, err := tagTarget(r, at)
+	if err != nil {
+		return "", fmt.Errorf("failed to create git tag: %w", err)
+	}
+
+	tagName, err := g.tagName(r, tc, env)
+	if err != nil {
+		return "", err
+	}
+	return g.createAndPushTag(ctx, r, tagName, target, deployTagMessage(env, schemas))
+}
+
+// TagRollback creates and pushes a rollback tag, which records that the deployment rolledBack has been rolled
+// back to the deployment restored. The tag points to the commit of restored, or to the repository's initial
+// commit if restored is empty. Tags are never signed.
+func (g *GoGitter) TagRollback(ctx context.Context, env config.Env, rolledBack, restored Revision) (string, error) {
+	if _, err := g.cfg.EnvConfig(env); err != nil {
+		return "", err
+	}
+	tc := rollbackTagConfig(g.cfg)
+
+	r, _, err := g.open()
+	if err != nil {
+		return "", fmt.Errorf("failed to create git tag: %w", err)
+	}
+	var target plumbing.Hash
+	if restored == "" {
+		target, err = rootCommit(r)
+	} else {
+		var c *object.Commit
+		if c, err = resolveCommit(r, restored); err == nil {
+			target = c.Hash
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create git tag: %w", err)
+	}
+
+	tagName, err := g.tagName(r, tc, env)
+	if err != nil {
+		return "", err
+	}
+	return g.createAndPushTag(ctx, r, tagName, target, rollbackTagMessage(env, rolledBack, restored))
+}
+
+// tagName returns the name of a new tag for the environment, named as configured.
+func (g *GoGitter) tagName(r *git.Repository, tc config.DeployTagConfig, env config.Env) (string, error) {
+	tagName, err := deployTagName(tc, env, g.now(), func(name string) (bool, error) {
+		_, tErr := r.Tag(name)
+		if errors.Is(tErr, git.ErrTagNotFound) {
+			return false, nil
+		}
+		return tErr == nil, tErr
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create git tag: %w", err)
+	}
+	return tagName, nil
+}
+
+// createAndPushTag creates the local annotated tag tagName of the given commit, then pushes the tag to the
+// remote. The tagger is read from the user.name and user.email git configuration.
+func (g *GoGitter) createAndPushTag(
+	ctx context.Context,
+	r *git.Repository,
+	tagName string,
+	target plumbing.Hash,
+	message string,
+) (string, error) {
+	// 1. Create the local annotated tag
+	if _, err := r.CreateTag(tagName, target, &git.CreateTagOptions{Message: message}); err != nil {
+		return "", fmt.Errorf("failed to create git tag: %w", err)
+	}
+
+	// 2. Push the tag to the remote
+	remote := gitRemote(g.cfg)
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tagName, tagName))
+	err := r.PushContext(ctx, &git.PushOptions{RemoteName: remote, RefSpecs: []gitconfig.RefSpec{refSpec}})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return tagName, fmt.Errorf("failed to push git tag to %s: %w", remote, err)
+	}
+
+	return tagName, nil
+}
+
+// rootCommit returns the repository's initial commit: the first commit without parents found from HEAD.
+func rootCommit(r *git.Repository) (plumbing.Hash, error) {
+	head, err := r.Head()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	commits, err := r.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderBSF})
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	var root plumbing.Hash
+	err = commits.ForEach(func(c *object.Commit) error {
+		if c.NumParents() == 0 {
+			root = c.Hash
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return root, err
+}
+
+// GetSchemaChanges identifies files with the given suffix changed between the anchor and HEAD.
+// As with git diff, the tracked files in the working tree are compared with the anchor, so uncommitted
+// changes are included, and renames are detected between files with the suffix.
+func (g *GoGitter) GetSchemaChanges(ctx context.Context, anchor Revision, sourceDir, suffix string) ([]Change, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	r, root, err := g.open()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := g.relPath(root, sourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := anchorFiles(r, anchor, rel, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	after, err := worktreeFiles(r, root, rel, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+
+	// Paths are relative to the repo root.
+	// Resolve these to absolute paths so they are correctly handled regardless of CWD.
+	return resolveChanges(root, diffFiles(before, after), suffix), nil
+}
+
+// GetSchemaChangesBetween identifies files with the given suffix changed between two revisions. If from is
+// empty, every file at the revision to is reported as added.
+func (g *GoGitter) GetSchemaChangesBetween(
+	ctx context.Context,
+	from, to Revision,
+	sourceDir, suffix string,
+) ([]Change, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	r, root, err := g.open()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := g.relPath(root, sourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	before := make(map[string]fileState)
+	if from != "" {
+		if before, err = anchorFiles(r, from, rel, suffix); err != nil {
+			return nil, fmt.Errorf("git diff failed: %w", err)
+		}
+	}
+	after, err := anchorFiles(r, to, rel, suffix)
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+
+	return resolveChanges(root, diffFiles(before, after), suffix), nil
+}
+
+// Deployments returns the deployments to an environment recorded by its deployment tags, oldest first.
+// As with git for-each-ref, the time of a lightweight tag is the time of the commit it points to.
+func (g *GoGitter) Deployments(ctx context.Context, env config.Env) ([]Deployment, error) {
+	if _, err := g.cfg.EnvConfig(env); err != nil {
+		return nil, err
+	}
+
+	tags, err := g.listTags(ctx, deployTagPrefix(deployTagConfig(g.cfg), env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployment tags: %w", err)
+	}
+
+	deployments := make([]Deployment, 0, len(tags))
+	for _, t := range tags {
+		deployments = append(deployments, Deployment{
+			Env:     env,
+			Tag:     t.name,
+			Time:    t.created,
+			Schemas: ParseDeployTagMessage(t.message),
+		})
+	}
+	sortDeployments(deployments)
+	return deployments, nil
+}
+
+// Rollbacks returns the rollbacks of deployments to an environment recorded by its rollback tags, oldest
+// first.
+func (g *GoGitter) Rollbacks(ctx context.Context, env config.Env) ([]Rollback, error) {
+	if _, err := g.cfg.EnvConfig(env); err != nil {
+		return nil, err
+	}
+
+	tags, err := g.listTags(ctx, deployTagPrefix(rollbackTagConfig(g.cfg), env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rollback tags: %w", err)
+	}
+
+	rollbacks := make([]Rollback, 0, len(tags))
+	for _, t := range tags {
+		rb := Rollback{Env: env, Tag: t.name, Time: t.created}
+		rb.RolledBack, rb.Restored = ParseRollbackTagMessage(t.message)
+		rollbacks = append(rollbacks, rb)
+	}
+	sortRollbacks(rollbacks)
+	return rollbacks, nil
+}
+
+// listTags returns the tags of commits whose names have the given prefix. Tags of other objects are skipped.
+func (g *GoGitter) listTags(ctx context.Context, prefix string) ([]commitTag, error) {
+	r, _, err := g.open()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix += "/"
+	var tags []commitTag
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		name := ref.Name().Short()
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		t := commitTag{name: Revision(name)}
+		if obj, tErr := r.TagObject(ref.Hash()); tErr == nil {
+			if obj.TargetType != plumbing.CommitObject {
+				return nil
+			}
+			t.created, t.message = obj.Tagger.When, obj.Message
+		} else {
+			c, cErr := r.CommitObject(ref.Hash())
+			if cErr != nil {
+				// Tags of anything other than a commit are not deployment tags.
+				return nil //nolint:nilerr // Skipped rather than failing
+			}
+			t.created = c.Committer.When
+		}
+		t.created = t.created.Truncate(time.Second).UTC()
+		tags = append(tags, t)
+		return nil
+	})
+	return tags, err
+}
+
+// fileState is the state of a file in a tree or the working tree.
+type fileState struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+	read func() ([]byte, error)
+}
+
+// anchorFiles returns the files with the given suffix in the directory dir, relative to the top-level
+// directory, at the revision, by their path relative to the top-level directory.
+func anchorFiles(r *git.Repository, rev Revision, dir, suffix string) (map[string]fileState, error) {
+	commit, err := resolveCommit(r, rev)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]fileState)
+	if dir != "." {
+		if tree, err = tree.Tree(dir); errors.Is(err, object.ErrDirectoryNotFound) {
+			return files, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !strings.HasSuffix(f.Name, suffix) {
+			return nil
+		}
+		name := path.Join(dir, f.Name)
+		files[name] = fileState{hash: f.Hash, mode: f.Mode, read: func() ([]byte, error) { return readFile(f) }}
+		return nil
+	})
+	return files, err
+}
+
+// worktreeFiles returns the tracked files with the given suffix in the directory dir, relative to the
+// top-level directory root, in the working tree, by their path relative to the top-level directory.
+func worktreeFiles(r *git.Repository, root, dir, suffix string) (map[string]fileState, error) {
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]fileState)
+	for _, e := range idx.Entries {
+		if e.Mode == filemode.Submodule || !strings.HasSuffix(e.Name, suffix) {
+			continue
+		}
+		if dir != "." && !strings.HasPrefix(e.Name, dir+"/") {
+			continue
+		}
+
+		p := filepath.Join(root, filepath.FromSlash(e.Name))
+		info, sErr := os.Lstat(p)
+		if errors.Is(sErr, fs.ErrNotExist) {
+			continue
+		}
+		if sErr != nil {
+			return nil, sErr
+		}
+
+		mode := filemode.Regular
+		var data []byte
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, lErr := os.Readlink(p)
+			if lErr != nil {
+				return nil, lErr
+			}
+			mode, data = filemode.Symlink, []byte(target)
+		default:
+			if info.Mode()&0o111 != 0 {
+				mode = filemode.Executable
+			}
+			//nolint:gosec // Path is a tracked file of the repository
+			if data, sErr = os.ReadFile(p); sErr != nil {
+				return nil, sErr
+			}
+		}
+		files[e.Name] = fileState{
+			hash: plumbing.ComputeHash(plumbing.BlobObject, data),
+			mode: mode,
+			read: func() ([]byte, error) { return data, nil },
+		}
+	}
+	return files, nil
+}
+
+// diffFiles returns the changes between two sets of files, detecting renames between the files which have
+// been deleted and added.
+func diffFiles(before, after map[string]fileState) []Change {
+	var changes []Change
+	var deleted, added []string
+	for p, b := range before {
+		a, ok := after[p]
+		switch {
+		case !ok:
+			deleted = append(deleted, p)
+		case (a.mode == filemode.Symlink) != (b.mode == filemode.Symlink):
+			changes = append(changes, Change{Path: p, Status: ChangeTypeChanged})
+		case a.hash != b.hash || a.mode != b.mode:
+			changes = append(changes, Change{Path: p, Status: ChangeModified})
+		}
+	}
+	for p := range after {
+		if _, ok := before[p]; !ok {
+			added = append(added, p)
+		}
+	}
+	slices.Sort(deleted)
+	slices.Sort(added)
+
+	renames := detectRenames(before, after, deleted, added)
+	for _, p := range deleted {
+		if !slices.ContainsFunc(renames, func(c Change) bool { return c.OldPath == p }) {
+			changes = append(changes, Change{Path: p, Status: ChangeDeleted})
+		}
+	}
+	for _, p := range added {
+		if !slices.ContainsFunc(renames, func(c Change) bool { return c.Path == p }) {
+			changes = append(changes, Change{Path: p, Status: ChangeAdded})
+		}
+	}
+	changes = append(changes, renames...)
+
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Path, b.Path) })
+	return changes
+}
+
+// detectRenames pairs deleted files with added files whose content is at least renameThreshold percent
+// similar, most similar first. Empty files are never renamed.
+func detectRenames(before, after map[string]fileState, deleted, added []string) []Change {
+	type candidate struct {
+		from, to string
+		score    int
+	}
+	var candidates []candidate
+	for _, to := range added {
+		dst, err := after[to].read()
+		if err != nil || len(dst) == 0 {
+			continue
+		}
+		for _, from := range deleted {
+			if before[from].hash == after[to].hash {
+				candidates = append(candidates, candidate{from: from, to: to, score: 100})
+				continue
+			}
+			src, rErr := before[from].read()
+			if rErr != nil {
+				continue
+			}
+			if score := similarity(src, dst); score >= renameThreshold {
+				candidates = append(candidates, candidate{from: from, to: to, score: score})
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return cmp.Compare(b.score, a.score) })
+
+	var renames []Change
+	used := make(map[string]bool)
+	for _, c := range candidates {
+		if used[c.from] || used[c.to] {
+			continue
+		}
+		used[c.from], used[c.to] = true, true
+		renames = append(renames, Change{Path: c.to, OldPath: c.from, Status: ChangeRenamed})
+	}
+	return renames
+}
+
+// similarity returns the percentage of the content of two files which they have in common, measured as git
+// does: the content is split into lines of at most 64 bytes, and the bytes of the lines the files share are
+// counted against the size of the larger file.
+func similarity(src, dst []byte) int {
+	maxSize := max(len(src), len(dst))
+	if maxSize == 0 {
+		return 0
+	}
+
+	srcChunks := chunkSizes(src)
+	common := 0
+	for chunk, size := range chunkSizes(dst) {
+		common += min(size, srcChunks[chunk])
+	}
+	return common * 100 / maxSize
+}
+
+// chunkSizes returns the number of bytes of content in each distinct chunk: a line, or 64 bytes of a
+// longer line.
+func chunkSizes(data []byte) map[string]int {
+	const maxChunk = 64
+	sizes := make(map[string]int)
+	for len(data) > 0 {
+		n := min(len(data), maxChunk)
+		if i := slices.Index(data[:n], '\n'); i >= 0 {
+			n = i + 1
+		}
+		sizes[string(data[:n])] += n
+		data = data[n:]
+	}
+	return sizes
+}
+
+// GetFileAtRevision returns the content of the file at path as it was at the given revision.
+// If the file did not exist at the revision, the error wraps fs.ErrNotExist.
+func (g *GoGitter) GetFileAtRevision(ctx context.Context, rev Revision, file string) ([]byte, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	r, root, err := g.open()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := g.relPath(root, file)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolveCommit(r, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := commit.File(rel)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, fmt.Errorf("%s does not exist at %s: %w", file, rev, fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readFile(f)
+}
+
+// RevisionFS returns a read-only filesystem holding the tree of the directory dir as it was at the given
+// revision. If the directory did not exist at the revision, the error wraps fs.ErrNotExist.
+func (g *GoGitter) RevisionFS(ctx context.Context, rev Revision, dir string) (fs.FS, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	r, root, err := g.open()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := g.relPath(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolveCommit(r, rev)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if rel != "." {
+		if tree, err = tree.Tree(rel); errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, fmt.Errorf("%s does not exist at %s: %w", dir, rev, fs.ErrNotExist)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	// As with git archive, only regular files are included, with the permissions of a umask of 002.
+	t := newEmptyTreeFS()
+	err = tree.Files().ForEach(func(f *object.File) error {
+		mode := fs.FileMode(0o664)
+		switch f.Mode {
+		case filemode.Regular, filemode.Deprecated:
+		case filemode.Executable:
+			mode = 0o775
+		default:
+			return nil
+		}
+		data, rErr := readFile(f)
+		if rErr != nil {
+			return rErr
+		}
+		t.addFile(&treeFile{name: f.Name, data: data, mode: mode, modTime: commit.Committer.When})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.sortDirs()
+	return t, nil
+}
+
+// tagTarget returns the hash of the commit of the revision at, or of HEAD if at is empty.
+func tagTarget(r *git.Repository, at Revision) (plumbing.Hash, error) {
+	if at == "" {
+		head, err := r.Head()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return head.Hash(), nil
+	}
+	c, err := resolveCommit(r, at)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return c.Hash, nil
+}
+
+// resolveCommit returns the commit a revision refers to.
+func resolveCommit(r *git.Repository, rev Revision) (*object.Commit, error) {
+	hash, err := r.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", rev, err)
+	}
+	return r.CommitObject(*hash)
+}
+
+// readFile reads the content of a file in a tree.
+func readFile(f *object.File) ([]byte, error) {
+	rc, err := f.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+)
+
+func TestNewGitter(t *testing.T) {
+	t.Parallel()
+
+	cfg := newTestConfig(t)
+	assert.IsType(t, &CLIGitter{}, NewGitter(config.GitBackendCLI, cfg, fsh.NewPathResolver(), ""))
+	assert.IsType(t, &GoGitter{}, NewGitter(config.GitBackendGo, cfg, fsh.NewPathResolver(), ""))
+	assert.IsType(t, &CLIGitter{}, NewGitter("", cfg, fsh.NewPathResolver(), ""))
+}
+
+func TestTopLevel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("in a repository", func(t *testing.T) {
+		t.Parallel()
+		dir := setupTestRepo(t)
+		sub := filepath.Join(dir, "a", "b")
+		require.NoError(t, os.MkdirAll(sub, 0o750))
+
+		want, err := filepath.EvalSymlinks(dir)
+		require.NoError(t, err)
+		got, err := filepath.EvalSymlinks(TopLevel(sub))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("not in a repository", func(t *testing.T) {
+		t.Parallel()
+		assert.Empty(t, TopLevel(t.TempDir()))
+	})
+}
+
+func TestGoGitter_Errors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := newTestConfig(t)
+
+	t.Run("not a repository", func(t *testing.T) {
+		t.Parallel()
+		g := NewGoGitter(cfg, fsh.NewPathResolver(), t.TempDir())
+
+		_, err := g.GetLatestAnchor(ctx, "prod")
+		require.ErrorContains(t, err, "could not find git history")
+
+		_, err = g.GetSchemaChanges(ctx, "HEAD", ".", ".schema.json")
+		require.ErrorContains(t, err, "failed to find git root")
+
+		_, err = g.TagDeploymentSuccess(ctx, "prod", "", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		t.Parallel()
+		g := NewGoGitter(cfg, fsh.NewPathResolver(), setupTestRepo(t))
+		_, err := g.GetLatestAnchor(ctx, "staging")
+		require.Error(t, err)
+	})
+
+	t.Run("path outside the repository", func(t *testing.T) {
+		t.Parallel()
+		g := NewGoGitter(cfg, fsh.NewPathResolver(), setupTestRepo(t))
+		_, err := g.GetSchemaChanges(ctx, "HEAD", t.TempDir(), ".schema.json")
+		require.ErrorContains(t, err, "is outside the repository")
+	})
+
+	t.Run("path resolver fails", func(t *testing.T) {
+		t.Parallel()
+		resolver := &mockPathResolver{absFn: func(string) (string, error) { return "", errors.New("abs failed") }}
+		g := NewGoGitter(cfg, resolver, setupTestRepo(t))
+		_, err := g.GetFileAtRevision(ctx, "HEAD", "a.json")
+		require.ErrorContains(t, err, "abs failed")
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		t.Parallel()
+		g := NewGoGitter(cfg, fsh.NewPathResolver(), setupTestRepo(t))
+		_, err := g.RevisionFS(ctx, "no-such-revision", ".")
+		require.Error(t, err)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		t.Parallel()
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		g := NewGoGitter(cfg, fsh.NewPathResolver(), setupTestRepo(t))
+		_, err := g.GetSchemaChanges(cancelled, "HEAD", ".", ".schema.json")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestDiffFiles(t *testing.T) {
+	t.Parallel()
+
+	state := func(content string, mode filemode.FileMode) fileState {
+		return fileState{
+			hash: plumbing.ComputeHash(plumbing.BlobObject, []byte(content)),
+			mode: mode,
+			read: func() ([]byte, error) { return []byte(content), nil },
+		}
+	}
+	long := strings.Repeat("a line of a schema\n", 10)
+
+	tests := []struct {
+		name   string
+		before map[string]fileState
+		after  map[string]fileState
+		want   []Change
+	}{
+		{
+			name:   "unchanged",
+			before: map[string]fileState{"a.json": state("a", filemode.Regular)},
+			after:  map[string]fileState{"a.json": state("a", filemode.Regular)},
+		},
+		{
+			name:   "mode change",
+			before: map[string]fileState{"a.json": state("a", filemode.Regular)},
+			after:  map[string]fileState{"a.json": state("a", filemode.Executable)},
+			want:   []Change{{Path: "a.json", Status: ChangeModified}},
+		},
+		{
+			name:   "type change",
+			before: map[string]fileState{"a.json": state("a", filemode.Regular)},
+			after:  map[string]fileState{"a.json": state("a", filemode.Symlink)},
+			want:   []Change{{Path: "a.json", Status: ChangeTypeChanged}},
+		},
+		{
+			name:   "similar content is renamed",
+			before: map[string]fileState{"a.json": state(long, filemode.Regular)},
+			after:  map[string]fileState{"b.json": state(long+"one more line\n", filemode.Regular)},
+			want:   []Change{{Path: "b.json", OldPath: "a.json", Status: ChangeRenamed}},
+		},
+		{
+			name:   "dissimilar content is deleted and added",
+			before: map[string]fileState{"a.json": state(long, filemode.Regular)},
+			after:  map[string]fileState{"b.json": state("something else\n", filemode.Regular)},
+			want: []Change{
+				{Path: "a.json", Status: ChangeDeleted},
+				{Path: "b.json", Status: ChangeAdded},
+			},
+		},
+		{
+			name:   "empty files are not renamed",
+			before: map[string]fileState{"a.json": state("", filemode.Regular)},
+			after:  map[string]fileState{"b.json": state("", filemode.Regular)},
+			want: []Change{
+				{Path: "a.json", Status: ChangeDeleted},
+				{Path: "b.json", Status: ChangeAdded},
+			},
+		},
+		{
+			name: "most similar file is renamed",
+			before: map[string]fileState{
+				"a.json": state(long, filemode.Regular),
+			},
+			after: map[string]fileState{
+				"b.json": state(long+"one more line\n", filemode.Regular),
+				"c.json": state(long, filemode.Regular),
+			},
+			want: []Change{
+				{Path: "b.json", Status: ChangeAdded},
+				{Path: "c.json", OldPath: "a.json", Status: ChangeRenamed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, diffFiles(tt.before, tt.after))
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+
+	assert.Zero(t, similarity(nil, nil))
+	assert.Equal(t, 100, similarity([]byte("a\nb\n"), []byte("b\na\n")))
+	assert.Equal(t, 50, similarity([]byte("a\nb\n"), []byte("a\nc\n")))
+	assert.Zero(t, similarity([]byte("a\n"), []byte("b\n")))
+
+	// Lines longer than 64 bytes are compared in chunks.
+	line := strings.Repeat("x", 64)
+	assert.Equal(t, map[string]int{line: 128, "y\n": 2}, chunkSizes([]byte(line+line+"y\n")))
+}
@@ -0,0 +1,210 @@
+package repo
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// treeFS is a read-only, in-memory fs.FS holding the files of a git tree, read from a tar archive.
+type treeFS struct {
+	files map[string]*treeFile     // File content by name
+	dirs  map[string][]fs.DirEntry // Directory entries by name, sorted by filename
+}
+
+// treeFile is a file in a treeFS.
+type treeFile struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// newTreeFS reads the regular files in a tar archive into a treeFS. Directories are created for the parents
+// of every file, whether or not the archive holds them, and other entries, such as symlinks, are skipped.
+func newTreeFS(r io.Reader) (*treeFS, error) {
+	t := newEmptyTreeFS()
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if !fs.ValidPath(name) || name == "." {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		t.addFile(&treeFile{name: name, data: data, mode: fs.FileMode(hdr.Mode).Perm(), modTime: hdr.ModTime})
+	}
+
+	t.sortDirs()
+	return t, nil
+}
+
+// newEmptyTreeFS creates a treeFS holding only the root directory.
+func newEmptyTreeFS() *treeFS {
+	return &treeFS{
+		files: make(map[string]*treeFile),
+		dirs:  map[string][]fs.DirEntry{".": nil},
+	}
+}
+
+// sortDirs sorts the entries of every directory by filename, once all the files have been added.
+func (t *treeFS) sortDirs() {
+	for _, entries := range t.dirs {
+		slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	}
+}
+
+// addFile adds a file, and any of its parent directories which have not been added, to the treeFS.
+func (t *treeFS) addFile(f *treeFile) {
+	t.files[f.name] = f
+	entry := fs.FileInfoToDirEntry(f.info())
+	// The root directory always exists, so this ends there at the latest.
+	for name := f.name; ; name = path.Dir(name) {
+		dir := path.Dir(name)
+		_, exists := t.dirs[dir]
+		t.dirs[dir] = append(t.dirs[dir], entry)
+		if exists {
+			return
+		}
+		entry = fs.FileInfoToDirEntry(dirInfo(dir))
+	}
+}
+
+// Open opens the named file or directory.
+func (t *treeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if f, ok := t.files[name]; ok {
+		return &openTreeFile{info: f.info(), Reader: bytes.NewReader(f.data)}, nil
+	}
+	if entries, ok := t.dirs[name]; ok {
+		return &openTreeDir{info: dirInfo(name), entries: entries}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile reads the named file.
+func (t *treeFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+	f, ok := t.files[name]
+	if !ok {
+		if _, isDir := t.dirs[name]; isDir {
+			return nil, &fs.PathError{Op: "readfile", Path: name, Err: errors.New("is a directory")}
+		}
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+	}
+	return slices.Clone(f.data), nil
+}
+
+// ReadDir reads the named directory, returning its entries sorted by filename.
+func (t *treeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries, ok := t.dirs[name]
+	if !ok {
+		if _, isFile := t.files[name]; isFile {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+		}
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return slices.Clone(entries), nil
+}
+
+// Stat returns the FileInfo of the named file or directory.
+func (t *treeFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if f, ok := t.files[name]; ok {
+		return f.info(), nil
+	}
+	if _, ok := t.dirs[name]; ok {
+		return dirInfo(name), nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// treeFileInfo is the fs.FileInfo of a file or directory in a treeFS.
+type treeFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (f *treeFile) info() *treeFileInfo {
+	return &treeFileInfo{name: path.Base(f.name), size: int64(len(f.data)), mode: f.mode, modTime: f.modTime}
+}
+
+func dirInfo(name string) *treeFileInfo {
+	return &treeFileInfo{name: path.Base(name), mode: fs.ModeDir | 0o555}
+}
+
+func (i *treeFileInfo) Name() string       { return i.name }
+func (i *treeFileInfo) Size() int64        { return i.size }
+func (i *treeFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i *treeFileInfo) ModTime() time.Time { return i.modTime }
+func (i *treeFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *treeFileInfo) Sys() any           { return nil }
+
+// openTreeFile is an open file in a treeFS.
+type openTreeFile struct {
+	*bytes.Reader
+	info *treeFileInfo
+}
+
+func (f *openTreeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openTreeFile) Close() error               { return nil }
+
+// openTreeDir is an open directory in a treeFS.
+type openTreeDir struct {
+	info    *treeFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openTreeDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *openTreeDir) Close() error               { return nil }
+
+func (d *openTreeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir reads the entries of the directory, as described by fs.ReadDirFile.
+func (d *openTreeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return slices.Clone(remaining), nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return slices.Clone(remaining[:n]), nil
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tarArchive returns a tar archive holding the given entries.
+func tarArchive(t *testing.T, entries ...*tar.Header) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range entries {
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(strings.Repeat("x", int(hdr.Size))))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestNewTreeFS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("files and directories", func(t *testing.T) {
+		t.Parallel()
+		archive := tarArchive(t,
+			&tar.Header{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{
+				"comment": "0123456789abcdef",
+			}},
+			&tar.Header{Typeflag: tar.TypeDir, Name: "a/", Mode: 0o755},
+			&tar.Header{Typeflag: tar.TypeReg, Name: "a/z.json", Mode: 0o644, Size: 2},
+			&tar.Header{Typeflag: tar.TypeReg, Name: "a/b/c.json", Mode: 0o644, Size: 3},
+			&tar.Header{Typeflag: tar.TypeReg, Name: "root.json", Mode: 0o755, Size: 1},
+			&tar.Header{Typeflag: tar.TypeSymlink, Name: "link", Linkname: "root.json"},
+			&tar.Header{Typeflag: tar.TypeReg, Name: "../escape.json", Mode: 0o644, Size: 1},
+		)
+
+		fsys, err := newTreeFS(bytes.NewReader(archive))
+		require.NoError(t, err)
+		require.NoError(t, fstest.TestFS(fsys, "a/z.json", "a/b/c.json", "root.json"))
+
+		data, err := fs.ReadFile(fsys, "a/b/c.json")
+		require.NoError(t, err)
+		assert.Equal(t, "xxx", string(data))
+
+		entries, err := fs.ReadDir(fsys, "a")
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "b", entries[0].Name())
+		assert.True(t, entries[0].IsDir())
+		assert.Equal(t, "z.json", entries[1].Name())
+
+		for _, name := range []string{"link", "escape.json", "missing"} {
+			_, err = fs.Stat(fsys, name)
+			require.ErrorIs(t, err, fs.ErrNotExist, name)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		fsys, err := newTreeFS(bytes.NewReader(tarArchive(t,
+			&tar.Header{Typeflag: tar.TypeReg, Name: "a/b.json", Mode: 0o644, Size: 1},
+		)))
+		require.NoError(t, err)
+
+		_, err = fsys.ReadFile("a")
+		require.Error(t, err)
+		_, err = fsys.ReadFile("missing")
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		_, err = fsys.ReadDir("a/b.json")
+		require.Error(t, err)
+		_, err = fsys.ReadDir("missing")
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		_, err = fsys.Open("missing")
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		for _, op := range []func(string) error{
+			func(n string) error { _, oErr := fsys.Open(n); return oErr },
+			func(n string) error { _, rErr := fsys.ReadFile(n); return rErr },
+			func(n string) error { _, rErr := fsys.ReadDir(n); return rErr },
+			func(n string) error { _, sErr := fsys.Stat(n); return sErr },
+		} {
+			require.ErrorIs(t, op("../a"), fs.ErrInvalid)
+		}
+
+		dir, err := fsys.Open("a")
+		require.NoError(t, err)
+		_, err = dir.Read(make([]byte, 1))
+		require.Error(t, err)
+		require.NoError(t, dir.Close())
+	})
+
+	t.Run("invalid archive", func(t *testing.T) {
+		t.Parallel()
+		_, err := newTreeFS(strings.NewReader("not a tar archive"))
+		require.Error(t, err)
+
+		archive := tarArchive(t, &tar.Header{Typeflag: tar.TypeReg, Name: "a.json", Mode: 0o644, Size: 10})
+		_, err = newTreeFS(io.LimitReader(bytes.NewReader(archive), 515))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// HistoryReporter writes out a deployment history.
+type HistoryReporter interface {
+	Write(w io.Writer, h *schema.DeploymentHistory) error
+}
+
+// historyTimeFormat is the format of the times of deployments in text output.
+const historyTimeFormat = "2006-01-02 15:04:05 MST"
+
+// HistoryTextReporter implements HistoryReporter for plain text output. A history restricted to a target is
+// written as a timeline for each schema. Otherwise, it is written as a timeline for each environment.
+type HistoryTextReporter struct{}
+
+// Write implements the HistoryReporter interface.
+func (r *HistoryTextReporter) Write(w io.Writer, h *schema.DeploymentHistory) error {
+	if h.Target != "" {
+		writeSchemaTimelines(w, h)
+		return nil
+	}
+
+	for i, env := range h.Envs {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintln(w, env)
+
+		found := false
+		for _, d := range h.Deployments {
+			if d.Env != env {
+				continue
+			}
+			found = true
+			_, _ = fmt.Fprintf(w, "  %s  %s\n", d.Time.Format(historyTimeFormat), d.Tag)
+			if !d.Changed() {
+				_, _ = fmt.Fprintln(w, "      no schema changes")
+			}
+			for _, k := range d.Added {
+				_, _ = fmt.Fprintf(w, "      + %s\n", k)
+			}
+			for _, k := range d.Modified {
+				_, _ = fmt.Fprintf(w, "      ~ %s\n", k)
+			}
+			for _, k := range d.Removed {
+				_, _ = fmt.Fprintf(w, "      - %s\n", k)
+			}
+		}
+		if !found {
+			_, _ = fmt.Fprintln(w, "  No deployments")
+		}
+	}
+	return nil
+}
+
+// schemaEvent is a deployment which changed a schema.
+type schemaEvent struct {
+	d      *schema.HistoricDeployment
+	action string
+}
+
+// writeSchemaTimelines writes the deployments of each schema in the history, ordered by key.
+func writeSchemaTimelines(w io.Writer, h *schema.DeploymentHistory) {
+	// Deployments are in time order, so the events of each schema are too.
+	events := make(map[schema.Key][]schemaEvent)
+	for i := range h.Deployments {
+		d := &h.Deployments[i]
+		for _, k := range d.Added {
+			events[k] = append(events[k], schemaEvent{d: d, action: "added"})
+		}
+		for _, k := range d.Modified {
+			events[k] = append(events[k], schemaEvent{d: d, action: "modified"})
+		}
+		for _, k := range d.Removed {
+			events[k] = append(events[k], schemaEvent{d: d, action: "removed"})
+		}
+	}
+
+	if len(events) == 0 {
+		envs := make([]string, 0, len(h.Envs))
+		for _, env := range h.Envs {
+			envs = append(envs, string(env))
+		}
+		_, _ = fmt.Fprintf(w, "No deployments of %s found in %s\n", h.Target, strings.Join(envs, ", "))
+		return
+	}
+
+	for i, k := range slices.Sorted(maps.Keys(events)) {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintln(w, k)
+		for _, e := range events[k] {
+			_, _ = fmt.Fprintf(w, "  %s  %-8s  %-8s  %s\n", e.d.Time.Format(historyTimeFormat), e.d.Env, e.action, e.d.Tag)
+		}
+	}
+}
+
+// HistoryJSONReporter implements HistoryReporter for JSON output.
+type HistoryJSONReporter struct{}
+
+// Write implements the HistoryReporter interface.
+func (r *HistoryJSONReporter) Write(w io.Writer, h *schema.DeploymentHistory) error {
+	// Empty lists are written as [] rather than null, for the convenience of consumers.
+	out := *h
+	out.Deployments = make([]schema.HistoricDeployment, 0, len(h.Deployments))
+	for _, d := range h.Deployments {
+		d.Added = nonNilKeys(d.Added)
+		d.Modified = nonNilKeys(d.Modified)
+		d.Removed = nonNilKeys(d.Removed)
+		out.Deployments = append(out.Deployments, d)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func nonNilKeys(s []schema.Key) []schema.Key {
+	if s == nil {
+		return []schema.Key{}
+	}
+	return s
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func testHistory() *schema.DeploymentHistory {
+	return &schema.DeploymentHistory{
+		Envs: []config.Env{"dev", "prod", "staging"},
+		Deployments: []schema.HistoricDeployment{
+			{
+				Env:   "dev",
+				Tag:   "jsm-deploy/dev/1",
+				Time:  time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+				Added: []schema.Key{"domain_order_1_0_0"},
+			},
+			{
+				Env:      "prod",
+				Tag:      "jsm-deploy/prod/1",
+				Time:     time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+				Added:    []schema.Key{"domain_order_1_0_0"},
+				Modified: []schema.Key{"domain_person_1_0_0"},
+				Removed:  []schema.Key{"domain_gone_1_0_0"},
+			},
+			{
+				Env:      "dev",
+				Tag:      "jsm-deploy/dev/2",
+				Time:     time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC),
+				Previous: "jsm-deploy/dev/1",
+			},
+		},
+	}
+}
+
+func TestHistoryTextReporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("timeline per environment", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&HistoryTextReporter{}).Write(&buf, testHistory()))
+
+		assert.Equal(t, `dev
+  2026-03-01 09:00:00 UTC  jsm-deploy/dev/1
+      + domain_order_1_0_0
+  2026-03-03 09:00:00 UTC  jsm-deploy/dev/2
+      no schema changes
+
+prod
+  2026-03-02 09:00:00 UTC  jsm-deploy/prod/1
+      + domain_order_1_0_0
+      ~ domain_person_1_0_0
+      - domain_gone_1_0_0
+
+staging
+  No deployments
+`, buf.String())
+	})
+
+	t.Run("timeline per schema", func(t *testing.T) {
+		t.Parallel()
+		h := testHistory()
+		h.Target = "domain"
+		var buf bytes.Buffer
+		require.NoError(t, (&HistoryTextReporter{}).Write(&buf, h))
+
+		assert.Equal(t, `domain_gone_1_0_0
+  2026-03-02 09:00:00 UTC  prod      removed   jsm-deploy/prod/1
+
+domain_order_1_0_0
+  2026-03-01 09:00:00 UTC  dev       added     jsm-deploy/dev/1
+  2026-03-02 09:00:00 UTC  prod      added     jsm-deploy/prod/1
+
+domain_person_1_0_0
+  2026-03-02 09:00:00 UTC  prod      modified  jsm-deploy/prod/1
+`, buf.String())
+	})
+
+	t.Run("target never deployed", func(t *testing.T) {
+		t.Parallel()
+		h := &schema.DeploymentHistory{Envs: []config.Env{"dev", "prod"}, Target: "domain_new_1_0_0"}
+		var buf bytes.Buffer
+		require.NoError(t, (&HistoryTextReporter{}).Write(&buf, h))
+
+		assert.Equal(t, "No deployments of domain_new_1_0_0 found in dev, prod\n", buf.String())
+	})
+}
+
+func TestHistoryJSONReporter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, (&HistoryJSONReporter{}).Write(&buf, testHistory()))
+
+	var got struct {
+		Envs        []string         `json:"envs"`
+		Deployments []map[string]any `json:"deployments"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+
+	assert.Equal(t, []string{"dev", "prod", "staging"}, got.Envs)
+	require.Len(t, got.Deployments, 3)
+	assert.Equal(t, "jsm-deploy/dev/1", got.Deployments[0]["tag"])
+	assert.Equal(t, "2026-03-01T09:00:00Z", got.Deployments[0]["time"])
+	assert.NotContains(t, got.Deployments[0], "previous")
+	assert.Equal(t, []any{}, got.Deployments[0]["removed"])
+	assert.Equal(t, "jsm-deploy/dev/1", got.Deployments[2]["previous"])
+	assert.Equal(t, []any{}, got.Deployments[2]["added"])
+}
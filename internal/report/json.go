@@ -13,9 +13,10 @@ import (
 type JSONReporter struct{}
 
 type jsonSpec struct {
-	Path        string `json:"path"`
-	TestDocType string `json:"type"`
-	Error       string `json:"error,omitempty"`
+	Path         string `json:"path"`
+	TestDocType  string `json:"type"`
+	MigratedFrom string `json:"migratedFrom,omitempty"`
+	Error        string `json:"error,omitempty"`
 }
 
 type jsonSchemaResults struct {
@@ -28,50 +29,73 @@ type jsonOutput struct {
 	EndTime   string `json:"endTime"`
 	Duration  string `json:"duration"`
 	Stats     struct {
-		TotalPassed int `json:"totalPassed"`
-		TotalFailed int `json:"totalFailed"`
+		TotalPassed           int `json:"totalPassed"`
+		TotalFailed           int `json:"totalFailed"`
+		TotalMigrationsPassed int `json:"totalMigrationsPassed"`
+		TotalMigrationsFailed int `json:"totalMigrationsFailed"`
 	} `json:"stats"`
-	Results map[schema.Key]jsonSchemaResults `json:"results"`
+	Results        map[schema.Key]jsonSchemaResults `json:"results"`
+	Migrations     map[schema.Key]jsonSchemaResults `json:"migrations,omitempty"`
+	UnusedPartials []string                         `json:"unusedPartials,omitempty"`
 }
 
 func (jr *JSONReporter) Write(w io.Writer, r *schema.TestReport) error {
 	out := jsonOutput{
-		StartTime: r.StartTime.Format(time.RFC3339),
-		EndTime:   r.EndTime.Format(time.RFC3339),
-		Duration:  r.EndTime.Sub(r.StartTime).String(),
-		Results:   make(map[schema.Key]jsonSchemaResults),
+		StartTime:      r.StartTime.Format(time.RFC3339),
+		EndTime:        r.EndTime.Format(time.RFC3339),
+		Duration:       r.EndTime.Sub(r.StartTime).String(),
+		Results:        make(map[schema.Key]jsonSchemaResults),
+		UnusedPartials: r.UnusedPartials,
 	}
 
-	for k, specs := range r.PassedTests {
-		res := out.Results[k]
+	out.Stats.TotalPassed, out.Stats.TotalFailed = addJSONResults(out.Results, r.PassedTests, r.FailedTests)
+
+	if len(r.PassedMigrations)+len(r.FailedMigrations) > 0 {
+		out.Migrations = make(map[schema.Key]jsonSchemaResults)
+		out.Stats.TotalMigrationsPassed, out.Stats.TotalMigrationsFailed = addJSONResults(
+			out.Migrations, r.PassedMigrations, r.FailedMigrations)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// addJSONResults adds the passed and failed specs to results, and returns the number of each.
+func addJSONResults(results map[schema.Key]jsonSchemaResults, passed, failed schema.TestLog) (int, int) {
+	var totalPassed, totalFailed int
+
+	for k, specs := range passed {
+		res := results[k]
 		for _, s := range specs {
-			res.Passed = append(res.Passed, jsonSpec{
-				Path:        s.TestInfo.Path,
-				TestDocType: string(s.TestDocType),
-			})
+			res.Passed = append(res.Passed, newJSONSpec(&s))
 		}
-		out.Results[k] = res
-		out.Stats.TotalPassed += len(specs)
+		results[k] = res
+		totalPassed += len(specs)
 	}
 
-	for k, specs := range r.FailedTests {
-		res := out.Results[k]
+	for k, specs := range failed {
+		res := results[k]
 		for _, s := range specs {
-			errMsg := ""
-			if s.Err != nil {
-				errMsg = s.Err.Error()
-			}
-			res.Failed = append(res.Failed, jsonSpec{
-				Path:        s.TestInfo.Path,
-				TestDocType: string(s.TestDocType),
-				Error:       errMsg,
-			})
+			res.Failed = append(res.Failed, newJSONSpec(&s))
 		}
-		out.Results[k] = res
-		out.Stats.TotalFailed += len(specs)
+		results[k] = res
+		totalFailed += len(specs)
 	}
 
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(out)
+	return totalPassed, totalFailed
+}
+
+func newJSONSpec(s *schema.Spec) jsonSpec {
+	js := jsonSpec{
+		Path:        s.TestInfo.Path,
+		TestDocType: string(s.TestDocType),
+	}
+	if s.MigratedFrom != nil {
+		js.MigratedFrom = string(*s.MigratedFrom)
+	}
+	if s.Err != nil {
+		js.Error = s.Err.Error()
+	}
+	return js
 }
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// OwnersReporter writes out the ownership details of schema families.
+type OwnersReporter interface {
+	Write(w io.Writer, owners []schema.FamilyOwnership) error
+}
+
+// notRecorded is shown in text output for metadata fields which have not been set.
+const notRecorded = "-"
+
+// OwnersTextReporter implements OwnersReporter for plain text output.
+type OwnersTextReporter struct{}
+
+// Write implements the OwnersReporter interface.
+func (r *OwnersTextReporter) Write(w io.Writer, owners []schema.FamilyOwnership) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FAMILY\tOWNER\tSTATUS\tCLASSIFICATION\tCONTACT")
+
+	for _, o := range owners {
+		owner, status, classification, contact := notRecorded, notRecorded, notRecorded, notRecorded
+		if fm := o.Metadata; fm != nil {
+			owner = fm.Owner
+			status = orNotRecorded(string(fm.Status))
+			classification = orNotRecorded(string(fm.Classification))
+
+			var contacts []string
+			if fm.SlackChannel != "" {
+				contacts = append(contacts, fm.SlackChannel)
+			}
+			if fm.Email != "" {
+				contacts = append(contacts, fm.Email)
+			}
+			contact = orNotRecorded(strings.Join(contacts, ", "))
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", o.Scope, owner, status, classification, contact)
+	}
+
+	return tw.Flush()
+}
+
+func orNotRecorded(s string) string {
+	if s == "" {
+		return notRecorded
+	}
+	return s
+}
+
+// OwnersJSONReporter implements OwnersReporter for JSON output.
+type OwnersJSONReporter struct{}
+
+type jsonFamilyOwnership struct {
+	Family   schema.SearchScope     `json:"family"`
+	Metadata *schema.FamilyMetadata `json:"metadata"`
+}
+
+// Write implements the OwnersReporter interface.
+func (r *OwnersJSONReporter) Write(w io.Writer, owners []schema.FamilyOwnership) error {
+	out := make([]jsonFamilyOwnership, 0, len(owners))
+	for _, o := range owners {
+		out = append(out, jsonFamilyOwnership{Family: o.Scope, Metadata: o.Metadata})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// CodeOwnersReporter implements OwnersReporter, writing a GitHub CODEOWNERS file which assigns
+// each family directory to the code owners listed in its family.yml file.
+// Families without code owners are omitted.
+type CodeOwnersReporter struct {
+	// PathPrefix is the location of the registry root relative to the root of the git repository,
+	// using forward slashes. It is empty if the registry root is the repository root.
+	PathPrefix string
+}
+
+// Write implements the OwnersReporter interface.
+func (r *CodeOwnersReporter) Write(w io.Writer, owners []schema.FamilyOwnership) error {
+	_, _ = fmt.Fprintln(w, "# This file is generated by jsm from the family.yml files in the schema registry.")
+	_, _ = fmt.Fprintln(w, "# Do not edit it by hand - run 'jsm owners --codeowners' instead.")
+
+	for _, o := range owners {
+		if o.Metadata == nil || len(o.Metadata.CodeOwners) == 0 {
+			continue
+		}
+		dir := "/" + path.Join(r.PathPrefix, string(o.Scope)) + "/"
+		if _, err := fmt.Fprintf(w, "%s %s\n", dir, strings.Join(o.Metadata.CodeOwners, " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func testOwners() []schema.FamilyOwnership {
+	return []schema.FamilyOwnership{
+		{
+			Scope: "domain-a/family-a",
+			Dir:   "/reg/domain-a/family-a",
+			Metadata: &schema.FamilyMetadata{
+				Owner:          "team-a",
+				SlackChannel:   "#team-a",
+				Email:          "team-a@myorg.com",
+				Classification: schema.ClassificationInternal,
+				Status:         schema.StatusActive,
+				CodeOwners:     []string{"@myorg/team-a", "jane@myorg.com"},
+			},
+		},
+		{
+			Scope:    "domain-a/family-b",
+			Dir:      "/reg/domain-a/family-b",
+			Metadata: &schema.FamilyMetadata{Owner: "team-b"},
+		},
+		{
+			Scope: "domain-b/family-c",
+			Dir:   "/reg/domain-b/family-c",
+		},
+	}
+}
+
+func TestOwnersTextReporter(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, (&OwnersTextReporter{}).Write(&buf, testOwners()))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 4)
+	assert.Regexp(t, `^FAMILY\s+OWNER\s+STATUS\s+CLASSIFICATION\s+CONTACT$`, string(lines[0]))
+	assert.Regexp(t, `^domain-a/family-a\s+team-a\s+active\s+internal\s+#team-a, team-a@myorg.com$`, string(lines[1]))
+	assert.Regexp(t, `^domain-a/family-b\s+team-b\s+-\s+-\s+-$`, string(lines[2]))
+	assert.Regexp(t, `^domain-b/family-c\s+-\s+-\s+-\s+-$`, string(lines[3]))
+}
+
+func TestOwnersJSONReporter(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, (&OwnersJSONReporter{}).Write(&buf, testOwners()))
+
+	var got []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "domain-a/family-a", got[0]["family"])
+	md, ok := got[0]["metadata"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "team-a", md["owner"])
+	assert.Equal(t, "#team-a", md["slackChannel"])
+	assert.Equal(t, "internal", md["classification"])
+	assert.Equal(t, []interface{}{"@myorg/team-a", "jane@myorg.com"}, md["codeOwners"])
+
+	md, ok = got[1]["metadata"].(map[string]interface{})
+	require.True(t, ok)
+	assert.NotContains(t, md, "slackChannel")
+
+	assert.Nil(t, got[2]["metadata"])
+}
+
+func TestOwnersJSONReporter_Empty(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, (&OwnersJSONReporter{}).Write(&buf, nil))
+	assert.JSONEq(t, "[]", buf.String())
+}
+
+func TestCodeOwnersReporter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+	}{
+		{
+			name:   "registry at repository root",
+			prefix: "",
+			want:   "/domain-a/family-a/ @myorg/team-a jane@myorg.com\n",
+		},
+		{
+			name:   "registry below repository root",
+			prefix: "schemas/registry",
+			want:   "/schemas/registry/domain-a/family-a/ @myorg/team-a jane@myorg.com\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, (&CodeOwnersReporter{PathPrefix: tt.prefix}).Write(&buf, testOwners()))
+
+			out := buf.String()
+			assert.Contains(t, out, "# This file is generated by jsm")
+			assert.Contains(t, out, tt.want)
+			// Families without code owners are omitted.
+			assert.NotContains(t, out, "family-b")
+			assert.NotContains(t, out, "family-c")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+// PlanReporter writes out a deployment plan.
+type PlanReporter interface {
+	Write(w io.Writer, plan *schema.DeploymentPlan) error
+}
+
+// mutationExplanations explain in text output why each deployed schema is mutated.
+var mutationExplanations = map[schema.MutationReason]string{
+	schema.MutationModified:       "its source has been modified",
+	schema.MutationDeleted:        "it has been deleted",
+	schema.MutationRenamed:        "it has been renamed or moved",
+	schema.MutationPartialChanged: "a partial it includes has changed",
+	schema.MutationRangeResolved:  "a version range it references now resolves to a new version",
+}
+
+// PlanTextReporter implements PlanReporter for plain text output.
+type PlanTextReporter struct{}
+
+// Write implements the PlanReporter interface.
+func (r *PlanTextReporter) Write(w io.Writer, plan *schema.DeploymentPlan) error {
+	_, _ = fmt.Fprintf(w, "Deployment plan for %s (changes since %s)\n", plan.Env, plan.Anchor)
+
+	if len(plan.Added) == 0 && len(plan.Mutated) == 0 {
+		_, _ = fmt.Fprintln(w, "\nNo changes to deploy")
+		return nil
+	}
+
+	if len(plan.Added) > 0 {
+		_, _ = fmt.Fprintf(w, "\nSchemas to add (%d):\n", len(plan.Added))
+		for _, s := range plan.Added {
+			writePlannedSchema(w, "+", s)
+		}
+	}
+
+	if len(plan.Mutated) > 0 {
+		_, _ = fmt.Fprintf(w, "\nDeployed schemas to change (%d):\n", len(plan.Mutated))
+		for _, s := range plan.Mutated {
+			writePlannedSchema(w, "~", s)
+			_, _ = fmt.Fprintf(w, "      because %s\n", mutationExplanations[s.Reason])
+		}
+	}
+
+	if plan.Blocked() {
+		_, _ = fmt.Fprintf(w, "\nThis deployment would be refused: %s does not allow deployed schemas to be changed.\n",
+			plan.Env)
+		_, _ = fmt.Fprintln(w, "Revert the changes to the deployed schemas, and create a new version of each with")
+		_, _ = fmt.Fprintln(w, "'jsm create-schema-version' instead.")
+	}
+	return nil
+}
+
+// writePlannedSchema writes a line describing a planned schema, followed by any changes to its dependencies.
+func writePlannedSchema(w io.Writer, marker string, s schema.PlannedSchema) {
+	switch {
+	case s.Key == "":
+		_, _ = fmt.Fprintf(w, "  %s %s\n", marker, s.Path)
+		return
+	case s.ID == "":
+		_, _ = fmt.Fprintf(w, "  %s %s\n", marker, s.Key)
+	default:
+		_, _ = fmt.Fprintf(w, "  %s %s (%s) %s\n", marker, s.Key, s.Visibility, s.ID)
+	}
+
+	var deps []string
+	for _, k := range s.DependenciesAdded {
+		deps = append(deps, "+"+string(k))
+	}
+	for _, k := range s.DependenciesRemoved {
+		deps = append(deps, "-"+string(k))
+	}
+	if len(deps) > 0 {
+		_, _ = fmt.Fprintf(w, "      dependencies: %s\n", strings.Join(deps, ", "))
+	}
+}
+
+// PlanJSONReporter implements PlanReporter for JSON output.
+type PlanJSONReporter struct{}
+
+type jsonPlan struct {
+	*schema.DeploymentPlan
+	Blocked bool `json:"blocked"`
+}
+
+// Write implements the PlanReporter interface.
+func (r *PlanJSONReporter) Write(w io.Writer, plan *schema.DeploymentPlan) error {
+	// Empty lists are written as [] rather than null, for the convenience of consumers.
+	p := *plan
+	p.Added = nonNil(p.Added)
+	p.Mutated = nonNil(p.Mutated)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonPlan{DeploymentPlan: &p, Blocked: plan.Blocked()})
+}
+
+func nonNil(s []schema.PlannedSchema) []schema.PlannedSchema {
+	if s == nil {
+		return []schema.PlannedSchema{}
+	}
+	return s
+}
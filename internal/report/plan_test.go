@@ -0,0 +1,133 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/schema"
+)
+
+func testPlan() *schema.DeploymentPlan {
+	return &schema.DeploymentPlan{
+		Env:    "prod",
+		Anchor: "jsm-deploy/prod/v1",
+		Added: []schema.PlannedSchema{{
+			Path:              "domain/order/2/0/0/domain_order_2_0_0.schema.json",
+			Key:               "domain_order_2_0_0",
+			Visibility:        "public",
+			ID:                "https://p/domain_order_2_0_0.schema.json",
+			DependenciesAdded: []schema.Key{"domain_address_1_0_0"},
+		}},
+		Mutated: []schema.PlannedSchema{
+			{
+				Path:                "domain/order/1/0/0/domain_order_1_0_0.schema.json",
+				Key:                 "domain_order_1_0_0",
+				Visibility:          "private",
+				ID:                  "https://pr/domain_order_1_0_0.schema.json",
+				Reason:              schema.MutationModified,
+				DependenciesAdded:   []schema.Key{"domain_address_1_0_0"},
+				DependenciesRemoved: []schema.Key{"domain_customer_1_0_0"},
+			},
+			{
+				Path:   "domain/gone/1/0/0/domain_gone_1_0_0.schema.json",
+				Reason: schema.MutationDeleted,
+			},
+			{
+				Path:       "domain/moved/1/0/0/domain_moved_1_0_0.schema.json",
+				Key:        "domain_moved_1_0_0",
+				Visibility: "private",
+				ID:         "https://pr/domain_moved_1_0_0.schema.json",
+				Reason:     schema.MutationRenamed,
+			},
+			{
+				Path:   "domain/unread/1/0/0/domain_unread_1_0_0.schema.json",
+				Key:    "domain_unread_1_0_0",
+				Reason: schema.MutationDeleted,
+			},
+		},
+	}
+}
+
+func TestPlanTextReporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("blocked deployment", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&PlanTextReporter{}).Write(&buf, testPlan()))
+
+		assert.Equal(t, `Deployment plan for prod (changes since jsm-deploy/prod/v1)
+
+Schemas to add (1):
+  + domain_order_2_0_0 (public) https://p/domain_order_2_0_0.schema.json
+      dependencies: +domain_address_1_0_0
+
+Deployed schemas to change (4):
+  ~ domain_order_1_0_0 (private) https://pr/domain_order_1_0_0.schema.json
+      dependencies: +domain_address_1_0_0, -domain_customer_1_0_0
+      because its source has been modified
+  ~ domain/gone/1/0/0/domain_gone_1_0_0.schema.json
+      because it has been deleted
+  ~ domain_moved_1_0_0 (private) https://pr/domain_moved_1_0_0.schema.json
+      because it has been renamed or moved
+  ~ domain_unread_1_0_0
+      because it has been deleted
+
+This deployment would be refused: prod does not allow deployed schemas to be changed.
+Revert the changes to the deployed schemas, and create a new version of each with
+'jsm create-schema-version' instead.
+`, buf.String())
+	})
+
+	t.Run("mutations allowed", func(t *testing.T) {
+		t.Parallel()
+		plan := testPlan()
+		plan.MutationsAllowed = true
+		var buf bytes.Buffer
+		require.NoError(t, (&PlanTextReporter{}).Write(&buf, plan))
+		assert.NotContains(t, buf.String(), "refused")
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&PlanTextReporter{}).Write(&buf, &schema.DeploymentPlan{Env: "prod", Anchor: "HEAD"}))
+		assert.Equal(t, "Deployment plan for prod (changes since HEAD)\n\nNo changes to deploy\n", buf.String())
+	})
+}
+
+func TestPlanJSONReporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("blocked deployment", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&PlanJSONReporter{}).Write(&buf, testPlan()))
+
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, "prod", got["env"])
+		assert.Equal(t, "jsm-deploy/prod/v1", got["anchor"])
+		assert.Equal(t, false, got["mutationsAllowed"])
+		assert.Equal(t, true, got["blocked"])
+		require.Len(t, got["added"], 1)
+		require.Len(t, got["mutated"], 4)
+
+		modified := got["mutated"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "modified", modified["reason"])
+		assert.Equal(t, []interface{}{"domain_customer_1_0_0"}, modified["dependenciesRemoved"])
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&PlanJSONReporter{}).Write(&buf, &schema.DeploymentPlan{Env: "prod", Anchor: "HEAD"}))
+		assert.JSONEq(t,
+			`{"env": "prod", "anchor": "HEAD", "mutationsAllowed": false, "added": [], "mutated": [], "blocked": false}`,
+			buf.String())
+	})
+}
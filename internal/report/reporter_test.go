@@ -160,3 +160,117 @@ func TestJSONReporter(t *testing.T) {
 	assert.Contains(t, output, `"path": "fail.json"`)
 	assert.Contains(t, output, `"error": "boom"`)
 }
+
+func migrationTestReport() *schema.TestReport {
+	r := schema.NewTestReport()
+	k := schema.Key("d1_f1_2_0_0")
+	from := schema.Key("d1_f1_1_3_0")
+
+	specPass := schema.Spec{
+		TestInfo:    schema.TestInfo{Path: "pass.json"},
+		TestDocType: schema.TestDocTypePass,
+	}
+	migratedPass := schema.NewMigrationSpec(nil, schema.TestInfo{Path: "old-a.json"}, from)
+	migratedFail := schema.NewMigrationSpec(nil, schema.TestInfo{Path: "old-b.json"}, from)
+	migratedFail.Err = fmt.Errorf("missing familyName")
+
+	r.AddPassedTest(k, &specPass)
+	r.AddPassedMigration(k, &migratedPass)
+	r.AddFailedMigration(k, &migratedFail)
+	return r
+}
+
+func TestTextReporter_Migrations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Concise Mode", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&TextReporter{}).Write(&buf, migrationTestReport()))
+
+		output := buf.String()
+		assert.Contains(t, output, "MIGRATIONS")
+		assert.Contains(t, output, "[FAIL] d1_f1_2_0_0.schema.json (migrated from 1.3.0 - pass: 1, fail: 1)")
+		assert.Contains(t, output, "✗ old-b.json (failed - migration from 1.3.0 did not produce a valid document)")
+		assert.Contains(t, output, "missing familyName")
+		assert.NotContains(t, output, "old-a.json")
+		assert.Contains(t, output, "Migration summary: 1 passed, 1 failed")
+		// Migrations are not counted as ordinary tests
+		assert.Contains(t, output, "Test summary: 1 passed, 0 failed")
+	})
+
+	t.Run("Verbose Mode", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&TextReporter{Verbose: true}).Write(&buf, migrationTestReport()))
+		assert.Contains(t, buf.String(), "✓ old-a.json (migrated from 1.3.0 passed)")
+	})
+
+	t.Run("All Passed Colour", func(t *testing.T) {
+		t.Parallel()
+		r := schema.NewTestReport()
+		spec := schema.NewMigrationSpec(nil, schema.TestInfo{Path: "old-a.json"}, "d1_f1_1_3_0")
+		r.AddPassedMigration("d1_f1_2_0_0", &spec)
+
+		var buf bytes.Buffer
+		require.NoError(t, (&TextReporter{UseColour: true}).Write(&buf, r))
+		output := buf.String()
+		assert.Contains(t, output, "\033[32m[PASS]\033[0m")
+		assert.Contains(t, output, "\033[1;32m1 passed, 0 failed\033[0m")
+	})
+
+	t.Run("No Migrations", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&TextReporter{}).Write(&buf, schema.NewTestReport()))
+		assert.NotContains(t, buf.String(), "MIGRATIONS")
+		assert.NotContains(t, buf.String(), "Migration summary")
+	})
+}
+
+func TestJSONReporter_Migrations(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, (&JSONReporter{}).Write(&buf, migrationTestReport()))
+
+	output := buf.String()
+	assert.Contains(t, output, `"totalPassed": 1`)
+	assert.Contains(t, output, `"totalMigrationsPassed": 1`)
+	assert.Contains(t, output, `"totalMigrationsFailed": 1`)
+	assert.Contains(t, output, `"migrations": {`)
+	assert.Contains(t, output, `"migratedFrom": "d1_f1_1_3_0"`)
+	assert.Contains(t, output, `"error": "missing familyName"`)
+
+	buf.Reset()
+	require.NoError(t, (&JSONReporter{}).Write(&buf, schema.NewTestReport()))
+	assert.NotContains(t, buf.String(), `"migrations"`)
+}
+
+func TestReporters_UnusedPartials(t *testing.T) {
+	t.Parallel()
+
+	r := schema.NewTestReport()
+	r.UnusedPartials = []string{"address", "money"}
+
+	t.Run("Text", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&TextReporter{}).Write(&buf, r))
+		assert.Contains(t, buf.String(), "⚠ Unused partials: address, money")
+
+		buf.Reset()
+		require.NoError(t, (&TextReporter{}).Write(&buf, schema.NewTestReport()))
+		assert.NotContains(t, buf.String(), "Unused partials")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, (&JSONReporter{}).Write(&buf, r))
+		assert.Contains(t, buf.String(), `"unusedPartials": [`)
+
+		buf.Reset()
+		require.NoError(t, (&JSONReporter{}).Write(&buf, schema.NewTestReport()))
+		assert.NotContains(t, buf.String(), `"unusedPartials"`)
+	})
+}
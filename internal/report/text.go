@@ -20,6 +20,7 @@ const (
 	colRed       = "\033[31m"
 	colGreen     = "\033[32m"
 	colGrey      = "\033[90m"
+	colYellow    = "\033[33m"
 	colWhite     = "\033[37m"
 	colBoldRed   = "\033[1;31m"
 	colBoldGreen = "\033[1;32m"
@@ -116,7 +117,23 @@ func (tr *TextReporter) Write(w io.Writer, r *schema.TestReport) error {
 		}
 	}
 
+	migrationsPassed, migrationsFailed := tr.writeMigrations(w, r, divider)
+
+	if len(r.UnusedPartials) > 0 {
+		_, _ = fmt.Fprintf(w, "%s\n", divider)
+		_, _ = fmt.Fprintf(w, "%s %s\n", tr.cs(colYellow, "⚠ Unused partials:"),
+			strings.Join(r.UnusedPartials, ", "))
+	}
+
 	_, _ = fmt.Fprintf(w, "%s\n", divider)
+	if migrationsPassed+migrationsFailed > 0 {
+		migrationStats := fmt.Sprintf("%d passed, %d failed", migrationsPassed, migrationsFailed)
+		migrationCol := colBoldGreen
+		if migrationsFailed > 0 {
+			migrationCol = colBoldRed
+		}
+		_, _ = fmt.Fprintf(w, "%s%s\n", tr.cs(colBoldWhite, "Migration summary: "), tr.cs(migrationCol, migrationStats))
+	}
 	summaryLabel := tr.cs(colBoldWhite, "Test summary: ")
 	summaryStats := fmt.Sprintf("%d passed, %d failed", totalPassed, totalFailed)
 	statsColor := colBoldGreen
@@ -130,3 +147,79 @@ func (tr *TextReporter) Write(w io.Writer, r *schema.TestReport) error {
 	_, err = fmt.Fprintf(w, "%s\n", divider)
 	return err
 }
+
+// writeMigrations writes the results of migration tests, if there are any, and returns
+// the number which passed and failed.
+func (tr *TextReporter) writeMigrations(w io.Writer, r *schema.TestReport, divider string) (int, int) {
+	keysMap := make(map[schema.Key]bool)
+	for k := range r.PassedMigrations {
+		keysMap[k] = true
+	}
+	for k := range r.FailedMigrations {
+		keysMap[k] = true
+	}
+	if len(keysMap) == 0 {
+		return 0, 0
+	}
+
+	keys := make([]schema.Key, 0, len(keysMap))
+	for k := range keysMap {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	_, _ = fmt.Fprintf(w, "%s\n", divider)
+	_, _ = fmt.Fprint(w, tr.cs(colBoldWhite, "MIGRATIONS\n\n"))
+
+	totalPassed := 0
+	totalFailed := 0
+
+	for _, k := range keys {
+		passed := r.PassedMigrations[k]
+		failed := r.FailedMigrations[k]
+		totalPassed += len(passed)
+		totalFailed += len(failed)
+
+		statusText := "PASS"
+		statusCol := colGreen
+		keyCol := colWhite
+		if len(failed) > 0 {
+			statusText = "FAIL"
+			statusCol = colRed
+			keyCol = colRed
+		}
+
+		// All migration specs for a schema are migrated from the same version.
+		first := passed
+		if len(first) == 0 {
+			first = failed
+		}
+		from := ""
+		if first[0].MigratedFrom != nil {
+			from = " from " + first[0].MigratedFrom.Version().String('.')
+		}
+		suffix := fmt.Sprintf("(migrated%s - pass: %d, fail: %d)", from, len(passed), len(failed))
+		_, _ = fmt.Fprintf(w, "%s %s %s\n", tr.cs(statusCol, "["+statusText+"]"),
+			tr.cs(keyCol, string(k)+schema.SchemaSuffix), tr.cs(statusCol, suffix))
+
+		if tr.Verbose {
+			for _, spec := range passed {
+				_, _ = fmt.Fprintf(w, "  %s %s (%s)\n",
+					tr.cs(colGreen, "✓"),
+					tr.cs(colGrey, spec.TestInfo.Path),
+					tr.cs(colGreen, spec.ResultLabel()))
+			}
+		}
+		for _, spec := range failed {
+			_, _ = fmt.Fprintf(w, "  %s %s (%s):\n",
+				tr.cs(colRed, "✗"),
+				tr.cs(colGrey, spec.TestInfo.Path),
+				tr.cs(colRed, spec.ResultLabel()))
+			_, _ = fmt.Fprintf(w, "    %v\n", spec.Err)
+		}
+	}
+
+	return totalPassed, totalFailed
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+// annotationKeywords are the JSON Schema keywords which describe a schema without affecting validation.
+var annotationKeywords = []string{"title", "description", "examples", "$comment"}
+
+// The keywords whose values are subschemas, keyed by how the subschemas are held.
+var (
+	subschemaKeywords = map[string]bool{
+		"additionalItems": true, "additionalProperties": true, "contains": true, "contentSchema": true,
+		"else": true, "if": true, "items": true, "not": true, "propertyNames": true, "then": true,
+		"unevaluatedItems": true, "unevaluatedProperties": true,
+	}
+	subschemaArrayKeywords = map[string]bool{
+		"allOf": true, "anyOf": true, "items": true, "oneOf": true, "prefixItems": true,
+	}
+	subschemaMapKeywords = map[string]bool{
+		"$defs": true, "definitions": true, "dependencies": true, "dependentSchemas": true,
+		"patternProperties": true, "properties": true,
+	}
+)
+
+// withoutAnnotations returns a copy of the schema with the annotation keywords of the environment removed
+// from it and all its subschemas. Values which are not subschemas, such as property names, enum values and
+// defaults, are left untouched, so a property called "description" is not removed.
+func withoutAnnotations(schema any, ec *config.EnvConfig) any {
+	keywords := make(map[string]bool)
+	for _, k := range annotationKeywords {
+		keywords[k] = true
+	}
+	for _, k := range ec.AnnotationKeys {
+		keywords[k] = true
+	}
+	return stripAnnotations(schema, keywords)
+}
+
+// stripAnnotations removes the given keywords from a schema and its subschemas. Boolean schemas are returned
+// as they are.
+func stripAnnotations(schema any, keywords map[string]bool) any {
+	obj, ok := schema.(map[string]any)
+	if !ok {
+		return schema
+	}
+
+	stripped := make(map[string]any, len(obj))
+	for k, v := range obj {
+		if keywords[k] {
+			continue
+		}
+		stripped[k] = stripKeywordValue(k, v, keywords)
+	}
+	return stripped
+}
+
+// stripKeywordValue removes annotations from any subschemas held in the value of a keyword.
+func stripKeywordValue(keyword string, v any, keywords map[string]bool) any {
+	switch val := v.(type) {
+	case []any:
+		if !subschemaArrayKeywords[keyword] {
+			return v
+		}
+		schemas := make([]any, len(val))
+		for i, s := range val {
+			schemas[i] = stripAnnotations(s, keywords)
+		}
+		return schemas
+	case map[string]any:
+		if subschemaKeywords[keyword] {
+			return stripAnnotations(val, keywords)
+		}
+		if !subschemaMapKeywords[keyword] {
+			return v
+		}
+		// The keys are names, not keywords. Values which are not schemas (e.g. the property
+		// dependencies of draft-07 dependencies) are left untouched by stripAnnotations.
+		schemas := make(map[string]any, len(val))
+		for name, s := range val {
+			schemas[name] = stripAnnotations(s, keywords)
+		}
+		return schemas
+	default:
+		return v
+	}
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+func TestWithoutAnnotations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "top level annotations",
+			in:   `{"title": "T", "description": "D", "examples": [1], "$comment": "C", "x-owner": "a", "type": "integer"}`,
+			want: `{"type": "integer"}`,
+		},
+		{
+			name: "unconfigured extension keywords are kept",
+			in:   `{"x-other": {"description": "kept"}, "type": "integer"}`,
+			want: `{"x-other": {"description": "kept"}, "type": "integer"}`,
+		},
+		{
+			name: "properties named like annotations are kept",
+			in: `{"properties": {"description": {"type": "string", "description": "D"}}, ` +
+				`"required": ["description"]}`,
+			want: `{"properties": {"description": {"type": "string"}}, "required": ["description"]}`,
+		},
+		{
+			name: "subschemas",
+			in: `{"$defs": {"a": {"title": "A"}}, "allOf": [{"title": "B"}, true], "not": {"title": "C"}, ` +
+				`"items": {"title": "D"}, "patternProperties": {"^x": {"title": "E"}}}`,
+			want: `{"$defs": {"a": {}}, "allOf": [{}, true], "not": {}, "items": {}, "patternProperties": {"^x": {}}}`,
+		},
+		{
+			name: "draft-07 tuple items and dependencies",
+			in:   `{"items": [{"title": "A"}], "dependencies": {"a": ["b"], "c": {"title": "C", "required": ["d"]}}}`,
+			want: `{"items": [{}], "dependencies": {"a": ["b"], "c": {"required": ["d"]}}}`,
+		},
+		{
+			name: "instance values are kept",
+			in: `{"enum": [{"title": "x"}], "const": {"description": "y"}, "default": {"title": "z"}, ` +
+				`"examples": [{"title": "removed"}]}`,
+			want: `{"enum": [{"title": "x"}], "const": {"description": "y"}, "default": {"title": "z"}}`,
+		},
+		{
+			name: "boolean schema",
+			in:   `true`,
+			want: `true`,
+		},
+	}
+
+	ec := &config.EnvConfig{AnnotationKeys: []string{"x-owner"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var in any
+			require.NoError(t, json.Unmarshal([]byte(tt.in), &in))
+
+			got, err := json.Marshal(withoutAnnotations(in, ec))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
@@ -14,6 +14,7 @@ type RenderInfo struct {
 	Rendered     []byte               // The template-substituted document prior to unmarshalling
 	Unmarshalled validator.JSONSchema // The UnmarshalJSON unmarshalled version of the rendered schema.
 	Validator    validator.Validator  // The compiled validator which can be used to validate JSON documents.
+	Dependencies []Dependency         // The schemas referenced by the rendered schema, with version ranges resolved.
 }
 
 // RenderCache is the type used to store rendered information for a schema in memory.
@@ -31,8 +32,12 @@ type TestInfo struct {
 
 // NewTestInfo attempts to read in and parse a test JSON document.
 func NewTestInfo(filePath string) (TestInfo, error) {
-	//nolint:gosec // Path is constructed from internal registry logic
-	data, err := os.ReadFile(filePath)
+	return readTestInfo(os.ReadFile, filePath)
+}
+
+// readTestInfo attempts to read in, with the given function, and parse a test JSON document.
+func readTestInfo(readFile func(string) ([]byte, error), filePath string) (TestInfo, error) {
+	data, err := readFile(filePath)
 	if err != nil {
 		return TestInfo{}, CannotReadTestDocumentError{Path: filePath}
 	}
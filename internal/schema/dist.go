@@ -2,16 +2,18 @@ package schema
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strings"
+	"slices"
 	"sync"
 
 	"github.com/bitshepherds/json-schema-manager/internal/config"
 	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/jcs"
 	"github.com/bitshepherds/json-schema-manager/internal/repo"
 )
 
@@ -28,7 +30,10 @@ func (e *RegistryRootAtGitRootError) Error() string {
 type DistBuilder interface {
 	BuildAll(ctx context.Context, env config.Env) (int, error)
 	BuildChanged(ctx context.Context, env config.Env, anchor repo.Revision) (int, error)
+	BuildPromotion(ctx context.Context, p *Promotion) (int, error)
+	BuildRollback(ctx context.Context, rb *Rollback) (int, error)
 	SetNumWorkers(n int)
+	DistDir(env config.Env) string
 }
 
 // FSDistBuilder builds distribution directories of rendered schemas on the filesystem.
@@ -67,6 +72,11 @@ func (b *FSDistBuilder) SetNumWorkers(n int) {
 	b.numWorkers = n
 }
 
+// DistDir returns the directory the distribution for the given environment is built into.
+func (b *FSDistBuilder) DistDir(env config.Env) string {
+	return filepath.Join(b.distDir, string(env))
+}
+
 // BuildAll renders all schemas in the registry in parallel for the given environment.
 func (b *FSDistBuilder) BuildAll(ctx context.Context, env config.Env) (int, error) {
 	if err := b.ensureDistDir(env); err != nil {
@@ -88,8 +98,8 @@ func (b *FSDistBuilder) BuildAll(ctx context.Context, env config.Env) (int, erro
 
 	var finalErr error
 	var errOnce sync.Once
-	var count int
-	var countMu sync.Mutex
+	var entries []ManifestEntry
+	var entriesMu sync.Mutex
 
 Loop:
 	for res := range resultC {
@@ -112,7 +122,8 @@ Loop:
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			if rErr := b.renderAndWrite(runCtx, env, k); rErr != nil {
+			entry, rErr := b.renderAndWrite(runCtx, env, k)
+			if rErr != nil {
 				errOnce.Do(func() {
 					finalErr = rErr
 					cancelRun()
@@ -120,23 +131,23 @@ Loop:
 				return
 			}
 
-			countMu.Lock()
-			count++
-			countMu.Unlock()
+			entriesMu.Lock()
+			entries = append(entries, entry)
+			entriesMu.Unlock()
 		}(res.Key)
 	}
 
 	wg.Wait()
 
 	if ctx.Err() != nil {
-		return count, ctx.Err()
+		return len(entries), ctx.Err()
 	}
 
 	if finalErr != nil {
-		return count, finalErr
+		return len(entries), finalErr
 	}
 
-	return count, nil
+	return len(entries), b.writeIndexes(Manifest{Env: env, Schemas: entries})
 }
 
 // BuildChanged renders schemas that have changed since the given anchor for the given environment.
@@ -145,65 +156,213 @@ func (b *FSDistBuilder) BuildChanged(ctx context.Context, env config.Env, anchor
 		return 0, err
 	}
 
-	changes, err := b.gitter.GetSchemaChanges(ctx, anchor, b.registry.RootDirectory(), SchemaSuffix)
+	keys, err := b.changedKeys(ctx, env, anchor)
 	if err != nil {
 		return 0, err
 	}
+	return b.buildKeys(ctx, env, keys)
+}
 
-	var count int
-	for _, change := range changes {
+// BuildPromotion renders the schemas of a promotion for the environment they are promoted to. The schemas
+// are rendered as they were at the latest deployment to the environment they are promoted from, and the
+// manifest records that deployment, so that it is the revision tagged once the distribution is deployed.
+func (b *FSDistBuilder) BuildPromotion(ctx context.Context, p *Promotion) (int, error) {
+	if err := b.ensureDistDir(p.To); err != nil {
+		return 0, err
+	}
+
+	pb := *b
+	pb.registry = p.Registry()
+	return pb.buildManifest(ctx, Manifest{Env: p.To, Promoted: p.FromAnchor}, p.Keys())
+}
+
+// BuildRollback renders the schemas a rollback reverts for the environment rolled back, as they were at the
+// deployment restored. The manifest records the deployment rolled back and the schemas to remove.
+func (b *FSDistBuilder) BuildRollback(ctx context.Context, rb *Rollback) (int, error) {
+	if err := b.ensureDistDir(rb.Env); err != nil {
+		return 0, err
+	}
+
+	pb := *b
+	pb.registry = rb.Registry()
+	m := Manifest{Env: rb.Env, RolledBack: rb.RolledBack, Restored: rb.Restored, Removed: rb.Removed}
+	return pb.buildManifest(ctx, m, rb.Reverted)
+}
+
+// buildKeys renders the schemas with the given keys for the environment, and writes the indexes of their
+// families.
+func (b *FSDistBuilder) buildKeys(ctx context.Context, env config.Env, keys []Key) (int, error) {
+	return b.buildManifest(ctx, Manifest{Env: env}, keys)
+}
+
+// buildManifest renders the schemas with the given keys for the environment of the manifest, and writes the
+// manifest with their entries, and the indexes of their families.
+func (b *FSDistBuilder) buildManifest(ctx context.Context, m Manifest, keys []Key) (int, error) {
+	m.Schemas = make([]ManifestEntry, 0, len(keys))
+	for _, k := range keys {
 		if ctx.Err() != nil {
-			return count, ctx.Err()
+			return len(m.Schemas), ctx.Err()
 		}
 
+		entry, rwErr := b.renderAndWrite(ctx, m.Env, k)
+		if rwErr != nil {
+			return len(m.Schemas), rwErr
+		}
+		m.Schemas = append(m.Schemas, entry)
+	}
+
+	return len(m.Schemas), b.writeIndexes(m)
+}
+
+// changedKeys returns the keys of the schemas which render differently since the anchor. These are the
+// schemas which have been added, modified or renamed, those which include a changed partial, and those
+// which reference a new schema via a version range. Deleted schemas have nothing to render.
+func (b *FSDistBuilder) changedKeys(ctx context.Context, env config.Env, anchor repo.Revision) ([]Key, error) {
+	changes, err := b.gitter.GetSchemaChanges(ctx, anchor, b.registry.RootDirectory(), SchemaSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys, newKeys []Key
+	for _, change := range changes {
+		if change.Status == repo.ChangeDeleted {
+			continue
+		}
 		k, kErr := b.registry.KeyFromSchemaPath(change.Path)
 		if kErr != nil {
 			// Skip files that don't map to valid keys
 			continue
 		}
-
-		if rwErr := b.renderAndWrite(ctx, env, k); rwErr != nil {
-			return count, rwErr
+		keys = append(keys, k)
+		if change.IsNew() {
+			newKeys = append(newKeys, k)
 		}
-		count++
 	}
 
-	return count, nil
+	// Schemas which include a changed partial have changed too.
+	dependents, err := b.registry.ChangedPartialDependents(ctx, b.gitter, anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	// As have schemas whose version ranges now resolve to a new schema.
+	ec, err := b.config.EnvConfig(env)
+	if err != nil {
+		return nil, err
+	}
+	rangeDependents, err := b.registry.RangeDependents(ctx, ec, newKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range append(dependents, rangeDependents...) {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
 }
 
 // renderAndWrite renders a single schema and writes it to the dist directory for the given environment.
-func (b *FSDistBuilder) renderAndWrite(ctx context.Context, env config.Env, k Key) error {
+// It returns the manifest entry describing the written schema.
+func (b *FSDistBuilder) renderAndWrite(ctx context.Context, env config.Env, k Key) (ManifestEntry, error) {
 	if ctx.Err() != nil {
-		return ctx.Err()
+		return ManifestEntry{}, ctx.Err()
 	}
 
 	ec, err := b.config.EnvConfig(env)
 	if err != nil {
-		return fmt.Errorf("failed to get environment config for %s: %w", env, err)
+		return ManifestEntry{}, fmt.Errorf("failed to get environment config for %s: %w", env, err)
 	}
 
 	s, err := b.registry.GetSchemaByKey(k)
 	if err != nil {
-		return fmt.Errorf("failed to get schema %s: %w", k, err)
+		return ManifestEntry{}, fmt.Errorf("failed to get schema %s: %w", k, err)
 	}
 
 	ri, err := b.registry.CoordinateRender(s, ec)
 	if err != nil {
-		return fmt.Errorf("failed to render schema %s: %w", k, err)
+		return ManifestEntry{}, fmt.Errorf("failed to render schema %s: %w", k, err)
 	}
 
-	envDir := filepath.Join(b.distDir, string(env))
-	subDir := "private"
-	if s.IsPublic() {
-		subDir = "public"
+	envDir := b.DistDir(env)
+	distPath := filepath.Join(s.visibility(), s.Filename())
+
+	output, err := distOutput(ri, ec)
+	if err != nil {
+		return ManifestEntry{}, fmt.Errorf("failed to canonicalise schema %s: %w", k, err)
 	}
 
-	outputPath := filepath.Join(envDir, subDir, s.Filename())
-	if wErr := os.WriteFile(outputPath, ri.Rendered, 0o600); wErr != nil {
-		return fmt.Errorf("failed to write schema %s: %w", k, wErr)
+	outputPath := filepath.Join(envDir, distPath)
+	if wErr := os.WriteFile(outputPath, output, 0o600); wErr != nil {
+		return ManifestEntry{}, fmt.Errorf("failed to write schema %s: %w", k, wErr)
 	}
 
-	return nil
+	sourcePath, err := filepath.Rel(b.registry.RootDirectory(), s.Path(FilePath))
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+
+	var deps []Key
+	for _, d := range ri.Dependencies {
+		if !slices.Contains(deps, d.Key) {
+			deps = append(deps, d.Key)
+		}
+	}
+
+	var status LifecycleStatus
+	if fm := s.Family(); fm != nil {
+		status = fm.Status
+	}
+
+	digest := sha256.Sum256(output)
+	return ManifestEntry{
+		Key:          k,
+		ID:           s.CanonicalID(ec),
+		Visibility:   s.visibility(),
+		SHA256:       hex.EncodeToString(digest[:]),
+		DistPath:     filepath.ToSlash(distPath),
+		SourcePath:   filepath.ToSlash(sourcePath),
+		Draft:        schemaDraft(ri),
+		Dependencies: deps,
+		Status:       status,
+	}, nil
+}
+
+// distOutput returns the bytes to write to the distribution for a rendered schema. If the environment
+// requires canonical output, this is the RFC 8785 canonical form of the schema.
+func distOutput(ri RenderInfo, ec *config.EnvConfig) ([]byte, error) {
+	if !ec.CanonicalOutput {
+		return ri.Rendered, nil
+	}
+	return jcs.Transform(ri.Rendered)
+}
+
+// writeIndexes writes the manifest and the family indexes describing the built schemas. The indexes of the
+// families of removed schemas are rewritten too, if the family still has versions in the registry.
+func (b *FSDistBuilder) writeIndexes(m Manifest) error {
+	ec, err := b.config.EnvConfig(m.Env)
+	if err != nil {
+		return err
+	}
+
+	envDir := b.DistDir(m.Env)
+	if err = writeManifest(envDir, m); err != nil {
+		return err
+	}
+
+	keys := make([]Key, 0, len(m.Schemas)+len(m.Removed))
+	for _, e := range m.Schemas {
+		keys = append(keys, e.Key)
+	}
+	for _, k := range m.Removed {
+		if b.registry != nil {
+			if fk, fErr := b.registry.familyKeys(k); fErr == nil && len(fk) > 0 {
+				keys = append(keys, k)
+			}
+		}
+	}
+	return b.writeFamilyIndexes(envDir, ec, keys)
 }
 
 // ensureDistDir prepares the distribution directory for the given environment.
@@ -213,7 +372,7 @@ func (b *FSDistBuilder) ensureDistDir(env config.Env) error {
 		return fmt.Errorf("failed to create base dist directory: %w", err)
 	}
 
-	envDir := filepath.Join(b.distDir, string(env))
+	envDir := b.DistDir(env)
 
 	// Clean environment-specific directory
 	if err := os.RemoveAll(envDir); err != nil {
@@ -221,7 +380,7 @@ func (b *FSDistBuilder) ensureDistDir(env config.Env) error {
 	}
 
 	// Recreate environment-specific directory with public/private subdirectories
-	for _, sub := range []string{"public", "private"} {
+	for _, sub := range []string{visibilityPublic, visibilityPrivate} {
 		if err := os.MkdirAll(filepath.Join(envDir, sub), 0o750); err != nil {
 			return fmt.Errorf("failed to create environment dist subdirectory %s/%s: %w", env, sub, err)
 		}
@@ -238,14 +397,7 @@ func distDirectory(
 	pathResolver fsh.PathResolver,
 	registryRoot, distDirName string,
 ) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "-C", registryRoot, "rev-parse", "--show-toplevel")
-	out, err := cmd.Output()
-	if err != nil {
-		// If git fails, we assume we're not in a git repo and just return a sibling
-		return filepath.Join(filepath.Dir(registryRoot), distDirName), nil //nolint:nilerr // fallback
-	}
-
-	gitRoot, err := pathResolver.CanonicalPath(strings.TrimSpace(string(out)))
+	gitRoot, err := gitTopLevel(ctx, pathResolver, registryRoot)
 	if err != nil {
 		return "", err
 	}
@@ -256,3 +408,18 @@ func distDirectory(
 
 	return filepath.Join(filepath.Dir(registryRoot), distDirName), nil
 }
+
+// gitTopLevel returns the canonical root of the git repository containing dir.
+// If dir is not within a git repository, an empty string is returned. The repository is found without
+// running git, so git need not be installed.
+func gitTopLevel(ctx context.Context, pathResolver fsh.PathResolver, dir string) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	root := repo.TopLevel(dir)
+	if root == "" {
+		return "", nil
+	}
+	return pathResolver.CanonicalPath(root)
+}
@@ -2,12 +2,17 @@ package schema
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -21,9 +26,14 @@ import (
 
 // mockGitter is a test mock for the repo.Gitter interface.
 type mockGitter struct {
-	getLatestAnchorFunc  func(ctx context.Context, env config.Env) (repo.Revision, error)
-	tagDeploymentFunc    func(ctx context.Context, env config.Env) (string, error)
-	getSchemaChangesFunc func(ctx context.Context, anchor repo.Revision, sourceDir, suffix string) ([]repo.Change, error)
+	getLatestAnchorFunc   func(ctx context.Context, env config.Env) (repo.Revision, error)
+	tagDeploymentFunc     func(ctx context.Context, env config.Env) (string, error)
+	getSchemaChangesFunc  func(ctx context.Context, anchor repo.Revision, sourceDir, suffix string) ([]repo.Change, error)
+	getFileAtRevisionFunc func(ctx context.Context, rev repo.Revision, path string) ([]byte, error)
+	revisionFSFunc        func(ctx context.Context, rev repo.Revision, dir string) (fs.FS, error)
+	changesBetweenFunc    func(ctx context.Context, from, to repo.Revision, dir, suffix string) ([]repo.Change, error)
+	deploymentsFunc       func(ctx context.Context, env config.Env) ([]repo.Deployment, error)
+	rollbacksFunc         func(ctx context.Context, env config.Env) ([]repo.Rollback, error)
 }
 
 func (m *mockGitter) GetLatestAnchor(ctx context.Context, env config.Env) (repo.Revision, error) {
@@ -33,7 +43,12 @@ func (m *mockGitter) GetLatestAnchor(ctx context.Context, env config.Env) (repo.
 	return "HEAD", nil
 }
 
-func (m *mockGitter) TagDeploymentSuccess(ctx context.Context, env config.Env) (string, error) {
+func (m *mockGitter) TagDeploymentSuccess(
+	ctx context.Context,
+	env config.Env,
+	_ repo.Revision,
+	_ []repo.DeployedSchema,
+) (string, error) {
 	if m.tagDeploymentFunc != nil {
 		return m.tagDeploymentFunc(ctx, env)
 	}
@@ -51,6 +66,49 @@ func (m *mockGitter) GetSchemaChanges(
 	return nil, nil
 }
 
+func (m *mockGitter) GetSchemaChangesBetween(
+	ctx context.Context,
+	from, to repo.Revision,
+	sourceDir, suffix string,
+) ([]repo.Change, error) {
+	if m.changesBetweenFunc != nil {
+		return m.changesBetweenFunc(ctx, from, to, sourceDir, suffix)
+	}
+	return nil, nil
+}
+
+func (m *mockGitter) Deployments(ctx context.Context, env config.Env) ([]repo.Deployment, error) {
+	if m.deploymentsFunc != nil {
+		return m.deploymentsFunc(ctx, env)
+	}
+	return nil, nil
+}
+
+func (m *mockGitter) TagRollback(_ context.Context, env config.Env, _, _ repo.Revision) (string, error) {
+	return "jsm-rollback/" + string(env) + "/20260130-120000", nil
+}
+
+func (m *mockGitter) Rollbacks(ctx context.Context, env config.Env) ([]repo.Rollback, error) {
+	if m.rollbacksFunc != nil {
+		return m.rollbacksFunc(ctx, env)
+	}
+	return nil, nil
+}
+
+func (m *mockGitter) GetFileAtRevision(ctx context.Context, rev repo.Revision, path string) ([]byte, error) {
+	if m.getFileAtRevisionFunc != nil {
+		return m.getFileAtRevisionFunc(ctx, rev, path)
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (m *mockGitter) RevisionFS(ctx context.Context, rev repo.Revision, dir string) (fs.FS, error) {
+	if m.revisionFSFunc != nil {
+		return m.revisionFSFunc(ctx, rev, dir)
+	}
+	return fstest.MapFS{}, nil
+}
+
 func TestDistBuilder_BuildAll(t *testing.T) {
 	t.Parallel()
 
@@ -236,6 +294,13 @@ func TestDistBuilder_SetNumWorkers(t *testing.T) {
 	assert.Equal(t, 8, builder.numWorkers)
 }
 
+func TestDistBuilder_DistDir(t *testing.T) {
+	t.Parallel()
+
+	builder := &FSDistBuilder{distDir: filepath.Join("repo", "dist")}
+	assert.Equal(t, filepath.Join("repo", "dist", "prod"), builder.DistDir("prod"))
+}
+
 func TestDistBuilder_renderAndWrite(t *testing.T) {
 	t.Parallel()
 
@@ -256,7 +321,7 @@ func TestDistBuilder_renderAndWrite(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		err = builder.renderAndWrite(ctx, "production", Key("domain_test_1_0_0"))
+		_, err = builder.renderAndWrite(ctx, "production", Key("domain_test_1_0_0"))
 		assert.ErrorIs(t, err, context.Canceled)
 	})
 
@@ -274,7 +339,7 @@ func TestDistBuilder_renderAndWrite(t *testing.T) {
 			distDir:  distDir,
 		}
 
-		err = builder.renderAndWrite(context.Background(), "invalid", Key("domain_test_1_0_0"))
+		_, err = builder.renderAndWrite(context.Background(), "invalid", Key("domain_test_1_0_0"))
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to get environment config")
 	})
@@ -293,7 +358,7 @@ func TestDistBuilder_renderAndWrite(t *testing.T) {
 			distDir:  distDir,
 		}
 
-		err = builder.renderAndWrite(context.Background(), "production", Key("nonexistent_1_0_0"))
+		_, err = builder.renderAndWrite(context.Background(), "production", Key("nonexistent_1_0_0"))
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to get schema")
 	})
@@ -321,7 +386,7 @@ func TestDistBuilder_renderAndWrite(t *testing.T) {
 		require.NoError(t, os.Chmod(envDir, 0o000))
 		defer func() { _ = os.Chmod(envDir, 0o755) }()
 
-		err = builder.renderAndWrite(context.Background(), "production", Key("domain_test_1_0_0"))
+		_, err = builder.renderAndWrite(context.Background(), "production", Key("domain_test_1_0_0"))
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to write schema")
 	})
@@ -347,12 +412,47 @@ func TestDistBuilder_renderAndWrite(t *testing.T) {
 			distDir:  distDir,
 		}
 
-		err = builder.renderAndWrite(context.Background(), "production", Key("domain_test_1_0_0"))
+		_, err = builder.renderAndWrite(context.Background(), "production", Key("domain_test_1_0_0"))
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to render schema")
 	})
 }
 
+func TestDistBuilder_CanonicalOutput(t *testing.T) {
+	t.Parallel()
+
+	reg := newTestRegistryWithSchema(t)
+	createSchemaFiles(t, reg, schemaMap{
+		"domain_canonical_1_0_0": "{\n  \"type\": \"object\",\n  \"maximum\": 1.50,\n  \"$id\": \"{{ ID }}\"\n}\n",
+	})
+	cfg, err := reg.Config()
+	require.NoError(t, err)
+	cfg.Environments["production"].CanonicalOutput = true
+
+	builder, err := NewFSDistBuilder(context.Background(), reg, cfg, &mockGitter{}, "dist")
+	require.NoError(t, err)
+	_, err = builder.BuildAll(context.Background(), "production")
+	require.NoError(t, err)
+
+	envDir := filepath.Join(filepath.Dir(reg.RootDirectory()), "dist", "production")
+	data, err := os.ReadFile(filepath.Join(envDir, "private", "domain_canonical_1_0_0.schema.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"$id":"https://example.com/domain_canonical_1_0_0.schema.json","maximum":1.5,"type":"object"}`,
+		string(data))
+
+	// The manifest digest is of the canonical output.
+	var m Manifest
+	readDistJSON(t, filepath.Join(envDir, ManifestFile), &m)
+	digest := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(digest[:]), m.Schemas[0].SHA256)
+
+	t.Run("output which cannot be canonicalised", func(t *testing.T) {
+		t.Parallel()
+		_, rErr := distOutput(RenderInfo{Rendered: []byte(`{"n": 1e400}`)}, cfg.Environments["production"])
+		require.Error(t, rErr)
+	})
+}
+
 func TestDistBuilder_BuildChanged(t *testing.T) {
 	t.Parallel()
 
@@ -368,7 +468,7 @@ func TestDistBuilder_BuildChanged(t *testing.T) {
 		gitter := &mockGitter{
 			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
 				return []repo.Change{
-					{Path: filepath.Join(reg.rootDirectory, schemaPath), IsNew: false},
+					{Path: filepath.Join(reg.rootDirectory, schemaPath), Status: repo.ChangeModified},
 				}, nil
 			},
 		}
@@ -386,6 +486,41 @@ func TestDistBuilder_BuildChanged(t *testing.T) {
 		assert.Len(t, files, 1)
 	})
 
+	t.Run("renamed and deleted schemas", func(t *testing.T) {
+		t.Parallel()
+
+		reg := newTestRegistryWithSchema(t)
+		cfg, err := reg.Config()
+		require.NoError(t, err)
+
+		schemaPath := filepath.Join("domain", "test", "1", "0", "0", "domain_test_1_0_0.schema.json")
+		oldPath := filepath.Join("domain", "old", "1", "0", "0", "domain_old_1_0_0.schema.json")
+		deletedPath := filepath.Join("domain", "gone", "1", "0", "0", "domain_gone_1_0_0.schema.json")
+		gitter := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+				return []repo.Change{
+					{
+						Path:    filepath.Join(reg.rootDirectory, schemaPath),
+						OldPath: filepath.Join(reg.rootDirectory, oldPath),
+						Status:  repo.ChangeRenamed,
+					},
+					{Path: filepath.Join(reg.rootDirectory, deletedPath), Status: repo.ChangeDeleted},
+				}, nil
+			},
+		}
+		builder, err := NewFSDistBuilder(context.Background(), reg, cfg, gitter, "dist")
+		require.NoError(t, err)
+
+		count, err := builder.BuildChanged(context.Background(), "production", repo.Revision("HEAD"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		var m Manifest
+		readDistJSON(t, filepath.Join(builder.DistDir("production"), ManifestFile), &m)
+		require.Len(t, m.Schemas, 1)
+		assert.Equal(t, Key("domain_test_1_0_0"), m.Schemas[0].Key)
+	})
+
 	t.Run("no changes", func(t *testing.T) {
 		t.Parallel()
 
@@ -426,6 +561,118 @@ func TestDistBuilder_BuildChanged(t *testing.T) {
 		assert.Contains(t, err.Error(), "git failed")
 	})
 
+	t.Run("schemas including changed partials are built", func(t *testing.T) {
+		t.Parallel()
+
+		reg := newTestRegistryWithSchema(t)
+		cfg, err := reg.Config()
+		require.NoError(t, err)
+		writePartial(t, reg, "currency", currencyPartial)
+		createSchemaFiles(t, reg, schemaMap{
+			"domain_test_1_0_0":  `{"properties": {"from": {{ template "currency" }}}}`,
+			"domain_rate_1_0_0":  `{"properties": {"to": {{ template "currency" }}}}`,
+			"domain_plain_1_0_0": `{}`,
+		})
+
+		gitter := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix == PartialSuffix {
+					return []repo.Change{{Path: filepath.Join(reg.RootDirectory(), PartialsDir, "currency.tmpl")}}, nil
+				}
+				return []repo.Change{{Path: New("domain_test_1_0_0", reg).Path(FilePath)}}, nil
+			},
+		}
+		builder, err := NewFSDistBuilder(context.Background(), reg, cfg, gitter, "dist")
+		require.NoError(t, err)
+
+		count, err := builder.BuildChanged(context.Background(), "production", repo.Revision("HEAD"))
+		require.NoError(t, err)
+		// domain_test_1_0_0 both changed and includes the partial, so is only built once
+		assert.Equal(t, 2, count)
+
+		distDir := filepath.Join(filepath.Dir(reg.RootDirectory()), "dist", "production", "private")
+		assert.FileExists(t, filepath.Join(distDir, "domain_rate_1_0_0.schema.json"))
+		assert.NoFileExists(t, filepath.Join(distDir, "domain_plain_1_0_0.schema.json"))
+	})
+
+	t.Run("schemas referencing a new schema via a version range are built", func(t *testing.T) {
+		t.Parallel()
+
+		reg := newTestRegistryWithSchema(t)
+		cfg, err := reg.Config()
+		require.NoError(t, err)
+		createSchemaFiles(t, reg, schemaMap{
+			"domain_person_1_0_0": `{}`,
+			"domain_person_1_1_0": `{}`,
+			"domain_latest_1_0_0": `{"$ref": "{{ JSM "domain_person_1" }}"}`,
+			"domain_pinned_1_0_0": `{"$ref": "{{ JSM "domain_person_1_0_0" }}"}`,
+		})
+
+		gitter := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix == PartialSuffix {
+					return nil, nil
+				}
+				return []repo.Change{{Path: New("domain_person_1_1_0", reg).Path(FilePath), Status: repo.ChangeAdded}}, nil
+			},
+		}
+		builder, err := NewFSDistBuilder(context.Background(), reg, cfg, gitter, "dist")
+		require.NoError(t, err)
+
+		count, err := builder.BuildChanged(context.Background(), "production", repo.Revision("HEAD"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		distDir := filepath.Join(filepath.Dir(reg.RootDirectory()), "dist", "production", "private")
+		assert.FileExists(t, filepath.Join(distDir, "domain_latest_1_0_0.schema.json"))
+		assert.NoFileExists(t, filepath.Join(distDir, "domain_pinned_1_0_0.schema.json"))
+	})
+
+	t.Run("range dependents error", func(t *testing.T) {
+		t.Parallel()
+
+		reg := newTestRegistryWithSchema(t)
+		cfg, err := reg.Config()
+		require.NoError(t, err)
+		createSchemaFiles(t, reg, schemaMap{"domain_bad_1_0_0": `{"$ref": "{{ JSM "domain_person_1" }}"}`})
+
+		gitter := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix == PartialSuffix {
+					return nil, nil
+				}
+				return []repo.Change{{Path: New("domain_test_1_0_0", reg).Path(FilePath), Status: repo.ChangeAdded}}, nil
+			},
+		}
+		builder, err := NewFSDistBuilder(context.Background(), reg, cfg, gitter, "dist")
+		require.NoError(t, err)
+
+		_, err = builder.BuildChanged(context.Background(), "production", repo.Revision("HEAD"))
+		require.ErrorAs(t, err, new(*TemplateExecutionFailedError))
+	})
+
+	t.Run("partial dependents error", func(t *testing.T) {
+		t.Parallel()
+
+		reg := newTestRegistryWithSchema(t)
+		cfg, err := reg.Config()
+		require.NoError(t, err)
+
+		gitter := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix == PartialSuffix {
+					return nil, errors.New("partials diff failed")
+				}
+				return nil, nil
+			},
+		}
+		builder, err := NewFSDistBuilder(context.Background(), reg, cfg, gitter, "dist")
+		require.NoError(t, err)
+
+		_, err = builder.BuildChanged(context.Background(), "production", repo.Revision("HEAD"))
+		require.EqualError(t, err, "partials diff failed")
+	})
+
 	t.Run("skip invalid paths", func(t *testing.T) {
 		t.Parallel()
 
@@ -437,8 +684,8 @@ func TestDistBuilder_BuildChanged(t *testing.T) {
 		gitter := &mockGitter{
 			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
 				return []repo.Change{
-					{Path: "invalid/path.schema.json", IsNew: false},
-					{Path: filepath.Join(reg.rootDirectory, schemaPath), IsNew: false},
+					{Path: "invalid/path.schema.json", Status: repo.ChangeModified},
+					{Path: filepath.Join(reg.rootDirectory, schemaPath), Status: repo.ChangeModified},
 				}, nil
 			},
 		}
@@ -462,14 +709,17 @@ func TestDistBuilder_BuildChanged(t *testing.T) {
 		for i := 0; i < 500; i++ {
 			schemaPath := fmt.Sprintf("schema%d.json", i)
 			changes = append(changes, repo.Change{
-				Path:  filepath.Join(reg.rootDirectory, schemaPath),
-				IsNew: true,
+				Path:   filepath.Join(reg.rootDirectory, schemaPath),
+				Status: repo.ChangeAdded,
 			})
 		}
 
 		returned := make(chan struct{})
 		gitter := &mockGitter{
-			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix != SchemaSuffix {
+					return nil, nil
+				}
 				close(returned)
 				return changes, nil
 			},
@@ -499,7 +749,7 @@ func TestDistBuilder_BuildChanged(t *testing.T) {
 		gitter := &mockGitter{
 			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
 				return []repo.Change{
-					{Path: filepath.Join(reg.rootDirectory, schemaPath), IsNew: false},
+					{Path: filepath.Join(reg.rootDirectory, schemaPath), Status: repo.ChangeModified},
 				}, nil
 			},
 		}
@@ -897,3 +1147,116 @@ environments:
 	assert.Len(t, privateFiles, 1)
 	assert.Equal(t, "domain_private_1_0_0.schema.json", privateFiles[0].Name())
 }
+
+func TestDistBuilder_BuildPromotion(t *testing.T) {
+	t.Parallel()
+
+	r := setupPromotionRegistry(t, schemaMap{"domain_a_1_0_0": `{"title": "not yet deployed to dev"}`})
+	dev := setupPromotionRegistry(t, schemaMap{"domain_a_1_0_0": `{"title": "deployed to dev"}`})
+	fromRegistry, err := NewRegistryFS(r.RootDirectory(), os.DirFS(dev.RootDirectory()), &mockCompiler{},
+		fsh.NewPathResolver(), fsh.NewEnvProvider())
+	require.NoError(t, err)
+
+	builder, err := NewFSDistBuilder(context.Background(), r, r.config, &mockGitter{}, "dist")
+	require.NoError(t, err)
+
+	p := &Promotion{
+		From:       "dev",
+		To:         "prod",
+		FromAnchor: "jsm-deploy/dev/1",
+		Added:      []Key{"domain_a_1_0_0"},
+		registry:   fromRegistry,
+	}
+	count, err := builder.BuildPromotion(context.Background(), p)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	data, err := os.ReadFile(filepath.Join(builder.DistDir("prod"), "private", "domain_a_1_0_0.schema.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "deployed to dev")
+
+	data, err = os.ReadFile(filepath.Join(builder.DistDir("prod"), ManifestFile))
+	require.NoError(t, err)
+	var m Manifest
+	require.NoError(t, json.Unmarshal(data, &m))
+	assert.Equal(t, repo.Revision("jsm-deploy/dev/1"), m.Promoted)
+	assert.Len(t, m.Schemas, 1)
+}
+
+func TestDistBuilder_BuildRollback(t *testing.T) {
+	t.Parallel()
+
+	readManifest := func(t *testing.T, b DistBuilder) Manifest {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(b.DistDir("prod"), ManifestFile))
+		require.NoError(t, err)
+		var m Manifest
+		require.NoError(t, json.Unmarshal(data, &m))
+		return m
+	}
+
+	t.Run("reverted and removed schemas", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, schemaMap{
+			"domain_a_1_0_0": `{"title": "deployed"}`,
+			"domain_a_1_1_0": `{"title": "deployed"}`,
+		})
+		previous := setupPromotionRegistry(t, schemaMap{"domain_a_1_0_0": `{"title": "previously deployed"}`})
+		restored, err := NewRegistryFS(r.RootDirectory(), os.DirFS(previous.RootDirectory()), &mockCompiler{},
+			fsh.NewPathResolver(), fsh.NewEnvProvider())
+		require.NoError(t, err)
+
+		builder, err := NewFSDistBuilder(context.Background(), r, r.config, &mockGitter{}, "dist")
+		require.NoError(t, err)
+
+		rb := &Rollback{
+			Env:        "prod",
+			RolledBack: "jsm-deploy/prod/2",
+			Restored:   "jsm-deploy/prod/1",
+			Reverted:   []Key{"domain_a_1_0_0"},
+			Removed:    []Key{"domain_a_1_1_0"},
+			registry:   restored,
+		}
+		count, err := builder.BuildRollback(context.Background(), rb)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		data, err := os.ReadFile(filepath.Join(builder.DistDir("prod"), "private", "domain_a_1_0_0.schema.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "previously deployed")
+		assert.NoFileExists(t, filepath.Join(builder.DistDir("prod"), "private", "domain_a_1_1_0.schema.json"))
+
+		m := readManifest(t, builder)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/2"), m.RolledBack)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), m.Restored)
+		assert.Equal(t, []Key{"domain_a_1_1_0"}, m.Removed)
+		require.Len(t, m.Schemas, 1)
+
+		// The family index no longer lists the removed version.
+		data, err = os.ReadFile(filepath.Join(builder.DistDir("prod"), FamilyIndexDir, "private", "domain", "a",
+			FamilyIndexFile))
+		require.NoError(t, err)
+		var idx FamilyIndex
+		require.NoError(t, json.Unmarshal(data, &idx))
+		assert.Equal(t, Key("domain_a_1_0_0"), idx.Latest)
+		assert.Len(t, idx.Versions, 1)
+	})
+
+	t.Run("nothing restored", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, schemaMap{"domain_a_1_0_0": `{"title": "deployed"}`})
+		builder, err := NewFSDistBuilder(context.Background(), r, r.config, &mockGitter{}, "dist")
+		require.NoError(t, err)
+
+		rb := &Rollback{Env: "prod", RolledBack: "jsm-deploy/prod/1", Removed: []Key{"domain_a_1_0_0"}}
+		count, err := builder.BuildRollback(context.Background(), rb)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		m := readManifest(t, builder)
+		assert.Empty(t, m.Schemas)
+		assert.Empty(t, m.Restored)
+		assert.Equal(t, []Key{"domain_a_1_0_0"}, m.Removed)
+		assert.NoDirExists(t, filepath.Join(builder.DistDir("prod"), FamilyIndexDir))
+	})
+}
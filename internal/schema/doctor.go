@@ -0,0 +1,289 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Problem is a structural problem in a registry, which would otherwise surface as a confusing error part way
+// through a command, or leave files silently ignored.
+type Problem struct {
+	Path  string // The file or directory with the problem
+	Err   error  // What is wrong
+	Fix   string // How to fix the problem, when it has not been fixed
+	Fixed bool   // Whether the problem was fixed by Doctor
+}
+
+// versionLevel is the position of a version directory in a schema family tree.
+type versionLevel int
+
+const (
+	notVersionDir versionLevel = iota
+	majorDir
+	minorDir
+	patchDir
+)
+
+// doctor holds the state of a scan of the registry for structural problems.
+type doctor struct {
+	registry    *Registry
+	fix         bool
+	problems    []Problem
+	versionDirs map[string]versionLevel // Every version directory, by path
+	withSchema  map[string]bool         // The directories which hold a correctly located schema file
+	testDirs    map[string]bool         // The pass and fail directories of schemas
+	unreadDirs  map[string]bool         // The directories in schema home directories which are never read
+	familyDirs  map[string]bool         // Whether each directory checked has version directories, by path
+}
+
+// Doctor scans the whole registry for structural problems, and returns every problem found, ordered by path.
+// If fix is true, the problems which are safe to fix are fixed: missing pass and fail directories are created,
+// and version directories which hold no files at all are removed.
+func (r *Registry) Doctor(ctx context.Context, fix bool) ([]Problem, error) {
+	d := &doctor{
+		registry:    r,
+		fix:         fix,
+		versionDirs: make(map[string]versionLevel),
+		withSchema:  make(map[string]bool),
+		testDirs:    make(map[string]bool),
+		unreadDirs:  make(map[string]bool),
+		familyDirs:  make(map[string]bool),
+	}
+
+	err := r.walkDir(r.rootDirectory, func(path string, entry fs.DirEntry, wErr error) error {
+		if wErr != nil {
+			return wErr
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if path == r.rootDirectory {
+			return nil
+		}
+		if entry.IsDir() {
+			return d.checkDir(path, entry.Name())
+		}
+		return d.checkFile(path, entry.Name())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = d.checkEmptyVersionDirs(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(d.problems, func(a, b Problem) int { return strings.Compare(a.Path, b.Path) })
+	return d.problems, nil
+}
+
+// checkDir records the version directories of the registry, and reports directories in a family tree which are
+// ignored because they are not version numbers. The directories in the home directory of a schema are recorded,
+// so that the JSON files in those which are never read can be reported. Migrations are skipped.
+func (d *doctor) checkDir(path, name string) error {
+	parent := filepath.Dir(path)
+	if parent == d.registry.rootDirectory && (name == PartialsDir || name == VendorDir) {
+		return fs.SkipDir
+	}
+	if strings.HasPrefix(name, ".") {
+		return fs.SkipDir
+	}
+
+	parentLevel := d.versionDirs[parent]
+	switch {
+	case parentLevel == patchDir:
+		switch name {
+		case MigrationsDir:
+			return fs.SkipDir
+		case string(TestDocTypePass), string(TestDocTypeFail):
+			d.testDirs[path] = true
+		default:
+			d.unreadDirs[path] = true
+		}
+		return nil
+	case d.testDirs[parent] || d.unreadDirs[parent]:
+		// Test documents are only read from the top of the pass and fail directories.
+		d.unreadDirs[path] = true
+		return nil
+	case !isVersionNumber(name):
+		isFamilyDir, err := d.isFamilyDir(parent)
+		if err != nil {
+			return err
+		}
+		if parentLevel != notVersionDir || isFamilyDir {
+			d.problems = append(d.problems, Problem{
+				Path: path,
+				Err:  &NonNumericVersionDirectoryError{Path: path},
+				Fix:  "Rename the directory to a version number, or move its contents into one",
+			})
+			return fs.SkipDir
+		}
+		return nil
+	default:
+		d.versionDirs[path] = parentLevel + 1
+		return nil
+	}
+}
+
+// isFamilyDir returns true if dir is a family directory, which holds major version directories. The registry
+// root is never a family directory.
+func (d *doctor) isFamilyDir(dir string) (bool, error) {
+	if dir == d.registry.rootDirectory || d.versionDirs[dir] != notVersionDir {
+		return false, nil
+	}
+	if isFamily, ok := d.familyDirs[dir]; ok {
+		return isFamily, nil
+	}
+
+	entries, err := d.registry.readDir(dir)
+	if err != nil {
+		return false, err
+	}
+	isFamily := slices.ContainsFunc(entries, func(e fs.DirEntry) bool {
+		return e.IsDir() && isVersionNumber(e.Name())
+	})
+	d.familyDirs[dir] = isFamily
+	return isFamily, nil
+}
+
+// checkFile checks that a schema file has a valid name, is located in the directory of its key, and has pass
+// and fail directories. Other JSON files in a family tree are reported, since they are never read, except for
+// the test documents in pass and fail directories.
+func (d *doctor) checkFile(path, name string) error {
+	dir := filepath.Dir(path)
+	if d.testDirs[dir] {
+		return nil
+	}
+	if !strings.HasSuffix(name, SchemaSuffix) {
+		if (d.versionDirs[dir] != notVersionDir || d.unreadDirs[dir]) && filepath.Ext(name) == ".json" {
+			d.problems = append(d.problems, Problem{
+				Path: path,
+				Err:  &StrayJSONFileError{Path: path},
+				Fix:  "Move the file into the pass or fail directory of the schema, if it is a test document",
+			})
+		}
+		return nil
+	}
+
+	c, err := NewCoreFromString(strings.TrimSuffix(name, SchemaSuffix), KeySeparator)
+	if err != nil {
+		d.problems = append(d.problems, Problem{
+			Path: path,
+			Err:  &InvalidSchemaFilenameError{Path: path},
+			Fix:  "Rename the file to [domain(s)]_[family]_[major]_[minor]_[patch]" + SchemaSuffix,
+		})
+		return nil
+	}
+
+	s := New(c.Key(), d.registry)
+	if expected := s.Path(FilePath); expected != path {
+		d.problems = append(d.problems, Problem{
+			Path: path,
+			Err:  &SchemaLocationMismatchError{Key: s.Key(), Expected: expected, Actual: path},
+			Fix:  "Move the file to " + expected + ", or rename it to match its directory",
+		})
+		return nil
+	}
+
+	for dir := filepath.Dir(path); dir != d.registry.rootDirectory; dir = filepath.Dir(dir) {
+		d.withSchema[dir] = true
+	}
+	for _, tt := range []TestDocType{TestDocTypePass, TestDocTypeFail} {
+		if err = d.checkTestDir(filepath.Join(s.Path(HomeDir), string(tt)), tt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTestDir reports a missing pass or fail directory, creating it if problems are being fixed.
+func (d *doctor) checkTestDir(dir string, tt TestDocType) error {
+	info, err := d.registry.stat(dir)
+	if err == nil && info.IsDir() {
+		return nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	p := Problem{Path: dir, Err: &TestDirMissingConfigError{Path: dir, Type: tt}}
+	if err != nil && d.fix {
+		if err = os.Mkdir(dir, 0o750); err != nil {
+			return err
+		}
+		p.Fixed = true
+	} else {
+		p.Fix = "Create the " + string(tt) + " directory, and add " + string(tt) + " test documents to it"
+	}
+	d.problems = append(d.problems, p)
+	return nil
+}
+
+// checkEmptyVersionDirs reports the version directories which hold no correctly located schema file. Only the
+// outermost of nested empty directories is reported. Those which hold no files at all are removed if problems
+// are being fixed.
+func (d *doctor) checkEmptyVersionDirs() error {
+	dirs := make([]string, 0, len(d.versionDirs))
+	for dir := range d.versionDirs {
+		if !d.withSchema[dir] {
+			dirs = append(dirs, dir)
+		}
+	}
+	slices.Sort(dirs)
+
+	var reported []string
+	for _, dir := range dirs {
+		if slices.ContainsFunc(reported, func(r string) bool {
+			return strings.HasPrefix(dir, r+string(filepath.Separator))
+		}) {
+			continue
+		}
+		reported = append(reported, dir)
+
+		p := Problem{Path: dir, Err: &EmptyVersionDirectoryError{Path: dir}}
+		hasFiles, err := d.hasFiles(dir)
+		switch {
+		case err != nil:
+			return err
+		case hasFiles:
+			p.Fix = "Add the schema file of the version, or remove the directory"
+		case d.fix:
+			if err = os.RemoveAll(dir); err != nil {
+				return err
+			}
+			p.Fixed = true
+		default:
+			p.Fix = "Remove the directory"
+		}
+		d.problems = append(d.problems, p)
+	}
+	return nil
+}
+
+// hasFiles returns true if there are any files in the tree at dir.
+func (d *doctor) hasFiles(dir string) (bool, error) {
+	found := false
+	err := d.registry.walkDir(dir, func(_ string, entry fs.DirEntry, wErr error) error {
+		if wErr != nil {
+			return wErr
+		}
+		if !entry.IsDir() {
+			found = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found, err
+}
+
+// isVersionNumber returns true if the directory name is a version number component.
+func isVersionNumber(name string) bool {
+	_, err := strconv.ParseUint(name, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,165 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Doctor(t *testing.T) {
+	t.Parallel()
+
+	// newRegistry creates a registry with one of each structural problem, alongside healthy schemas.
+	newRegistry := func(t *testing.T) *Registry {
+		t.Helper()
+		r := setupTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{"domain_family_1_0_0": `{}`, "domain_family_1_1_0": `{}`})
+		root := r.RootDirectory()
+		for _, dir := range []string{
+			"domain/family/1/0/0/pass",
+			"domain/family/1/0/0/fail",
+			"domain/family/1/0/0/migrations",
+			"domain/family/1/0/0/pass/sub",
+			"domain/family/1/0/0/examples",
+			"domain/family/1/1/0/pass",
+			"domain/family/1/2/0",
+			"domain/family/1/latest",
+			"domain/family/2/0/0/pass",
+			"domain/family/v3/0/0",
+			filepath.Join(VendorDir, "x", "1"),
+			filepath.Join(".git", "1", "0"),
+		} {
+			require.NoError(t, os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o755))
+		}
+		for _, file := range []string{
+			"domain/family/1/0/0/pass/doc.json",
+			"domain/family/1/0/0/pass/sub/doc.json",
+			"domain/family/1/0/0/examples/doc.json",
+			"domain/family/1/0/0/migrations/1.0.0-to-1.1.0.json",
+			"domain/family/1/0/0/doc.json",
+			"domain/family/1/0/0/bad.schema.json",
+			"domain/family/1/2/0/domain_family_1_0_0.schema.json",
+			"domain/family/1/latest/domain_family_1_3_0.schema.json",
+			filepath.Join(VendorDir, "x", "1", "bad.schema.json"),
+			filepath.Join(".git", "1", "0", "a.json"),
+		} {
+			require.NoError(t, os.WriteFile(filepath.Join(root, filepath.FromSlash(file)), []byte("{}"), 0o600))
+		}
+		return r
+	}
+
+	// problemErrs returns the errors of the problems, by the path of each relative to the registry root.
+	problemErrs := func(t *testing.T, r *Registry, problems []Problem) map[string]error {
+		t.Helper()
+		errs := make(map[string]error, len(problems))
+		for _, p := range problems {
+			rel, err := filepath.Rel(r.RootDirectory(), p.Path)
+			require.NoError(t, err)
+			errs[filepath.ToSlash(rel)] = p.Err
+		}
+		return errs
+	}
+
+	t.Run("report", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		problems, err := r.Doctor(context.Background(), false)
+		require.NoError(t, err)
+
+		root := r.RootDirectory()
+		misplaced := filepath.Join(root, "domain", "family", "1", "2", "0", "domain_family_1_0_0.schema.json")
+		assert.Equal(t, map[string]error{
+			"domain/family/1/0/0/bad.schema.json": &InvalidSchemaFilenameError{
+				Path: filepath.Join(root, "domain", "family", "1", "0", "0", "bad.schema.json"),
+			},
+			"domain/family/1/0/0/doc.json": &StrayJSONFileError{
+				Path: filepath.Join(root, "domain", "family", "1", "0", "0", "doc.json"),
+			},
+			"domain/family/1/0/0/examples/doc.json": &StrayJSONFileError{
+				Path: filepath.Join(root, "domain", "family", "1", "0", "0", "examples", "doc.json"),
+			},
+			"domain/family/1/0/0/pass/sub/doc.json": &StrayJSONFileError{
+				Path: filepath.Join(root, "domain", "family", "1", "0", "0", "pass", "sub", "doc.json"),
+			},
+			"domain/family/1/1/0/fail": &TestDirMissingConfigError{
+				Path: filepath.Join(root, "domain", "family", "1", "1", "0", "fail"),
+				Type: TestDocTypeFail,
+			},
+			"domain/family/1/2": &EmptyVersionDirectoryError{Path: filepath.Join(root, "domain", "family", "1", "2")},
+			"domain/family/1/2/0/domain_family_1_0_0.schema.json": &SchemaLocationMismatchError{
+				Key:      "domain_family_1_0_0",
+				Expected: New("domain_family_1_0_0", r).Path(FilePath),
+				Actual:   misplaced,
+			},
+			"domain/family/1/latest": &NonNumericVersionDirectoryError{
+				Path: filepath.Join(root, "domain", "family", "1", "latest"),
+			},
+			"domain/family/2": &EmptyVersionDirectoryError{Path: filepath.Join(root, "domain", "family", "2")},
+			"domain/family/v3": &NonNumericVersionDirectoryError{
+				Path: filepath.Join(root, "domain", "family", "v3"),
+			},
+		}, problemErrs(t, r, problems))
+
+		for i, p := range problems {
+			assert.False(t, p.Fixed)
+			assert.NotEmpty(t, p.Fix)
+			if i > 0 {
+				assert.Less(t, problems[i-1].Path, p.Path)
+			}
+		}
+		assert.DirExists(t, filepath.Join(root, "domain", "family", "2"))
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		problems, err := r.Doctor(context.Background(), true)
+		require.NoError(t, err)
+
+		var fixed []string
+		for _, p := range problems {
+			if p.Fixed {
+				assert.Empty(t, p.Fix)
+				fixed = append(fixed, p.Path)
+			}
+		}
+		root := r.RootDirectory()
+		assert.Equal(t, []string{
+			filepath.Join(root, "domain", "family", "1", "1", "0", "fail"),
+			filepath.Join(root, "domain", "family", "2"),
+		}, fixed)
+		assert.DirExists(t, filepath.Join(root, "domain", "family", "1", "1", "0", "fail"))
+		assert.NoDirExists(t, filepath.Join(root, "domain", "family", "2"))
+		// The empty version directory holding a misplaced schema file is not removed.
+		assert.DirExists(t, filepath.Join(root, "domain", "family", "1", "2"))
+
+		problems, err = r.Doctor(context.Background(), false)
+		require.NoError(t, err)
+		assert.Len(t, problems, 8)
+	})
+
+	t.Run("healthy", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		s := New("domain_family_1_0_0", r)
+		require.NoError(t, s.WriteNewSchemaFiles())
+		require.NoError(t, os.MkdirAll(filepath.Join(r.RootDirectory(), PartialsDir), 0o755))
+
+		problems, err := r.Doctor(context.Background(), true)
+		require.NoError(t, err)
+		assert.Empty(t, problems)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := r.Doctor(ctx, false)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -3,6 +3,9 @@ package schema
 
 import (
 	"fmt"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
 )
 
 // NoDomainError is returned when a schema does not have a domain.
@@ -150,8 +153,8 @@ type TemplateExecutionFailedError struct {
 }
 
 func (e TemplateExecutionFailedError) Error() string {
-	return fmt.Sprintf("%s cannot be rendered. Check {{ID }} and {{JSM `<schema key>`}} for validity. "+
-		"Error: %v", e.Path, e.Wrapped)
+	return fmt.Sprintf("%s cannot be rendered. Check {{ID }}, {{JSM `<schema key>`}}, "+
+		"{{JSMRef `<schema key>` `<pointer>`}} and {{Var `<name>`}} for validity. Error: %v", e.Path, e.Wrapped)
 }
 
 // RegistryInitError is returned when the registry cannot be initialised.
@@ -192,6 +195,174 @@ func (e *JSMArgNotFoundError) Error() string {
 	return fmt.Sprintf("A $ref to a JSM schema ({{ JSM `%s` }}) could not be loaded. Error: %s", e.Key, e.Wrapped)
 }
 
+func (e *JSMArgNotFoundError) Unwrap() error {
+	return e.Wrapped
+}
+
+// UnknownExternalRegistryError is returned when a JSM template argument names an external registry which
+// is not configured.
+type UnknownExternalRegistryError struct {
+	Name string
+}
+
+func (e *UnknownExternalRegistryError) Error() string {
+	return fmt.Sprintf(
+		"A $ref to a JSM schema ({{ JSM `%s:<schema key>` }}) names an external registry which is not "+
+			"configured in json-schema-manager-config.yml externalRegistries: %s",
+		e.Name, e.Name,
+	)
+}
+
+// ExternalRegistryInitError is returned when the checkout of an external registry cannot be opened.
+type ExternalRegistryInitError struct {
+	Name    string
+	Wrapped error
+}
+
+func (e *ExternalRegistryInitError) Error() string {
+	return fmt.Sprintf("External registry %s could not be initialised: %v", e.Name, e.Wrapped)
+}
+
+func (e *ExternalRegistryInitError) Unwrap() error {
+	return e.Wrapped
+}
+
+// ExternalSchemaNotFoundError is returned when a schema is not in the distribution of an external registry.
+type ExternalSchemaNotFoundError struct {
+	Key      Key
+	Manifest string
+}
+
+func (e *ExternalSchemaNotFoundError) Error() string {
+	return fmt.Sprintf("Schema %s is not listed in the external distribution manifest %s", e.Key, e.Manifest)
+}
+
+// InvalidVendorLockError is returned when the vendor lock file cannot be parsed.
+type InvalidVendorLockError struct {
+	Path    string
+	Wrapped error
+}
+
+func (e *InvalidVendorLockError) Error() string {
+	return fmt.Sprintf("vendor lock file %s is invalid: %v", e.Path, e.Wrapped)
+}
+
+func (e *InvalidVendorLockError) Unwrap() error {
+	return e.Wrapped
+}
+
+// VendoredChecksumMismatchError is returned when a vendored schema does not match the digest recorded in the
+// vendor lock file.
+type VendoredChecksumMismatchError struct {
+	URL  string
+	Path string
+}
+
+func (e *VendoredChecksumMismatchError) Error() string {
+	return fmt.Sprintf(
+		"vendored copy %s of %s does not match the sha256 in the vendor lock file. Run jsm vendor add %s <file> "+
+			"to vendor it again",
+		e.Path, e.URL, e.URL,
+	)
+}
+
+// InvalidVendorURLError is returned when a URL to vendor is not an absolute http(s) URL of a document.
+type InvalidVendorURLError struct {
+	URL string
+}
+
+func (e *InvalidVendorURLError) Error() string {
+	return fmt.Sprintf("%s cannot be vendored: it must be an http(s) URL of a document, without a query or fragment",
+		e.URL)
+}
+
+// InvalidVersionRangeError is returned when a version range does not identify a major or minor version
+// of a schema family.
+type InvalidVersionRangeError struct {
+	Range string
+}
+
+func (e *InvalidVersionRangeError) Error() string {
+	return fmt.Sprintf("invalid version range '%s'. It must be <domain(s)>_<family>_<major> "+
+		"or <domain(s)>_<family>_<major>_<minor>", e.Range)
+}
+
+// VersionRangeEmptyError is returned when there are no versions of a schema family in a version range.
+type VersionRangeEmptyError struct {
+	Range string
+}
+
+func (e *VersionRangeEmptyError) Error() string {
+	return fmt.Sprintf("there are no versions in the range %s", e.Range)
+}
+
+// JSMRefInvalidPointerError is returned when a JSMRef template argument is not a JSON Pointer fragment.
+type JSMRefInvalidPointerError struct {
+	Key     Key
+	Pointer string
+}
+
+func (e *JSMRefInvalidPointerError) Error() string {
+	return fmt.Sprintf("A $ref to a JSM schema fragment ({{ JSMRef `%s` `%s` }}) has an invalid pointer. "+
+		"It must be a JSON Pointer fragment such as `#/$defs/name`", e.Key, e.Pointer)
+}
+
+// JSMRefFragmentNotFoundError is returned when a JSMRef template argument points to a location which does
+// not exist in the referenced schema.
+type JSMRefFragmentNotFoundError struct {
+	From    Key // The schema containing the $ref
+	To      Key // The referenced schema
+	Pointer string
+}
+
+func (e *JSMRefFragmentNotFoundError) Error() string {
+	return fmt.Sprintf("schema %s references %s%s ({{ JSMRef `%s` `%s` }}), but %s does not contain %s",
+		e.From, e.To, e.Pointer, e.To, e.Pointer, e.To, e.Pointer)
+}
+
+// UndefinedVariableError is returned when a {{ Var }} template argument is not defined for the target environment.
+type UndefinedVariableError struct {
+	Name string
+	Env  config.Env
+}
+
+func (e *UndefinedVariableError) Error() string {
+	return fmt.Sprintf("variable '%s' used in {{ Var `%s` }} is not defined for environment '%s'. "+
+		"Add it to environments.%s.variables in json-schema-manager-config.yml", e.Name, e.Name, e.Env, e.Env)
+}
+
+// InvalidVariableError is returned when the value of a {{ Var }} template argument cannot be converted to JSON.
+type InvalidVariableError struct {
+	Name    string
+	Env     config.Env
+	Wrapped error
+}
+
+func (e *InvalidVariableError) Error() string {
+	return fmt.Sprintf("variable '%s' for environment '%s' cannot be converted to JSON: %v", e.Name, e.Env, e.Wrapped)
+}
+
+// InvalidPartialError is returned when a template partial cannot be parsed.
+type InvalidPartialError struct {
+	Path    string
+	Wrapped error
+}
+
+func (e *InvalidPartialError) Error() string {
+	return fmt.Sprintf("template partial %s has a template syntax error: %v", e.Path, e.Wrapped)
+}
+
+// UnknownPartialError is returned when a schema or partial includes a partial which does not exist.
+type UnknownPartialError struct {
+	Path string
+	Name string
+}
+
+func (e *UnknownPartialError) Error() string {
+	return fmt.Sprintf("%s includes {{ template `%s` }}, but there is no partial %s/%s%s in the registry",
+		e.Path, e.Name, PartialsDir, e.Name, PartialSuffix)
+}
+
 // NotFoundError is returned when a schema is not found.
 type NotFoundError struct {
 	Path string
@@ -350,3 +521,225 @@ type ChangedDeployedSchemasError struct {
 func (e *ChangedDeployedSchemasError) Error() string {
 	return fmt.Sprintf("cannot modify deployed schemas: %v", e.Paths)
 }
+
+// InvalidFamilyMetadataError is returned when a family metadata file cannot be parsed or does not
+// satisfy the family metadata meta-schema.
+type InvalidFamilyMetadataError struct {
+	Path    string
+	Wrapped error
+}
+
+func (e *InvalidFamilyMetadataError) Error() string {
+	return fmt.Sprintf("%s is not a valid family metadata file: %v", e.Path, e.Wrapped)
+}
+
+// InvalidMigrationError is returned when a migration file cannot be parsed or is not well-formed.
+type InvalidMigrationError struct {
+	Path   string
+	Reason string
+}
+
+func (e *InvalidMigrationError) Error() string {
+	return fmt.Sprintf("%s is not a valid migration: %s", e.Path, e.Reason)
+}
+
+// MigrationFailedError is returned when a migration operation cannot be applied to a document.
+type MigrationFailedError struct {
+	MigrationPath string
+	Operation     int // The 1-based index of the failing operation
+	Op            MigrationOp
+	Wrapped       error
+}
+
+func (e *MigrationFailedError) Error() string {
+	return fmt.Sprintf("%s operation %d (%s) could not be applied: %v", e.MigrationPath, e.Operation, e.Op, e.Wrapped)
+}
+
+// MigrationsWithoutPreviousMajorError is returned when a schema has migrations, but there is no earlier
+// major version in its family to migrate from.
+type MigrationsWithoutPreviousMajorError struct {
+	Path string
+}
+
+func (e *MigrationsWithoutPreviousMajorError) Error() string {
+	return fmt.Sprintf("%s contains migrations, but there is no earlier major version to migrate from", e.Path)
+}
+
+// MigratedTestFailedError is returned when a pass test document from the previous major version of a schema
+// does not validate against the schema after its migrations have been applied.
+type MigratedTestFailedError struct {
+	SchemaPath  string
+	TestDocPath string
+	From        Key
+	Wrapped     error
+}
+
+func (e *MigratedTestFailedError) Error() string {
+	return fmt.Sprintf("%s, migrated from %s, is not valid against %s: %v",
+		e.TestDocPath, e.From, e.SchemaPath, e.Wrapped)
+}
+
+// NoDeploymentsError is returned when schemas are promoted from an environment which has no deployments.
+type NoDeploymentsError struct {
+	Env config.Env
+}
+
+func (e *NoDeploymentsError) Error() string {
+	return fmt.Sprintf("no deployments to %s have been tagged, so there is nothing to promote", e.Env)
+}
+
+// UnpromotedSchemasError is returned when a distribution would deploy schemas to an environment which have
+// not yet been deployed to the stage before it in the promotion order.
+type UnpromotedSchemasError struct {
+	Env   config.Env
+	Stage config.Env
+	Keys  []Key
+}
+
+func (e *UnpromotedSchemasError) Error() string {
+	return fmt.Sprintf("cannot deploy schemas to %s which have not been deployed to %s: %v", e.Env, e.Stage, e.Keys)
+}
+
+// PromotionTestsFailedError is returned when schemas being promoted fail their tests at the revision they
+// are promoted from.
+type PromotionTestsFailedError struct {
+	From   config.Env
+	Anchor repo.Revision
+}
+
+func (e *PromotionTestsFailedError) Error() string {
+	return fmt.Sprintf("schemas to promote from %s failed their tests at %s", e.From, e.Anchor)
+}
+
+// NothingToRollBackError is returned when an environment is rolled back, but every deployment to it has
+// already been rolled back, or none has been tagged.
+type NothingToRollBackError struct {
+	Env config.Env
+}
+
+func (e *NothingToRollBackError) Error() string {
+	return fmt.Sprintf("no deployments to %s have been tagged which have not been rolled back", e.Env)
+}
+
+// SchemaLocationMismatchError is returned when a schema file is not in the directory its key implies, so that
+// it would be read as a different schema than its location suggests.
+type SchemaLocationMismatchError struct {
+	Key      Key
+	Expected string
+	Actual   string
+}
+
+func (e *SchemaLocationMismatchError) Error() string {
+	return fmt.Sprintf("schema file %s is not where its key %s implies: expected %s", e.Actual, e.Key, e.Expected)
+}
+
+// NonNumericVersionDirectoryError is returned when a directory among the version directories of a schema
+// family is not a version number, so is ignored.
+type NonNumericVersionDirectoryError struct {
+	Path string
+}
+
+func (e *NonNumericVersionDirectoryError) Error() string {
+	return fmt.Sprintf("version directory %s is not a version number, so is ignored", e.Path)
+}
+
+// EmptyVersionDirectoryError is returned when a version directory holds no schema file.
+type EmptyVersionDirectoryError struct {
+	Path string
+}
+
+func (e *EmptyVersionDirectoryError) Error() string {
+	return fmt.Sprintf("version directory %s holds no schema file", e.Path)
+}
+
+// StrayJSONFileError is returned when a JSON file in a schema family is neither a schema file nor a test
+// document in a pass or fail directory, so is never read.
+type StrayJSONFileError struct {
+	Path string
+}
+
+func (e *StrayJSONFileError) Error() string {
+	return fmt.Sprintf("%s is not a schema file or a test document in a pass or fail directory, so is ignored", e.Path)
+}
+
+// RegistryProblemsError is returned when the registry has structural problems which have not been fixed.
+type RegistryProblemsError struct {
+	Count int
+}
+
+func (e *RegistryProblemsError) Error() string {
+	return fmt.Sprintf("found %d structural problems in the registry", e.Count)
+}
+
+// InvalidFamilyArgError is returned when a schema family argument is not in the correct format.
+type InvalidFamilyArgError struct {
+	Arg string
+}
+
+func (e *InvalidFamilyArgError) Error() string {
+	return fmt.Sprintf("Invalid schema family argument: %s - "+
+		"you must provide a chain of one or more domains and a family name, "+
+		"separated by '/' e.g. 'domain/subdomain/family'", e.Arg)
+}
+
+// FamilyExistsError is returned when a schema family is moved to the location of a family which already exists.
+type FamilyExistsError struct {
+	Family SearchScope
+}
+
+func (e *FamilyExistsError) Error() string {
+	return fmt.Sprintf("schema family %s already exists", e.Family)
+}
+
+// DeployedFamilyMoveError is returned when moving a schema family would remove or change schemas which have been
+// deployed to an environment which does not allow schema mutation.
+type DeployedFamilyMoveError struct {
+	Env  config.Env
+	Keys []Key
+}
+
+func (e *DeployedFamilyMoveError) Error() string {
+	return fmt.Sprintf("cannot move a schema family when it would remove or change schemas deployed to %s: %v - "+
+		"use --copy to create new versions at the new location instead", e.Env, e.Keys)
+}
+
+// ScaffoldNotFoundError is returned when a new schema is created from a scaffold which does not exist.
+type ScaffoldNotFoundError struct {
+	Name      string
+	Available []string
+}
+
+func (e *ScaffoldNotFoundError) Error() string {
+	return fmt.Sprintf("scaffold %s not found: add %s/%s%s to the registry, or use one of %v",
+		e.Name, ScaffoldsDir, e.Name, ScaffoldSuffix, e.Available)
+}
+
+// InvalidScaffoldError is returned when a scaffold cannot be parsed or executed, or does not generate JSON.
+type InvalidScaffoldError struct {
+	Name    string
+	Wrapped error
+}
+
+func (e *InvalidScaffoldError) Error() string {
+	return fmt.Sprintf("scaffold %s is invalid: %v", e.Name, e.Wrapped)
+}
+
+func (e *InvalidScaffoldError) Unwrap() error {
+	return e.Wrapped
+}
+
+// StarterDocumentInvalidError is returned when the starter pass document generated for a new schema does not
+// pass validation against it.
+type StarterDocumentInvalidError struct {
+	Key     Key
+	Wrapped error
+}
+
+func (e *StarterDocumentInvalidError) Error() string {
+	return fmt.Sprintf("schema %s was not created, as the starter pass document generated for it does not pass "+
+		"validation: %v", e.Key, e.Wrapped)
+}
+
+func (e *StarterDocumentInvalidError) Unwrap() error {
+	return e.Wrapped
+}
@@ -216,6 +216,180 @@ func TestErrors(t *testing.T) {
 			err:      &InvalidTestDocumentDirectoryError{Path: "/invalid/test.json"},
 			contains: []string{"test document must be in a 'pass' or 'fail' directory"},
 		},
+		{
+			name:     "InvalidFamilyMetadataError",
+			err:      &InvalidFamilyMetadataError{Path: "/a/family.yml", Wrapped: errors.New("missing owner")},
+			contains: []string{"/a/family.yml", "not a valid family metadata file", "missing owner"},
+		},
+		{
+			name:     "InvalidMigrationError",
+			err:      &InvalidMigrationError{Path: "/m/from-1.yml", Reason: "no operations are defined"},
+			contains: []string{"/m/from-1.yml", "not a valid migration", "no operations are defined"},
+		},
+		{
+			name: "MigrationFailedError",
+			err: &MigrationFailedError{
+				MigrationPath: "/m/from-1.yml", Operation: 2, Op: MigrationOpMove, Wrapped: errors.New("no parent"),
+			},
+			contains: []string{"/m/from-1.yml operation 2 (move) could not be applied", "no parent"},
+		},
+		{
+			name:     "MigrationsWithoutPreviousMajorError",
+			err:      &MigrationsWithoutPreviousMajorError{Path: "/d/f/1/0/0/migrations"},
+			contains: []string{"/d/f/1/0/0/migrations", "no earlier major version"},
+		},
+		{
+			name: "MigratedTestFailedError",
+			err: &MigratedTestFailedError{
+				SchemaPath: "s.schema.json", TestDocPath: "a.json", From: "d_f_1_0_0", Wrapped: errors.New("bad"),
+			},
+			contains: []string{"a.json, migrated from d_f_1_0_0, is not valid against s.schema.json", "bad"},
+		},
+		{
+			name:     "InvalidVersionRangeError",
+			err:      &InvalidVersionRangeError{Range: "a_1"},
+			contains: []string{"invalid version range 'a_1'", "<domain(s)>_<family>_<major>"},
+		},
+		{
+			name:     "VersionRangeEmptyError",
+			err:      &VersionRangeEmptyError{Range: "a_b_1"},
+			contains: []string{"no versions in the range a_b_1"},
+		},
+		{
+			name:     "JSMRefInvalidPointerError",
+			err:      &JSMRefInvalidPointerError{Key: "a_b_1_0_0", Pointer: "#defs"},
+			contains: []string{"{{ JSMRef `a_b_1_0_0` `#defs` }}", "invalid pointer"},
+		},
+		{
+			name:     "JSMRefFragmentNotFoundError",
+			err:      &JSMRefFragmentNotFoundError{From: "a_b_1_0_0", To: "a_c_1_0_0", Pointer: "#/$defs/x"},
+			contains: []string{"schema a_b_1_0_0 references a_c_1_0_0#/$defs/x", "a_c_1_0_0 does not contain #/$defs/x"},
+		},
+		{
+			name:     "UndefinedVariableError",
+			err:      &UndefinedVariableError{Name: "host", Env: "dev"},
+			contains: []string{"variable 'host'", "environment 'dev'", "environments.dev.variables"},
+		},
+		{
+			name:     "InvalidVariableError",
+			err:      &InvalidVariableError{Name: "host", Env: "dev", Wrapped: errors.New("bad")},
+			contains: []string{"variable 'host' for environment 'dev'", "bad"},
+		},
+		{
+			name:     "InvalidPartialError",
+			err:      &InvalidPartialError{Path: "/r/partials/money.tmpl", Wrapped: errors.New("unexpected EOF")},
+			contains: []string{"/r/partials/money.tmpl", "unexpected EOF"},
+		},
+		{
+			name:     "UnknownPartialError",
+			err:      &UnknownPartialError{Path: "/r/a.schema.json", Name: "money"},
+			contains: []string{"/r/a.schema.json includes {{ template `money` }}", "partials/money.tmpl"},
+		},
+		{
+			name:     "NoDeploymentsError",
+			err:      &NoDeploymentsError{Env: "dev"},
+			contains: []string{"no deployments to dev have been tagged"},
+		},
+		{
+			name:     "UnpromotedSchemasError",
+			err:      &UnpromotedSchemasError{Env: "prod", Stage: "dev", Keys: []Key{"d_f_1_0_0"}},
+			contains: []string{"cannot deploy schemas to prod which have not been deployed to dev", "d_f_1_0_0"},
+		},
+		{
+			name:     "PromotionTestsFailedError",
+			err:      &PromotionTestsFailedError{From: "dev", Anchor: "jsm-deploy/dev/1"},
+			contains: []string{"schemas to promote from dev failed their tests at jsm-deploy/dev/1"},
+		},
+		{
+			name:     "NothingToRollBackError",
+			err:      &NothingToRollBackError{Env: "prod"},
+			contains: []string{"no deployments to prod have been tagged which have not been rolled back"},
+		},
+		{
+			name:     "UnknownExternalRegistryError",
+			err:      &UnknownExternalRegistryError{Name: "payments"},
+			contains: []string{"{{ JSM `payments:<schema key>` }}", "externalRegistries: payments"},
+		},
+		{
+			name:     "ExternalRegistryInitError",
+			err:      &ExternalRegistryInitError{Name: "payments", Wrapped: errors.New("no config")},
+			contains: []string{"External registry payments could not be initialised: no config"},
+		},
+		{
+			name:     "ExternalSchemaNotFoundError",
+			err:      &ExternalSchemaNotFoundError{Key: "domain_family_1_0_0", Manifest: "/dist/prod/manifest.json"},
+			contains: []string{"domain_family_1_0_0 is not listed", "/dist/prod/manifest.json"},
+		},
+		{
+			name:     "InvalidVendorLockError",
+			err:      &InvalidVendorLockError{Path: "/vendor/vendor.lock.yml", Wrapped: errors.New("bad yaml")},
+			contains: []string{"vendor lock file /vendor/vendor.lock.yml is invalid: bad yaml"},
+		},
+		{
+			name:     "VendoredChecksumMismatchError",
+			err:      &VendoredChecksumMismatchError{URL: "https://a.com/a.json", Path: "/vendor/a.com/a.json"},
+			contains: []string{"/vendor/a.com/a.json of https://a.com/a.json", "jsm vendor add https://a.com/a.json"},
+		},
+		{
+			name:     "InvalidVendorURLError",
+			err:      &InvalidVendorURLError{URL: "ftp://a.com"},
+			contains: []string{"ftp://a.com cannot be vendored"},
+		},
+		{
+			name:     "SchemaLocationMismatchError",
+			err:      &SchemaLocationMismatchError{Key: "a_b_1_0_0", Expected: "/a/b/1/0/0/f", Actual: "/a/b/1/1/0/f"},
+			contains: []string{"/a/b/1/1/0/f is not where its key a_b_1_0_0 implies: expected /a/b/1/0/0/f"},
+		},
+		{
+			name:     "NonNumericVersionDirectoryError",
+			err:      &NonNumericVersionDirectoryError{Path: "/a/b/v1"},
+			contains: []string{"/a/b/v1 is not a version number"},
+		},
+		{
+			name:     "EmptyVersionDirectoryError",
+			err:      &EmptyVersionDirectoryError{Path: "/a/b/1"},
+			contains: []string{"/a/b/1 holds no schema file"},
+		},
+		{
+			name:     "StrayJSONFileError",
+			err:      &StrayJSONFileError{Path: "/a/b/1/0/0/doc.json"},
+			contains: []string{"/a/b/1/0/0/doc.json is not a schema file or a test document"},
+		},
+		{
+			name:     "RegistryProblemsError",
+			err:      &RegistryProblemsError{Count: 2},
+			contains: []string{"found 2 structural problems"},
+		},
+		{
+			name:     "InvalidFamilyArgError",
+			err:      &InvalidFamilyArgError{Arg: "family"},
+			contains: []string{"Invalid schema family argument: family"},
+		},
+		{
+			name:     "FamilyExistsError",
+			err:      &FamilyExistsError{Family: "new/family"},
+			contains: []string{"schema family new/family already exists"},
+		},
+		{
+			name:     "DeployedFamilyMoveError",
+			err:      &DeployedFamilyMoveError{Env: "prod", Keys: []Key{"old_family_1_0_0"}},
+			contains: []string{"deployed to prod: [old_family_1_0_0]", "use --copy"},
+		},
+		{
+			name:     "ScaffoldNotFoundError",
+			err:      &ScaffoldNotFoundError{Name: "event", Available: []string{"entity"}},
+			contains: []string{"scaffold event not found", "scaffolds/event.schema.json.tmpl", "[entity]"},
+		},
+		{
+			name:     "InvalidScaffoldError",
+			err:      &InvalidScaffoldError{Name: "event", Wrapped: errors.New("unexpected }")},
+			contains: []string{"scaffold event is invalid", "unexpected }"},
+		},
+		{
+			name:     "StarterDocumentInvalidError",
+			err:      &StarterDocumentInvalidError{Key: "domain_family_1_0_0", Wrapped: errors.New("missing id")},
+			contains: []string{"schema domain_family_1_0_0 was not created", "starter pass document", "missing id"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,171 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// ExternalKeySeparator separates the name of an external registry from the key of one of its schemas in a
+// {{ JSM }} argument, such as "payments:payments_refund_1_0_0".
+const ExternalKeySeparator = ":"
+
+// externalRegistry is the registry of another team, whose schemas are referenced but never built.
+type externalRegistry interface {
+	// schema returns the canonical ID and rendered content of the schema with the key in the environment,
+	// having added it to the compiler, so that the schemas which reference it can be compiled.
+	schema(k Key, env config.Env) (ID, validator.JSONSchema, error)
+}
+
+// external returns the external registry with the given name, opening it if it has not yet been referenced.
+func (r *Registry) external(name string) (externalRegistry, error) {
+	r.externalsMu.Lock()
+	defer r.externalsMu.Unlock()
+
+	if er, ok := r.externals[name]; ok {
+		return er, nil
+	}
+	erc, ok := r.config.ExternalRegistries[name]
+	if !ok {
+		return nil, &UnknownExternalRegistryError{Name: name}
+	}
+
+	var er externalRegistry
+	if erc.Path != "" {
+		reg, err := NewRegistry(r.externalPath(erc.Path), r.compiler, r.pathResolver, r.envProvider)
+		if err != nil {
+			return nil, &ExternalRegistryInitError{Name: name, Wrapped: err}
+		}
+		er = &checkoutRegistry{registry: reg}
+	} else {
+		er = &distRegistry{dir: r.externalPath(erc.Dist), compiler: r.compiler}
+	}
+
+	if r.externals == nil {
+		r.externals = make(map[string]externalRegistry)
+	}
+	r.externals[name] = er
+	return er, nil
+}
+
+// externalPath returns a path from the configuration of an external registry, relative to the registry
+// root directory if it is not absolute.
+func (r *Registry) externalPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(r.rootDirectory, path)
+}
+
+// checkoutRegistry is an external registry read from a checkout of its repository. Its schemas are rendered
+// for the environment of its own configuration with the same name.
+type checkoutRegistry struct {
+	registry *Registry
+}
+
+func (c *checkoutRegistry) schema(k Key, env config.Env) (ID, validator.JSONSchema, error) {
+	ec, err := c.registry.config.EnvConfig(env)
+	if err != nil {
+		return "", nil, err
+	}
+	s, err := c.registry.GetSchemaByKey(k)
+	if err != nil {
+		return "", nil, err
+	}
+	ri, err := s.Render(ec)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.CanonicalID(ec), ri.Unmarshalled, nil
+}
+
+// distRegistry is an external registry read from a distribution built from it by build-dist, which holds
+// the manifest and rendered schemas of each environment in a directory named after it.
+type distRegistry struct {
+	dir       string
+	compiler  validator.Compiler
+	mu        sync.Mutex                  // Protects manifests and added
+	manifests map[config.Env]*Manifest    // The manifests read so far, by environment
+	added     map[ID]validator.JSONSchema // The schemas added to the compiler so far, by ID
+}
+
+func (d *distRegistry) schema(k Key, env config.Env) (ID, validator.JSONSchema, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	envDir := filepath.Join(d.dir, string(env))
+	m, err := d.manifest(env, envDir)
+	if err != nil {
+		return "", nil, err
+	}
+	return d.add(m, envDir, k)
+}
+
+// manifest returns the manifest of the distribution built for the environment into envDir.
+func (d *distRegistry) manifest(env config.Env, envDir string) (*Manifest, error) {
+	if m, ok := d.manifests[env]; ok {
+		return m, nil
+	}
+
+	fp := filepath.Join(envDir, ManifestFile)
+	//nolint:gosec // Path is constructed from the configured dist directory
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, &InvalidJSONError{Path: fp, Wrapped: err}
+	}
+	if d.manifests == nil {
+		d.manifests = make(map[config.Env]*Manifest)
+	}
+	d.manifests[env] = &m
+	return &m, nil
+}
+
+// add adds the schema with the key, and the schemas it depends on, to the compiler.
+func (d *distRegistry) add(m *Manifest, envDir string, k Key) (ID, validator.JSONSchema, error) {
+	i := slices.IndexFunc(m.Schemas, func(e ManifestEntry) bool { return e.Key == k })
+	if i < 0 {
+		return "", nil, &ExternalSchemaNotFoundError{Key: k, Manifest: filepath.Join(envDir, ManifestFile)}
+	}
+	e := m.Schemas[i]
+	if doc, ok := d.added[e.ID]; ok {
+		return e.ID, doc, nil
+	}
+
+	fp := filepath.Join(envDir, filepath.FromSlash(e.DistPath))
+	//nolint:gosec // Path is read from the manifest of the distribution
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return "", nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, &InvalidJSONError{Path: fp, Wrapped: err}
+	}
+	if err = d.compiler.AddSchema(string(e.ID), doc); err != nil {
+		return "", nil, err
+	}
+	if d.added == nil {
+		d.added = make(map[ID]validator.JSONSchema)
+	}
+	d.added[e.ID] = doc
+
+	for _, dep := range e.Dependencies {
+		if _, _, err = d.add(m, envDir, dep); err != nil {
+			return "", nil, fmt.Errorf("dependency of %s: %w", k, err)
+		}
+	}
+	return e.ID, doc, nil
+}
@@ -0,0 +1,245 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+const paymentsConfigData = `
+environments:
+  prod:
+    privateUrlRoot: "https://payments.internal.myorg.io/"
+    publicUrlRoot: "https://payments.myorg.io/"
+    isProduction: true
+`
+
+// setupExternalRegistry creates a registry in dir with the given configuration and schemas, which compiles
+// schemas with the given compiler.
+func setupExternalRegistry(
+	t *testing.T,
+	dir, configData string,
+	compiler validator.Compiler,
+	schemas schemaMap,
+) *Registry {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.JsmRegistryConfigFile), []byte(configData), 0o600))
+	r, err := NewRegistry(dir, compiler, fsh.NewPathResolver(), fsh.NewEnvProvider())
+	require.NoError(t, err)
+	createSchemaFiles(t, r, schemas)
+	return r
+}
+
+// writeExternalDist writes a distribution for prod into dir, holding the given manifest entries and schemas.
+func writeExternalDist(t *testing.T, dir string, entries []ManifestEntry, schemas map[string]string) {
+	t.Helper()
+	envDir := filepath.Join(dir, "prod")
+	require.NoError(t, os.MkdirAll(filepath.Join(envDir, visibilityPrivate), 0o755))
+	require.NoError(t, writeJSONFile(filepath.Join(envDir, ManifestFile), Manifest{Env: "prod", Schemas: entries}))
+	for distPath, content := range schemas {
+		require.NoError(t, os.WriteFile(filepath.Join(envDir, filepath.FromSlash(distPath)), []byte(content), 0o600))
+	}
+}
+
+// renderExternalReference renders a schema in r which references the argument with the template action.
+func renderExternalReference(t *testing.T, r *Registry, action string) (RenderInfo, error) {
+	t.Helper()
+	createSchemaFiles(t, r, schemaMap{
+		"orders_order_1_0_0": `{"$id": "{{ ID }}", "properties": {"refund": {"$ref": "` + action + `"}}}`,
+	})
+	s, err := r.GetSchemaByKey("orders_order_1_0_0")
+	require.NoError(t, err)
+	return r.CoordinateRender(s, r.config.ProductionEnvConfig())
+}
+
+func TestRenderer_ExternalRegistries(t *testing.T) {
+	t.Parallel()
+
+	// newRegistries creates the checkout of a payments registry, and a distribution of an identity
+	// registry, alongside a registry which references both.
+	newRegistries := func(t *testing.T) *Registry {
+		t.Helper()
+		dir := t.TempDir()
+		compiler := validator.NewSanthoshCompiler()
+		setupExternalRegistry(t, filepath.Join(dir, "payments"), paymentsConfigData, compiler, schemaMap{
+			"payments_refund_1_0_0": `{"$id": "{{ ID }}", "$defs": {"amount": {"type": "number"}}, "type": "object"}`,
+		})
+		writeExternalDist(t, filepath.Join(dir, "identity-dist"), []ManifestEntry{
+			{
+				Key:          "identity_user_1_0_0",
+				ID:           "https://identity.myorg.io/identity_user_1_0_0.schema.json",
+				DistPath:     "private/identity_user_1_0_0.schema.json",
+				Dependencies: []Key{"identity_name_1_0_0"},
+			},
+			{
+				Key:      "identity_name_1_0_0",
+				ID:       "https://identity.myorg.io/identity_name_1_0_0.schema.json",
+				DistPath: "private/identity_name_1_0_0.schema.json",
+			},
+		}, map[string]string{
+			"private/identity_user_1_0_0.schema.json": `{"$id": "https://identity.myorg.io/identity_user_1_0_0.schema.json",
+				"properties": {"name": {"$ref": "https://identity.myorg.io/identity_name_1_0_0.schema.json"}}}`,
+			"private/identity_name_1_0_0.schema.json": `{"$id": "https://identity.myorg.io/identity_name_1_0_0.schema.json",
+				"type": "string"}`,
+		})
+
+		return setupExternalRegistry(t, filepath.Join(dir, "orders"), testConfigData+`
+externalRegistries:
+  payments:
+    path: "../payments"
+  identity:
+    dist: "`+filepath.Join(dir, "identity-dist")+`"
+`, compiler, nil)
+	}
+
+	t.Run("checkout", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistries(t)
+		ri, err := renderExternalReference(t, r, `{{ JSM "payments:payments_refund_1_0_0" }}`)
+		require.NoError(t, err)
+		assert.Contains(t, string(ri.Rendered), `"https://payments.internal.myorg.io/payments_refund_1_0_0.schema.json"`)
+		assert.Empty(t, ri.Dependencies)
+		require.NoError(t, ri.Validator.Validate(map[string]any{"refund": map[string]any{}}))
+		require.Error(t, ri.Validator.Validate(map[string]any{"refund": "not an object"}))
+	})
+
+	t.Run("sub-schema of a checkout", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistries(t)
+		ri, err := renderExternalReference(t, r, `{{ JSMRef "payments:payments_refund_1_0_0" "#/$defs/amount" }}`)
+		require.NoError(t, err)
+		assert.Contains(t, string(ri.Rendered),
+			`"https://payments.internal.myorg.io/payments_refund_1_0_0.schema.json#/$defs/amount"`)
+
+		renderer := NewRenderer(New("orders_order_1_0_0", r), r.config.ProductionEnvConfig())
+		_, err = renderer.JSMRef("payments:payments_refund_1_0_0", "#/$defs/x")
+		var target *JSMRefFragmentNotFoundError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, Key("payments:payments_refund_1_0_0"), target.To)
+	})
+
+	t.Run("distribution", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistries(t)
+		ri, err := renderExternalReference(t, r, `{{ JSM "identity:identity_user_1_0_0" }}`)
+		require.NoError(t, err)
+		assert.Contains(t, string(ri.Rendered), `"https://identity.myorg.io/identity_user_1_0_0.schema.json"`)
+		require.NoError(t, ri.Validator.Validate(map[string]any{"refund": map[string]any{"name": "Jo"}}))
+		require.Error(t, ri.Validator.Validate(map[string]any{"refund": map[string]any{"name": 1}}))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistries(t)
+		renderer := NewRenderer(New("orders_order_1_0_0", r), r.config.ProductionEnvConfig())
+
+		_, err := renderer.JSM("billing:billing_invoice_1_0_0")
+		var unknownErr *UnknownExternalRegistryError
+		require.ErrorAs(t, err, &unknownErr)
+		assert.Equal(t, "billing", unknownErr.Name)
+
+		_, err = renderer.JSM("payments:payments_refund_1")
+		var keyErr *JSMArgInvalidKeyError
+		require.ErrorAs(t, err, &keyErr)
+
+		_, err = renderer.JSM("payments:payments_refund_2_0_0")
+		var notFoundErr *JSMArgNotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+		assert.Equal(t, Key("payments:payments_refund_2_0_0"), notFoundErr.Key)
+
+		_, err = renderer.JSM("identity:identity_group_1_0_0")
+		var distErr *ExternalSchemaNotFoundError
+		require.ErrorAs(t, err, &distErr)
+		assert.Equal(t, Key("identity_group_1_0_0"), distErr.Key)
+	})
+}
+
+func TestRegistry_External(t *testing.T) {
+	t.Parallel()
+
+	newRegistry := func(t *testing.T, external string) *Registry {
+		t.Helper()
+		return setupExternalRegistry(t, t.TempDir(), testConfigData+"externalRegistries:\n"+external, &mockCompiler{}, nil)
+	}
+
+	t.Run("opened once", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t, "  identity:\n    dist: dist\n")
+		er, err := r.external("identity")
+		require.NoError(t, err)
+		again, err := r.external("identity")
+		require.NoError(t, err)
+		assert.Same(t, er, again)
+		assert.Equal(t, filepath.Join(r.RootDirectory(), "dist"), er.(*distRegistry).dir)
+
+		r.Reset()
+		again, err = r.external("identity")
+		require.NoError(t, err)
+		assert.NotSame(t, er, again)
+	})
+
+	t.Run("checkout cannot be opened", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t, "  payments:\n    path: ../missing\n")
+		_, err := r.external("payments")
+		var target *ExternalRegistryInitError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, "payments", target.Name)
+	})
+
+	t.Run("checkout without the environment", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		setupExternalRegistry(t, filepath.Join(dir, "payments"), promotionConfigData, &mockCompiler{}, schemaMap{
+			"payments_refund_1_0_0": `{}`,
+		})
+		r := newRegistry(t, "  payments:\n    path: "+filepath.Join(dir, "payments")+"\n")
+		er, err := r.external("payments")
+		require.NoError(t, err)
+
+		_, _, err = er.schema("payments_refund_1_0_0", "staging")
+		var target *config.UnknownEnvironmentError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("invalid distribution", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t, "  identity:\n    dist: dist\n")
+		er, err := r.external("identity")
+		require.NoError(t, err)
+
+		_, _, err = er.schema("identity_user_1_0_0", "prod")
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		envDir := filepath.Join(r.RootDirectory(), "dist", "prod")
+		require.NoError(t, os.MkdirAll(envDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(envDir, ManifestFile), []byte("{"), 0o600))
+		_, _, err = er.schema("identity_user_1_0_0", "prod")
+		var jsonErr *InvalidJSONError
+		require.ErrorAs(t, err, &jsonErr)
+
+		data, err := json.Marshal(Manifest{Env: "prod", Schemas: []ManifestEntry{
+			{Key: "identity_user_1_0_0", ID: "https://identity.myorg.io/user", DistPath: "user.json"},
+		}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(envDir, ManifestFile), data, 0o600))
+		r.Reset()
+		er, err = r.external("identity")
+		require.NoError(t, err)
+		_, _, err = er.schema("identity_user_1_0_0", "prod")
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		require.NoError(t, os.WriteFile(filepath.Join(envDir, "user.json"), []byte("{"), 0o600))
+		_, _, err = er.schema("identity_user_1_0_0", "prod")
+		require.ErrorAs(t, err, &jsonErr)
+	})
+}
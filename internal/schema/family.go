@@ -0,0 +1,235 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// FamilyMetadataFile is the name of the optional file in a schema family directory which records
+// ownership and lifecycle information for every version of the family.
+const FamilyMetadataFile = "family.yml"
+
+// familyMetaSchemaID is the ID under which the family metadata meta-schema is registered with the compiler.
+const familyMetaSchemaID = "https://json-schema-manager.bitshepherds.com/meta/family.schema.json"
+
+// familyMetaSchema is the built-in JSON Schema which every family.yml file must satisfy.
+const familyMetaSchema = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"$id": "` + familyMetaSchemaID + `",
+	"title": "JSM schema family metadata",
+	"type": "object",
+	"additionalProperties": false,
+	"required": ["owner"],
+	"properties": {
+		"owner": {
+			"description": "The team which owns the schema family",
+			"type": "string",
+			"minLength": 1
+		},
+		"slackChannel": {
+			"description": "The Slack channel where the owning team can be contacted",
+			"type": "string",
+			"pattern": "^#[a-z0-9._-]+$"
+		},
+		"email": {
+			"description": "An email address where the owning team can be contacted",
+			"type": "string",
+			"pattern": "^[^@\\s]+@[^@\\s]+$"
+		},
+		"classification": {
+			"description": "The classification of the data described by the schema family",
+			"enum": ["public", "internal", "confidential", "restricted"]
+		},
+		"status": {
+			"description": "The lifecycle status of the schema family",
+			"enum": ["experimental", "active", "deprecated", "retired"]
+		},
+		"codeOwners": {
+			"description": "GitHub users, teams or email addresses which own the family directory",
+			"type": "array",
+			"uniqueItems": true,
+			"items": {
+				"type": "string",
+				"pattern": "^(@[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)?|[^@\\s]+@[^@\\s]+)$"
+			}
+		}
+	}
+}`
+
+// DataClassification describes the sensitivity of the data described by a schema family.
+type DataClassification string
+
+const (
+	// ClassificationPublic is data which may be shared outside of the organisation.
+	ClassificationPublic DataClassification = "public"
+	// ClassificationInternal is data which may be shared freely within the organisation.
+	ClassificationInternal DataClassification = "internal"
+	// ClassificationConfidential is data which must only be shared with those who need it.
+	ClassificationConfidential DataClassification = "confidential"
+	// ClassificationRestricted is highly sensitive data, such as personal or payment data.
+	ClassificationRestricted DataClassification = "restricted"
+)
+
+// LifecycleStatus describes where a schema family is in its lifecycle.
+type LifecycleStatus string
+
+const (
+	// StatusExperimental indicates a family which may still change significantly.
+	StatusExperimental LifecycleStatus = "experimental"
+	// StatusActive indicates a family which is in active use.
+	StatusActive LifecycleStatus = "active"
+	// StatusDeprecated indicates a family which should not be used for new integrations.
+	StatusDeprecated LifecycleStatus = "deprecated"
+	// StatusRetired indicates a family which is no longer in use.
+	StatusRetired LifecycleStatus = "retired"
+)
+
+// FamilyMetadata holds the ownership and contact details recorded in a family's family.yml file.
+type FamilyMetadata struct {
+	Owner          string             `yaml:"owner"          json:"owner"`
+	SlackChannel   string             `yaml:"slackChannel"   json:"slackChannel,omitempty"`
+	Email          string             `yaml:"email"          json:"email,omitempty"`
+	Classification DataClassification `yaml:"classification" json:"classification,omitempty"`
+	Status         LifecycleStatus    `yaml:"status"         json:"status,omitempty"`
+	CodeOwners     []string           `yaml:"codeOwners"     json:"codeOwners,omitempty"`
+}
+
+// FamilyOwnership associates a schema family with its metadata, if it has any.
+type FamilyOwnership struct {
+	Scope    SearchScope     // The domain and family name of the family - e.g. "domain-a/family-a"
+	Dir      string          // The family directory
+	Metadata *FamilyMetadata // nil if the family has no family.yml file
+}
+
+// familyMetadata returns the metadata for the family in the given family directory, loading and validating
+// it on first use. If the family has no metadata file, nil is returned without error.
+func (r *Registry) familyMetadata(familyDir string) (*FamilyMetadata, error) {
+	r.familyMu.Lock()
+	defer r.familyMu.Unlock()
+
+	if fm, ok := r.families[familyDir]; ok {
+		return fm, nil
+	}
+
+	fm, err := r.loadFamilyMetadata(filepath.Join(familyDir, FamilyMetadataFile))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.families == nil {
+		r.families = make(map[string]*FamilyMetadata)
+	}
+	r.families[familyDir] = fm
+	return fm, nil
+}
+
+// loadFamilyMetadata reads a family.yml file and validates it against the family meta-schema.
+func (r *Registry) loadFamilyMetadata(fp string) (*FamilyMetadata, error) {
+	data, err := r.readFile(fp)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Convert the YAML to JSON so it can be validated by the JSON Schema compiler.
+	var raw interface{}
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return nil, &InvalidFamilyMetadataError{Path: fp, Wrapped: err}
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, &InvalidFamilyMetadataError{Path: fp, Wrapped: err}
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, &InvalidFamilyMetadataError{Path: fp, Wrapped: err}
+	}
+
+	v, err := r.familyValidator()
+	if err != nil {
+		return nil, err
+	}
+	if err = v.Validate(doc); err != nil {
+		return nil, &InvalidFamilyMetadataError{Path: fp, Wrapped: err}
+	}
+
+	var fm FamilyMetadata
+	if err = json.Unmarshal(jsonData, &fm); err != nil {
+		return nil, &InvalidFamilyMetadataError{Path: fp, Wrapped: err}
+	}
+	return &fm, nil
+}
+
+// familyValidator compiles the family metadata meta-schema. Caller must hold Registry.familyMu.
+func (r *Registry) familyValidator() (validator.Validator, error) {
+	if r.familySchema != nil {
+		return r.familySchema, nil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(familyMetaSchema))
+	if err != nil {
+		return nil, err
+	}
+	if err = r.compiler.AddSchema(familyMetaSchemaID, doc); err != nil {
+		return nil, err
+	}
+	v, err := r.compiler.Compile(familyMetaSchemaID)
+	if err != nil {
+		return nil, err
+	}
+	r.familySchema = v
+	return v, nil
+}
+
+// FamilyOwners returns the ownership details of every schema family containing schemas within the
+// given search scope, in the order in which they are found.
+func (r *Registry) FamilyOwners(ctx context.Context, ss SearchScope) ([]FamilyOwnership, error) {
+	searcher, err := NewSearcher(r, ss)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the search stops if we return early.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var owners []FamilyOwnership
+	seen := make(map[SearchScope]bool)
+
+	for res := range searcher.Schemas(ctx) {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		scope := res.Key.FamilyScope()
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+
+		dir := NewCoreFromKey(res.Key).Path(FamilyDir, r.rootDirectory)
+		fm, fErr := r.familyMetadata(dir)
+		if fErr != nil {
+			return nil, fErr
+		}
+		owners = append(owners, FamilyOwnership{Scope: scope, Dir: dir, Metadata: fm})
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return owners, nil
+}
@@ -0,0 +1,246 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// writeFamilyMetadata writes a family.yml file into the family directory of the given key.
+func writeFamilyMetadata(t *testing.T, r *Registry, k Key, content string) string {
+	t.Helper()
+	dir := NewCoreFromKey(k).Path(FamilyDir, r.RootDirectory())
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	fp := filepath.Join(dir, FamilyMetadataFile)
+	require.NoError(t, os.WriteFile(fp, []byte(content), 0o600))
+	return fp
+}
+
+func TestSchema_Family(t *testing.T) {
+	t.Parallel()
+
+	t.Run("family metadata is loaded with the schema", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		k := Key("domain-a_family-a_1_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+		writeFamilyMetadata(t, r, k, `
+owner: team-a
+slackChannel: "#team-a"
+email: team-a@myorg.com
+classification: confidential
+status: active
+codeOwners:
+  - "@myorg/team-a"
+  - jane@myorg.com
+`)
+
+		s, err := r.GetSchemaByKey(k)
+		require.NoError(t, err)
+
+		want := &FamilyMetadata{
+			Owner:          "team-a",
+			SlackChannel:   "#team-a",
+			Email:          "team-a@myorg.com",
+			Classification: ClassificationConfidential,
+			Status:         StatusActive,
+			CodeOwners:     []string{"@myorg/team-a", "jane@myorg.com"},
+		}
+		assert.Equal(t, want, s.Family())
+	})
+
+	t.Run("family without metadata file", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		k := Key("domain-a_family-a_1_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+
+		s, err := r.GetSchemaByKey(k)
+		require.NoError(t, err)
+		assert.Nil(t, s.Family())
+	})
+
+	t.Run("metadata is shared by every version in the family", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		k1 := Key("domain-a_family-a_1_0_0")
+		k2 := Key("domain-a_family-a_2_0_0")
+		createSchemaFiles(t, r, schemaMap{k1: "{}", k2: "{}"})
+		writeFamilyMetadata(t, r, k1, "owner: team-a\n")
+
+		s1, err := r.GetSchemaByKey(k1)
+		require.NoError(t, err)
+		s2, err := r.GetSchemaByKey(k2)
+		require.NoError(t, err)
+		assert.Same(t, s1.Family(), s2.Family())
+	})
+}
+
+func TestRegistry_familyMetadata_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "invalid YAML", content: "owner: [unclosed"},
+		{name: "missing owner", content: "status: active\n"},
+		{name: "unknown property", content: "owner: team-a\nteam: team-b\n"},
+		{name: "invalid classification", content: "owner: team-a\nclassification: secret\n"},
+		{name: "invalid status", content: "owner: team-a\nstatus: sunset\n"},
+		{name: "invalid slack channel", content: "owner: team-a\nslackChannel: team-a\n"},
+		{name: "invalid code owner", content: "owner: team-a\ncodeOwners: [team-a]\n"},
+		{name: "not an object", content: "- team-a\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := setupCompilingTestRegistry(t)
+			k := Key("domain-a_family-a_1_0_0")
+			createSchemaFiles(t, r, schemaMap{k: "{}"})
+			fp := writeFamilyMetadata(t, r, k, tt.content)
+
+			_, err := r.GetSchemaByKey(k)
+			var target *InvalidFamilyMetadataError
+			require.ErrorAs(t, err, &target)
+			assert.Equal(t, fp, target.Path)
+		})
+	}
+}
+
+func TestRegistry_familyMetadata_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unreadable metadata file", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		k := Key("domain-a_family-a_1_0_0")
+		dir := NewCoreFromKey(k).Path(FamilyDir, r.RootDirectory())
+		// A directory in place of the file cannot be read.
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, FamilyMetadataFile), 0o755))
+
+		_, err := r.familyMetadata(dir)
+		require.Error(t, err)
+	})
+
+	t.Run("meta-schema cannot be compiled", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		r.compiler = &mockCompiler{CompileFunc: func(_ string) (validator.Validator, error) {
+			return nil, errors.New("compile failed")
+		}}
+		k := Key("domain-a_family-a_1_0_0")
+		fp := writeFamilyMetadata(t, r, k, "owner: team-a\n")
+
+		_, err := r.familyMetadata(filepath.Dir(fp))
+		require.EqualError(t, err, "compile failed")
+	})
+}
+
+func TestRegistry_Reset_ClearsFamilyMetadata(t *testing.T) {
+	t.Parallel()
+	r := setupCompilingTestRegistry(t)
+	k := Key("domain-a_family-a_1_0_0")
+	createSchemaFiles(t, r, schemaMap{k: "{}"})
+	writeFamilyMetadata(t, r, k, "owner: team-a\n")
+
+	s, err := r.GetSchemaByKey(k)
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", s.Family().Owner)
+
+	writeFamilyMetadata(t, r, k, "owner: team-b\n")
+	r.Reset()
+
+	s, err = r.GetSchemaByKey(k)
+	require.NoError(t, err)
+	assert.Equal(t, "team-b", s.Family().Owner)
+}
+
+func TestRegistry_FamilyOwners(t *testing.T) {
+	t.Parallel()
+
+	t.Run("one entry per family", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		ka1 := Key("domain-a_family-a_1_0_0")
+		ka2 := Key("domain-a_family-a_1_1_0")
+		kb := Key("domain-a_sub_family-b_1_0_0")
+		kc := Key("domain-b_family-c_1_0_0")
+		createSchemaFiles(t, r, schemaMap{ka1: "{}", ka2: "{}", kb: "{}", kc: "{}"})
+		writeFamilyMetadata(t, r, ka1, "owner: team-a\n")
+		writeFamilyMetadata(t, r, kc, "owner: team-c\n")
+
+		owners, err := r.FamilyOwners(context.Background(), "")
+		require.NoError(t, err)
+		require.Len(t, owners, 3)
+
+		byScope := make(map[SearchScope]FamilyOwnership)
+		for _, o := range owners {
+			byScope[o.Scope] = o
+		}
+		assert.Equal(t, "team-a", byScope["domain-a/family-a"].Metadata.Owner)
+		assert.Nil(t, byScope["domain-a/sub/family-b"].Metadata)
+		assert.Equal(t, "team-c", byScope["domain-b/family-c"].Metadata.Owner)
+		assert.Equal(t, filepath.Join(r.RootDirectory(), "domain-b", "family-c"), byScope["domain-b/family-c"].Dir)
+	})
+
+	t.Run("scoped search", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{"domain-a_family-a_1_0_0": "{}", "domain-b_family-b_1_0_0": "{}"})
+
+		owners, err := r.FamilyOwners(context.Background(), "domain-b")
+		require.NoError(t, err)
+		require.Len(t, owners, 1)
+		assert.Equal(t, SearchScope("domain-b/family-b"), owners[0].Scope)
+	})
+
+	t.Run("scope does not exist", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+
+		_, err := r.FamilyOwners(context.Background(), "missing")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid schema filename", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		require.NoError(t, os.WriteFile(filepath.Join(r.RootDirectory(), "bad"+SchemaSuffix), []byte("{}"), 0o600))
+
+		_, err := r.FamilyOwners(context.Background(), "")
+		var target *InvalidSchemaFilenameError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("invalid family metadata", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		k := Key("domain-a_family-a_1_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+		writeFamilyMetadata(t, r, k, "status: active\n")
+
+		_, err := r.FamilyOwners(context.Background(), "")
+		var target *InvalidFamilyMetadataError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{"domain-a_family-a_1_0_0": "{}"})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := r.FamilyOwners(ctx, "")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"cmp"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+// DeploymentHistory lists the deployments to one or more environments, and the schemas each deployment
+// shipped.
+type DeploymentHistory struct {
+	Envs        []config.Env         `json:"envs"`
+	Target      string               `json:"target,omitempty"` // The key or scope the history is restricted to
+	Deployments []HistoricDeployment `json:"deployments"`      // Oldest first
+}
+
+// HistoricDeployment describes a deployment to an environment, and the schemas which were changed since the
+// previous deployment to the environment.
+type HistoricDeployment struct {
+	Env      config.Env            `json:"env"`
+	Tag      repo.Revision         `json:"tag"`
+	Time     time.Time             `json:"time"`
+	Previous repo.Revision         `json:"previous,omitempty"` // The tag of the previous deployment, if any
+	Added    []Key                 `json:"added"`
+	Modified []Key                 `json:"modified"`
+	Removed  []Key                 `json:"removed"`
+	Recorded []repo.DeployedSchema `json:"recorded,omitempty"` // The schemas and digests recorded by the tag
+}
+
+// Changed returns true if the deployment added, modified or removed any schemas.
+func (d *HistoricDeployment) Changed() bool {
+	return len(d.Added) > 0 || len(d.Modified) > 0 || len(d.Removed) > 0
+}
+
+// DeploymentHistory returns the history of the deployments to the given environments. The schemas shipped by
+// each deployment are those changed between its tag and the tag of the previous deployment to the same
+// environment. The first deployment to an environment added every schema at its tag. If target is not nil,
+// only the schemas it targets are included, and deployments which changed none of them are omitted.
+func (r *Registry) DeploymentHistory(
+	ctx context.Context,
+	g repo.Gitter,
+	envs []config.Env,
+	target *ResolvedTarget,
+) (*DeploymentHistory, error) {
+	h := &DeploymentHistory{Envs: envs}
+	match := func(Key) bool { return true }
+	switch {
+	case target != nil && target.Key != nil:
+		h.Target = string(*target.Key)
+		match = func(k Key) bool { return k == *target.Key }
+	case target != nil && target.Scope != nil && *target.Scope != "":
+		h.Target = string(*target.Scope)
+		match = func(k Key) bool { return k.InScope(*target.Scope) }
+	}
+
+	for _, env := range envs {
+		deployments, err := g.Deployments(ctx, env)
+		if err != nil {
+			return nil, err
+		}
+
+		var previous repo.Revision
+		for _, d := range deployments {
+			changes, cErr := g.GetSchemaChangesBetween(ctx, previous, d.Tag, r.rootDirectory, SchemaSuffix)
+			if cErr != nil {
+				return nil, cErr
+			}
+			hd := historicDeployment(d, previous, changes, match)
+			previous = d.Tag
+			if h.Target != "" && !hd.Changed() {
+				continue
+			}
+			h.Deployments = append(h.Deployments, hd)
+		}
+	}
+
+	slices.SortStableFunc(h.Deployments, func(a, b HistoricDeployment) int {
+		return cmp.Or(a.Time.Compare(b.Time), strings.Compare(string(a.Env), string(b.Env)))
+	})
+	return h, nil
+}
+
+// historicDeployment describes a deployment which made the given changes since the previous deployment,
+// including only the schemas accepted by match.
+func historicDeployment(
+	d repo.Deployment,
+	previous repo.Revision,
+	changes []repo.Change,
+	match func(Key) bool,
+) HistoricDeployment {
+	hd := HistoricDeployment{Env: d.Env, Tag: d.Tag, Time: d.Time, Previous: previous}
+	add := func(keys *[]Key, path string) {
+		if k, ok := keyFromFilename(path); ok && match(k) {
+			*keys = append(*keys, k)
+		}
+	}
+
+	for _, c := range changes {
+		switch {
+		case c.Status == repo.ChangeAdded:
+			add(&hd.Added, c.Path)
+		case c.Status == repo.ChangeDeleted:
+			add(&hd.Removed, c.Path)
+		case c.Status == repo.ChangeRenamed && filepath.Base(c.Path) != filepath.Base(c.OldPath):
+			// A renamed schema is served under its new key, so its old key has been removed.
+			add(&hd.Added, c.Path)
+			add(&hd.Removed, c.OldPath)
+		default:
+			add(&hd.Modified, c.Path)
+		}
+	}
+	slices.Sort(hd.Added)
+	slices.Sort(hd.Modified)
+	slices.Sort(hd.Removed)
+
+	for _, s := range d.Schemas {
+		if match(Key(s.Key)) {
+			hd.Recorded = append(hd.Recorded, s)
+		}
+	}
+	return hd
+}
+
+// keyFromFilename returns the key of the schema file at path, which need not exist.
+func keyFromFilename(path string) (Key, bool) {
+	name := filepath.Base(path)
+	if path == "" || !strings.HasSuffix(name, SchemaSuffix) {
+		return "", false
+	}
+	k, err := NewKey(strings.TrimSuffix(name, SchemaSuffix))
+	return k, err == nil
+}
@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+func TestRegistry_DeploymentHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	file := func(k string) string { return filepath.Join("a", "b", k+SchemaSuffix) }
+
+	historyGitter := func() *mockGitter {
+		return &mockGitter{
+			deploymentsFunc: func(_ context.Context, env config.Env) ([]repo.Deployment, error) {
+				if env == "dev" {
+					return []repo.Deployment{{Env: env, Tag: "jsm-deploy/dev/1", Time: day(2)}}, nil
+				}
+				return []repo.Deployment{
+					{
+						Env:     env,
+						Tag:     "jsm-deploy/prod/1",
+						Time:    day(1),
+						Schemas: []repo.DeployedSchema{{Key: "domain_a_1_0_0", SHA256: "aa"}},
+					},
+					{Env: env, Tag: "jsm-deploy/prod/2", Time: day(3)},
+					{Env: env, Tag: "jsm-deploy/prod/3", Time: day(4)},
+				}, nil
+			},
+			changesBetweenFunc: func(_ context.Context, from, to repo.Revision, _, _ string) ([]repo.Change, error) {
+				switch {
+				case from == "" && to == "jsm-deploy/dev/1":
+					return []repo.Change{{Path: file("other_x_1_0_0"), Status: repo.ChangeAdded}}, nil
+				case from == "":
+					return []repo.Change{
+						{Path: file("domain_b_1_0_0"), Status: repo.ChangeAdded},
+						{Path: file("domain_a_1_0_0"), Status: repo.ChangeAdded},
+						{Path: "a/README.md", Status: repo.ChangeAdded},
+					}, nil
+				case from == "jsm-deploy/prod/1":
+					return []repo.Change{
+						{Path: file("domain_a_1_0_0"), Status: repo.ChangeModified},
+						{Path: filepath.Join("c", "domain_b_1_0_0"+SchemaSuffix), OldPath: file("domain_b_1_0_0"),
+							Status: repo.ChangeRenamed},
+						{Path: file("domain_c_1_0_0"), OldPath: file("domain_b_1_0_0"), Status: repo.ChangeRenamed},
+					}, nil
+				default:
+					return nil, nil
+				}
+			},
+		}
+	}
+
+	t.Run("every deployment", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+
+		h, err := r.DeploymentHistory(ctx, historyGitter(), []config.Env{"dev", "prod"}, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []config.Env{"dev", "prod"}, h.Envs)
+		assert.Empty(t, h.Target)
+		require.Len(t, h.Deployments, 4)
+
+		first := h.Deployments[0]
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), first.Tag)
+		assert.Empty(t, first.Previous)
+		assert.Equal(t, []Key{"domain_a_1_0_0", "domain_b_1_0_0"}, first.Added)
+		assert.Equal(t, []repo.DeployedSchema{{Key: "domain_a_1_0_0", SHA256: "aa"}}, first.Recorded)
+
+		assert.Equal(t, repo.Revision("jsm-deploy/dev/1"), h.Deployments[1].Tag)
+		assert.Equal(t, []Key{"other_x_1_0_0"}, h.Deployments[1].Added)
+
+		second := h.Deployments[2]
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), second.Previous)
+		assert.Equal(t, []Key{"domain_c_1_0_0"}, second.Added)
+		assert.Equal(t, []Key{"domain_a_1_0_0", "domain_b_1_0_0"}, second.Modified)
+		assert.Equal(t, []Key{"domain_b_1_0_0"}, second.Removed)
+
+		assert.False(t, h.Deployments[3].Changed())
+	})
+
+	t.Run("restricted to a key", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		k := Key("domain_c_1_0_0")
+
+		h, err := r.DeploymentHistory(ctx, historyGitter(), []config.Env{"prod"}, &ResolvedTarget{Key: &k})
+		require.NoError(t, err)
+
+		assert.Equal(t, "domain_c_1_0_0", h.Target)
+		require.Len(t, h.Deployments, 1)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/2"), h.Deployments[0].Tag)
+		assert.Equal(t, []Key{"domain_c_1_0_0"}, h.Deployments[0].Added)
+		assert.Empty(t, h.Deployments[0].Modified)
+	})
+
+	t.Run("restricted to a scope", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		scope := SearchScope("other")
+
+		h, err := r.DeploymentHistory(ctx, historyGitter(), []config.Env{"dev", "prod"}, &ResolvedTarget{Scope: &scope})
+		require.NoError(t, err)
+
+		assert.Equal(t, "other", h.Target)
+		require.Len(t, h.Deployments, 1)
+		assert.Equal(t, config.Env("dev"), h.Deployments[0].Env)
+		assert.Empty(t, h.Deployments[0].Recorded)
+	})
+
+	t.Run("listing deployments fails", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		g := &mockGitter{deploymentsFunc: func(context.Context, config.Env) ([]repo.Deployment, error) {
+			return nil, errors.New("no tags")
+		}}
+
+		_, err := r.DeploymentHistory(ctx, g, []config.Env{"prod"}, nil)
+		require.ErrorContains(t, err, "no tags")
+	})
+
+	t.Run("diffing a deployment fails", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		g := historyGitter()
+		g.changesBetweenFunc = func(context.Context, repo.Revision, repo.Revision, string, string) ([]repo.Change, error) {
+			return nil, errors.New("diff failed")
+		}
+
+		_, err := r.DeploymentHistory(ctx, g, []config.Env{"prod"}, nil)
+		require.ErrorContains(t, err, "diff failed")
+	})
+}
+
+func TestKeyFromFilename(t *testing.T) {
+	t.Parallel()
+
+	k, ok := keyFromFilename(filepath.Join("a", "domain_a_1_0_0"+SchemaSuffix))
+	assert.True(t, ok)
+	assert.Equal(t, Key("domain_a_1_0_0"), k)
+
+	for _, path := range []string{"", "a/README.md", "a/not-a-key" + SchemaSuffix} {
+		_, ok = keyFromFilename(path)
+		assert.False(t, ok, path)
+	}
+}
@@ -87,3 +87,10 @@ func (k Key) InScope(ss SearchScope) bool {
 	}
 	return true
 }
+
+// FamilyScope returns the search scope which covers every version in the key's family.
+// e.g. "domain-a_family-a_1_0_0" has the family scope "domain-a/family-a".
+func (k Key) FamilyScope() SearchScope {
+	parts := strings.Split(string(k), KeySeparatorString)
+	return SearchScope(strings.Join(parts[:len(parts)-3], SearchSeparatorString))
+}
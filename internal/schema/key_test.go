@@ -212,3 +212,23 @@ func TestKey_InScope(t *testing.T) {
 		})
 	}
 }
+
+func TestKey_FamilyScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key  Key
+		want SearchScope
+	}{
+		{key: "domain_family_1_0_0", want: "domain/family"},
+		{key: "domain_subdomain_family_2_3_4", want: "domain/subdomain/family"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.key), func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.key.FamilyScope())
+			assert.True(t, tt.key.InScope(tt.key.FamilyScope()))
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// ManifestFile is the name of the file in each environment's dist directory which describes the built schemas.
+const ManifestFile = "manifest.json"
+
+// FamilyIndexFile is the name of the file which lists the available versions of a schema family.
+// It is written to <dist>/<env>/index/<public|private>/<domain(s)>/<family>/ for each family with a built schema.
+const FamilyIndexFile = "index.json"
+
+// FamilyIndexDir is the name of the directory in each environment's dist directory which contains family indexes.
+const FamilyIndexDir = "index"
+
+const (
+	visibilityPublic  = "public"
+	visibilityPrivate = "private"
+)
+
+// Manifest describes every schema written to an environment's dist directory by build-dist,
+// so that deployment tooling does not need to re-derive keys, visibility and IDs.
+type Manifest struct {
+	Env        config.Env      `json:"env"`
+	Schemas    []ManifestEntry `json:"schemas"`
+	Promoted   repo.Revision   `json:"promoted,omitempty"`   // The deployment a promotion distribution is built from
+	RolledBack repo.Revision   `json:"rolledBack,omitempty"` // The deployment undone by a rollback distribution
+	Restored   repo.Revision   `json:"restored,omitempty"`   // The deployment a rollback returns to, if any
+	Removed    []Key           `json:"removed,omitempty"`    // Schemas a rollback removes from the environment
+}
+
+// ManifestEntry describes a single built schema.
+type ManifestEntry struct {
+	Key          Key             `json:"key"`
+	ID           ID              `json:"id"`
+	Visibility   string          `json:"visibility"`
+	SHA256       string          `json:"sha256"`     // The hex encoded digest of the rendered schema
+	DistPath     string          `json:"distPath"`   // Relative to the environment's dist directory
+	SourcePath   string          `json:"sourcePath"` // Relative to the registry root directory
+	Draft        validator.Draft `json:"draft"`
+	Dependencies []Key           `json:"dependencies,omitempty"`
+	Status       LifecycleStatus `json:"status,omitempty"` // The family's status, if recorded in its family.yml
+}
+
+// FamilyIndex lists the versions of a schema family which are available at one visibility.
+type FamilyIndex struct {
+	Family   SearchScope          `json:"family"`
+	Latest   Key                  `json:"latest"`
+	Versions []FamilyIndexVersion `json:"versions"`
+}
+
+// FamilyIndexVersion is a single version in a FamilyIndex.
+type FamilyIndexVersion struct {
+	Version string `json:"version"`
+	Key     Key    `json:"key"`
+	ID      ID     `json:"id"`
+}
+
+// visibility returns the name of the visibility of the schema, which is also the name of the
+// dist subdirectory it is written to.
+func (s *Schema) visibility() string {
+	if s.IsPublic() {
+		return visibilityPublic
+	}
+	return visibilityPrivate
+}
+
+// schemaDraft returns the JSON Schema draft declared by $schema in the rendered schema. If none is
+// declared, the validator's default draft is assumed.
+func schemaDraft(ri RenderInfo) validator.Draft {
+	if m, ok := ri.Unmarshalled.(map[string]any); ok {
+		if d, isStr := m["$schema"].(string); isStr && d != "" {
+			return validator.Draft(d)
+		}
+	}
+	return validator.Draft2020_12
+}
+
+// writeManifest writes the manifest, with its entries sorted by key, to the environment's dist directory.
+func writeManifest(envDir string, m Manifest) error {
+	slices.SortFunc(m.Schemas, func(a, b ManifestEntry) int { return strings.Compare(string(a.Key), string(b.Key)) })
+	if m.Schemas == nil {
+		m.Schemas = []ManifestEntry{}
+	}
+
+	return writeJSONFile(filepath.Join(envDir, ManifestFile), m)
+}
+
+// writeFamilyIndexes writes the index of the family of each of the given keys, listing every version
+// of the family in the registry. Each visibility gets its own index, so that private versions
+// are never listed in a public index.
+func (b *FSDistBuilder) writeFamilyIndexes(envDir string, ec *config.EnvConfig, keys []Key) error {
+	families := make(map[SearchScope]Key)
+	for _, k := range keys {
+		families[k.FamilyScope()] = k
+	}
+
+	for scope, k := range families {
+		indexes, err := b.registry.familyIndexes(k, ec)
+		if err != nil {
+			return err
+		}
+
+		for vis, idx := range indexes {
+			dir := filepath.Join(envDir, FamilyIndexDir, vis, filepath.FromSlash(string(scope)))
+			if mErr := os.MkdirAll(dir, 0o750); mErr != nil {
+				return mErr
+			}
+			if wErr := writeJSONFile(filepath.Join(dir, FamilyIndexFile), idx); wErr != nil {
+				return wErr
+			}
+		}
+	}
+
+	return nil
+}
+
+// familyIndexes returns the indexes of the family of the given key, keyed by visibility.
+func (r *Registry) familyIndexes(k Key, ec *config.EnvConfig) (map[string]*FamilyIndex, error) {
+	keys, err := r.familyKeys(k)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make(map[string]*FamilyIndex)
+	for _, fk := range keys {
+		s, gErr := r.GetSchemaByKey(fk)
+		if gErr != nil {
+			return nil, gErr
+		}
+		idx, ok := indexes[s.visibility()]
+		if !ok {
+			idx = &FamilyIndex{Family: fk.FamilyScope()}
+			indexes[s.visibility()] = idx
+		}
+		idx.Versions = append(idx.Versions, FamilyIndexVersion{
+			Version: fk.Version().String('.'),
+			Key:     fk,
+			ID:      s.CanonicalID(ec),
+		})
+		// Keys are in version order, so the last is the latest.
+		idx.Latest = fk
+	}
+	return indexes, nil
+}
+
+// familyKeys returns the keys of every version in the family of the given key, in version order.
+func (r *Registry) familyKeys(k Key) ([]Key, error) {
+	c := NewCoreFromKey(k)
+	fd := c.Path(FamilyDir, r.rootDirectory)
+
+	majors, err := r.pathResolver.GetUintSubdirectories(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	for _, major := range majors {
+		majorDir := filepath.Join(fd, strconv.FormatUint(major, 10))
+		minors, mErr := r.pathResolver.GetUintSubdirectories(majorDir)
+		if mErr != nil {
+			return nil, mErr
+		}
+		for _, minor := range minors {
+			c.version.Set(major, minor, 0)
+			if keys, err = r.appendPatchKeys(c, keys); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// appendPatchKeys appends the keys of the patch versions of the major and minor version of c to keys.
+func (r *Registry) appendPatchKeys(c *Core, keys []Key) ([]Key, error) {
+	major, minor := c.version.Major(), c.version.Minor()
+	minorDir := filepath.Join(c.Path(FamilyDir, r.rootDirectory),
+		strconv.FormatUint(major, 10), strconv.FormatUint(minor, 10))
+
+	patches, err := r.pathResolver.GetUintSubdirectories(minorDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, patch := range patches {
+		c.version.Set(major, minor, patch)
+		// Version directories without a schema file are not versions of the family.
+		if _, sErr := r.stat(c.Path(FilePath, r.rootDirectory)); sErr == nil {
+			keys = append(keys, c.Key())
+		}
+	}
+	return keys, nil
+}
+
+// writeJSONFile writes v to fp as indented JSON.
+func writeJSONFile(fp string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(fp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", fp, err)
+	}
+	return nil
+}
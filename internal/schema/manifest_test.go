@@ -0,0 +1,206 @@
+package schema
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// readDistJSON unmarshals a JSON file written by the dist builder.
+func readDistJSON(t *testing.T, fp string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v))
+}
+
+// setupManifestTestBuilder creates a dist builder for a registry containing a family with private
+// and public versions, and a schema which depends on it.
+func setupManifestTestBuilder(t *testing.T, g repo.Gitter) (*Registry, DistBuilder, string) {
+	t.Helper()
+	reg := newTestRegistryWithSchema(t)
+	createSchemaFiles(t, reg, schemaMap{
+		"domain_person_1_0_0": `{"$schema": "http://json-schema.org/draft-07/schema#"}`,
+		"domain_person_1_1_0": `{"x-public": true}`,
+		"domain_person_2_0_0": `{}`,
+		"domain_order_1_0_0": `{"allOf": [{"$ref": "{{ JSM "domain_person_1" }}"}, ` +
+			`{"$ref": "{{ JSM "domain_person_1_0_0" }}"}]}`,
+	})
+	cfg, err := reg.Config()
+	require.NoError(t, err)
+
+	builder, err := NewFSDistBuilder(context.Background(), reg, cfg, g, "dist")
+	require.NoError(t, err)
+	return reg, builder, filepath.Join(filepath.Dir(reg.RootDirectory()), "dist", "production")
+}
+
+func TestDistBuilder_Manifest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("every built schema is described", func(t *testing.T) {
+		t.Parallel()
+		_, builder, envDir := setupManifestTestBuilder(t, &mockGitter{})
+
+		count, err := builder.BuildAll(context.Background(), "production")
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+
+		var m Manifest
+		readDistJSON(t, filepath.Join(envDir, ManifestFile), &m)
+		assert.Equal(t, "production", string(m.Env))
+		require.Len(t, m.Schemas, 5)
+
+		keys := make([]Key, 0, len(m.Schemas))
+		for _, e := range m.Schemas {
+			keys = append(keys, e.Key)
+		}
+		assert.Equal(t, []Key{
+			"domain_order_1_0_0", "domain_person_1_0_0", "domain_person_1_1_0", "domain_person_2_0_0", "domain_test_1_0_0",
+		}, keys)
+
+		order := m.Schemas[0]
+		assert.Equal(t, ID("https://example.com/domain_order_1_0_0.schema.json"), order.ID)
+		assert.Equal(t, "private", order.Visibility)
+		assert.Equal(t, "private/domain_order_1_0_0.schema.json", order.DistPath)
+		assert.Equal(t, "domain/order/1/0/0/domain_order_1_0_0.schema.json", order.SourcePath)
+		assert.Equal(t, validator.Draft2020_12, order.Draft)
+		assert.Equal(t, []Key{"domain_person_1_1_0", "domain_person_1_0_0"}, order.Dependencies)
+
+		rendered, err := os.ReadFile(filepath.Join(envDir, filepath.FromSlash(order.DistPath)))
+		require.NoError(t, err)
+		digest := sha256.Sum256(rendered)
+		assert.Equal(t, hex.EncodeToString(digest[:]), order.SHA256)
+
+		assert.Equal(t, validator.Draft7, m.Schemas[1].Draft)
+		assert.Equal(t, "public", m.Schemas[2].Visibility)
+		assert.Equal(t, "public/domain_person_1_1_0.schema.json", m.Schemas[2].DistPath)
+		assert.Empty(t, m.Schemas[2].Dependencies)
+	})
+
+	t.Run("family status is described", func(t *testing.T) {
+		t.Parallel()
+		reg, builder, envDir := setupManifestTestBuilder(t, &mockGitter{})
+		require.NoError(t, os.WriteFile(
+			filepath.Join(reg.RootDirectory(), "domain", "person", FamilyMetadataFile),
+			[]byte("owner: people\nstatus: experimental\n"),
+			0o600,
+		))
+
+		_, err := builder.BuildAll(context.Background(), "production")
+		require.NoError(t, err)
+
+		var m Manifest
+		readDistJSON(t, filepath.Join(envDir, ManifestFile), &m)
+		assert.Empty(t, m.Schemas[0].Status)
+		assert.Equal(t, StatusExperimental, m.Schemas[1].Status)
+	})
+
+	t.Run("only changed schemas are described", func(t *testing.T) {
+		t.Parallel()
+		var reg *Registry
+		gitter := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+				if suffix == PartialSuffix {
+					return nil, nil
+				}
+				return []repo.Change{{Path: New("domain_person_2_0_0", reg).Path(FilePath)}}, nil
+			},
+		}
+		reg, builder, envDir := setupManifestTestBuilder(t, gitter)
+
+		_, err := builder.BuildChanged(context.Background(), "production", "HEAD")
+		require.NoError(t, err)
+
+		var m Manifest
+		readDistJSON(t, filepath.Join(envDir, ManifestFile), &m)
+		require.Len(t, m.Schemas, 1)
+		assert.Equal(t, Key("domain_person_2_0_0"), m.Schemas[0].Key)
+	})
+
+	t.Run("nothing changed", func(t *testing.T) {
+		t.Parallel()
+		_, builder, envDir := setupManifestTestBuilder(t, &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+				return nil, nil
+			},
+		})
+
+		_, err := builder.BuildChanged(context.Background(), "production", "HEAD")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(envDir, ManifestFile))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"schemas": []`)
+	})
+}
+
+func TestDistBuilder_FamilyIndexes(t *testing.T) {
+	t.Parallel()
+
+	_, builder, envDir := setupManifestTestBuilder(t, &mockGitter{})
+	_, err := builder.BuildAll(context.Background(), "production")
+	require.NoError(t, err)
+
+	var private FamilyIndex
+	readDistJSON(t, filepath.Join(envDir, FamilyIndexDir, "private", "domain", "person", FamilyIndexFile), &private)
+	assert.Equal(t, FamilyIndex{
+		Family: "domain/person",
+		Latest: "domain_person_2_0_0",
+		Versions: []FamilyIndexVersion{
+			{Version: "1.0.0", Key: "domain_person_1_0_0", ID: "https://example.com/domain_person_1_0_0.schema.json"},
+			{Version: "2.0.0", Key: "domain_person_2_0_0", ID: "https://example.com/domain_person_2_0_0.schema.json"},
+		},
+	}, private)
+
+	// Private versions are never listed in a public index.
+	var public FamilyIndex
+	readDistJSON(t, filepath.Join(envDir, FamilyIndexDir, "public", "domain", "person", FamilyIndexFile), &public)
+	assert.Equal(t, Key("domain_person_1_1_0"), public.Latest)
+	assert.Len(t, public.Versions, 1)
+
+	assert.FileExists(t, filepath.Join(envDir, FamilyIndexDir, "private", "domain", "order", FamilyIndexFile))
+	assert.NoFileExists(t, filepath.Join(envDir, FamilyIndexDir, "public", "domain", "order", FamilyIndexFile))
+}
+
+func TestRegistry_familyKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("versions without a schema file are skipped", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{"domain_family_1_0_0": "{}", "domain_family_1_10_0": "{}"})
+		require.NoError(t, os.MkdirAll(filepath.Join(r.RootDirectory(), "domain", "family", "1", "2", "0"), 0o755))
+
+		keys, err := r.familyKeys("domain_family_1_0_0")
+		require.NoError(t, err)
+		assert.Equal(t, []Key{"domain_family_1_0_0", "domain_family_1_10_0"}, keys)
+	})
+
+	t.Run("family does not exist", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+
+		_, err := r.familyKeys("domain_missing_1_0_0")
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestWriteJSONFile_Error(t *testing.T) {
+	t.Parallel()
+
+	err := writeJSONFile(filepath.Join(t.TempDir(), "missing", ManifestFile), Manifest{})
+	require.ErrorContains(t, err, "failed to write")
+
+	err = writeJSONFile(filepath.Join(t.TempDir(), ManifestFile), func() {})
+	require.Error(t, err)
+}
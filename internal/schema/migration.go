@@ -0,0 +1,340 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MigrationsDir is the name of the optional directory in a schema home directory which contains migrations
+// from the latest version of the previous major version of the family.
+const MigrationsDir = "migrations"
+
+// MigrationOp is the type of a single transformation in a migration.
+type MigrationOp string
+
+const (
+	// MigrationOpRename renames the property at Path to the property name To, keeping it in the same object.
+	MigrationOpRename MigrationOp = "rename"
+	// MigrationOpMove moves the value at From to Path.
+	MigrationOpMove MigrationOp = "move"
+	// MigrationOpDelete removes the property at Path.
+	MigrationOpDelete MigrationOp = "delete"
+	// MigrationOpDefault sets the property at Path to Value if the property is not already present.
+	MigrationOpDefault MigrationOp = "default"
+)
+
+// MigrationOperation is a single declarative transformation of a JSON document.
+// Paths are JSON Pointers (RFC 6901) - e.g. "/customer/firstName".
+type MigrationOperation struct {
+	Op    MigrationOp `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	To    string      `json:"to,omitempty"`
+	Value any         `json:"value,omitempty"`
+}
+
+// Migration describes how to transform a document which is valid against the latest version of the
+// previous major version of a schema family into a document which is valid against a schema.
+// Migrations are read from YAML files in a schema's migrations directory, e.g.:
+//
+//	description: Split name into given and family names
+//	operations:
+//	  - op: rename
+//	    path: /name
+//	    to: familyName
+//	  - op: default
+//	    path: /givenNames
+//	    value: []
+type Migration struct {
+	Path        string               `json:"-"` // The path of the migration file
+	Description string               `json:"description,omitempty"`
+	Operations  []MigrationOperation `json:"operations"`
+}
+
+// parseMigration parses and checks the content of the migration file at fp.
+func parseMigration(fp string, data []byte) (Migration, error) {
+	// Round-trip the YAML through JSON so that default values have the same types
+	// as values in unmarshalled JSON test documents.
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return Migration{}, &InvalidMigrationError{Path: fp, Reason: err.Error()}
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return Migration{}, &InvalidMigrationError{Path: fp, Reason: err.Error()}
+	}
+
+	m := Migration{Path: fp}
+	dec := json.NewDecoder(strings.NewReader(string(jsonData)))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&m); err != nil {
+		return Migration{}, &InvalidMigrationError{Path: fp, Reason: err.Error()}
+	}
+
+	if len(m.Operations) == 0 {
+		return Migration{}, &InvalidMigrationError{Path: fp, Reason: "no operations are defined"}
+	}
+	for i, op := range m.Operations {
+		if reason := op.check(); reason != "" {
+			return Migration{}, &InvalidMigrationError{Path: fp, Reason: fmt.Sprintf("operation %d: %s", i+1, reason)}
+		}
+	}
+
+	return m, nil
+}
+
+// check returns a description of the problem with the operation, or an empty string if it is well-formed.
+func (o *MigrationOperation) check() string {
+	if _, err := parsePointer(o.Path); err != nil || o.Path == "" {
+		return fmt.Sprintf("path '%s' must be a JSON Pointer to a property", o.Path)
+	}
+
+	switch o.Op {
+	case MigrationOpRename:
+		if o.To == "" {
+			return "rename requires 'to'"
+		}
+	case MigrationOpMove:
+		if _, err := parsePointer(o.From); err != nil || o.From == "" {
+			return fmt.Sprintf("move requires 'from' to be a JSON Pointer to a property, got '%s'", o.From)
+		}
+	case MigrationOpDelete:
+	case MigrationOpDefault:
+		if o.Value == nil {
+			return "default requires 'value'"
+		}
+	default:
+		return fmt.Sprintf("unknown op '%s' - must be one of rename, move, delete, default", o.Op)
+	}
+
+	return ""
+}
+
+// Apply returns a transformed copy of doc. The original document is not modified.
+// Operations whose source property is absent from the document are skipped, as documents
+// will often omit optional properties.
+func (m *Migration) Apply(doc any) (any, error) {
+	out := deepCopyJSON(doc)
+
+	for i, op := range m.Operations {
+		if err := op.apply(out); err != nil {
+			return nil, &MigrationFailedError{MigrationPath: m.Path, Operation: i + 1, Op: op.Op, Wrapped: err}
+		}
+	}
+
+	return out, nil
+}
+
+// apply transforms doc in place.
+func (o *MigrationOperation) apply(doc any) error {
+	tokens, _ := parsePointer(o.Path)
+
+	switch o.Op {
+	case MigrationOpRename:
+		v, ok, err := removeAt(doc, tokens)
+		if err != nil || !ok {
+			return err
+		}
+		renamed := append(slices.Clone(tokens[:len(tokens)-1]), o.To)
+		return setAt(doc, renamed, v)
+	case MigrationOpMove:
+		from, _ := parsePointer(o.From)
+		v, ok, err := removeAt(doc, from)
+		if err != nil || !ok {
+			return err
+		}
+		return setAt(doc, tokens, v)
+	case MigrationOpDelete:
+		_, _, err := removeAt(doc, tokens)
+		return err
+	case MigrationOpDefault:
+		parent, ok, err := resolve(doc, tokens[:len(tokens)-1])
+		if err != nil || !ok {
+			return err
+		}
+		obj, isObj := parent.(map[string]any)
+		if !isObj {
+			return fmt.Errorf("parent of '%s' is not an object", o.Path)
+		}
+		if _, exists := obj[tokens[len(tokens)-1]]; !exists {
+			obj[tokens[len(tokens)-1]] = deepCopyJSON(o.Value)
+		}
+	}
+
+	return nil
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("JSON Pointer '%s' must start with '/'", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// resolve returns the value in doc identified by tokens. ok is false if the value is absent.
+func resolve(doc any, tokens []string) (v any, ok bool, err error) {
+	v = doc
+	for _, t := range tokens {
+		switch node := v.(type) {
+		case map[string]any:
+			if v, ok = node[t]; !ok {
+				return nil, false, nil
+			}
+		case []any:
+			idx, iErr := strconv.Atoi(t)
+			if iErr != nil || idx < 0 {
+				return nil, false, fmt.Errorf("'%s' is not a valid array index", t)
+			}
+			if idx >= len(node) {
+				return nil, false, nil
+			}
+			v = node[idx]
+		default:
+			return nil, false, fmt.Errorf("cannot resolve '%s' in a value which is not an object or array", t)
+		}
+	}
+	return v, true, nil
+}
+
+// removeAt removes and returns the object property identified by tokens. ok is false if it is absent.
+func removeAt(doc any, tokens []string) (v any, ok bool, err error) {
+	parent, ok, err := resolve(doc, tokens[:len(tokens)-1])
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	obj, isObj := parent.(map[string]any)
+	if !isObj {
+		return nil, false, fmt.Errorf("parent of '%s' is not an object", tokens[len(tokens)-1])
+	}
+	name := tokens[len(tokens)-1]
+	if v, ok = obj[name]; ok {
+		delete(obj, name)
+	}
+	return v, ok, nil
+}
+
+// setAt sets the object property identified by tokens. The parent object must already exist.
+func setAt(doc any, tokens []string, v any) error {
+	parent, ok, err := resolve(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+	obj, isObj := parent.(map[string]any)
+	if !ok || !isObj {
+		return fmt.Errorf("target parent '/%s' is not an object in the document",
+			strings.Join(tokens[:len(tokens)-1], "/"))
+	}
+	obj[tokens[len(tokens)-1]] = v
+	return nil
+}
+
+// deepCopyJSON copies an unmarshalled JSON value so it can be modified without side effects.
+func deepCopyJSON(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(node))
+		for k, e := range node {
+			c[k] = deepCopyJSON(e)
+		}
+		return c
+	case []any:
+		c := make([]any, len(node))
+		for i, e := range node {
+			c[i] = deepCopyJSON(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// Migrations returns the migrations defined in the schema's migrations directory, in filename order.
+// If the schema has no migrations directory, nil is returned. Migrations are read from the registry
+// filesystem, so a registry at a git revision has the migrations of that revision.
+func (s *Schema) Migrations() ([]Migration, error) {
+	dir := filepath.Join(s.Path(HomeDir), MigrationsDir)
+
+	entries, err := s.registry.readDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		fp := filepath.Join(dir, entry.Name())
+		data, rErr := s.registry.readFile(fp)
+		if rErr != nil {
+			return nil, rErr
+		}
+		m, mErr := parseMigration(fp, data)
+		if mErr != nil {
+			return nil, mErr
+		}
+		migrations = append(migrations, m)
+	}
+
+	return migrations, nil
+}
+
+// PreviousMajorLatestSchema identifies the latest version of the previous major version in the schema's family.
+// e.g. for 3.1.0 in a family also containing 1.0.0, 2.0.0, 2.1.0 and 2.1.1, it returns the key for 2.1.1.
+// ok is false if there is no earlier major version.
+func (s *Schema) PreviousMajorLatestSchema() (k Key, ok bool, err error) {
+	fd := s.Path(FamilyDir)
+	pr := s.registry.pathResolver
+
+	majors, err := pr.GetUintSubdirectories(fd)
+	if err != nil {
+		return "", false, err
+	}
+
+	var prev []uint64
+	for _, m := range majors {
+		if m < s.core.version.Major() {
+			prev = append(prev, m)
+		}
+	}
+	if len(prev) == 0 {
+		return "", false, nil
+	}
+	major := slices.Max(prev)
+
+	majorDir := filepath.Join(fd, strconv.FormatUint(major, 10))
+	minors, err := pr.GetUintSubdirectories(majorDir)
+	if err != nil || len(minors) == 0 {
+		return "", false, err
+	}
+	minor := slices.Max(minors)
+
+	patches, err := pr.GetUintSubdirectories(filepath.Join(majorDir, strconv.FormatUint(minor, 10)))
+	if err != nil || len(patches) == 0 {
+		return "", false, err
+	}
+
+	c := Core{domain: s.core.domain, familyName: s.core.familyName}
+	c.version.Set(major, minor, slices.Max(patches))
+	return c.Key(), true, nil
+}
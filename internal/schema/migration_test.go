@@ -0,0 +1,327 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigration writes a migration file into the migrations directory of the schema with the given key.
+func writeMigration(t *testing.T, r *Registry, k Key, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(New(k, r).Path(HomeDir), MigrationsDir)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	fp := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(fp, []byte(content), 0o600))
+	return fp
+}
+
+func unmarshalDoc(t *testing.T, s string) any {
+	t.Helper()
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(s), &doc))
+	return doc
+}
+
+func TestParseMigration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid migration", func(t *testing.T) {
+		t.Parallel()
+		fp := filepath.Join("migrations", "from-1.yml")
+		m, err := parseMigration(fp, []byte(`
+description: Restructure the name
+operations:
+  - op: rename
+    path: /name
+    to: familyName
+  - op: move
+    from: /address/town
+    path: /town
+  - op: delete
+    path: /legacyId
+  - op: default
+    path: /givenNames
+    value: []
+`))
+		require.NoError(t, err)
+		assert.Equal(t, fp, m.Path)
+		assert.Equal(t, "Restructure the name", m.Description)
+		require.Len(t, m.Operations, 4)
+		assert.Equal(t, MigrationOperation{Op: MigrationOpRename, Path: "/name", To: "familyName"}, m.Operations[0])
+		assert.Equal(t, MigrationOperation{Op: MigrationOpMove, Path: "/town", From: "/address/town"}, m.Operations[1])
+		assert.Equal(t, MigrationOperation{Op: MigrationOpDelete, Path: "/legacyId"}, m.Operations[2])
+		assert.Equal(t, []any{}, m.Operations[3].Value)
+	})
+
+	invalid := []struct {
+		name    string
+		content string
+	}{
+		{name: "invalid YAML", content: "operations: [unclosed"},
+		{name: "unknown field", content: "operations: [{op: delete, path: /a}]\nauthor: me\n"},
+		{name: "no operations", content: "description: nothing\n"},
+		{name: "unknown op", content: "operations: [{op: copy, path: /a}]\n"},
+		{name: "missing path", content: "operations: [{op: delete}]\n"},
+		{name: "path is not a pointer", content: "operations: [{op: delete, path: a}]\n"},
+		{name: "rename without to", content: "operations: [{op: rename, path: /a}]\n"},
+		{name: "move without from", content: "operations: [{op: move, path: /a}]\n"},
+		{name: "move from is not a pointer", content: "operations: [{op: move, from: b, path: /a}]\n"},
+		{name: "default without value", content: "operations: [{op: default, path: /a}]\n"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fp := filepath.Join("migrations", "m.yml")
+			_, err := parseMigration(fp, []byte(tt.content))
+			var target *InvalidMigrationError
+			require.ErrorAs(t, err, &target)
+			assert.Equal(t, fp, target.Path)
+		})
+	}
+}
+
+func TestMigration_Apply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ops  []MigrationOperation
+		doc  string
+		want string
+	}{
+		{
+			name: "rename",
+			ops:  []MigrationOperation{{Op: MigrationOpRename, Path: "/person/name", To: "familyName"}},
+			doc:  `{"person": {"name": "Smith"}}`,
+			want: `{"person": {"familyName": "Smith"}}`,
+		},
+		{
+			name: "move",
+			ops:  []MigrationOperation{{Op: MigrationOpMove, From: "/address/town", Path: "/town"}},
+			doc:  `{"address": {"town": "Leeds"}}`,
+			want: `{"address": {}, "town": "Leeds"}`,
+		},
+		{
+			name: "delete",
+			ops:  []MigrationOperation{{Op: MigrationOpDelete, Path: "/legacyId"}},
+			doc:  `{"legacyId": 1, "id": 2}`,
+			want: `{"id": 2}`,
+		},
+		{
+			name: "default when absent",
+			ops:  []MigrationOperation{{Op: MigrationOpDefault, Path: "/tags", Value: []any{"new"}}},
+			doc:  `{}`,
+			want: `{"tags": ["new"]}`,
+		},
+		{
+			name: "default when present",
+			ops:  []MigrationOperation{{Op: MigrationOpDefault, Path: "/tags", Value: []any{"new"}}},
+			doc:  `{"tags": []}`,
+			want: `{"tags": []}`,
+		},
+		{
+			name: "absent properties are skipped",
+			ops: []MigrationOperation{
+				{Op: MigrationOpRename, Path: "/name", To: "familyName"},
+				{Op: MigrationOpMove, From: "/a/b", Path: "/b"},
+				{Op: MigrationOpDelete, Path: "/legacyId"},
+				{Op: MigrationOpDefault, Path: "/missing/tags", Value: true},
+			},
+			doc:  `{"id": 1}`,
+			want: `{"id": 1}`,
+		},
+		{
+			name: "array indices and escaped tokens",
+			ops:  []MigrationOperation{{Op: MigrationOpRename, Path: "/items/1/a~1b", To: "ab"}},
+			doc:  `{"items": [{"a/b": 1}, {"a/b": 2}]}`,
+			want: `{"items": [{"a/b": 1}, {"ab": 2}]}`,
+		},
+		{
+			name: "operations are applied in order",
+			ops: []MigrationOperation{
+				{Op: MigrationOpRename, Path: "/name", To: "fullName"},
+				{Op: MigrationOpMove, From: "/fullName", Path: "/person/fullName"},
+			},
+			doc:  `{"name": "Jo Smith", "person": {}}`,
+			want: `{"person": {"fullName": "Jo Smith"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := Migration{Path: "m.yml", Operations: tt.ops}
+			doc := unmarshalDoc(t, tt.doc)
+
+			got, err := m.Apply(doc)
+			require.NoError(t, err)
+			assert.Equal(t, unmarshalDoc(t, tt.want), got)
+			// The original document is untouched
+			assert.Equal(t, unmarshalDoc(t, tt.doc), doc)
+		})
+	}
+}
+
+func TestMigration_Apply_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		op   MigrationOperation
+		doc  string
+	}{
+		{
+			name: "path through a scalar",
+			op:   MigrationOperation{Op: MigrationOpDelete, Path: "/name/first"},
+			doc:  `{"name": "Smith"}`,
+		},
+		{
+			name: "invalid array index",
+			op:   MigrationOperation{Op: MigrationOpDelete, Path: "/items/x/a"},
+			doc:  `{"items": [{}]}`,
+		},
+		{
+			name: "removing from an array",
+			op:   MigrationOperation{Op: MigrationOpDelete, Path: "/items/0"},
+			doc:  `{"items": [1]}`,
+		},
+		{
+			name: "move to a missing parent",
+			op:   MigrationOperation{Op: MigrationOpMove, From: "/a", Path: "/b/a"},
+			doc:  `{"a": 1}`,
+		},
+		{
+			name: "default in a non-object",
+			op:   MigrationOperation{Op: MigrationOpDefault, Path: "/items/x", Value: 1.0},
+			doc:  `{"items": []}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := Migration{Path: "m.yml", Operations: []MigrationOperation{tt.op}}
+
+			_, err := m.Apply(unmarshalDoc(t, tt.doc))
+			var target *MigrationFailedError
+			require.ErrorAs(t, err, &target)
+			assert.Equal(t, "m.yml", target.MigrationPath)
+			assert.Equal(t, 1, target.Operation)
+			assert.Equal(t, tt.op.Op, target.Op)
+		})
+	}
+}
+
+func TestSchema_Migrations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no migrations directory", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		k := Key("domain_family_2_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+
+		m, err := New(k, r).Migrations()
+		require.NoError(t, err)
+		assert.Nil(t, m)
+	})
+
+	t.Run("migrations are loaded in filename order", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		k := Key("domain_family_2_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+		second := writeMigration(t, r, k, "02-tags.yaml", "operations: [{op: delete, path: /tags}]\n")
+		first := writeMigration(t, r, k, "01-name.yml", "operations: [{op: delete, path: /name}]\n")
+		writeMigration(t, r, k, "README.md", "Not a migration")
+		require.NoError(t, os.Mkdir(filepath.Join(filepath.Dir(first), "sub.yml"), 0o755))
+
+		m, err := New(k, r).Migrations()
+		require.NoError(t, err)
+		require.Len(t, m, 2)
+		assert.Equal(t, first, m[0].Path)
+		assert.Equal(t, second, m[1].Path)
+	})
+
+	t.Run("invalid migration", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		k := Key("domain_family_2_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+		writeMigration(t, r, k, "bad.yml", "operations: []\n")
+
+		_, err := New(k, r).Migrations()
+		require.ErrorAs(t, err, new(*InvalidMigrationError))
+	})
+
+	t.Run("migrations path is not a directory", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		k := Key("domain_family_2_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+		require.NoError(t, os.WriteFile(filepath.Join(New(k, r).Path(HomeDir), MigrationsDir), nil, 0o600))
+
+		_, err := New(k, r).Migrations()
+		require.Error(t, err)
+	})
+}
+
+func TestSchema_PreviousMajorLatestSchema(t *testing.T) {
+	t.Parallel()
+
+	r := setupTestRegistry(t)
+	createSchemaFiles(t, r, schemaMap{
+		"domain_family_1_0_0": "{}",
+		"domain_family_1_2_0": "{}",
+		"domain_family_2_0_0": "{}",
+		"domain_family_2_1_0": "{}",
+		"domain_family_2_1_3": "{}",
+		"domain_family_4_0_0": "{}",
+	})
+
+	tests := []struct {
+		key    Key
+		want   Key
+		wantOK bool
+	}{
+		{key: "domain_family_1_2_0", wantOK: false},
+		{key: "domain_family_2_0_0", want: "domain_family_1_2_0", wantOK: true},
+		{key: "domain_family_4_0_0", want: "domain_family_2_1_3", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.key), func(t *testing.T) {
+			t.Parallel()
+			got, ok, err := New(tt.key, r).PreviousMajorLatestSchema()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("family directory cannot be read", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+
+		_, _, err := New("domain_missing_2_0_0", r).PreviousMajorLatestSchema()
+		require.Error(t, err)
+	})
+
+	t.Run("previous major has no versions", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		k := Key("domain_family_2_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+		require.NoError(t, os.MkdirAll(filepath.Join(New(k, r).Path(FamilyDir), "1"), 0o755))
+
+		_, ok, err := New(k, r).PreviousMajorLatestSchema()
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
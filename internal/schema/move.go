@@ -0,0 +1,342 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+// FamilyMove is the move of a schema family to another domain or family name.
+type FamilyMove struct {
+	From      SearchScope
+	To        SearchScope
+	Keys      map[Key]Key // The key of each version at the new location, by its key at the old location
+	Rewritten []string    // The sorted paths of the schema files and partials whose references were rewritten
+	Copied    bool        // Whether the versions at the old location were left in place
+
+	fromDir  string
+	toDir    string
+	rewrites map[string][]byte // The rewritten content of each file, by its path once the family has moved
+	partials []string          // The names of the partials which were rewritten
+}
+
+// MoveFamily moves the schema family from, given as [domain(s)]/[family], to the family to. The version
+// directories and family.yml file of the family are relocated, every schema file is renamed to its new key, and
+// the {{ JSM }} and {{ JSMRef }} references to the family in every schema and partial in the registry are
+// rewritten.
+//
+// Moving a schema which has been deployed to an environment which does not allow schema mutation would
+// remove it from that environment, as would rewriting a deployed schema which references the family, so the
+// move is refused. If copyFamily is true, the family is copied to its new location instead, creating new
+// versions there, and only the references within the copies are rewritten.
+func (r *Registry) MoveFamily(
+	ctx context.Context,
+	g repo.Gitter,
+	from, to string,
+	copyFamily bool,
+) (*FamilyMove, error) {
+	m, err := r.planFamilyMove(ctx, from, to, copyFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	if !copyFamily {
+		if err = r.checkMoveDeployments(ctx, g, m); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = m.apply(r); err != nil {
+		return nil, err
+	}
+	r.Reset()
+	return m, nil
+}
+
+// familyCore returns the core of version 1.0.0 of the family given as [domain(s)]/[family].
+func familyCore(arg string) (*Core, error) {
+	if _, err := NewSearchScope(arg); err != nil {
+		return nil, &InvalidFamilyArgError{Arg: arg}
+	}
+	c, err := NewCoreFromString(arg+"/1/0/0", '/')
+	if err != nil {
+		return nil, &InvalidFamilyArgError{Arg: arg}
+	}
+	return c, nil
+}
+
+// familyKeyPrefix returns the part of the keys of the core's family which precedes the version.
+func familyKeyPrefix(c *Core) string {
+	return strings.Join(append(slices.Clone(c.domain), c.familyName), KeySeparatorString)
+}
+
+// planFamilyMove works out the keys of the versions of the family at the new location, and the rewritten
+// content of the files which reference the family, without changing any files.
+func (r *Registry) planFamilyMove(ctx context.Context, from, to string, copyFamily bool) (*FamilyMove, error) {
+	fromCore, err := familyCore(from)
+	if err != nil {
+		return nil, err
+	}
+	toCore, err := familyCore(to)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &FamilyMove{
+		From:     fromCore.Key().FamilyScope(),
+		To:       toCore.Key().FamilyScope(),
+		Keys:     make(map[Key]Key),
+		Copied:   copyFamily,
+		fromDir:  fromCore.Path(FamilyDir, r.rootDirectory),
+		toDir:    toCore.Path(FamilyDir, r.rootDirectory),
+		rewrites: make(map[string][]byte),
+	}
+	if _, err = r.stat(m.toDir); err == nil {
+		return nil, &FamilyExistsError{Family: m.To}
+	}
+
+	fromPrefix, toPrefix := familyKeyPrefix(fromCore), familyKeyPrefix(toCore)
+	all, err := r.allKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range all {
+		if k.FamilyScope() == m.From {
+			m.Keys[k] = Key(toPrefix + strings.TrimPrefix(string(k), fromPrefix))
+		}
+	}
+	if len(m.Keys) == 0 {
+		return nil, &NotFoundError{Path: m.fromDir}
+	}
+
+	// The copies of a family reference each other, but the rest of the registry still references the original.
+	rewrite := familyReferenceRewriter(fromPrefix, toPrefix)
+	referencing := all
+	if copyFamily {
+		referencing = slices.Collect(maps.Keys(m.Keys))
+	}
+	for _, k := range referencing {
+		if err = m.addRewrite(r, New(k, r).Path(FilePath), m.newPath(r, k), rewrite); err != nil {
+			return nil, err
+		}
+	}
+
+	if !copyFamily {
+		if err = m.addPartialRewrites(r, rewrite); err != nil {
+			return nil, err
+		}
+	}
+
+	m.Rewritten = slices.Sorted(maps.Keys(m.rewrites))
+	return m, nil
+}
+
+// allKeys returns the keys of every schema in the registry.
+func (r *Registry) allKeys(ctx context.Context) ([]Key, error) {
+	searcher, err := NewSearcher(r, "")
+	if err != nil {
+		return nil, err
+	}
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var keys []Key
+	for res := range searcher.Schemas(searchCtx) {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		keys = append(keys, res.Key)
+	}
+	return keys, ctx.Err()
+}
+
+// newPath returns the path of the schema file with the key once the family has moved.
+func (m *FamilyMove) newPath(r *Registry, k Key) string {
+	if nk, ok := m.Keys[k]; ok {
+		return New(nk, r).Path(FilePath)
+	}
+	return New(k, r).Path(FilePath)
+}
+
+// addRewrite records the rewritten content of the file at path, to be written to newPath, if it references
+// the family.
+func (m *FamilyMove) addRewrite(r *Registry, path, newPath string, rewrite func([]byte) []byte) error {
+	data, err := r.readFile(path)
+	if err != nil {
+		return err
+	}
+	if rewritten := rewrite(data); string(rewritten) != string(data) {
+		m.rewrites[newPath] = rewritten
+	}
+	return nil
+}
+
+// addPartialRewrites records the rewritten content of the partials which reference the family.
+func (m *FamilyMove) addPartialRewrites(r *Registry, rewrite func([]byte) []byte) error {
+	dir := filepath.Join(r.rootDirectory, PartialsDir)
+	entries, err := r.readDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		name, ok := r.PartialNameFromPath(path)
+		if e.IsDir() || !ok {
+			continue
+		}
+		if err = m.addRewrite(r, path, path, rewrite); err != nil {
+			return err
+		}
+		if _, rewritten := m.rewrites[path]; rewritten {
+			m.partials = append(m.partials, name)
+		}
+	}
+	return nil
+}
+
+// familyReferenceRewriter returns a function which rewrites the {{ JSM }} and {{ JSMRef }} references to keys
+// and version ranges with the fromPrefix, so that they have the toPrefix instead. Keys may be double-quoted or
+// backquoted.
+func familyReferenceRewriter(fromPrefix, toPrefix string) func([]byte) []byte {
+	re := regexp.MustCompile("(\\bJSM(?:Ref)?\\s+[\"`])" + regexp.QuoteMeta(fromPrefix) + "((?:_[0-9]+){1,3}[\"`])")
+	return func(data []byte) []byte {
+		return re.ReplaceAll(data, []byte("${1}"+toPrefix+"${2}"))
+	}
+}
+
+// checkMoveDeployments returns a DeployedFamilyMoveError if the move would remove or change a schema which has
+// been deployed to an environment which does not allow schema mutation.
+func (r *Registry) checkMoveDeployments(ctx context.Context, g repo.Gitter, m *FamilyMove) error {
+	affected := slices.Collect(maps.Keys(m.Keys))
+	newKeys := slices.Collect(maps.Values(m.Keys))
+	for _, path := range m.Rewritten {
+		if k, ok := keyFromFilename(path); ok && !slices.Contains(newKeys, k) {
+			affected = appendUnique(affected, k)
+		}
+	}
+	for _, name := range m.partials {
+		dependents, err := r.PartialDependents(ctx, name)
+		if err != nil {
+			return err
+		}
+		for _, k := range dependents {
+			affected = appendUnique(affected, k)
+		}
+	}
+	slices.Sort(affected)
+
+	for _, env := range slices.Sorted(maps.Keys(r.config.Environments)) {
+		if r.config.Environments[env].AllowSchemaMutation {
+			continue
+		}
+		deployed, err := r.deployedKeys(ctx, g, env, affected)
+		if err != nil {
+			return err
+		}
+		if len(deployed) > 0 {
+			return &DeployedFamilyMoveError{Env: env, Keys: deployed}
+		}
+	}
+	return nil
+}
+
+// deployedKeys returns those of the keys whose schemas were in the registry at the latest deployment to the
+// environment.
+func (r *Registry) deployedKeys(ctx context.Context, g repo.Gitter, env config.Env, keys []Key) ([]Key, error) {
+	anchor, err := latestDeployment(ctx, g, env)
+	if err != nil || anchor == "" {
+		return nil, err
+	}
+	atAnchor, err := r.atRevision(ctx, g, anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	var deployed []Key
+	for _, k := range keys {
+		if _, sErr := atAnchor.stat(New(k, atAnchor).Path(FilePath)); sErr == nil {
+			deployed = append(deployed, k)
+		}
+	}
+	return deployed, nil
+}
+
+// apply moves or copies the family to its new location, renames its schema files, and writes the rewritten
+// files.
+func (m *FamilyMove) apply(r *Registry) error {
+	entries, err := os.ReadDir(m.fromDir)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(m.toDir, 0o750); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.Name() != FamilyMetadataFile && !(e.IsDir() && isVersionNumber(e.Name())) {
+			// Other directories hold the families of a subdomain with the same name as the family.
+			continue
+		}
+		from, to := filepath.Join(m.fromDir, e.Name()), filepath.Join(m.toDir, e.Name())
+		if err = m.relocate(from, to, e.IsDir()); err != nil {
+			return err
+		}
+	}
+
+	for k, nk := range m.Keys {
+		moved := filepath.Join(New(nk, r).Path(HomeDir), string(k)+SchemaSuffix)
+		if err = os.Rename(moved, New(nk, r).Path(FilePath)); err != nil {
+			return err
+		}
+	}
+
+	for path, data := range m.rewrites {
+		if err = os.WriteFile(path, data, 0o600); err != nil {
+			return err
+		}
+	}
+
+	if !m.Copied {
+		removeEmptyDirs(m.fromDir, r.rootDirectory)
+	}
+	return nil
+}
+
+// relocate moves the file or directory at from to to, or copies it if the family is being copied.
+func (m *FamilyMove) relocate(from, to string, isDir bool) error {
+	switch {
+	case !m.Copied:
+		return os.Rename(from, to)
+	case isDir:
+		return os.CopyFS(to, os.DirFS(from))
+	default:
+		data, err := os.ReadFile(from) //nolint:gosec // Path is constructed from internal registry logic
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(to, data, 0o600)
+	}
+}
+
+// removeEmptyDirs removes dir, and then each of its parents below root, for as long as they are empty.
+func removeEmptyDirs(dir, root string) {
+	for ; dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
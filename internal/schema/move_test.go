@@ -0,0 +1,202 @@
+package schema
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+func TestRegistry_MoveFamily(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// newRegistry creates a registry holding the old/family family, a subdomain of the same name, and schemas
+	// and a partial which reference the family.
+	newRegistry := func(t *testing.T) *Registry {
+		t.Helper()
+		r := setupPromotionRegistry(t, schemaMap{
+			"old_family_1_0_0":     `{"$id": "{{ ID }}"}`,
+			"old_family_1_1_0":     `{"$id": "{{ ID }}", "allOf": [{"$ref": "{{ JSM "old_family_1_0_0" }}"}]}`,
+			"old_family_sub_1_0_0": `{"$id": "{{ ID }}"}`,
+			"other_user_1_0_0": `{"properties": {
+				"a": {"$ref": "{{ JSM "old_family_1" }}"},
+				"b": {"$ref": "{{ JSMRef "old_family_1_0_0" "#/x" }}"},
+				"c": {"$ref": "{{ JSM "old_family-x_1_0_0" }}"}}}`,
+		})
+		root := r.RootDirectory()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "old", "family", FamilyMetadataFile),
+			[]byte("owner: team-a\n"), 0o600))
+		require.NoError(t, os.MkdirAll(filepath.Join(root, PartialsDir), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, PartialsDir, "family"+PartialSuffix),
+			[]byte(`{"$ref": "{{ JSM "old_family_1_1_0" }}"}`), 0o600))
+		return r
+	}
+
+	// deployedGitter returns a gitter with a deployment to each environment, at which the registry was as it is
+	// in dir.
+	deployedGitter := func(dir string) *mockGitter {
+		return &mockGitter{
+			deploymentsFunc: func(_ context.Context, _ config.Env) ([]repo.Deployment, error) {
+				return make([]repo.Deployment, 1), nil
+			},
+			revisionFSFunc: func(_ context.Context, _ repo.Revision, _ string) (fs.FS, error) {
+				return os.DirFS(dir), nil
+			},
+		}
+	}
+	undeployedGitter := &mockGitter{
+		deploymentsFunc: func(_ context.Context, _ config.Env) ([]repo.Deployment, error) { return nil, nil },
+	}
+
+	read := func(t *testing.T, r *Registry, k Key) string {
+		t.Helper()
+		data, err := os.ReadFile(New(k, r).Path(FilePath))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("move", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		root := r.RootDirectory()
+		m, err := r.MoveFamily(ctx, undeployedGitter, "old/family", "new/place", false)
+		require.NoError(t, err)
+
+		assert.Equal(t, SearchScope("old/family"), m.From)
+		assert.Equal(t, SearchScope("new/place"), m.To)
+		assert.Equal(t, map[Key]Key{
+			"old_family_1_0_0": "new_place_1_0_0",
+			"old_family_1_1_0": "new_place_1_1_0",
+		}, m.Keys)
+		assert.Equal(t, []string{
+			New("new_place_1_1_0", r).Path(FilePath),
+			New("other_user_1_0_0", r).Path(FilePath),
+			filepath.Join(root, PartialsDir, "family"+PartialSuffix),
+		}, m.Rewritten)
+
+		assert.Equal(t, `{"$id": "{{ ID }}"}`, read(t, r, "new_place_1_0_0"))
+		assert.Contains(t, read(t, r, "new_place_1_1_0"), `{{ JSM "new_place_1_0_0" }}`)
+		user := read(t, r, "other_user_1_0_0")
+		assert.Contains(t, user, `{{ JSM "new_place_1" }}`)
+		assert.Contains(t, user, `{{ JSMRef "new_place_1_0_0" "#/x" }}`)
+		assert.Contains(t, user, `{{ JSM "old_family-x_1_0_0" }}`)
+		partial, err := os.ReadFile(filepath.Join(root, PartialsDir, "family"+PartialSuffix))
+		require.NoError(t, err)
+		assert.Equal(t, `{"$ref": "{{ JSM "new_place_1_1_0" }}"}`, string(partial))
+
+		assert.FileExists(t, filepath.Join(root, "new", "place", FamilyMetadataFile))
+		assert.NoDirExists(t, filepath.Join(root, "old", "family", "1"))
+		assert.NoFileExists(t, filepath.Join(root, "old", "family", FamilyMetadataFile))
+		assert.FileExists(t, New("old_family_sub_1_0_0", r).Path(FilePath))
+
+		s, err := r.GetSchemaByKey("new_place_1_1_0")
+		require.NoError(t, err)
+		assert.Equal(t, Key("new_place_1_1_0"), s.Key())
+	})
+
+	t.Run("empty directories are removed", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, schemaMap{"old_family_1_0_0": `{}`, "kept_family_1_0_0": `{}`})
+		_, err := r.MoveFamily(ctx, undeployedGitter, "old/family", "kept/moved", false)
+		require.NoError(t, err)
+		assert.NoDirExists(t, filepath.Join(r.RootDirectory(), "old"))
+		assert.FileExists(t, New("kept_moved_1_0_0", r).Path(FilePath))
+	})
+
+	t.Run("deployed", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		_, err := r.MoveFamily(ctx, deployedGitter(r.RootDirectory()), "old/family", "new/place", false)
+		var target *DeployedFamilyMoveError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, config.Env("prod"), target.Env)
+		assert.Equal(t, []Key{"old_family_1_0_0", "old_family_1_1_0", "other_user_1_0_0"}, target.Keys)
+		assert.FileExists(t, New("old_family_1_0_0", r).Path(FilePath))
+		assert.NoDirExists(t, filepath.Join(r.RootDirectory(), "new"))
+	})
+
+	t.Run("deployed before the family existed", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		before := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(before, config.JsmRegistryConfigFile),
+			[]byte(promotionConfigData), 0o600))
+		_, err := r.MoveFamily(ctx, deployedGitter(before), "old/family", "new/place", false)
+		require.NoError(t, err)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		m, err := r.MoveFamily(ctx, deployedGitter(r.RootDirectory()), "old/family", "new/place", true)
+		require.NoError(t, err)
+		assert.True(t, m.Copied)
+		assert.Equal(t, []string{New("new_place_1_1_0", r).Path(FilePath)}, m.Rewritten)
+
+		assert.Contains(t, read(t, r, "new_place_1_1_0"), `{{ JSM "new_place_1_0_0" }}`)
+		assert.Contains(t, read(t, r, "old_family_1_1_0"), `{{ JSM "old_family_1_0_0" }}`)
+		assert.Contains(t, read(t, r, "other_user_1_0_0"), `{{ JSM "old_family_1" }}`)
+		assert.FileExists(t, filepath.Join(r.RootDirectory(), "new", "place", FamilyMetadataFile))
+		assert.FileExists(t, filepath.Join(r.RootDirectory(), "old", "family", FamilyMetadataFile))
+		assert.NoFileExists(t, filepath.Join(New("new_place_1_0_0", r).Path(HomeDir), "old_family_1_0_0.schema.json"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+
+		for _, args := range [][2]string{{"family", "new/place"}, {"old/family", "New/Place"}} {
+			_, err := r.MoveFamily(ctx, undeployedGitter, args[0], args[1], false)
+			var target *InvalidFamilyArgError
+			require.ErrorAs(t, err, &target)
+		}
+
+		_, err := r.MoveFamily(ctx, undeployedGitter, "old/family", "other/user", false)
+		var existsErr *FamilyExistsError
+		require.ErrorAs(t, err, &existsErr)
+		assert.Equal(t, SearchScope("other/user"), existsErr.Family)
+
+		_, err = r.MoveFamily(ctx, undeployedGitter, "old/missing", "new/place", false)
+		var notFoundErr *NotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+	})
+}
+
+func TestFamilyReferenceRewriter(t *testing.T) {
+	t.Parallel()
+
+	rewrite := familyReferenceRewriter("old_family", "new_place")
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "double-quoted", src: `{{ JSM "old_family_1_0_0" }}`, want: `{{ JSM "new_place_1_0_0" }}`},
+		{name: "backquoted", src: "{{ JSM `old_family_1_0_0` }}", want: "{{ JSM `new_place_1_0_0` }}"},
+		{name: "backquoted range", src: "{{ JSM `old_family_1` }}", want: "{{ JSM `new_place_1` }}"},
+		{
+			name: "backquoted JSMRef",
+			src:  "{{ JSMRef `old_family_1_0_0` `#/x` }}",
+			want: "{{ JSMRef `new_place_1_0_0` `#/x` }}",
+		},
+		{name: "other family", src: "{{ JSM `old_family-x_1_0_0` }}", want: "{{ JSM `old_family-x_1_0_0` }}"},
+		{name: "not a reference", src: `{"const": "old_family_1_0_0"}`, want: `{"const": "old_family_1_0_0"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, string(rewrite([]byte(tt.src))))
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package schema
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/jcs"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// mutationDigest returns the hex encoded SHA-256 digest of the canonical form of an unmarshalled rendered
+// schema. If the environment's mutation policy allows annotations to change, they are excluded from the digest.
+func mutationDigest(doc validator.JSONSchema, ec *config.EnvConfig) (string, error) {
+	var v any = doc
+	if ec.MutationPolicy == config.MutationPolicyAnnotationsAllowed {
+		v = withoutAnnotations(v, ec)
+	}
+	canonical, err := jcs.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return digest(canonical), nil
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// RenderedMutations returns the keys, of those given, whose canonical rendered output for the environment
+// differs from their output at the anchor revision, ignoring any differences permitted by the environment's
+// mutation policy. The output at the anchor is rendered from the schema source and partials at the anchor.
+// Schemas referenced with {{ JSM }} are rendered as they are now, since any change to them is checked in its
+// own right.
+func (r *Registry) RenderedMutations(
+	ctx context.Context,
+	g repo.Gitter,
+	anchor repo.Revision,
+	keys []Key,
+	ec *config.EnvConfig,
+) ([]Key, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	partials, err := r.partialsAtRevision(ctx, g, anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	var mutated []Key
+	for _, k := range keys {
+		changed, cErr := r.renderedChanged(ctx, g, anchor, k, partials, ec)
+		if cErr != nil {
+			return nil, cErr
+		}
+		if changed {
+			mutated = append(mutated, k)
+		}
+	}
+	return mutated, nil
+}
+
+// renderedChanged reports whether the canonical rendered output of the schema with the given key
+// differs from its output at the anchor revision under the environment's mutation policy.
+func (r *Registry) renderedChanged(
+	ctx context.Context,
+	g repo.Gitter,
+	anchor repo.Revision,
+	k Key,
+	anchorPartials map[string]*Partial,
+	ec *config.EnvConfig,
+) (bool, error) {
+	s, err := r.GetSchemaByKey(k)
+	if err != nil {
+		return false, err
+	}
+	ri, err := r.CoordinateRender(s, ec)
+	if err != nil {
+		return false, err
+	}
+	current, err := mutationDigest(ri.Unmarshalled, ec)
+	if err != nil {
+		return false, err
+	}
+
+	src, err := g.GetFileAtRevision(ctx, anchor, s.Path(FilePath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// If the source at the anchor no longer renders (e.g. a schema it referenced has since been
+	// removed), its output cannot be compared, so the schema is treated as changed.
+	var previous string
+	doc, err := r.renderSource(k, src, anchorPartials, ec)
+	if err == nil {
+		previous, err = mutationDigest(doc, ec)
+	}
+	if err != nil {
+		return true, nil //nolint:nilerr // A failure to render the previous output is a change
+	}
+
+	return current != previous, nil
+}
+
+// renderSource renders the given source of the schema with the given key for the environment, and returns
+// the unmarshalled result. It is neither cached nor compiled, so the source need not be the schema's current
+// source.
+func (r *Registry) renderSource(
+	k Key,
+	src []byte,
+	partials map[string]*Partial,
+	ec *config.EnvConfig,
+) (validator.JSONSchema, error) {
+	s, err := loadSource(k, r, src, partials)
+	if err != nil {
+		return nil, err
+	}
+	_, doc, err := NewRenderer(s, ec).Render()
+	return doc, err
+}
+
+// partialsAtRevision returns the registry's template partials as they were at the given revision.
+func (r *Registry) partialsAtRevision(
+	ctx context.Context,
+	g repo.Gitter,
+	rev repo.Revision,
+) (map[string]*Partial, error) {
+	current, err := r.partials()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := g.GetSchemaChanges(ctx, rev, filepath.Join(r.rootDirectory, PartialsDir), PartialSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Partials which have since been removed are only found in the changes.
+	paths := make(map[string]bool)
+	for _, p := range current {
+		paths[p.Path] = true
+	}
+	for _, change := range changes {
+		if _, ok := r.PartialNameFromPath(change.Path); ok {
+			paths[change.Path] = true
+		}
+	}
+
+	sources := make(map[string][]byte)
+	for fp := range paths {
+		data, gErr := g.GetFileAtRevision(ctx, rev, fp)
+		if errors.Is(gErr, fs.ErrNotExist) {
+			// The partial has been added since the revision.
+			continue
+		}
+		if gErr != nil {
+			return nil, gErr
+		}
+		sources[fp] = data
+	}
+
+	return parsePartials(sources)
+}
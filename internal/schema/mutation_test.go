@@ -0,0 +1,288 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// anchorGitter returns a mock gitter whose files at any revision have the given content, keyed by path.
+// Files without content did not exist at the revision.
+func anchorGitter(files map[string]string, changes ...repo.Change) *mockGitter {
+	return &mockGitter{
+		getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+			return changes, nil
+		},
+		getFileAtRevisionFunc: func(_ context.Context, _ repo.Revision, path string) ([]byte, error) {
+			content, ok := files[path]
+			if !ok {
+				return nil, fs.ErrNotExist
+			}
+			return []byte(content), nil
+		},
+	}
+}
+
+func TestMutationDigest(t *testing.T) {
+	t.Parallel()
+
+	strict := &config.EnvConfig{MutationPolicy: config.MutationPolicyStrict}
+	annotations := &config.EnvConfig{
+		MutationPolicy: config.MutationPolicyAnnotationsAllowed,
+		AnnotationKeys: []string{"x-owner"},
+	}
+	unmarshal := func(t *testing.T, s string) validator.JSONSchema {
+		t.Helper()
+		doc, err := jsonschema.UnmarshalJSON(strings.NewReader(s))
+		require.NoError(t, err)
+		return doc
+	}
+	digestOf := func(t *testing.T, s string, ec *config.EnvConfig) string {
+		t.Helper()
+		d, err := mutationDigest(unmarshal(t, s), ec)
+		require.NoError(t, err)
+		return d
+	}
+
+	base := `{"type": "object", "minimum": 1.0, "description": "A thing", "x-owner": "team-a"}`
+	cosmetic := "{\n\t\"x-owner\": \"team-a\",\n\t\"minimum\": 1,\n\t\"type\": \"object\",\"description\": \"A thing\"\n}"
+	annotated := `{"type": "object", "minimum": 1, "description": "A better thing", "x-owner": "team-b", "title": "T"}`
+	semantic := `{"type": "object", "minimum": 2, "description": "A thing", "x-owner": "team-a"}`
+
+	assert.Equal(t, digestOf(t, base, strict), digestOf(t, cosmetic, strict))
+	assert.NotEqual(t, digestOf(t, base, strict), digestOf(t, annotated, strict))
+	assert.Equal(t, digestOf(t, base, annotations), digestOf(t, annotated, annotations))
+	assert.NotEqual(t, digestOf(t, base, annotations), digestOf(t, semantic, annotations))
+
+	_, err := mutationDigest(struct{}{}, strict)
+	require.Error(t, err)
+}
+
+func TestRegistry_RenderedMutations(t *testing.T) {
+	t.Parallel()
+
+	const key = Key("domain_person_1_0_0")
+	const current = `{"$id": "{{ ID }}", "type": "object", "required": ["name"]}`
+
+	setup := func(t *testing.T, content string) (*Registry, string) {
+		t.Helper()
+		r := setupTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{key: content})
+		return r, New(key, r).Path(FilePath)
+	}
+
+	tests := []struct {
+		name    string
+		anchor  string
+		mutated bool
+	}{
+		{
+			name:    "unchanged",
+			anchor:  current,
+			mutated: false,
+		},
+		{
+			name:    "whitespace and property order",
+			anchor:  "{\n  \"type\": \"object\",\n  \"required\": [\"name\"],\n  \"$id\": \"{{ ID }}\"\n}",
+			mutated: false,
+		},
+		{
+			name:    "semantic change",
+			anchor:  `{"$id": "{{ ID }}", "type": "object"}`,
+			mutated: true,
+		},
+		{
+			name:    "visibility change",
+			anchor:  `{"$id": "{{ ID }}", "type": "object", "required": ["name"], "x-public": true}`,
+			mutated: true,
+		},
+		{
+			name:    "previous source cannot be rendered",
+			anchor:  `{"$ref": "{{ JSM "domain_removed_1_0_0" }}"}`,
+			mutated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r, fp := setup(t, current)
+
+			got, err := r.RenderedMutations(context.Background(), anchorGitter(map[string]string{fp: tt.anchor}),
+				"HEAD", []Key{key}, r.config.ProductionEnvConfig())
+			require.NoError(t, err)
+			if tt.mutated {
+				assert.Equal(t, []Key{key}, got)
+			} else {
+				assert.Empty(t, got)
+			}
+		})
+	}
+
+	t.Run("annotation change", func(t *testing.T) {
+		t.Parallel()
+		r, fp := setup(t, `{"$id": "{{ ID }}", "type": "object", "required": ["name"], "description": "A person"}`)
+		g := anchorGitter(map[string]string{fp: current})
+
+		// Strict policy
+		got, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Equal(t, []Key{key}, got)
+
+		ec := *r.config.ProductionEnvConfig()
+		ec.MutationPolicy = config.MutationPolicyAnnotationsAllowed
+		got, err = r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, &ec)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("schema did not exist at the anchor", func(t *testing.T) {
+		t.Parallel()
+		r, _ := setup(t, current)
+
+		got, err := r.RenderedMutations(context.Background(), anchorGitter(nil),
+			"HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Equal(t, []Key{key}, got)
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		t.Parallel()
+		r, _ := setup(t, current)
+
+		got, err := r.RenderedMutations(context.Background(), &mockGitter{}, "HEAD", nil,
+			r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("git error", func(t *testing.T) {
+		t.Parallel()
+		r, _ := setup(t, current)
+		g := &mockGitter{
+			getFileAtRevisionFunc: func(_ context.Context, _ repo.Revision, _ string) ([]byte, error) {
+				return nil, errors.New("git failure")
+			},
+		}
+
+		_, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.ErrorContains(t, err, "git failure")
+	})
+
+	t.Run("current schema cannot be rendered", func(t *testing.T) {
+		t.Parallel()
+		r, fp := setup(t, `{"$ref": "{{ JSM "domain_missing_1_0_0" }}"}`)
+
+		_, err := r.RenderedMutations(context.Background(), anchorGitter(map[string]string{fp: current}),
+			"HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.Error(t, err)
+	})
+}
+
+func TestRegistry_RenderedMutations_Partials(t *testing.T) {
+	t.Parallel()
+
+	const key = Key("domain_payment_1_0_0")
+	const schemaSrc = `{"properties": {"amount": {{ template "money" }}}}`
+
+	setup := func(t *testing.T) (*Registry, string, string) {
+		t.Helper()
+		r := setupTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{key: schemaSrc})
+		partialPath := writePartial(t, r, "money", `{"type": "number", "minimum": 0}`)
+		return r, New(key, r).Path(FilePath), partialPath
+	}
+
+	t.Run("cosmetic change to a partial", func(t *testing.T) {
+		t.Parallel()
+		r, fp, partialPath := setup(t)
+		g := anchorGitter(map[string]string{fp: schemaSrc, partialPath: `{ "minimum": 0, "type": "number" }`},
+			repo.Change{Path: partialPath})
+
+		got, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("semantic change to a partial", func(t *testing.T) {
+		t.Parallel()
+		r, fp, partialPath := setup(t)
+		g := anchorGitter(map[string]string{fp: schemaSrc, partialPath: `{"type": "number"}`},
+			repo.Change{Path: partialPath})
+
+		got, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Equal(t, []Key{key}, got)
+	})
+
+	t.Run("partial added since the anchor", func(t *testing.T) {
+		t.Parallel()
+		r, fp, _ := setup(t)
+		g := anchorGitter(map[string]string{fp: `{"properties": {"amount": {"type": "number", "minimum": 0}}}`})
+
+		got, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("partial removed since the anchor", func(t *testing.T) {
+		t.Parallel()
+		r, fp, partialPath := setup(t)
+		removed := filepath.Join(filepath.Dir(partialPath), "currency"+PartialSuffix)
+		g := anchorGitter(map[string]string{
+			fp:          schemaSrc,
+			partialPath: `{"type": "number", "minimum": 0, "x-currency": {{ template "currency" }}}`,
+			removed:     `"GBP"`,
+		}, repo.Change{Path: removed})
+
+		got, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Equal(t, []Key{key}, got)
+	})
+
+	t.Run("invalid partial at the anchor", func(t *testing.T) {
+		t.Parallel()
+		r, fp, partialPath := setup(t)
+		g := anchorGitter(map[string]string{fp: schemaSrc, partialPath: `{{ template "unknown" }}`})
+
+		_, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		var target *UnknownPartialError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("partial changes cannot be found", func(t *testing.T) {
+		t.Parallel()
+		r, _, _ := setup(t)
+		g := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+				return nil, errors.New("git diff failure")
+			},
+		}
+
+		_, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.ErrorContains(t, err, "git diff failure")
+	})
+
+	t.Run("partial cannot be read at the anchor", func(t *testing.T) {
+		t.Parallel()
+		r, _, _ := setup(t)
+		g := &mockGitter{
+			getFileAtRevisionFunc: func(_ context.Context, _ repo.Revision, _ string) ([]byte, error) {
+				return nil, errors.New("git show failure")
+			},
+		}
+
+		_, err := r.RenderedMutations(context.Background(), g, "HEAD", []Key{key}, r.config.ProductionEnvConfig())
+		require.ErrorContains(t, err, "git show failure")
+	})
+}
@@ -0,0 +1,318 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+// PartialsDir is the name of the optional directory in the registry root which contains template partials.
+const PartialsDir = "partials"
+
+// PartialSuffix is the file suffix of a template partial.
+const PartialSuffix = ".tmpl"
+
+// Partial is a named go template fragment which can be included in any schema in the registry with
+// {{ template "<name>" }}. The name of a partial is its filename without the suffix - e.g. the partial
+// in partials/money.tmpl is included with {{ template "money" }}.
+type Partial struct {
+	Name string
+	Path string
+	tree *parse.Tree
+	refs []string // The names of the templates this partial includes directly
+}
+
+// templatePlaceholderFuncs returns the template functions available to schemas and partials.
+// These let the parser recognise the function names without the need for the . prefix
+// (e.g. {{ ID }} instead of {{ .ID }}). The real functions are provided by the Renderer.
+func templatePlaceholderFuncs() template.FuncMap {
+	return template.FuncMap{
+		"ID":     func() (string, error) { return "", nil },
+		"JSM":    func(string) (string, error) { return "", nil },
+		"JSMRef": func(string, string) (string, error) { return "", nil },
+		"Var":    func(string) (string, error) { return "", nil },
+	}
+}
+
+// partials returns the template partials defined in the registry, keyed by name.
+// They are read once and cached until the registry is Reset.
+func (r *Registry) partials() (map[string]*Partial, error) {
+	r.partialsMu.Lock()
+	defer r.partialsMu.Unlock()
+
+	if r.partialSet != nil {
+		return r.partialSet, nil
+	}
+
+	ps, err := r.loadPartials(filepath.Join(r.rootDirectory, PartialsDir))
+	if err != nil {
+		return nil, err
+	}
+	r.partialSet = ps
+	return ps, nil
+}
+
+// loadPartials parses every partial in dir. If dir does not exist, there are no partials.
+func (r *Registry) loadPartials(dir string) (map[string]*Partial, error) {
+	entries, err := r.readDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return make(map[string]*Partial), nil
+		}
+		return nil, err
+	}
+
+	sources := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), PartialSuffix) {
+			continue
+		}
+
+		fp := filepath.Join(dir, entry.Name())
+		data, rErr := r.readFile(fp)
+		if rErr != nil {
+			return nil, rErr
+		}
+		sources[fp] = data
+	}
+
+	return parsePartials(sources)
+}
+
+// parsePartials parses the given partial sources, keyed by path.
+func parsePartials(sources map[string][]byte) (map[string]*Partial, error) {
+	ps := make(map[string]*Partial)
+	for fp, data := range sources {
+		name := strings.TrimSuffix(filepath.Base(fp), PartialSuffix)
+		tmpl, err := template.New(name).Funcs(templatePlaceholderFuncs()).Parse(string(data))
+		if err != nil {
+			return nil, &InvalidPartialError{Path: fp, Wrapped: err}
+		}
+
+		ps[name] = &Partial{Name: name, Path: fp, tree: tmpl.Tree, refs: templateRefs(tmpl.Tree.Root)}
+	}
+
+	// Partials may include other partials, but only those which exist.
+	for _, p := range ps {
+		for _, ref := range p.refs {
+			if _, ok := ps[ref]; !ok {
+				return nil, &UnknownPartialError{Path: p.Path, Name: ref}
+			}
+		}
+	}
+
+	return ps, nil
+}
+
+// templateRefs returns the sorted names of the templates included with {{ template }} in the given node.
+func templateRefs(n parse.Node) []string {
+	refs := make(map[string]struct{})
+	collectTemplateRefs(n, refs)
+
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func collectTemplateRefs(n parse.Node, refs map[string]struct{}) {
+	switch node := n.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, c := range node.Nodes {
+			collectTemplateRefs(c, refs)
+		}
+	case *parse.TemplateNode:
+		refs[node.Name] = struct{}{}
+	case *parse.IfNode:
+		collectTemplateRefs(node.List, refs)
+		collectTemplateRefs(node.ElseList, refs)
+	case *parse.RangeNode:
+		collectTemplateRefs(node.List, refs)
+		collectTemplateRefs(node.ElseList, refs)
+	case *parse.WithNode:
+		collectTemplateRefs(node.List, refs)
+		collectTemplateRefs(node.ElseList, refs)
+	}
+}
+
+// includePartials adds the partials to the schema's parsed template, and records the names of
+// the partials the schema includes, directly or via other partials.
+func (s *Schema) includePartials(fp string, tmpl *template.Template, partials map[string]*Partial) error {
+	// Templates defined within the schema source itself are not partials.
+	defined := make(map[string]bool)
+	var pending []string
+	for _, t := range tmpl.Templates() {
+		defined[t.Name()] = true
+		if t.Tree != nil {
+			pending = append(pending, templateRefs(t.Tree.Root)...)
+		}
+	}
+
+	used := make(map[string]bool)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if defined[name] || used[name] {
+			continue
+		}
+		p, ok := partials[name]
+		if !ok {
+			return &UnknownPartialError{Path: fp, Name: name}
+		}
+		used[name] = true
+		pending = append(pending, p.refs...)
+	}
+
+	for name, p := range partials {
+		if defined[name] {
+			continue
+		}
+		if _, err := tmpl.AddParseTree(name, p.tree); err != nil {
+			return &InvalidPartialError{Path: p.Path, Wrapped: err}
+		}
+	}
+
+	s.partials = make([]string, 0, len(used))
+	for name := range used {
+		s.partials = append(s.partials, name)
+	}
+	slices.Sort(s.partials)
+	return nil
+}
+
+// Partials returns the sorted names of the template partials included by the schema, either directly
+// or via other partials.
+func (s *Schema) Partials() []string {
+	return s.partials
+}
+
+// PartialNameFromPath returns the name of the partial at the given path. ok is false if the path is not
+// a partial in the registry's partials directory.
+func (r *Registry) PartialNameFromPath(path string) (name string, ok bool) {
+	if !strings.HasSuffix(path, PartialSuffix) {
+		return "", false
+	}
+	if filepath.Dir(filepath.Clean(path)) != filepath.Join(r.rootDirectory, PartialsDir) {
+		return "", false
+	}
+	return strings.TrimSuffix(filepath.Base(path), PartialSuffix), true
+}
+
+// PartialDependents returns the keys of the schemas in the registry which include the named partial,
+// either directly or via other partials.
+func (r *Registry) PartialDependents(ctx context.Context, name string) ([]Key, error) {
+	var dependents []Key
+	err := r.forEachSchema(ctx, func(s *Schema) error {
+		if slices.Contains(s.Partials(), name) {
+			dependents = append(dependents, s.Key())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dependents, nil
+}
+
+// ChangedPartialDependents returns the keys of the schemas which include a template partial that has
+// changed since the anchor. Such schemas render differently even though their own files have not changed.
+func (r *Registry) ChangedPartialDependents(ctx context.Context, g repo.Gitter, anchor repo.Revision) ([]Key, error) {
+	changes, err := g.GetSchemaChanges(ctx, anchor, filepath.Join(r.rootDirectory, PartialsDir), PartialSuffix)
+	if err != nil {
+		return nil, err
+	}
+	return r.partialChangeDependents(ctx, changes)
+}
+
+// partialChangeDependents returns the keys of the schemas which include a template partial changed by one of
+// the given changes.
+func (r *Registry) partialChangeDependents(ctx context.Context, changes []repo.Change) ([]Key, error) {
+	changed := make(map[string]bool)
+	for _, change := range changes {
+		if name, ok := r.PartialNameFromPath(change.Path); ok {
+			changed[name] = true
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	var dependents []Key
+	err := r.forEachSchema(ctx, func(s *Schema) error {
+		if slices.ContainsFunc(s.Partials(), func(name string) bool { return changed[name] }) {
+			dependents = append(dependents, s.Key())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dependents, nil
+}
+
+// UnusedPartials returns the sorted names of the partials which are not included by any schema in the registry.
+func (r *Registry) UnusedPartials(ctx context.Context) ([]string, error) {
+	partials, err := r.partials()
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	err = r.forEachSchema(ctx, func(s *Schema) error {
+		for _, name := range s.Partials() {
+			used[name] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var unused []string
+	for name := range partials {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	slices.Sort(unused)
+	return unused, nil
+}
+
+// forEachSchema loads every schema in the registry in turn, and calls fn with it.
+// It stops at the first error returned by fn.
+func (r *Registry) forEachSchema(ctx context.Context, fn func(s *Schema) error) error {
+	searcher, err := NewSearcher(r, "")
+	if err != nil {
+		return err
+	}
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for res := range searcher.Schemas(searchCtx) {
+		if res.Err != nil {
+			return res.Err
+		}
+		s, gErr := r.GetSchemaByKey(res.Key)
+		if gErr != nil {
+			return gErr
+		}
+		if fErr := fn(s); fErr != nil {
+			return fErr
+		}
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,385 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+// writePartial writes a template partial into the registry's partials directory.
+func writePartial(t *testing.T, r *Registry, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(r.RootDirectory(), PartialsDir)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	fp := filepath.Join(dir, name+PartialSuffix)
+	require.NoError(t, os.WriteFile(fp, []byte(content), 0o600))
+	return fp
+}
+
+const (
+	moneyPartial = `{"type": "object", "properties": {"amount": {"type": "number"}, ` +
+		`"currency": {{ template "currency" }}}}`
+	currencyPartial = `{"type": "string", "pattern": "^[A-Z]{3}$"}`
+)
+
+func TestSchema_Partials(t *testing.T) {
+	t.Parallel()
+
+	t.Run("partials are rendered into the schema", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writePartial(t, r, "money", moneyPartial)
+		writePartial(t, r, "currency", currencyPartial)
+		writePartial(t, r, "address", `{"type": "object"}`)
+		k := Key("domain_order_1_0_0")
+		createSchemaFiles(t, r, schemaMap{k: `{"properties": {"price": {{ template "money" }}}}`})
+
+		s, err := r.GetSchemaByKey(k)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"currency", "money"}, s.Partials())
+
+		rb, _, err := NewRenderer(s, r.config.ProductionEnvConfig()).Render()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"properties": {"price": {"type": "object", "properties": {
+			"amount": {"type": "number"}, "currency": {"type": "string", "pattern": "^[A-Z]{3}$"}}}}}`, string(rb))
+	})
+
+	t.Run("partials can use template functions", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writePartial(t, r, "self", `"{{ ID }}"`)
+		k := Key("domain_order_1_0_0")
+		createSchemaFiles(t, r, schemaMap{k: `{"$id": {{ template "self" }}}`})
+
+		s, err := r.GetSchemaByKey(k)
+		require.NoError(t, err)
+
+		rb, _, err := NewRenderer(s, r.config.ProductionEnvConfig()).Render()
+		require.NoError(t, err)
+		assert.Contains(t, string(rb), "domain_order_1_0_0.schema.json")
+	})
+
+	t.Run("templates defined in the schema are not partials", func(t *testing.T) {
+		t.Parallel()
+		s := &Schema{srcDoc: []byte(`{{ define "local" }}1{{ end }}{"a": {{ template "local" }}}`)}
+
+		require.NoError(t, s.loadTemplate("test.json", nil))
+		assert.Empty(t, s.Partials())
+	})
+
+	t.Run("partials in conditional blocks are included", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writePartial(t, r, "a", "1")
+		writePartial(t, r, "b", "2")
+		writePartial(t, r, "c", "3")
+		writePartial(t, r, "d", "4")
+		ps, err := r.partials()
+		require.NoError(t, err)
+
+		s := &Schema{srcDoc: []byte(`{"a": {{ if true }}{{ template "a" }}{{ else }}{{ template "b" }}{{ end }}, ` +
+			`"c": {{ with 1 }}{{ template "c" }}{{ end }}, "d": [{{ range $i := "" }}{{ end }}]}`)}
+		require.NoError(t, s.loadTemplate("test.json", ps))
+		assert.Equal(t, []string{"a", "b", "c"}, s.Partials())
+	})
+
+	t.Run("unknown partial", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		k := Key("domain_order_1_0_0")
+		createSchemaFiles(t, r, schemaMap{k: `{"properties": {"price": {{ template "money" }}}}`})
+
+		_, err := r.GetSchemaByKey(k)
+		var target *UnknownPartialError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, "money", target.Name)
+		assert.Equal(t, New(k, r).Path(FilePath), target.Path)
+	})
+
+	t.Run("partial includes an unknown partial", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		fp := writePartial(t, r, "money", moneyPartial)
+		k := Key("domain_order_1_0_0")
+		createSchemaFiles(t, r, schemaMap{k: `{}`})
+
+		_, err := r.GetSchemaByKey(k)
+		var target *UnknownPartialError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, "currency", target.Name)
+		assert.Equal(t, fp, target.Path)
+	})
+
+	t.Run("invalid partial", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		fp := writePartial(t, r, "money", `{{ template "currency" }`)
+		k := Key("domain_order_1_0_0")
+		createSchemaFiles(t, r, schemaMap{k: `{}`})
+
+		_, err := r.GetSchemaByKey(k)
+		var target *InvalidPartialError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, fp, target.Path)
+	})
+}
+
+func TestRegistry_partials(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no partials directory", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+
+		ps, err := r.partials()
+		require.NoError(t, err)
+		assert.Empty(t, ps)
+	})
+
+	t.Run("only partial files are loaded", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		fp := writePartial(t, r, "currency", currencyPartial)
+		dir := filepath.Dir(fp)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Partials"), 0o600))
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"+PartialSuffix), 0o755))
+
+		ps, err := r.partials()
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+		assert.Equal(t, "currency", ps["currency"].Name)
+		assert.Equal(t, fp, ps["currency"].Path)
+	})
+
+	t.Run("partials directory cannot be read", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		require.NoError(t, os.WriteFile(filepath.Join(r.RootDirectory(), PartialsDir), nil, 0o600))
+
+		_, err := r.partials()
+		require.Error(t, err)
+	})
+
+	t.Run("partials are cached until reset", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writePartial(t, r, "currency", currencyPartial)
+
+		ps, err := r.partials()
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+
+		writePartial(t, r, "address", `{"type": "object"}`)
+		ps, err = r.partials()
+		require.NoError(t, err)
+		assert.Len(t, ps, 1)
+
+		r.Reset()
+		ps, err = r.partials()
+		require.NoError(t, err)
+		assert.Len(t, ps, 2)
+	})
+}
+
+func TestRegistry_PartialNameFromPath(t *testing.T) {
+	t.Parallel()
+	r := setupTestRegistry(t)
+	dir := filepath.Join(r.RootDirectory(), PartialsDir)
+
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{path: filepath.Join(dir, "money.tmpl"), want: "money", wantOK: true},
+		{path: filepath.Join(dir, "money.json"), wantOK: false},
+		{path: filepath.Join(dir, "nested", "money.tmpl"), wantOK: false},
+		{path: filepath.Join(r.RootDirectory(), "domain", "money.tmpl"), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			got, ok := r.PartialNameFromPath(tt.path)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// setupPartialsTestRegistry creates a registry in which the order schemas include the money partial
+// (which includes the currency partial), the rate schema includes only the currency partial, and the
+// address partial is not included by any schema.
+func setupPartialsTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r := setupTestRegistry(t)
+	writePartial(t, r, "money", moneyPartial)
+	writePartial(t, r, "currency", currencyPartial)
+	writePartial(t, r, "address", `{"type": "object"}`)
+	createSchemaFiles(t, r, schemaMap{
+		"domain_order_1_0_0": `{"properties": {"price": {{ template "money" }}}}`,
+		"domain_order_2_0_0": `{"properties": {"total": {{ template "money" }}}}`,
+		"domain_rate_1_0_0":  `{"properties": {"from": {{ template "currency" }}}}`,
+		"domain_plain_1_0_0": `{}`,
+	})
+	return r
+}
+
+func TestRegistry_PartialDependents(t *testing.T) {
+	t.Parallel()
+	r := setupPartialsTestRegistry(t)
+
+	tests := []struct {
+		partial string
+		want    []Key
+	}{
+		{partial: "money", want: []Key{"domain_order_1_0_0", "domain_order_2_0_0"}},
+		{partial: "currency", want: []Key{"domain_order_1_0_0", "domain_order_2_0_0", "domain_rate_1_0_0"}},
+		{partial: "address", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.partial, func(t *testing.T) {
+			t.Parallel()
+			got, err := r.PartialDependents(context.Background(), tt.partial)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+
+	t.Run("schema cannot be loaded", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{"domain_order_1_0_0": `{{ template "money" }}`})
+
+		_, err := r.PartialDependents(context.Background(), "money")
+		require.ErrorAs(t, err, new(*UnknownPartialError))
+	})
+
+	t.Run("invalid schema filename", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		require.NoError(t, os.WriteFile(filepath.Join(r.RootDirectory(), "bad"+SchemaSuffix), []byte("{}"), 0o600))
+
+		_, err := r.PartialDependents(context.Background(), "money")
+		require.ErrorAs(t, err, new(*InvalidSchemaFilenameError))
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := setupPartialsTestRegistry(t).PartialDependents(ctx, "money")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestRegistry_UnusedPartials(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unused partials", func(t *testing.T) {
+		t.Parallel()
+		unused, err := setupPartialsTestRegistry(t).UnusedPartials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"address"}, unused)
+	})
+
+	t.Run("partial only included by an unused partial", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writePartial(t, r, "money", moneyPartial)
+		writePartial(t, r, "currency", currencyPartial)
+		createSchemaFiles(t, r, schemaMap{"domain_plain_1_0_0": `{}`})
+
+		unused, err := r.UnusedPartials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"currency", "money"}, unused)
+	})
+
+	t.Run("invalid partial", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writePartial(t, r, "money", `{{ end }}`)
+
+		_, err := r.UnusedPartials(context.Background())
+		require.ErrorAs(t, err, new(*InvalidPartialError))
+	})
+
+	t.Run("schema cannot be loaded", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{"domain_order_1_0_0": `{{ template "money" }}`})
+
+		_, err := r.UnusedPartials(context.Background())
+		require.ErrorAs(t, err, new(*UnknownPartialError))
+	})
+}
+
+func TestRegistry_ChangedPartialDependents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dependents of changed partials", func(t *testing.T) {
+		t.Parallel()
+		r := setupPartialsTestRegistry(t)
+		var gotDir, gotSuffix string
+		g := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, dir, suffix string) ([]repo.Change, error) {
+				gotDir, gotSuffix = dir, suffix
+				return []repo.Change{
+					{Path: filepath.Join(r.RootDirectory(), PartialsDir, "money.tmpl")},
+					{Path: filepath.Join(r.RootDirectory(), "elsewhere", "currency.tmpl")},
+				}, nil
+			},
+		}
+
+		keys, err := r.ChangedPartialDependents(context.Background(), g, "HEAD")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []Key{"domain_order_1_0_0", "domain_order_2_0_0"}, keys)
+		assert.Equal(t, filepath.Join(r.RootDirectory(), PartialsDir), gotDir)
+		assert.Equal(t, PartialSuffix, gotSuffix)
+	})
+
+	t.Run("no partials changed", func(t *testing.T) {
+		t.Parallel()
+		r := setupPartialsTestRegistry(t)
+
+		keys, err := r.ChangedPartialDependents(context.Background(), &mockGitter{}, "HEAD")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("git error", func(t *testing.T) {
+		t.Parallel()
+		r := setupPartialsTestRegistry(t)
+		g := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+				return nil, errors.New("git diff failed")
+			},
+		}
+
+		_, err := r.ChangedPartialDependents(context.Background(), g, "HEAD")
+		require.EqualError(t, err, "git diff failed")
+	})
+
+	t.Run("schema cannot be loaded", func(t *testing.T) {
+		t.Parallel()
+		r := setupPartialsTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{"domain_broken_1_0_0": `{{ template "missing" }}`})
+		g := &mockGitter{
+			getSchemaChangesFunc: func(_ context.Context, _ repo.Revision, _, _ string) ([]repo.Change, error) {
+				return []repo.Change{{Path: filepath.Join(r.RootDirectory(), PartialsDir, "money.tmpl")}}, nil
+			},
+		}
+
+		_, err := r.ChangedPartialDependents(context.Background(), g, "HEAD")
+		require.ErrorAs(t, err, new(*UnknownPartialError))
+	})
+}
@@ -0,0 +1,210 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+// MutationReason explains why a deployed schema is mutated by a deployment.
+type MutationReason string
+
+const (
+	// MutationModified is a deployed schema whose source has been modified.
+	MutationModified MutationReason = "modified"
+	// MutationDeleted is a deployed schema which has been deleted.
+	MutationDeleted MutationReason = "deleted"
+	// MutationRenamed is a deployed schema which has been renamed or moved, so is no longer served at its ID.
+	MutationRenamed MutationReason = "renamed"
+	// MutationPartialChanged is a deployed schema which includes a partial that has been changed.
+	MutationPartialChanged MutationReason = "partial-changed"
+	// MutationRangeResolved is a deployed schema which references a version range that now resolves to
+	// a new version.
+	MutationRangeResolved MutationReason = "range-resolved"
+)
+
+// DeploymentPlan describes what deploying the schemas changed since an environment's last deployment
+// would do: the schemas which would be added, and the deployed schemas which would be mutated.
+type DeploymentPlan struct {
+	Env              config.Env      `json:"env"`
+	Anchor           repo.Revision   `json:"anchor"`           // The revision of the last deployment
+	MutationsAllowed bool            `json:"mutationsAllowed"` // Whether the environment allows schema mutation
+	Added            []PlannedSchema `json:"added"`
+	Mutated          []PlannedSchema `json:"mutated"`
+}
+
+// Blocked returns true if the deployment would be refused, because it would mutate deployed schemas in
+// an environment which does not allow schema mutation.
+func (p *DeploymentPlan) Blocked() bool {
+	return !p.MutationsAllowed && len(p.Mutated) > 0
+}
+
+// MutatedPaths returns the paths, relative to the registry root directory, of the mutated schemas.
+func (p *DeploymentPlan) MutatedPaths() []string {
+	paths := make([]string, 0, len(p.Mutated))
+	for _, s := range p.Mutated {
+		paths = append(paths, s.Path)
+	}
+	return paths
+}
+
+// PlannedSchema describes a schema which would be added or mutated by a deployment. The path of a deleted
+// or renamed schema is its path before the change, and its key, visibility and ID are those it was
+// deployed with.
+type PlannedSchema struct {
+	Path                string         `json:"path"` // Relative to the registry root directory
+	Key                 Key            `json:"key,omitempty"`
+	Visibility          string         `json:"visibility,omitempty"`
+	ID                  ID             `json:"id,omitempty"`
+	Reason              MutationReason `json:"reason,omitempty"` // Only set for mutated schemas
+	DependenciesAdded   []Key          `json:"dependenciesAdded,omitempty"`
+	DependenciesRemoved []Key          `json:"dependenciesRemoved,omitempty"`
+}
+
+// PlanSchema describes the schema at the given path as it would be deployed to the environment. If reason
+// is empty, the schema is new, so all its dependencies are added. Otherwise it is a deployed schema, and
+// its dependencies are compared with those of its source at the anchor revision.
+func (r *Registry) PlanSchema(
+	ctx context.Context,
+	g repo.Gitter,
+	anchor repo.Revision,
+	path string,
+	reason MutationReason,
+	ec *config.EnvConfig,
+) (PlannedSchema, error) {
+	ps := PlannedSchema{Path: path, Reason: reason}
+	if rel, err := filepath.Rel(r.rootDirectory, path); err == nil {
+		ps.Path = filepath.ToSlash(rel)
+	}
+
+	if reason == MutationDeleted || reason == MutationRenamed {
+		return r.planRemovedSchema(ctx, g, anchor, path, ps, ec)
+	}
+
+	k, err := r.KeyFromSchemaPath(path)
+	if err != nil {
+		if reason == "" {
+			return ps, err
+		}
+		// A deployed schema which cannot be found has been deleted.
+		ps.Reason = MutationDeleted
+		return r.planRemovedSchema(ctx, g, anchor, path, ps, ec)
+	}
+
+	s, err := r.GetSchemaByKey(k)
+	if err != nil {
+		return ps, err
+	}
+	ri, err := r.CoordinateRender(s, ec)
+	if err != nil {
+		return ps, err
+	}
+	ps.Key, ps.Visibility, ps.ID = k, s.visibility(), s.CanonicalID(ec)
+
+	var current, previous []Key
+	for _, d := range ri.Dependencies {
+		current = appendUnique(current, d.Key)
+	}
+	if reason != "" {
+		if previous, err = r.dependenciesAtRevision(ctx, g, anchor, k, ec); err != nil {
+			return ps, err
+		}
+	}
+
+	for _, d := range current {
+		if !slices.Contains(previous, d) {
+			ps.DependenciesAdded = append(ps.DependenciesAdded, d)
+		}
+	}
+	for _, d := range previous {
+		if !slices.Contains(current, d) {
+			ps.DependenciesRemoved = append(ps.DependenciesRemoved, d)
+		}
+	}
+	return ps, nil
+}
+
+// planRemovedSchema completes the description of a deleted or renamed schema, whose key is derived from
+// its path before the change, and whose visibility and ID are derived from its source at the anchor
+// revision. If that source cannot be found or read, only the key is known.
+func (r *Registry) planRemovedSchema(
+	ctx context.Context,
+	g repo.Gitter,
+	anchor repo.Revision,
+	path string,
+	ps PlannedSchema,
+	ec *config.EnvConfig,
+) (PlannedSchema, error) {
+	k, ok := keyFromFilename(path)
+	if !ok {
+		return ps, nil
+	}
+	ps.Key = k
+
+	src, err := g.GetFileAtRevision(ctx, anchor, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ps, nil
+	}
+	if err != nil {
+		return ps, err
+	}
+	isPublic, err := isSchemaPublic(path, withoutTemplateActions(src))
+	if err != nil {
+		return ps, nil //nolint:nilerr // The visibility of a source which cannot be read is not known
+	}
+
+	s := New(k, r)
+	s.isPublic = isPublic
+	ps.Visibility, ps.ID = s.visibility(), s.CanonicalID(ec)
+	return ps, nil
+}
+
+// dependenciesAtRevision returns the keys of the schemas referenced by the source of the schema with the
+// given key at the revision. Version ranges are resolved as they are now. If the schema did not exist at
+// the revision, or its source there no longer renders, it has no dependencies.
+func (r *Registry) dependenciesAtRevision(
+	ctx context.Context,
+	g repo.Gitter,
+	rev repo.Revision,
+	k Key,
+	ec *config.EnvConfig,
+) ([]Key, error) {
+	src, err := g.GetFileAtRevision(ctx, rev, New(k, r).Path(FilePath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	partials, err := r.partialsAtRevision(ctx, g, rev)
+	if err != nil {
+		return nil, err
+	}
+	s, err := loadSource(k, r, src, partials)
+	if err != nil {
+		return nil, nil //nolint:nilerr // A source which no longer loads has no comparable dependencies
+	}
+	renderer := NewRenderer(s, ec)
+	if _, _, err = renderer.Render(); err != nil {
+		return nil, nil //nolint:nilerr // A source which no longer renders has no comparable dependencies
+	}
+
+	var keys []Key
+	for _, d := range renderer.Dependencies() {
+		keys = appendUnique(keys, d.Key)
+	}
+	return keys, nil
+}
+
+func appendUnique(keys []Key, k Key) []Key {
+	if slices.Contains(keys, k) {
+		return keys
+	}
+	return append(keys, k)
+}
@@ -0,0 +1,153 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+func TestDeploymentPlan(t *testing.T) {
+	t.Parallel()
+
+	p := &DeploymentPlan{Added: []PlannedSchema{{Path: "a"}}}
+	assert.False(t, p.Blocked())
+	assert.Empty(t, p.MutatedPaths())
+
+	p.Mutated = []PlannedSchema{{Path: "b"}, {Path: "c"}}
+	assert.True(t, p.Blocked())
+	assert.Equal(t, []string{"b", "c"}, p.MutatedPaths())
+
+	p.MutationsAllowed = true
+	assert.False(t, p.Blocked())
+}
+
+func TestRegistry_PlanSchema(t *testing.T) {
+	t.Parallel()
+
+	const key = Key("domain_order_1_0_0")
+	setup := func(t *testing.T) (*Registry, string) {
+		t.Helper()
+		r := setupTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{
+			key:                     `{"properties": {"a": {"$ref": "{{ JSM %%domain_address_1_0_0%% }}"}}}`,
+			"domain_address_1_0_0":  `{"type": "object", "x-public": true}`,
+			"domain_customer_1_0_0": `{"type": "object"}`,
+		})
+		return r, New(key, r).Path(FilePath)
+	}
+
+	t.Run("added schema", func(t *testing.T) {
+		t.Parallel()
+		r, fp := setup(t)
+		ec := r.config.ProductionEnvConfig()
+
+		got, err := r.PlanSchema(context.Background(), anchorGitter(nil), "HEAD", fp, "", ec)
+		require.NoError(t, err)
+		assert.Equal(t, "domain/order/1/0/0/domain_order_1_0_0.schema.json", got.Path)
+		assert.Equal(t, key, got.Key)
+		assert.Equal(t, "private", got.Visibility)
+		assert.Equal(t, New(key, r).CanonicalID(ec), got.ID)
+		assert.Empty(t, got.Reason)
+		assert.Equal(t, []Key{"domain_address_1_0_0"}, got.DependenciesAdded)
+		assert.Empty(t, got.DependenciesRemoved)
+	})
+
+	t.Run("modified schema with changed dependencies", func(t *testing.T) {
+		t.Parallel()
+		r, fp := setup(t)
+		g := anchorGitter(map[string]string{fp: `{"$ref": "{{ JSM ` + "`domain_customer_1_0_0`" + ` }}"}`})
+
+		got, err := r.PlanSchema(context.Background(), g, "HEAD", fp, MutationModified, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Equal(t, MutationModified, got.Reason)
+		assert.Equal(t, []Key{"domain_address_1_0_0"}, got.DependenciesAdded)
+		assert.Equal(t, []Key{"domain_customer_1_0_0"}, got.DependenciesRemoved)
+	})
+
+	t.Run("modified schema whose previous source no longer renders", func(t *testing.T) {
+		t.Parallel()
+		r, fp := setup(t)
+		g := anchorGitter(map[string]string{fp: `{"$ref": "{{ JSM ` + "`domain_removed_1_0_0`" + ` }}"}`})
+
+		got, err := r.PlanSchema(context.Background(), g, "HEAD", fp, MutationModified, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Equal(t, []Key{"domain_address_1_0_0"}, got.DependenciesAdded)
+		assert.Empty(t, got.DependenciesRemoved)
+	})
+
+	t.Run("previous source cannot be read", func(t *testing.T) {
+		t.Parallel()
+		r, fp := setup(t)
+		g := &mockGitter{
+			getFileAtRevisionFunc: func(_ context.Context, _ repo.Revision, _ string) ([]byte, error) {
+				return nil, errors.New("git failed")
+			},
+		}
+
+		_, err := r.PlanSchema(context.Background(), g, "HEAD", fp, MutationModified, r.config.ProductionEnvConfig())
+		require.EqualError(t, err, "git failed")
+	})
+
+	t.Run("deleted schema", func(t *testing.T) {
+		t.Parallel()
+		r, _ := setup(t)
+		fp := New("domain_gone_1_0_0", r).Path(FilePath)
+
+		got, err := r.PlanSchema(context.Background(), anchorGitter(nil), "HEAD", fp, MutationModified,
+			r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Equal(t, PlannedSchema{
+			Path:   "domain/gone/1/0/0/domain_gone_1_0_0.schema.json",
+			Key:    "domain_gone_1_0_0",
+			Reason: MutationDeleted,
+		}, got)
+	})
+
+	t.Run("deleted and renamed schemas are described as deployed", func(t *testing.T) {
+		t.Parallel()
+		r, _ := setup(t)
+		ec := r.config.ProductionEnvConfig()
+		fp := New("domain_gone_1_0_0", r).Path(FilePath)
+		g := anchorGitter(map[string]string{fp: `{"$id": "{{ ID }}", "x-public": true}`})
+
+		for _, reason := range []MutationReason{MutationDeleted, MutationRenamed} {
+			got, err := r.PlanSchema(context.Background(), g, "HEAD", fp, reason, ec)
+			require.NoError(t, err)
+			assert.Equal(t, PlannedSchema{
+				Path:       "domain/gone/1/0/0/domain_gone_1_0_0.schema.json",
+				Key:        "domain_gone_1_0_0",
+				Visibility: "public",
+				ID:         "https://json-schemas.myorg.io/domain_gone_1_0_0.schema.json",
+				Reason:     reason,
+			}, got)
+		}
+
+		g = anchorGitter(map[string]string{fp: `{`})
+		got, err := r.PlanSchema(context.Background(), g, "HEAD", fp, MutationDeleted, ec)
+		require.NoError(t, err)
+		assert.Equal(t, Key("domain_gone_1_0_0"), got.Key)
+		assert.Empty(t, got.ID)
+
+		g = &mockGitter{
+			getFileAtRevisionFunc: func(_ context.Context, _ repo.Revision, _ string) ([]byte, error) {
+				return nil, errors.New("git failed")
+			},
+		}
+		_, err = r.PlanSchema(context.Background(), g, "HEAD", fp, MutationDeleted, ec)
+		require.EqualError(t, err, "git failed")
+	})
+
+	t.Run("added schema which cannot be found", func(t *testing.T) {
+		t.Parallel()
+		r, _ := setup(t)
+		fp := New("domain_gone_1_0_0", r).Path(FilePath)
+
+		_, err := r.PlanSchema(context.Background(), anchorGitter(nil), "HEAD", fp, "", r.config.ProductionEnvConfig())
+		require.Error(t, err)
+	})
+}
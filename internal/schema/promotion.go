@@ -0,0 +1,187 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+// Promotion describes the schemas which have been deployed to one environment, but not yet to another.
+type Promotion struct {
+	From       config.Env
+	To         config.Env
+	FromAnchor repo.Revision // The latest deployment to From
+	ToAnchor   repo.Revision // The latest deployment to To, or empty if there has been none
+	Added      []Key         // Schemas which have not been deployed to To
+	Modified   []Key         // Schemas deployed to To which have since changed in From
+	registry   *Registry     // The registry as it was at FromAnchor
+}
+
+// Keys returns the keys of all the schemas to promote, sorted.
+func (p *Promotion) Keys() []Key {
+	return slices.Sorted(slices.Values(append(slices.Clone(p.Added), p.Modified...)))
+}
+
+// Registry returns the registry as it was at the latest deployment to the environment promoted from.
+func (p *Promotion) Registry() *Registry {
+	return p.registry
+}
+
+// Promotion returns the schemas which have been deployed to the from environment but not yet to the to
+// environment: those which have changed between the latest deployments to each. As with a deployment of the
+// schemas changed since an anchor, these include the schemas which include a changed template partial, and
+// those whose version ranges resolve to a schema being promoted. Schemas are promoted as they were at the
+// latest deployment to from, so the promotion includes a registry read at that revision.
+func (r *Registry) Promotion(ctx context.Context, g repo.Gitter, from, to config.Env) (*Promotion, error) {
+	fromAnchor, err := latestDeployment(ctx, g, from)
+	if err != nil {
+		return nil, err
+	}
+	if fromAnchor == "" {
+		return nil, &NoDeploymentsError{Env: from}
+	}
+	toAnchor, err := latestDeployment(ctx, g, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRegistry, err := r.atRevision(ctx, g, fromAnchor)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Promotion{From: from, To: to, FromAnchor: fromAnchor, ToAnchor: toAnchor, registry: fromRegistry}
+	if err = p.addChanges(ctx, g); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// atRevision returns the registry as it was at the given revision.
+func (r *Registry) atRevision(ctx context.Context, g repo.Gitter, rev repo.Revision) (*Registry, error) {
+	fsys, err := g.RevisionFS(ctx, rev, r.rootDirectory)
+	if err != nil {
+		return nil, err
+	}
+	atRev, err := NewRegistryFS(r.rootDirectory, fsys, r.compiler, r.pathResolver, r.envProvider)
+	if err != nil {
+		return nil, fmt.Errorf("registry initialisation at %s failed: %w", rev, err)
+	}
+	return atRev, nil
+}
+
+// latestDeployment returns the anchor of the latest deployment to an environment, or an empty revision if
+// there has been none.
+func latestDeployment(ctx context.Context, g repo.Gitter, env config.Env) (repo.Revision, error) {
+	deployments, err := g.Deployments(ctx, env)
+	if err != nil || len(deployments) == 0 {
+		return "", err
+	}
+	return g.GetLatestAnchor(ctx, env)
+}
+
+// addChanges adds the schemas which have changed between the anchors of the promotion to it.
+func (p *Promotion) addChanges(ctx context.Context, g repo.Gitter) error {
+	r := p.registry
+	changes, err := g.GetSchemaChangesBetween(ctx, p.ToAnchor, p.FromAnchor, r.rootDirectory, SchemaSuffix)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change.Status == repo.ChangeDeleted {
+			continue
+		}
+		k, kErr := r.KeyFromSchemaPath(change.Path)
+		if kErr != nil {
+			// Skip files that don't map to valid keys
+			continue
+		}
+		if change.IsNew() {
+			p.Added = append(p.Added, k)
+		} else {
+			p.Modified = append(p.Modified, k)
+		}
+	}
+
+	partialChanges, err := g.GetSchemaChangesBetween(
+		ctx, p.ToAnchor, p.FromAnchor, filepath.Join(r.rootDirectory, PartialsDir), PartialSuffix,
+	)
+	if err != nil {
+		return err
+	}
+	dependents, err := r.partialChangeDependents(ctx, partialChanges)
+	if err != nil {
+		return err
+	}
+
+	ec, err := r.config.EnvConfig(p.To)
+	if err != nil {
+		return err
+	}
+	rangeDependents, err := r.RangeDependents(ctx, ec, p.Added)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range append(dependents, rangeDependents...) {
+		if !slices.Contains(p.Added, k) && !slices.Contains(p.Modified, k) {
+			p.Modified = append(p.Modified, k)
+		}
+	}
+	slices.Sort(p.Added)
+	slices.Sort(p.Modified)
+	return nil
+}
+
+// Unpromoted returns the sorted keys of the schemas which have changed since the given anchor of an
+// environment, and also since the anchor of the stage before it in the promotion order. Deploying these
+// schemas to the environment would skip the previous stage.
+func (r *Registry) Unpromoted(ctx context.Context, g repo.Gitter, anchor, stageAnchor repo.Revision) ([]Key, error) {
+	changed, err := r.changedSince(ctx, g, anchor)
+	if err != nil {
+		return nil, err
+	}
+	changedInStage, err := r.changedSince(ctx, g, stageAnchor)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	for _, k := range changed {
+		if slices.Contains(changedInStage, k) {
+			keys = appendUnique(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// changedSince returns the keys of the schemas whose source, or a partial they include, has changed since
+// the anchor. Deleted schemas are not included.
+func (r *Registry) changedSince(ctx context.Context, g repo.Gitter, anchor repo.Revision) ([]Key, error) {
+	changes, err := g.GetSchemaChanges(ctx, anchor, r.rootDirectory, SchemaSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	for _, change := range changes {
+		if change.Status == repo.ChangeDeleted {
+			continue
+		}
+		if k, kErr := r.KeyFromSchemaPath(change.Path); kErr == nil {
+			keys = append(keys, k)
+		}
+	}
+
+	dependents, err := r.ChangedPartialDependents(ctx, g, anchor)
+	if err != nil {
+		return nil, err
+	}
+	return append(keys, dependents...), nil
+}
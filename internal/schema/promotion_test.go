@@ -0,0 +1,226 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+const promotionConfigData = `
+environments:
+  dev:
+    privateUrlRoot: "https://dev.json-schemas.internal.myorg.io/"
+    publicUrlRoot: "https://dev.json-schemas.myorg.io/"
+    allowSchemaMutation: true
+  prod:
+    privateUrlRoot: "https://json-schemas.internal.myorg.io/"
+    publicUrlRoot: "https://json-schemas.myorg.io/"
+    isProduction: true
+promotionOrder: [dev, prod]
+`
+
+// setupPromotionRegistry creates a registry holding the given schemas, with dev and prod environments.
+func setupPromotionRegistry(t *testing.T, schemas schemaMap) *Registry {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.JsmRegistryConfigFile), []byte(promotionConfigData), 0o600))
+	r, err := NewRegistry(dir, &mockCompiler{}, fsh.NewPathResolver(), fsh.NewEnvProvider())
+	require.NoError(t, err)
+	createSchemaFiles(t, r, schemas)
+	return r
+}
+
+// promotionGitter returns a gitter whose environments have the given numbers of deployments, and whose
+// registry at the latest deployment to dev is the given registry.
+func promotionGitter(deployments map[config.Env]int, dev *Registry, changes []repo.Change) *mockGitter {
+	return &mockGitter{
+		deploymentsFunc: func(_ context.Context, env config.Env) ([]repo.Deployment, error) {
+			return make([]repo.Deployment, deployments[env]), nil
+		},
+		getLatestAnchorFunc: func(_ context.Context, env config.Env) (repo.Revision, error) {
+			return repo.Revision("jsm-deploy/" + string(env) + "/1"), nil
+		},
+		revisionFSFunc: func(_ context.Context, _ repo.Revision, _ string) (fs.FS, error) {
+			return os.DirFS(dev.RootDirectory()), nil
+		},
+		changesBetweenFunc: func(_ context.Context, _, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+			if suffix == PartialSuffix {
+				return nil, nil
+			}
+			return changes, nil
+		},
+	}
+}
+
+func TestRegistry_Promotion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	devSchemas := func() schemaMap {
+		return schemaMap{
+			"domain_a_1_0_0": `{"type": "object", "title": "deployed to dev"}`,
+			"domain_b_1_0_0": `{"type": "string"}`,
+		}
+	}
+	changes := func(r *Registry) []repo.Change {
+		path := func(k Key) string { return New(k, r).Path(FilePath) }
+		return []repo.Change{
+			{Path: path("domain_a_1_0_0"), Status: repo.ChangeAdded},
+			{Path: path("domain_b_1_0_0"), Status: repo.ChangeModified},
+			{Path: path("domain_gone_1_0_0"), Status: repo.ChangeDeleted},
+			{Path: filepath.Join(r.RootDirectory(), "README.md"), Status: repo.ChangeAdded},
+		}
+	}
+
+	t.Run("schemas deployed to one environment but not the other", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, schemaMap{"domain_a_1_0_0": `{"title": "not yet deployed to dev"}`})
+		dev := setupPromotionRegistry(t, devSchemas())
+		var from, to repo.Revision
+		g := promotionGitter(map[config.Env]int{"dev": 2, "prod": 1}, dev, changes(r))
+		g.changesBetweenFunc = func(_ context.Context, f, tr repo.Revision, _, suffix string) ([]repo.Change, error) {
+			if suffix == PartialSuffix {
+				return nil, nil
+			}
+			from, to = f, tr
+			return changes(r), nil
+		}
+
+		p, err := r.Promotion(ctx, g, "dev", "prod")
+		require.NoError(t, err)
+
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), from)
+		assert.Equal(t, repo.Revision("jsm-deploy/dev/1"), to)
+		assert.Equal(t, repo.Revision("jsm-deploy/dev/1"), p.FromAnchor)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), p.ToAnchor)
+		assert.Equal(t, []Key{"domain_a_1_0_0"}, p.Added)
+		assert.Equal(t, []Key{"domain_b_1_0_0"}, p.Modified)
+		assert.Equal(t, []Key{"domain_a_1_0_0", "domain_b_1_0_0"}, p.Keys())
+
+		// The schemas are read as they were when they were deployed to dev.
+		assert.Equal(t, r.RootDirectory(), p.Registry().RootDirectory())
+		s, err := p.Registry().GetSchemaByKey("domain_a_1_0_0")
+		require.NoError(t, err)
+		ri, err := p.Registry().CoordinateRender(s, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Contains(t, string(ri.Rendered), "deployed to dev")
+	})
+
+	t.Run("first deployment to an environment", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		dev := setupPromotionRegistry(t, devSchemas())
+		g := promotionGitter(map[config.Env]int{"dev": 1}, dev, changes(r))
+
+		p, err := r.Promotion(ctx, g, "dev", "prod")
+		require.NoError(t, err)
+		assert.Empty(t, p.ToAnchor)
+	})
+
+	t.Run("no deployments to promote from", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		g := promotionGitter(map[config.Env]int{"prod": 1}, r, nil)
+
+		_, err := r.Promotion(ctx, g, "dev", "prod")
+		var target *NoDeploymentsError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, config.Env("dev"), target.Env)
+	})
+
+	t.Run("git errors", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		dev := setupPromotionRegistry(t, devSchemas())
+
+		g := promotionGitter(map[config.Env]int{"dev": 1}, dev, nil)
+		g.deploymentsFunc = func(context.Context, config.Env) ([]repo.Deployment, error) {
+			return nil, errors.New("no tags")
+		}
+		_, err := r.Promotion(ctx, g, "dev", "prod")
+		require.ErrorContains(t, err, "no tags")
+
+		g = promotionGitter(map[config.Env]int{"dev": 1}, dev, nil)
+		g.revisionFSFunc = func(context.Context, repo.Revision, string) (fs.FS, error) {
+			return nil, errors.New("no tree")
+		}
+		_, err = r.Promotion(ctx, g, "dev", "prod")
+		require.ErrorContains(t, err, "no tree")
+
+		g = promotionGitter(map[config.Env]int{"dev": 1}, dev, nil)
+		g.changesBetweenFunc = func(context.Context, repo.Revision, repo.Revision, string, string) ([]repo.Change, error) {
+			return nil, errors.New("diff failed")
+		}
+		_, err = r.Promotion(ctx, g, "dev", "prod")
+		require.ErrorContains(t, err, "diff failed")
+	})
+
+	t.Run("no registry at the revision", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		g := promotionGitter(map[config.Env]int{"dev": 1}, r, nil)
+		g.revisionFSFunc = func(context.Context, repo.Revision, string) (fs.FS, error) {
+			return os.DirFS(t.TempDir()), nil
+		}
+
+		_, err := r.Promotion(ctx, g, "dev", "prod")
+		var target *config.MissingConfigError
+		require.ErrorAs(t, err, &target)
+	})
+}
+
+func TestRegistry_Unpromoted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	r := setupPromotionRegistry(t, schemaMap{
+		"domain_a_1_0_0": `{"type": "object"}`,
+		"domain_b_1_0_0": `{"type": "object"}`,
+		"domain_c_1_0_0": `{"type": "object"}`,
+	})
+	path := func(k Key) string { return New(k, r).Path(FilePath) }
+	changed := map[repo.Revision][]repo.Change{
+		"prod-anchor": {
+			{Path: path("domain_a_1_0_0"), Status: repo.ChangeModified},
+			{Path: path("domain_b_1_0_0"), Status: repo.ChangeAdded},
+			{Path: path("domain_old_1_0_0"), Status: repo.ChangeDeleted},
+		},
+		"dev-anchor": {
+			{Path: path("domain_b_1_0_0"), Status: repo.ChangeAdded},
+			{Path: path("domain_c_1_0_0"), Status: repo.ChangeAdded},
+			{Path: path("domain_old_1_0_0"), Status: repo.ChangeDeleted},
+		},
+	}
+	g := &mockGitter{
+		getSchemaChangesFunc: func(_ context.Context, anchor repo.Revision, _, suffix string) ([]repo.Change, error) {
+			if suffix == PartialSuffix {
+				return nil, nil
+			}
+			return changed[anchor], nil
+		},
+	}
+
+	keys, err := r.Unpromoted(ctx, g, "prod-anchor", "dev-anchor")
+	require.NoError(t, err)
+	assert.Equal(t, []Key{"domain_b_1_0_0"}, keys)
+
+	keys, err = r.Unpromoted(ctx, g, "prod-anchor", "up-to-date")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	g.getSchemaChangesFunc = func(context.Context, repo.Revision, string, string) ([]repo.Change, error) {
+		return nil, errors.New("diff failed")
+	}
+	_, err = r.Unpromoted(ctx, g, "prod-anchor", "dev-anchor")
+	require.ErrorContains(t, err, "diff failed")
+}
@@ -1,7 +1,9 @@
 package schema
 
 import (
+	"context"
 	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,14 +22,22 @@ const RootDirEnvVar = "JSM_REGISTRY_ROOT_DIR"
 // Registry is the object which represents a JSM Registry, and also stores the schemas in memory.
 type Registry struct {
 	rootDirectory string
+	fsys          fs.FS // The files of the registry, rooted at rootDirectory
 	config        *config.Config
 	cache         Cache
 	compiler      validator.Compiler
 	pathResolver  fsh.PathResolver
 	envProvider   fsh.EnvProvider
-	mu            sync.RWMutex       // Protects cache
-	loadGroup     singleflight.Group // Prevents duplicate loads
-	renderGroup   singleflight.Group // Prevents duplicate renders/compilations
+	mu            sync.RWMutex                // Protects cache
+	loadGroup     singleflight.Group          // Prevents duplicate loads
+	renderGroup   singleflight.Group          // Prevents duplicate renders/compilations
+	families      map[string]*FamilyMetadata  // Family metadata by family directory (nil if none)
+	familySchema  validator.Validator         // Compiled family metadata meta-schema
+	familyMu      sync.Mutex                  // Protects families and familySchema
+	partialSet    map[string]*Partial         // Template partials by name (nil until loaded)
+	partialsMu    sync.Mutex                  // Protects partialSet
+	externals     map[string]externalRegistry // External registries by name (nil until one is referenced)
+	externalsMu   sync.Mutex                  // Protects externals
 }
 
 // NewRegistry creates a new JSM registry.
@@ -48,14 +58,11 @@ func NewRegistry(
 		return nil, err
 	}
 
-	return &Registry{
-		cache:         make(Cache),
-		compiler:      compiler,
-		rootDirectory: rd,
-		config:        cfg,
-		pathResolver:  pathResolver,
-		envProvider:   envProvider,
-	}, nil
+	r := newRegistry(rd, os.DirFS(rd), cfg, compiler, pathResolver, envProvider)
+	if err = r.loadVendored(); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
 // initRootDirectory attempts to initialise the registry root directory.
@@ -88,6 +95,25 @@ func (r *Registry) RootDirectory() string {
 	return r.rootDirectory
 }
 
+// GitRelativeRootDirectory returns the registry root directory relative to the root of the git repository
+// containing it, using forward slashes. It is empty if the registry root is the repository root, or if the
+// registry is not within a git repository.
+func (r *Registry) GitRelativeRootDirectory(ctx context.Context) (string, error) {
+	gitRoot, err := gitTopLevel(ctx, r.pathResolver, r.rootDirectory)
+	if err != nil || gitRoot == "" {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(gitRoot, r.rootDirectory)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
 // Config returns the registry configuration.
 func (r *Registry) Config() (*config.Config, error) {
 	if r.config == nil {
@@ -106,11 +132,28 @@ func (r *Registry) Reset() {
 	r.mu.Lock()
 	r.cache = make(Cache)
 	r.mu.Unlock()
+
+	// The family meta-schema was cleared from the compiler, and family.yml files may have changed.
+	r.familyMu.Lock()
+	r.families = nil
+	r.familySchema = nil
+	r.familyMu.Unlock()
+
+	// Partials may have been added, removed or edited.
+	r.partialsMu.Lock()
+	r.partialSet = nil
+	r.partialsMu.Unlock()
+
+	// The schemas of external registries were cleared from the compiler too.
+	r.externalsMu.Lock()
+	r.externals = nil
+	r.externalsMu.Unlock()
 }
 
 // KeyFromSchemaPath converts a file path to a Key.
 // It handles both absolute and relative paths, validates the file ends with SchemaSuffix,
-// ensures it's a file (not a directory), and extracts the Key from the filename.
+// ensures it's a file (not a directory), extracts the Key from the filename, and checks
+// that the file is in the directory the Key implies.
 func (r *Registry) KeyFromSchemaPath(path string) (Key, error) {
 	var err error
 
@@ -119,7 +162,7 @@ func (r *Registry) KeyFromSchemaPath(path string) (Key, error) {
 		return "", &NotFoundError{Path: path}
 	}
 
-	info, err := os.Stat(path)
+	info, err := r.stat(path)
 	if err != nil {
 		return "", err
 	}
@@ -144,6 +187,9 @@ func (r *Registry) KeyFromSchemaPath(path string) (Key, error) {
 	if err != nil {
 		return "", err
 	}
+	if expected := c.Path(FilePath, r.rootDirectory); expected != path {
+		return "", &SchemaLocationMismatchError{Key: c.Key(), Expected: expected, Actual: path}
+	}
 	return c.Key(), nil
 }
 
@@ -207,6 +253,14 @@ func (r *Registry) GetSchema(path string) (*Schema, error) {
 // - e.g. "domain-a/subdomain-a/family-name"
 // There must be at least one domain component, and the family name is always the last component.
 func (r *Registry) CreateSchema(domainAndFamilyName string) (s *Schema, err error) {
+	return r.CreateSchemaFromScaffold(domainAndFamilyName, ScaffoldOptions{})
+}
+
+// CreateSchemaFromScaffold creates the first version of a new schema family, as CreateSchema does, but with
+// its source generated by the scaffold in the options from the details given. If details are given, a starter
+// pass test document is written too. If the schema cannot be created, the directories created for it are
+// removed, so that it can be created again once the problem is fixed.
+func (r *Registry) CreateSchemaFromScaffold(domainAndFamilyName string, opts ScaffoldOptions) (s *Schema, err error) {
 	if _, err = NewSearchScope(domainAndFamilyName); err != nil {
 		return nil, err
 	}
@@ -232,8 +286,28 @@ func (r *Registry) CreateSchema(domainAndFamilyName string) (s *Schema, err erro
 		return nil, &AlreadyExistsError{K: key}
 	}
 
-	if iErr := r.initNewRegistrySchema(s); iErr != nil {
-		return nil, iErr
+	content, err := r.scaffoldContent(s, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	created := outermostMissingDir(r.rootDirectory, s.Path(HomeDir))
+	defer func() {
+		if err != nil && created != "" {
+			r.mu.Lock()
+			delete(r.cache, key)
+			r.mu.Unlock()
+			_ = os.RemoveAll(created)
+		}
+	}()
+
+	if s, err = r.initNewRegistrySchema(s, content); err != nil {
+		return nil, err
+	}
+	if opts.Details != nil {
+		if err = writeStarterPassDocument(s, opts.Details); err != nil {
+			return nil, err
+		}
 	}
 
 	return s, nil
@@ -266,17 +340,36 @@ func (r *Registry) CreateSchemaVersion(k Key, rt ReleaseType) (*Schema, error) {
 	return ns, nil
 }
 
-// initNewRegistrySchema initialises a new schema in the registry.
-func (r *Registry) initNewRegistrySchema(s *Schema) error {
-	if err := s.WriteNewSchemaFiles(); err != nil {
-		return err
+// outermostMissingDir returns the outermost directory on the path from root to dir which does not exist, or an
+// empty string if dir exists.
+func outermostMissingDir(root, dir string) string {
+	missing := ""
+	for d := dir; d != root && d != filepath.Dir(d); d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = d
+	}
+	return missing
+}
+
+// initNewRegistrySchema initialises a new schema in the registry, with the given content. The schema is loaded
+// from the file written, so that it is cached with its visibility and template, as an existing schema would be.
+func (r *Registry) initNewRegistrySchema(s *Schema, content []byte) (*Schema, error) {
+	if err := s.writeNewSchemaFiles(content); err != nil {
+		return nil, err
+	}
+
+	ls, err := Load(s.Key(), r)
+	if err != nil {
+		return nil, err
 	}
 
 	r.mu.Lock()
-	r.cache[s.Key()] = s
+	r.cache[ls.Key()] = ls
 	r.mu.Unlock()
 
-	return nil
+	return ls, nil
 }
 
 // CoordinateRender coordinates the rendering and compilation of a schema using singleflight
@@ -304,6 +397,7 @@ func (r *Registry) CoordinateRender(s *Schema, ec *config.EnvConfig) (RenderInfo
 		if err != nil {
 			return RenderInfo{}, err
 		}
+		ri.Dependencies = renderer.Dependencies()
 
 		id := s.CanonicalID(ec)
 
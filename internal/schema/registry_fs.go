@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// NewRegistryFS creates a new JSM registry whose files are read from fsys rather than from the operating
+// system. The root of fsys holds the registry, and rootDirectory is the absolute path the registry is
+// presented at, so that schema paths and keys are the same as those of a registry read from disk.
+// A registry created from a read-only fsys, such as a git revision, can render, validate and test
+// schemas, but cannot create them.
+func NewRegistryFS(
+	rootDirectory string,
+	fsys fs.FS,
+	compiler validator.Compiler,
+	pathResolver fsh.PathResolver,
+	envProvider fsh.EnvProvider,
+) (*Registry, error) {
+	rd, err := pathResolver.Abs(rootDirectory)
+	if err != nil {
+		return nil, &RegistryInitError{Path: rootDirectory, Err: err}
+	}
+
+	cfg, err := config.NewFS(fsys, rd, compiler)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newRegistry(rd, fsys, cfg, compiler, fsh.NewFSPathResolver(pathResolver, fsys, rd), envProvider)
+	if err = r.loadVendored(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// newRegistry creates a new JSM registry from its initialised parts.
+func newRegistry(
+	rd string,
+	fsys fs.FS,
+	cfg *config.Config,
+	compiler validator.Compiler,
+	pathResolver fsh.PathResolver,
+	envProvider fsh.EnvProvider,
+) *Registry {
+	return &Registry{
+		cache:         make(Cache),
+		compiler:      compiler,
+		rootDirectory: rd,
+		fsys:          fsys,
+		config:        cfg,
+		pathResolver:  pathResolver,
+		envProvider:   envProvider,
+	}
+}
+
+// fsName returns the name in the registry filesystem of a path, and whether the path is within the
+// registry root directory.
+func (r *Registry) fsName(path string) (string, bool) {
+	rel, err := filepath.Rel(r.rootDirectory, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// readFile reads the file at the given path from the registry filesystem. Paths outside the registry
+// root directory are not part of the registry, and are read from the operating system.
+func (r *Registry) readFile(path string) ([]byte, error) {
+	name, ok := r.fsName(path)
+	if !ok {
+		return os.ReadFile(path) //nolint:gosec // Path is constructed from internal registry logic
+	}
+	data, err := fs.ReadFile(r.fsys, name)
+	return data, withPath(err, path)
+}
+
+// readDir reads the directory at the given path from the registry filesystem, or from the operating
+// system if the path is outside the registry root directory.
+func (r *Registry) readDir(path string) ([]fs.DirEntry, error) {
+	name, ok := r.fsName(path)
+	if !ok {
+		return os.ReadDir(path)
+	}
+	entries, err := fs.ReadDir(r.fsys, name)
+	return entries, withPath(err, path)
+}
+
+// stat returns the FileInfo of the file at the given path in the registry filesystem, or in the
+// operating system if the path is outside the registry root directory.
+func (r *Registry) stat(path string) (fs.FileInfo, error) {
+	name, ok := r.fsName(path)
+	if !ok {
+		return os.Stat(path)
+	}
+	info, err := fs.Stat(r.fsys, name)
+	return info, withPath(err, path)
+}
+
+// walkDir walks the tree at the given path in the registry filesystem, calling fn with the path of
+// each file or directory in the tree.
+func (r *Registry) walkDir(root string, fn fs.WalkDirFunc) error {
+	name, ok := r.fsName(root)
+	if !ok {
+		return &LocationOutsideRootDirectoryError{Location: root, RootDirectory: r.rootDirectory}
+	}
+	return fs.WalkDir(r.fsys, name, func(p string, d fs.DirEntry, wErr error) error {
+		path := filepath.Join(r.rootDirectory, filepath.FromSlash(p))
+		return fn(path, d, withPath(wErr, path))
+	})
+}
+
+// withPath sets the path of a *fs.PathError to the given path, since the paths in the errors of the
+// registry filesystem are relative to the registry root.
+func withPath(err error, path string) error {
+	var pErr *fs.PathError
+	if errors.As(err, &pErr) {
+		return &fs.PathError{Op: pErr.Op, Path: path, Err: pErr.Err}
+	}
+	return err
+}
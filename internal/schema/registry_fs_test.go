@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+// revisionFS returns a registry filesystem holding two versions of a schema family, a partial and a test
+// document, none of which exist on disk.
+func revisionFS() fstest.MapFS {
+	return fstest.MapFS{
+		config.JsmRegistryConfigFile: {Data: []byte(testConfigData)},
+		"domain/person/1/0/0/domain_person_1_0_0.schema.json": {
+			Data: []byte(`{"$id": "{{ ID }}", "type": "object"}`),
+		},
+		"domain/person/1/0/0/pass/valid.json": {Data: []byte(`{}`)},
+		"domain/person/1/0/1/domain_person_1_0_1.schema.json": {
+			Data: []byte(`{"$id": "{{ ID }}", "properties": {"name": {{ template "name" }}}}`),
+		},
+		PartialsDir + "/name" + PartialSuffix: {Data: []byte(`{"type": "string"}`)},
+	}
+}
+
+func TestNewRegistryFS(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(t.TempDir(), "revision")
+	newRegistry := func(t *testing.T) *Registry {
+		t.Helper()
+		r, err := NewRegistryFS(root, revisionFS(), &mockCompiler{}, &mockPathResolver{}, &mockEnvProvider{})
+		require.NoError(t, err)
+		return r
+	}
+
+	t.Run("schemas are rendered from the filesystem", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+		assert.Equal(t, root, r.RootDirectory())
+
+		s, err := r.GetSchemaByKey("domain_person_1_0_1")
+		require.NoError(t, err)
+		ri, err := s.Render(r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"$id": "https://json-schemas.internal.myorg.io/domain_person_1_0_1.schema.json", `+
+			`"properties": {"name": {"type": "string"}}}`, string(ri.Rendered))
+	})
+
+	t.Run("schemas are searched in the filesystem", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+
+		searcher, err := NewSearcher(r, "domain")
+		require.NoError(t, err)
+		var keys []Key
+		for res := range searcher.Schemas(context.Background()) {
+			require.NoError(t, res.Err)
+			keys = append(keys, res.Key)
+		}
+		assert.ElementsMatch(t, []Key{"domain_person_1_0_0", "domain_person_1_0_1"}, keys)
+
+		_, err = NewSearcher(r, "missing")
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("versions and test documents are read from the filesystem", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+
+		s, err := r.GetSchemaByKey("domain_person_1_0_1")
+		require.NoError(t, err)
+		earlier, err := s.MajorFamilyEarlierSchemas()
+		require.NoError(t, err)
+		assert.Equal(t, []Key{"domain_person_1_0_0"}, earlier)
+
+		previous, err := r.GetSchemaByKey(earlier[0])
+		require.NoError(t, err)
+		docs, err := previous.TestDocuments(TestDocTypePass)
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		assert.Equal(t, filepath.Join(previous.Path(HomeDir), "pass", "valid.json"), docs[0].Path)
+
+		_, err = s.TestDocuments(TestDocTypePass)
+		var target *TestDirMissingConfigError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("migrations are read from the filesystem", func(t *testing.T) {
+		t.Parallel()
+		fsys := revisionFS()
+		fsys["domain/person/2/0/0/domain_person_2_0_0.schema.json"] = &fstest.MapFile{Data: []byte(`{}`)}
+		fsys["domain/person/2/0/0/"+MigrationsDir+"/01-name.yml"] = &fstest.MapFile{
+			Data: []byte("operations: [{op: delete, path: /name}]\n"),
+		}
+		r, err := NewRegistryFS(root, fsys, &mockCompiler{}, &mockPathResolver{}, &mockEnvProvider{})
+		require.NoError(t, err)
+
+		s, err := r.GetSchemaByKey("domain_person_2_0_0")
+		require.NoError(t, err)
+		m, err := s.Migrations()
+		require.NoError(t, err)
+		require.Len(t, m, 1)
+		assert.Equal(t, filepath.Join(s.Path(HomeDir), MigrationsDir, "01-name.yml"), m[0].Path)
+		assert.Equal(t, []MigrationOperation{{Op: MigrationOpDelete, Path: "/name"}}, m[0].Operations)
+	})
+
+	t.Run("keys are found from paths in the filesystem", func(t *testing.T) {
+		t.Parallel()
+		r := newRegistry(t)
+
+		k, err := r.KeyFromSchemaPath(filepath.Join(root, "domain", "person", "1", "0", "0",
+			"domain_person_1_0_0.schema.json"))
+		require.NoError(t, err)
+		assert.Equal(t, Key("domain_person_1_0_0"), k)
+
+		_, err = r.KeyFromSchemaPath(filepath.Join(root, "domain", "person", "1", "0", "2",
+			"domain_person_1_0_2.schema.json"))
+		var pErr *fs.PathError
+		require.ErrorAs(t, err, &pErr)
+		assert.Equal(t, filepath.Join(root, "domain", "person", "1", "0", "2", "domain_person_1_0_2.schema.json"),
+			pErr.Path)
+	})
+
+	t.Run("missing config", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewRegistryFS(root, fstest.MapFS{}, &mockCompiler{}, &mockPathResolver{}, &mockEnvProvider{})
+		var target *config.MissingConfigError
+		require.ErrorAs(t, err, &target)
+	})
+
+	t.Run("root directory cannot be resolved", func(t *testing.T) {
+		t.Parallel()
+		pr := &mockPathResolver{absFn: func(_ string) (string, error) { return "", fs.ErrInvalid }}
+		_, err := NewRegistryFS("registry", revisionFS(), &mockCompiler{}, pr, &mockEnvProvider{})
+		var target *RegistryInitError
+		require.ErrorAs(t, err, &target)
+	})
+}
@@ -1,8 +1,10 @@
 package schema
 
 import (
+	"context"
 	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"sync"
@@ -239,6 +241,22 @@ func TestKeyFromSchemaPath(t *testing.T) {
 			},
 			wantErrMsgContains: "is not a valid schema key",
 		},
+		{
+			name: "schema file in the wrong version directory",
+			setup: func(t *testing.T, r *Registry) string {
+				t.Helper()
+				dir := filepath.Join(r.rootDirectory, "domain", "family", "1", "1", "0")
+				if err := os.MkdirAll(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				filePath := filepath.Join(dir, "domain_family_1_0_0.schema.json")
+				if err := os.WriteFile(filePath, []byte("{}"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				return filePath
+			},
+			wantErrMsgContains: "is not where its key domain_family_1_0_0 implies",
+		},
 	}
 
 	for _, tt := range tests {
@@ -938,3 +956,43 @@ func TestRegistry_Reset(t *testing.T) {
 	registry.mu.RUnlock()
 	assert.False(t, exists, "schema cache should be cleared after Reset")
 }
+
+func TestRegistry_GitRelativeRootDirectory(t *testing.T) {
+	t.Parallel()
+
+	newRegistryAt := func(t *testing.T, dir string) *Registry {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(dir, config.JsmRegistryConfigFile), []byte(testConfigData), 0o600))
+		r, err := NewRegistry(dir, &mockCompiler{}, fsh.NewPathResolver(), fsh.NewEnvProvider())
+		require.NoError(t, err)
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		gitInit bool
+		regDir  string
+		want    string
+	}{
+		{name: "registry below git root", gitInit: true, regDir: "schemas/registry", want: "schemas/registry"},
+		{name: "registry at git root", gitInit: true, regDir: "", want: ""},
+		{name: "not in a git repository", gitInit: false, regDir: "schemas", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root := t.TempDir()
+			if tt.gitInit {
+				require.NoError(t, exec.CommandContext(context.Background(), "git", "-C", root, "init").Run())
+			}
+			r := newRegistryAt(t, filepath.Join(root, tt.regDir))
+
+			got, err := r.GitRelativeRootDirectory(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
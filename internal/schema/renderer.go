@@ -2,6 +2,9 @@ package schema
 
 import (
 	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
 	"text/template"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
@@ -13,8 +16,9 @@ import (
 // Renderer is a go template renderer which converts a source schema into
 // a rendered schema targeting a specific environment.
 type Renderer struct {
-	s  *Schema           // The schema to render
-	ec *config.EnvConfig // The target environment for which the schema is being rendered.
+	s    *Schema           // The schema to render
+	ec   *config.EnvConfig // The target environment for which the schema is being rendered.
+	deps []Dependency      // The schemas referenced during the last Render
 }
 
 // NewRenderer creates a new renderer for the given schema and environment configuration.
@@ -38,10 +42,13 @@ func (r *Renderer) Render() ([]byte, validator.JSONSchema, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	r.deps = nil
 
 	tmpl.Funcs(template.FuncMap{
-		"ID":  r.ID,
-		"JSM": r.JSM,
+		"ID":     r.ID,
+		"JSM":    r.JSM,
+		"JSMRef": r.JSMRef,
+		"Var":    r.Var,
 	})
 
 	var buf bytes.Buffer
@@ -62,24 +69,143 @@ func (r *Renderer) ID() (ID, error) {
 	return r.s.CanonicalID(r.ec), nil
 }
 
+// Dependencies returns the schemas referenced with {{ JSM }} and {{ JSMRef }} during the last Render,
+// in the order in which they were first referenced.
+func (r *Renderer) Dependencies() []Dependency {
+	return r.deps
+}
+
 // JSM is a template function which returns the canonical ID of the referenced schema.
+// The argument is either a schema key, or a version range such as domain_family_1 or domain_family_1_2,
+// in which case the latest version in the range is referenced. A key may be prefixed by the name of an
+// external registry, such as payments:domain_family_1_0_0, to reference a schema in another team's registry.
 func (r *Renderer) JSM(arg string) (ID, error) {
-	c, err := NewCoreFromString(arg, KeySeparator)
+	ref, err := r.renderReferenced(arg)
+	if err != nil {
+		return "", err
+	}
+	return ref.id, nil
+}
+
+// JSMRef is a template function which returns a $ref URI for a sub-schema of the referenced schema,
+// identified by a JSON Pointer fragment - e.g. {{ JSMRef "domain_family_1_0_0" "#/$defs/address" }}.
+// The fragment must resolve within the rendered referenced schema.
+func (r *Renderer) JSMRef(arg, pointer string) (string, error) {
+	ref, err := r.renderReferenced(arg)
+	if err != nil {
+		return "", err
+	}
+
+	fragment := strings.TrimPrefix(pointer, "#")
+	tokens, err := parsePointer(fragment)
+	if err != nil {
+		return "", &JSMRefInvalidPointerError{Key: ref.key, Pointer: pointer}
+	}
+
+	if _, ok, rErr := resolve(any(ref.doc), tokens); rErr != nil || !ok {
+		return "", &JSMRefFragmentNotFoundError{From: r.s.Key(), To: ref.key, Pointer: pointer}
+	}
+
+	return string(ref.id) + "#" + fragment, nil
+}
+
+// reference is a schema referenced by a template argument.
+type reference struct {
+	key Key                  // The key of the schema, prefixed by its registry's name if it is external
+	id  ID                   // The canonical ID of the schema in the target environment
+	doc validator.JSONSchema // The rendered schema
+}
+
+// renderReferenced loads the schema with the key (or latest version in the range) given in a template
+// argument, renders it for the target environment, and records it as a dependency.
+func (r *Renderer) renderReferenced(arg string) (reference, error) {
+	if name, k, ok := strings.Cut(arg, ExternalKeySeparator); ok {
+		return r.renderExternal(arg, name, k)
+	}
+
+	key, vr, err := r.resolveKey(arg)
 	if err != nil {
-		return "", &JSMArgInvalidKeyError{Arg: arg}
+		return reference{}, err
 	}
-	key := c.Key()
 
 	s, err := r.s.registry.GetSchemaByKey(key)
 	if err != nil {
-		return "", &JSMArgNotFoundError{Key: key, Wrapped: err}
+		return reference{}, &JSMArgNotFoundError{Key: key, Wrapped: err}
 	}
 
 	// We also need to force a compilation of the schema to ensure that it is valid.
 	// This will render and compile the schema if it hasn't been rendered yet.
-	if _, err = s.Render(r.ec); err != nil {
-		return "", err
+	ri, err := s.Render(r.ec)
+	if err != nil {
+		return reference{}, err
+	}
+
+	d := Dependency{Key: key, Range: vr}
+	if !slices.Contains(r.deps, d) {
+		r.deps = append(r.deps, d)
 	}
 
-	return s.CanonicalID(r.ec), nil
+	return reference{key: key, id: s.CanonicalID(r.ec), doc: ri.Unmarshalled}, nil
+}
+
+// renderExternal resolves a template argument referencing the schema with key k in the external registry
+// with the given name. Version ranges are not supported. The schema is not recorded as a dependency, as it
+// is built and deployed with its own registry rather than this one.
+func (r *Renderer) renderExternal(arg, name, k string) (reference, error) {
+	c, err := NewCoreFromString(k, KeySeparator)
+	if err != nil {
+		return reference{}, &JSMArgInvalidKeyError{Arg: arg}
+	}
+
+	er, err := r.s.registry.external(name)
+	if err != nil {
+		return reference{}, err
+	}
+	id, doc, err := er.schema(c.Key(), r.ec.Env)
+	if err != nil {
+		return reference{}, &JSMArgNotFoundError{Key: Key(arg), Wrapped: err}
+	}
+	return reference{key: Key(arg), id: id, doc: doc}, nil
+}
+
+// resolveKey returns the key identified by a template argument. If the argument is a version range,
+// the range is also returned.
+func (r *Renderer) resolveKey(arg string) (Key, string, error) {
+	if c, err := NewCoreFromString(arg, KeySeparator); err == nil {
+		return c.Key(), "", nil
+	}
+
+	vr, err := ParseVersionRange(arg)
+	if err != nil {
+		return "", "", &JSMArgInvalidKeyError{Arg: arg}
+	}
+	key, err := vr.Latest(r.s.registry)
+	if err != nil {
+		return "", "", &JSMArgNotFoundError{Key: Key(arg), Wrapped: err}
+	}
+	return key, vr.String(), nil
+}
+
+// Var is a template function which returns the value of the named variable for the target environment.
+// String values are JSON-escaped without their surrounding quotes, so that they can be embedded within a JSON
+// string. Any other value is returned as JSON, so that it can be used directly as a JSON value - e.g.
+// "enum": {{ Var "statuses" }}.
+func (r *Renderer) Var(name string) (string, error) {
+	v, ok := r.ec.Variable(name)
+	if !ok {
+		return "", &UndefinedVariableError{Name: name, Env: r.ec.Env}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", &InvalidVariableError{Name: name, Env: r.ec.Env, Wrapped: err}
+	}
+
+	b := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	if _, isStr := v.(string); isStr {
+		b = b[1 : len(b)-1]
+	}
+	return string(b), nil
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
 )
 
 func TestRenderer_Render_Success(t *testing.T) {
@@ -140,6 +142,140 @@ func TestRenderer_JSM_Errs(t *testing.T) {
 	}
 }
 
+func TestRenderer_JSMRef(t *testing.T) {
+	t.Parallel()
+	r := setupTestRegistry(t)
+	createSchemaFiles(t, r, schemaMap{
+		"domain_dep_1_0_0": `{"$defs": {"address": {"type": "object"}, "a/b": {"type": "string"}}, ` +
+			`"items": [{"type": "number"}]}`,
+	})
+	s := New(Key("domain_root_1_0_0"), r)
+	renderer := NewRenderer(s, r.config.ProductionEnvConfig())
+	depID := "https://json-schemas.internal.myorg.io/domain_dep_1_0_0.schema.json"
+
+	t.Run("fragments which exist", func(t *testing.T) {
+		t.Parallel()
+		for pointer, want := range map[string]string{
+			"#/$defs/address": depID + "#/$defs/address",
+			"/$defs/address":  depID + "#/$defs/address",
+			"#/$defs/a~1b":    depID + "#/$defs/a~1b",
+			"#/items/0":       depID + "#/items/0",
+		} {
+			got, err := renderer.JSMRef("domain_dep_1_0_0", pointer)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+	})
+
+	t.Run("fragment not found", func(t *testing.T) {
+		t.Parallel()
+		for _, pointer := range []string{"#/$defs/missing", "#/items/1", "#/$defs/address/type/x"} {
+			_, err := renderer.JSMRef("domain_dep_1_0_0", pointer)
+			var target *JSMRefFragmentNotFoundError
+			require.ErrorAs(t, err, &target)
+			assert.Equal(t, Key("domain_root_1_0_0"), target.From)
+			assert.Equal(t, Key("domain_dep_1_0_0"), target.To)
+			assert.Equal(t, pointer, target.Pointer)
+		}
+	})
+
+	t.Run("invalid pointer", func(t *testing.T) {
+		t.Parallel()
+		_, err := renderer.JSMRef("domain_dep_1_0_0", "#$defs/address")
+		require.ErrorAs(t, err, new(*JSMRefInvalidPointerError))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		t.Parallel()
+		_, err := renderer.JSMRef("not-a-valid-key", "#/$defs/address")
+		require.ErrorAs(t, err, new(*JSMArgInvalidKeyError))
+	})
+
+	t.Run("schema not found", func(t *testing.T) {
+		t.Parallel()
+		_, err := renderer.JSMRef("domain_missing_1_0_0", "#/$defs/address")
+		require.ErrorAs(t, err, new(*JSMArgNotFoundError))
+	})
+}
+
+func TestRenderer_Render_JSMRef(t *testing.T) {
+	t.Parallel()
+	r := setupTestRegistry(t)
+	createSchemaFiles(t, r, schemaMap{
+		"domain_dep_1_0_0":  `{"$defs": {"address": {"type": "object"}}}`,
+		"domain_root_1_0_0": `{"$ref": "{{ JSMRef "domain_dep_1_0_0" "#/$defs/address" }}"}`,
+		"domain_bad_1_0_0":  `{"$ref": "{{ JSMRef "domain_dep_1_0_0" "#/$defs/missing" }}"}`,
+	})
+	ec := r.config.ProductionEnvConfig()
+
+	s, err := r.GetSchemaByKey("domain_root_1_0_0")
+	require.NoError(t, err)
+	rb, _, err := NewRenderer(s, ec).Render()
+	require.NoError(t, err)
+	assert.JSONEq(t,
+		`{"$ref": "https://json-schemas.internal.myorg.io/domain_dep_1_0_0.schema.json#/$defs/address"}`,
+		string(rb))
+
+	s, err = r.GetSchemaByKey("domain_bad_1_0_0")
+	require.NoError(t, err)
+	_, _, err = NewRenderer(s, ec).Render()
+	var target *TemplateExecutionFailedError
+	require.ErrorAs(t, err, &target)
+	assert.ErrorContains(t, err, "schema domain_bad_1_0_0 references domain_dep_1_0_0#/$defs/missing")
+}
+
+func TestRenderer_JSM_VersionRange(t *testing.T) {
+	t.Parallel()
+	r := setupTestRegistry(t)
+	createSchemaFiles(t, r, schemaMap{
+		"domain_person_1_0_0": `{"$defs": {"name": {"type": "string"}}}`,
+		"domain_person_1_2_3": `{"$defs": {"name": {"type": "string"}}}`,
+		"domain_person_2_0_0": "{}",
+	})
+	ec := r.config.ProductionEnvConfig()
+	idRoot := "https://json-schemas.internal.myorg.io/"
+
+	t.Run("latest version in the range is referenced", func(t *testing.T) {
+		t.Parallel()
+		renderer := NewRenderer(New("domain_root_1_0_0", r), ec)
+
+		id, err := renderer.JSM("domain_person_1")
+		require.NoError(t, err)
+		assert.Equal(t, ID(idRoot+"domain_person_1_2_3.schema.json"), id)
+
+		ref, err := renderer.JSMRef("domain_person_1_0", "#/$defs/name")
+		require.NoError(t, err)
+		assert.Equal(t, idRoot+"domain_person_1_0_0.schema.json#/$defs/name", ref)
+	})
+
+	t.Run("range without versions", func(t *testing.T) {
+		t.Parallel()
+		renderer := NewRenderer(New("domain_root_1_0_0", r), ec)
+
+		_, err := renderer.JSM("domain_person_3")
+		var target *JSMArgNotFoundError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, Key("domain_person_3"), target.Key)
+	})
+
+	t.Run("dependencies are recorded", func(t *testing.T) {
+		t.Parallel()
+		createSchemaFiles(t, r, schemaMap{
+			"domain_root_1_0_0": `{"allOf": [{"$ref": "{{ JSM "domain_person_1" }}"}, ` +
+				`{"$ref": "{{ JSM "domain_person_2_0_0" }}"}, {"$ref": "{{ JSMRef "domain_person_1" "#/$defs/name" }}"}]}`,
+		})
+		s, err := r.GetSchemaByKey("domain_root_1_0_0")
+		require.NoError(t, err)
+
+		ri, err := s.Render(ec)
+		require.NoError(t, err)
+		assert.Equal(t, []Dependency{
+			{Key: "domain_person_1_2_3", Range: "domain_person_1"},
+			{Key: "domain_person_2_0_0"},
+		}, ri.Dependencies)
+	})
+}
+
 func TestRenderer_ID(t *testing.T) {
 	t.Parallel()
 	r := setupTestRegistry(t)
@@ -156,12 +292,105 @@ func TestRenderer_ID(t *testing.T) {
 	assert.Equal(t, ID("https://json-schemas.myorg.io/domain_family_1_0_0.schema.json"), id)
 }
 
+func TestRenderer_Var(t *testing.T) {
+	t.Parallel()
+	r := setupTestRegistry(t)
+	s := New(Key("domain_family_1_0_0"), r)
+
+	ec := &config.EnvConfig{
+		Env: "dev",
+		Variables: map[string]any{
+			"host":     "api.dev.myorg.io",
+			"pattern":  `^"a\\b" & <c>$`,
+			"statuses": []any{"active", "test"},
+			"maxItems": 10,
+			"contact":  map[string]any{"email": "team@myorg.io"},
+			"invalid":  func() {},
+		},
+	}
+	renderer := NewRenderer(s, ec)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "host", want: "api.dev.myorg.io"},
+		{name: "pattern", want: `^\"a\\\\b\" & <c>$`},
+		{name: "statuses", want: `["active","test"]`},
+		{name: "maxItems", want: "10"},
+		{name: "contact", want: `{"email":"team@myorg.io"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := renderer.Var(tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("undefined variable", func(t *testing.T) {
+		t.Parallel()
+		_, err := renderer.Var("missing")
+		var target *UndefinedVariableError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, "missing", target.Name)
+		assert.Equal(t, config.Env("dev"), target.Env)
+	})
+
+	t.Run("value cannot be converted to JSON", func(t *testing.T) {
+		t.Parallel()
+		_, err := renderer.Var("invalid")
+		require.ErrorAs(t, err, new(*InvalidVariableError))
+	})
+}
+
+func TestRenderer_Render_Var(t *testing.T) {
+	t.Parallel()
+	r := setupTestRegistry(t)
+
+	k := Key("domain_family_1_0_0")
+	createSchemaFiles(t, r, schemaMap{
+		k: `{"properties": {"host": {"const": "{{ Var "host" }}"}, "status": {"enum": {{ Var "statuses" }}}}}`,
+	})
+	s, err := r.GetSchemaByKey(k)
+	require.NoError(t, err)
+
+	t.Run("variables are rendered for the environment", func(t *testing.T) {
+		t.Parallel()
+		ec := &config.EnvConfig{
+			Env:       "dev",
+			Variables: map[string]any{"host": "api.dev.myorg.io", "statuses": []any{"active", "test"}},
+		}
+
+		rb, _, rErr := NewRenderer(s, ec).Render()
+		require.NoError(t, rErr)
+		assert.JSONEq(t,
+			`{"properties": {"host": {"const": "api.dev.myorg.io"}, "status": {"enum": ["active", "test"]}}}`,
+			string(rb))
+	})
+
+	t.Run("undefined variable", func(t *testing.T) {
+		t.Parallel()
+		ec := &config.EnvConfig{Env: "prod", Variables: map[string]any{"host": "api.myorg.io"}}
+
+		_, _, rErr := NewRenderer(s, ec).Render()
+		var target *TemplateExecutionFailedError
+		require.ErrorAs(t, rErr, &target)
+		assert.ErrorContains(t, rErr, "variable 'statuses'")
+		assert.ErrorContains(t, rErr, "environment 'prod'")
+	})
+}
+
 // loadTemplate is a helper to create a template without full Load() cycle.
 func loadTemplate(t *testing.T, content string) *template.Template {
 	t.Helper()
 	tmpl, err := template.New("test").Funcs(template.FuncMap{
-		"ID":  func() string { return "" },
-		"JSM": func(_ string) string { return "" },
+		"ID":     func() string { return "" },
+		"JSM":    func(_ string) string { return "" },
+		"JSMRef": func(_, _ string) string { return "" },
+		"Var":    func(_ string) string { return "" },
 	}).Parse(content)
 	if err != nil {
 		t.Fatal(err)
@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+// Rollback describes how to undo the latest deployment to an environment: the schemas it introduced are
+// removed, and those it changed or deleted are reverted to the state of the deployment before it.
+type Rollback struct {
+	Env        config.Env
+	RolledBack repo.Revision // The latest deployment to Env which has not been rolled back
+	Restored   repo.Revision // The deployment before RolledBack, or empty if there was none
+	Reverted   []Key         // Schemas to restore as they were at Restored
+	Removed    []Key         // Schemas introduced by RolledBack, which did not exist at Restored
+	registry   *Registry     // The registry as it was at Restored, or nil if there was no deployment before
+}
+
+// Registry returns the registry as it was at the deployment restored, or nil if there was none.
+func (rb *Rollback) Registry() *Registry {
+	return rb.registry
+}
+
+// Rollback returns the rollback of the latest deployment to an environment which has not already been rolled
+// back. Deployments which have been rolled back are skipped, so successive rollbacks step back through the
+// deployments to the environment. As with a deployment, the schemas reverted include those which include a
+// partial changed by the deployment rolled back, and those whose version ranges resolved to a schema it
+// introduced.
+func (r *Registry) Rollback(ctx context.Context, g repo.Gitter, env config.Env) (*Rollback, error) {
+	ec, err := r.config.EnvConfig(env)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := g.Deployments(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	rollbacks, err := g.Rollbacks(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	current, previous := liveDeployments(deployments, rollbacks)
+	if current == "" {
+		return nil, &NothingToRollBackError{Env: env}
+	}
+	rb := &Rollback{Env: env, RolledBack: current, Restored: previous}
+	if err = rb.addChanges(ctx, g, r.rootDirectory); err != nil {
+		return nil, err
+	}
+	if previous == "" {
+		// Every schema was introduced by the only deployment, so there is nothing to revert.
+		return rb, nil
+	}
+
+	if rb.registry, err = r.atRevision(ctx, g, previous); err != nil {
+		return nil, err
+	}
+	dependents, err := rb.dependents(ctx, g, r, ec)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range dependents {
+		if !slices.Contains(rb.Reverted, k) && !slices.Contains(rb.Removed, k) {
+			rb.Reverted = append(rb.Reverted, k)
+		}
+	}
+	slices.Sort(rb.Reverted)
+	return rb, nil
+}
+
+// liveDeployments returns the tags of the latest deployment which has not been rolled back, and of the one
+// before it which has not been rolled back either. Either is empty if there is none. Deployments are
+// ordered oldest first.
+func liveDeployments(deployments []repo.Deployment, rollbacks []repo.Rollback) (current, previous repo.Revision) {
+	rolledBack := make(map[repo.Revision]bool, len(rollbacks))
+	for _, rb := range rollbacks {
+		rolledBack[rb.RolledBack] = true
+	}
+
+	for _, d := range slices.Backward(deployments) {
+		switch {
+		case rolledBack[d.Tag]:
+		case current == "":
+			current = d.Tag
+		default:
+			return current, d.Tag
+		}
+	}
+	return current, ""
+}
+
+// addChanges adds the schemas changed by the deployment rolled back to the rollback. Added schemas are
+// removed, and modified or deleted schemas are reverted. A renamed schema is removed at its new path, and
+// reverted at its old one.
+func (rb *Rollback) addChanges(ctx context.Context, g repo.Gitter, rootDirectory string) error {
+	changes, err := g.GetSchemaChangesBetween(ctx, rb.Restored, rb.RolledBack, rootDirectory, SchemaSuffix)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change.Status == repo.ChangeRenamed {
+			if k, ok := keyFromFilename(change.OldPath); ok {
+				rb.Reverted = appendUnique(rb.Reverted, k)
+			}
+		}
+		k, ok := keyFromFilename(change.Path)
+		if !ok {
+			// Skip files that don't map to valid keys
+			continue
+		}
+		if change.IsNew() {
+			rb.Removed = appendUnique(rb.Removed, k)
+		} else {
+			rb.Reverted = appendUnique(rb.Reverted, k)
+		}
+	}
+
+	// A schema renamed to the path of another is both removed and reverted, so is only reverted.
+	rb.Removed = slices.DeleteFunc(rb.Removed, func(k Key) bool { return slices.Contains(rb.Reverted, k) })
+	slices.Sort(rb.Removed)
+	slices.Sort(rb.Reverted)
+	return nil
+}
+
+// dependents returns the schemas at the deployment restored which render differently at the deployment
+// rolled back, without having changed themselves: those which include a partial it changed, and those whose
+// version ranges resolved to a schema it introduced.
+func (rb *Rollback) dependents(ctx context.Context, g repo.Gitter, r *Registry, ec *config.EnvConfig) ([]Key, error) {
+	partialChanges, err := g.GetSchemaChangesBetween(
+		ctx, rb.Restored, rb.RolledBack, filepath.Join(r.rootDirectory, PartialsDir), PartialSuffix,
+	)
+	if err != nil {
+		return nil, err
+	}
+	dependents, err := rb.registry.partialChangeDependents(ctx, partialChanges)
+	if err != nil {
+		return nil, err
+	}
+	if len(rb.Removed) == 0 {
+		return dependents, nil
+	}
+
+	deployed, err := r.atRevision(ctx, g, rb.RolledBack)
+	if err != nil {
+		return nil, err
+	}
+	rangeDependents, err := deployed.RangeDependents(ctx, ec, rb.Removed)
+	if err != nil {
+		return nil, err
+	}
+	return append(dependents, rangeDependents...), nil
+}
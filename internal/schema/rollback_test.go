@@ -0,0 +1,216 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+	"github.com/bitshepherds/json-schema-manager/internal/repo"
+)
+
+// rollbackGitter returns a gitter with the given deployments and rollbacks to prod, whose registry at every
+// revision is the given registry, and which reports the given changes between revisions.
+func rollbackGitter(
+	tags []repo.Revision,
+	rollbacks []repo.Rollback,
+	previous *Registry,
+	changes []repo.Change,
+) *mockGitter {
+	return &mockGitter{
+		deploymentsFunc: func(_ context.Context, env config.Env) ([]repo.Deployment, error) {
+			deployments := make([]repo.Deployment, 0, len(tags))
+			for _, tag := range tags {
+				deployments = append(deployments, repo.Deployment{Env: env, Tag: tag})
+			}
+			return deployments, nil
+		},
+		rollbacksFunc: func(context.Context, config.Env) ([]repo.Rollback, error) {
+			return rollbacks, nil
+		},
+		revisionFSFunc: func(context.Context, repo.Revision, string) (fs.FS, error) {
+			return os.DirFS(previous.RootDirectory()), nil
+		},
+		changesBetweenFunc: func(_ context.Context, _, _ repo.Revision, _, suffix string) ([]repo.Change, error) {
+			if suffix == PartialSuffix {
+				return nil, nil
+			}
+			return changes, nil
+		},
+	}
+}
+
+func TestRegistry_Rollback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	previousSchemas := func() schemaMap {
+		return schemaMap{
+			"domain_a_1_0_0":    `{"type": "object", "title": "previously deployed"}`,
+			"domain_gone_1_0_0": `{"type": "object"}`,
+			"domain_old_1_0_0":  `{"type": "object"}`,
+		}
+	}
+	changes := func(r *Registry) []repo.Change {
+		path := func(k Key) string { return New(k, r).Path(FilePath) }
+		return []repo.Change{
+			{Path: path("domain_a_1_0_0"), Status: repo.ChangeModified},
+			{Path: path("domain_b_1_0_0"), Status: repo.ChangeAdded},
+			{Path: path("domain_gone_1_0_0"), Status: repo.ChangeDeleted},
+			{Path: path("domain_new_1_0_0"), OldPath: path("domain_old_1_0_0"), Status: repo.ChangeRenamed},
+			{Path: filepath.Join(r.RootDirectory(), "README"+SchemaSuffix), Status: repo.ChangeAdded},
+		}
+	}
+
+	t.Run("latest deployment", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		previous := setupPromotionRegistry(t, previousSchemas())
+		var from, to repo.Revision
+		g := rollbackGitter([]repo.Revision{"jsm-deploy/prod/1", "jsm-deploy/prod/2"}, nil, previous, changes(r))
+		g.changesBetweenFunc = func(_ context.Context, f, tr repo.Revision, _, suffix string) ([]repo.Change, error) {
+			if suffix == PartialSuffix {
+				return nil, nil
+			}
+			from, to = f, tr
+			return changes(r), nil
+		}
+
+		rb, err := r.Rollback(ctx, g, "prod")
+		require.NoError(t, err)
+
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), from)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/2"), to)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/2"), rb.RolledBack)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), rb.Restored)
+		assert.Equal(t, []Key{"domain_a_1_0_0", "domain_gone_1_0_0", "domain_old_1_0_0"}, rb.Reverted)
+		assert.Equal(t, []Key{"domain_b_1_0_0", "domain_new_1_0_0"}, rb.Removed)
+
+		// The schemas are read as they were at the previous deployment.
+		s, err := rb.Registry().GetSchemaByKey("domain_a_1_0_0")
+		require.NoError(t, err)
+		ri, err := rb.Registry().CoordinateRender(s, r.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		assert.Contains(t, string(ri.Rendered), "previously deployed")
+	})
+
+	t.Run("rolled back deployments are skipped", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		previous := setupPromotionRegistry(t, previousSchemas())
+		rollbacks := []repo.Rollback{{Tag: "jsm-rollback/prod/1", RolledBack: "jsm-deploy/prod/3"}}
+		g := rollbackGitter([]repo.Revision{"jsm-deploy/prod/1", "jsm-deploy/prod/2", "jsm-deploy/prod/3"},
+			rollbacks, previous, changes(r))
+
+		rb, err := r.Rollback(ctx, g, "prod")
+		require.NoError(t, err)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/2"), rb.RolledBack)
+		assert.Equal(t, repo.Revision("jsm-deploy/prod/1"), rb.Restored)
+	})
+
+	t.Run("only deployment", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		path := func(k Key) string { return New(k, r).Path(FilePath) }
+		g := rollbackGitter([]repo.Revision{"jsm-deploy/prod/1"}, nil, r, []repo.Change{
+			{Path: path("domain_a_1_0_0"), Status: repo.ChangeAdded},
+		})
+
+		rb, err := r.Rollback(ctx, g, "prod")
+		require.NoError(t, err)
+		assert.Empty(t, rb.Restored)
+		assert.Empty(t, rb.Reverted)
+		assert.Equal(t, []Key{"domain_a_1_0_0"}, rb.Removed)
+		assert.Nil(t, rb.Registry())
+	})
+
+	t.Run("nothing to roll back", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		rollbacks := []repo.Rollback{{Tag: "jsm-rollback/prod/1", RolledBack: "jsm-deploy/prod/1"}}
+		g := rollbackGitter([]repo.Revision{"jsm-deploy/prod/1"}, rollbacks, r, nil)
+
+		_, err := r.Rollback(ctx, g, "prod")
+		var target *NothingToRollBackError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, config.Env("prod"), target.Env)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		_, err := r.Rollback(ctx, &mockGitter{}, "staging")
+		require.Error(t, err)
+	})
+
+	t.Run("git errors", func(t *testing.T) {
+		t.Parallel()
+		r := setupPromotionRegistry(t, nil)
+		previous := setupPromotionRegistry(t, previousSchemas())
+		tags := []repo.Revision{"jsm-deploy/prod/1", "jsm-deploy/prod/2"}
+
+		g := rollbackGitter(tags, nil, previous, nil)
+		g.deploymentsFunc = func(context.Context, config.Env) ([]repo.Deployment, error) {
+			return nil, errors.New("no tags")
+		}
+		_, err := r.Rollback(ctx, g, "prod")
+		require.ErrorContains(t, err, "no tags")
+
+		g = rollbackGitter(tags, nil, previous, nil)
+		g.rollbacksFunc = func(context.Context, config.Env) ([]repo.Rollback, error) {
+			return nil, errors.New("no rollback tags")
+		}
+		_, err = r.Rollback(ctx, g, "prod")
+		require.ErrorContains(t, err, "no rollback tags")
+
+		g = rollbackGitter(tags, nil, previous, nil)
+		g.changesBetweenFunc = func(context.Context, repo.Revision, repo.Revision, string, string) ([]repo.Change, error) {
+			return nil, errors.New("diff failed")
+		}
+		_, err = r.Rollback(ctx, g, "prod")
+		require.ErrorContains(t, err, "diff failed")
+
+		g = rollbackGitter(tags, nil, previous, nil)
+		g.revisionFSFunc = func(context.Context, repo.Revision, string) (fs.FS, error) {
+			return nil, errors.New("no tree")
+		}
+		_, err = r.Rollback(ctx, g, "prod")
+		require.ErrorContains(t, err, "no tree")
+	})
+}
+
+func TestLiveDeployments(t *testing.T) {
+	t.Parallel()
+
+	deployments := []repo.Deployment{{Tag: "d1"}, {Tag: "d2"}, {Tag: "d3"}, {Tag: "d4"}}
+	tests := []struct {
+		name       string
+		rolledBack []repo.Revision
+		current    repo.Revision
+		previous   repo.Revision
+	}{
+		{name: "no rollbacks", current: "d4", previous: "d3"},
+		{name: "latest rolled back", rolledBack: []repo.Revision{"d4"}, current: "d3", previous: "d2"},
+		{name: "earlier rolled back", rolledBack: []repo.Revision{"d3", "d2"}, current: "d4", previous: "d1"},
+		{name: "all but one rolled back", rolledBack: []repo.Revision{"d4", "d3", "d2"}, current: "d1"},
+		{name: "all rolled back", rolledBack: []repo.Revision{"d4", "d3", "d2", "d1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var rollbacks []repo.Rollback
+			for _, tag := range tt.rolledBack {
+				rollbacks = append(rollbacks, repo.Rollback{RolledBack: tag})
+			}
+			current, previous := liveDeployments(deployments, rollbacks)
+			assert.Equal(t, tt.current, current)
+			assert.Equal(t, tt.previous, previous)
+		})
+	}
+}
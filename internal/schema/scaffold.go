@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+// ScaffoldsDir is the name of the optional directory in the registry root which contains the scaffolds new
+// schemas can be created from.
+const ScaffoldsDir = "scaffolds"
+
+// ScaffoldSuffix is the suffix of scaffold files. The name of a scaffold is its filename without the suffix.
+const ScaffoldSuffix = ".schema.json.tmpl"
+
+// StarterPassDocument is the name of the pass test document written for a schema created with SchemaDetails.
+const StarterPassDocument = "starter.json"
+
+// PropertyTypes are the JSON Schema types a property given in SchemaDetails may have.
+var PropertyTypes = []string{"string", "number", "integer", "boolean", "object", "array"}
+
+// SchemaDetails are the details of a new schema given when it is created.
+type SchemaDetails struct {
+	Title       string
+	Description string
+	Public      bool // Whether the schema is published to the public URL root, with x-public
+	Properties  []PropertyDetails
+}
+
+// PropertyDetails are the details of a property of a new schema.
+type PropertyDetails struct {
+	Name     string
+	Type     string // One of PropertyTypes
+	Required bool
+}
+
+// ScaffoldOptions are the options with which the first version of a new schema family is created.
+type ScaffoldOptions struct {
+	Scaffold string         // The name of a scaffold in the scaffolds directory, or empty for the default
+	Details  *SchemaDetails // The details given for the schema, or nil if none were given
+}
+
+// ScaffoldData is the data a scaffold is executed with. Scaffolds are Go templates delimited by [[ and ]], so
+// that the {{ ID }}, {{ JSM }} and other actions of the schema template pass through them unchanged.
+type ScaffoldData struct {
+	SchemaDetails
+	Key      Key
+	Family   string
+	Draft    validator.Draft
+	Required []string // The names of the required properties
+}
+
+// defaultScaffoldName is the name of defaultScaffold in errors.
+const defaultScaffoldName = "default"
+
+// defaultScaffold is the scaffold used when details are given for a new schema without naming a scaffold.
+const defaultScaffold = `{
+	"$schema": "[[ .Draft ]]",
+	"$id": "{{ ID }}",
+[[- if .Title ]]
+	"title": [[ json .Title ]],
+[[- end ]]
+[[- if .Description ]]
+	"description": [[ json .Description ]],
+[[- end ]]
+[[- if .Public ]]
+	"x-public": true,
+[[- end ]]
+	"type": "object",
+	"properties": {
+[[- range $i, $p := .Properties ]][[ if $i ]],[[ end ]]
+		[[ json $p.Name ]]: {"type": [[ json $p.Type ]]}
+[[- end ]]
+[[- if .Properties ]]
+	}
+[[- else ]]}
+[[- end ]]
+[[- if .Required ]],
+	"required": [[ json .Required ]]
+[[- end ]]
+}
+`
+
+// scaffoldFuncs are the functions available to scaffolds.
+var scaffoldFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Scaffolds returns the names of the scaffolds in the registry, in filename order.
+func (r *Registry) Scaffolds() ([]string, error) {
+	entries, err := r.readDir(filepath.Join(r.rootDirectory, ScaffoldsDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ScaffoldSuffix) {
+			names = append(names, strings.TrimSuffix(e.Name(), ScaffoldSuffix))
+		}
+	}
+	return names, nil
+}
+
+// scaffoldContent returns the source of the new schema s, generated by the scaffold in the options. If no
+// scaffold is named and no details are given, the source is NewSchemaContent.
+func (r *Registry) scaffoldContent(s *Schema, opts ScaffoldOptions) ([]byte, error) {
+	cfg, err := r.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	name, src := defaultScaffoldName, defaultScaffold
+	if opts.Scaffold != "" {
+		data, rErr := r.readFile(filepath.Join(r.rootDirectory, ScaffoldsDir, opts.Scaffold+ScaffoldSuffix))
+		if errors.Is(rErr, fs.ErrNotExist) {
+			available, _ := r.Scaffolds()
+			return nil, &ScaffoldNotFoundError{Name: opts.Scaffold, Available: available}
+		}
+		if rErr != nil {
+			return nil, rErr
+		}
+		name, src = opts.Scaffold, string(data)
+	} else if opts.Details == nil {
+		return []byte(NewSchemaContent(cfg.DefaultJSONSchemaVersion)), nil
+	}
+
+	tmpl, err := template.New(name).Delims("[[", "]]").Funcs(scaffoldFuncs).Parse(src)
+	if err != nil {
+		return nil, &InvalidScaffoldError{Name: name, Wrapped: err}
+	}
+
+	data := ScaffoldData{Key: s.Key(), Family: s.core.familyName, Draft: cfg.DefaultJSONSchemaVersion}
+	if opts.Details != nil {
+		data.SchemaDetails = *opts.Details
+	}
+	for _, p := range data.Properties {
+		if p.Required {
+			data.Required = append(data.Required, p.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return nil, &InvalidScaffoldError{Name: name, Wrapped: err}
+	}
+
+	// The schema is checked as it would be when loaded, before any of its directories are created.
+	var doc any
+	if err = json.Unmarshal(withoutTemplateActions(buf.Bytes()), &doc); err != nil {
+		return nil, &InvalidScaffoldError{Name: name, Wrapped: fmt.Errorf("generated schema is not JSON: %w", err)}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeStarterPassDocument writes a pass test document for the new schema s, which has a value of the given
+// type for each property in the details. The document is only written if it passes validation against s, as
+// a scaffold may constrain the properties further.
+func writeStarterPassDocument(s *Schema, d *SchemaDetails) error {
+	values := make(map[string]any, len(d.Properties))
+	for _, p := range d.Properties {
+		values[p.Name] = starterValue(p.Type)
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	ri, err := s.Render(s.registry.config.ProductionEnvConfig())
+	if err != nil {
+		return err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if err = ri.Validator.Validate(doc); err != nil {
+		return &StarterDocumentInvalidError{Key: s.Key(), Wrapped: err}
+	}
+
+	fp := filepath.Join(s.Path(HomeDir), string(TestDocTypePass), StarterPassDocument)
+	return os.WriteFile(fp, append(data, '\n'), 0o600)
+}
+
+// starterValue returns a value of the JSON Schema type for a starter pass document.
+func starterValue(t string) any {
+	switch t {
+	case "string":
+		return "example"
+	case "number", "integer":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		return []any{}
+	default:
+		return map[string]any{}
+	}
+}
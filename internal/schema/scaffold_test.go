@@ -0,0 +1,206 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_CreateSchemaFromScaffold(t *testing.T) {
+	t.Parallel()
+
+	details := &SchemaDetails{
+		Title:       `Order "placed"`,
+		Description: "An order was placed",
+		Public:      true,
+		Properties: []PropertyDetails{
+			{Name: "orderId", Type: "string", Required: true},
+			{Name: "total", Type: "number"},
+			{Name: "lines", Type: "array", Required: true},
+		},
+	}
+
+	// writeScaffold writes a scaffold with the name and source to the registry.
+	writeScaffold := func(t *testing.T, r *Registry, name, src string) {
+		t.Helper()
+		dir := filepath.Join(r.RootDirectory(), ScaffoldsDir)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+ScaffoldSuffix), []byte(src), 0o600))
+	}
+
+	readJSON := func(t *testing.T, path string) map[string]any {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var v map[string]any
+		require.NoError(t, json.Unmarshal(data, &v), string(data))
+		return v
+	}
+
+	t.Run("no options", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		s, err := r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(s.Path(FilePath))
+		require.NoError(t, err)
+		cfg, err := r.Config()
+		require.NoError(t, err)
+		assert.Equal(t, NewSchemaContent(cfg.DefaultJSONSchemaVersion), string(data))
+		assert.NoFileExists(t, filepath.Join(s.Path(HomeDir), "pass", StarterPassDocument))
+	})
+
+	t.Run("default scaffold with details", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		s, err := r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{Details: details})
+		require.NoError(t, err)
+
+		// The schema is cached as it was written.
+		assert.True(t, s.IsPublic())
+		cached, err := r.GetSchemaByKey(s.Key())
+		require.NoError(t, err)
+		assert.Same(t, s, cached)
+
+		cfg, err := r.Config()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"$schema":     string(cfg.DefaultJSONSchemaVersion),
+			"$id":         "{{ ID }}",
+			"title":       `Order "placed"`,
+			"description": "An order was placed",
+			"x-public":    true,
+			"type":        "object",
+			"properties": map[string]any{
+				"orderId": map[string]any{"type": "string"},
+				"total":   map[string]any{"type": "number"},
+				"lines":   map[string]any{"type": "array"},
+			},
+			"required": []any{"orderId", "lines"},
+		}, readJSON(t, s.Path(FilePath)))
+
+		assert.Equal(t, map[string]any{"orderId": "example", "total": float64(0), "lines": []any{}},
+			readJSON(t, filepath.Join(s.Path(HomeDir), "pass", StarterPassDocument)))
+	})
+
+	t.Run("default scaffold with no properties", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		s, err := r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{Details: &SchemaDetails{}})
+		require.NoError(t, err)
+
+		v := readJSON(t, s.Path(FilePath))
+		assert.Equal(t, map[string]any{}, v["properties"])
+		assert.NotContains(t, v, "title")
+		assert.NotContains(t, v, "x-public")
+		assert.NotContains(t, v, "required")
+	})
+
+	t.Run("named scaffold", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writeScaffold(t, r, "event", `{"$id": "{{ ID }}", "x-key": "[[ .Key ]]", "x-family": "[[ .Family ]]", `+
+			`"title": [[ json .Title ]], "$ref": "{{ JSM "domain_envelope_1" }}"}`)
+		s, err := r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{Scaffold: "event"})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(s.Path(FilePath))
+		require.NoError(t, err)
+		assert.Equal(t, `{"$id": "{{ ID }}", "x-key": "domain_family_1_0_0", "x-family": "family", `+
+			`"title": "", "$ref": "{{ JSM "domain_envelope_1" }}"}`, string(data))
+		assert.NoFileExists(t, filepath.Join(s.Path(HomeDir), "pass", StarterPassDocument))
+	})
+
+	t.Run("starter document does not pass validation", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		writeScaffold(t, r, "strict", `{"$id": "{{ ID }}", "type": "object", `+
+			`"properties": {"orderId": {"type": "string", "minLength": 10}}}`)
+		_, err := r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{Scaffold: "strict", Details: details})
+
+		var target *StarterDocumentInvalidError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, Key("domain_family_1_0_0"), target.Key)
+
+		// Nothing is left behind, so the schema can be created again.
+		assert.NoDirExists(t, filepath.Join(r.RootDirectory(), "domain"))
+		_, err = r.GetSchemaByKey(target.Key)
+		require.Error(t, err)
+		_, err = r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{Details: details})
+		require.NoError(t, err)
+	})
+
+	t.Run("existing domain directory is kept", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{"domain_other_1_0_0": `{}`})
+		writeScaffold(t, r, "strict", `{"$id": "{{ ID }}", "type": "object", `+
+			`"properties": {"orderId": {"type": "string", "minLength": 10}}}`)
+		_, err := r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{Scaffold: "strict", Details: details})
+
+		var target *StarterDocumentInvalidError
+		require.ErrorAs(t, err, &target)
+		assert.NoDirExists(t, filepath.Join(r.RootDirectory(), "domain", "family"))
+		assert.FileExists(t, New("domain_other_1_0_0", r).Path(FilePath))
+	})
+
+	t.Run("scaffold not found", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writeScaffold(t, r, "entity", "{}")
+		writeScaffold(t, r, "event", "{}")
+		_, err := r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{Scaffold: "missing"})
+
+		var target *ScaffoldNotFoundError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, []string{"entity", "event"}, target.Available)
+		assert.NoDirExists(t, filepath.Join(r.RootDirectory(), "domain"))
+	})
+
+	t.Run("invalid scaffold", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writeScaffold(t, r, "parse", `{"title": [[ .Title }`)
+		writeScaffold(t, r, "execute", `{"title": [[ .Missing ]]}`)
+		writeScaffold(t, r, "json", `{"title": [[ .Title ]]}`)
+
+		for _, name := range []string{"parse", "execute", "json"} {
+			_, err := r.CreateSchemaFromScaffold("domain/family", ScaffoldOptions{Scaffold: name})
+			var target *InvalidScaffoldError
+			require.ErrorAs(t, err, &target)
+			assert.Equal(t, name, target.Name)
+		}
+		assert.NoDirExists(t, filepath.Join(r.RootDirectory(), "domain"))
+	})
+}
+
+func TestRegistry_Scaffolds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no scaffolds directory", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		names, err := r.Scaffolds()
+		require.NoError(t, err)
+		assert.Empty(t, names)
+	})
+
+	t.Run("scaffolds", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		dir := filepath.Join(r.RootDirectory(), ScaffoldsDir)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"+ScaffoldSuffix), 0o755))
+		for _, name := range []string{"event" + ScaffoldSuffix, "entity" + ScaffoldSuffix, "notes.txt"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o600))
+		}
+
+		names, err := r.Scaffolds()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"entity", "event"}, names)
+	})
+}
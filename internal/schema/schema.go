@@ -2,9 +2,12 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -96,10 +99,12 @@ type Schema struct {
 	registry *Registry // The registry this schema is in.
 
 	// fields set after reading the schema file:
-	exists   bool           // true if the schema file exists on disk
-	srcDoc   []byte         // The source schema document
-	tmpl     TemplateSource // The parsed template ready for execution
-	isPublic bool           // true if the schema is intended to be published to the public
+	exists   bool            // true if the schema file exists on disk
+	srcDoc   []byte          // The source schema document
+	tmpl     TemplateSource  // The parsed template ready for execution
+	isPublic bool            // true if the schema is intended to be published to the public
+	family   *FamilyMetadata // The metadata of the schema family, or nil if the family has none
+	partials []string        // The names of the template partials the schema includes
 
 	// information lazily evaluated after reading the schema file:
 	mu       sync.Mutex // Protects computed
@@ -122,31 +127,47 @@ func New(k Key, registry *Registry) *Schema {
 
 // Load loads the schema source file with the given key into the registry, and returns it.
 // Note that the schema will not be rendered at this point. See Validator() for that.
-func Load(k Key, r *Registry) (s *Schema, err error) {
-	s = New(k, r)
-	fp := s.Path(FilePath)
+func Load(k Key, r *Registry) (*Schema, error) {
+	data, err := r.readFile(New(k, r).Path(FilePath))
+	if err != nil {
+		return nil, err
+	}
 
-	//nolint:gosec // Path is constructed from internal registry logic
-	data, err := os.ReadFile(fp)
+	partials, err := r.partials()
 	if err != nil {
 		return nil, err
 	}
+
+	return loadSource(k, r, data, partials)
+}
+
+// loadSource initialises the schema with the given key from the given source document, which may
+// include any of the given partials.
+func loadSource(k Key, r *Registry, data []byte, partials map[string]*Partial) (s *Schema, err error) {
+	s = New(k, r)
+	fp := s.Path(FilePath)
 	s.srcDoc = data
 
-	if !json.Valid(data) {
+	doc := withoutTemplateActions(data)
+	if !json.Valid(doc) {
 		return nil, &InvalidJSONError{Path: fp}
 	}
 
 	s.exists = true
-	s.isPublic, err = isSchemaPublic(fp, data)
+	s.isPublic, err = isSchemaPublic(fp, doc)
 	if err != nil {
 		return nil, err
 	}
 
-	if tErr := s.loadTemplate(fp); tErr != nil {
+	if tErr := s.loadTemplate(fp, partials); tErr != nil {
 		return nil, tErr
 	}
 
+	s.family, err = r.familyMetadata(s.Path(FamilyDir))
+	if err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
 
@@ -155,22 +176,31 @@ func (s *Schema) IsPublic() bool {
 	return s.isPublic
 }
 
+// Family returns the metadata recorded in the family.yml file of the schema's family,
+// or nil if the family has no metadata file.
+func (s *Schema) Family() *FamilyMetadata {
+	return s.family
+}
+
 // loadTemplate initialises the template which will be used later to render the schema to target environments.
+// The registry's template partials are added to the template so the schema can include them.
 // This only needs to be done once per schema.
-func (s *Schema) loadTemplate(fp string) error {
+func (s *Schema) loadTemplate(fp string, partials map[string]*Partial) error {
 	tmpl := template.New(fp)
 
 	// Define the FuncMap so the parser recognises the template names without the need for the . prefix
 	// (e.g. {{ ID }} instead of {{ .ID }})
-	tmpl.Funcs(template.FuncMap{
-		"ID":  func() (string, error) { return "", nil },
-		"JSM": func(string) (string, error) { return "", nil },
-	})
+	tmpl.Funcs(templatePlaceholderFuncs())
 
 	parsed, err := tmpl.Parse(string(s.srcDoc))
 	if err != nil {
 		return &TemplateFormatInvalidError{Path: fp, Wrapped: err}
 	}
+
+	if pErr := s.includePartials(fp, parsed, partials); pErr != nil {
+		return pErr
+	}
+
 	s.tmpl = parsed
 	return nil
 }
@@ -189,6 +219,16 @@ func (s *Schema) Render(ec *config.EnvConfig) (RenderInfo, error) {
 	return s.registry.CoordinateRender(s, ec)
 }
 
+// templateActionRegex matches go template actions such as {{ ID }} and {{ Var "statuses" }}.
+var templateActionRegex = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// withoutTemplateActions replaces each template action in a source schema with null. This lets an action
+// stand in for a whole JSON value (e.g. "enum": {{ Var "statuses" }}) while the source can still be
+// checked as JSON before it is rendered.
+func withoutTemplateActions(data []byte) []byte {
+	return templateActionRegex.ReplaceAll(data, []byte("null"))
+}
+
 // isSchemaPublic will return false unless the schema explicitly has property x-public set totrue.
 func isSchemaPublic(filePath string, data []byte) (bool, error) {
 	var meta struct {
@@ -273,6 +313,15 @@ func (s *Schema) Filename() string {
 
 // WriteNewSchemaFiles creates a new schema file and its folders.
 func (s *Schema) WriteNewSchemaFiles() error {
+	cfg, err := s.registry.Config()
+	if err != nil {
+		return err
+	}
+	return s.writeNewSchemaFiles([]byte(NewSchemaContent(cfg.DefaultJSONSchemaVersion)))
+}
+
+// writeNewSchemaFiles creates a new schema file with the given content, and its folders.
+func (s *Schema) writeNewSchemaFiles(content []byte) error {
 	hd := s.Path(HomeDir)
 
 	// Create the missing parent folders
@@ -280,13 +329,7 @@ func (s *Schema) WriteNewSchemaFiles() error {
 		return err
 	}
 
-	cfg, err := s.registry.Config()
-	if err != nil {
-		return err
-	}
-
-	content := NewSchemaContent(cfg.DefaultJSONSchemaVersion)
-	err = os.WriteFile(s.Path(FilePath), []byte(content), 0o600)
+	err := os.WriteFile(s.Path(FilePath), content, 0o600)
 	if err != nil {
 		return err
 	}
@@ -399,9 +442,9 @@ func (s *Schema) TestDocuments(tt TestDocType) ([]TestInfo, error) {
 	docDir := filepath.Join(homeDir, string(tt))
 
 	// Identify files ending in .json
-	entries, err := os.ReadDir(docDir)
+	entries, err := s.registry.readDir(docDir)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, &TestDirMissingConfigError{Path: docDir, Type: tt}
 		}
 		return nil, err
@@ -414,7 +457,7 @@ func (s *Schema) TestDocuments(tt TestDocType) ([]TestInfo, error) {
 		}
 		if filepath.Ext(entry.Name()) == ".json" {
 			fp := filepath.Join(docDir, entry.Name())
-			ti, tErr := NewTestInfo(fp)
+			ti, tErr := readTestInfo(s.registry.readFile, fp)
 			if tErr != nil {
 				return nil, tErr
 			}
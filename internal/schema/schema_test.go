@@ -1768,12 +1768,48 @@ func TestLoad_TemplateParseErr(t *testing.T) {
 	require.ErrorAs(t, err, &target)
 }
 
+func TestLoad_TemplateActionAsValue(t *testing.T) {
+	t.Parallel()
+	r := setupTestRegistry(t)
+
+	key := Key("domain_family_1_0_0")
+	createSchemaFiles(t, r, schemaMap{
+		key: `{"x-public": true, "properties": {"status": {"enum": {{ Var "statuses" }}}}}`,
+	})
+
+	s, err := Load(key, r)
+	require.NoError(t, err)
+	assert.True(t, s.IsPublic())
+}
+
+func TestWithoutTemplateActions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: `{"$id": "{{ ID }}"}`, want: `{"$id": "null"}`},
+		{src: `{"enum": {{ Var "statuses" }}}`, want: `{"enum": null}`},
+		{src: `{"a": {{ Var "a" }}, "b": {{ Var "b" }}}`, want: `{"a": null, "b": null}`},
+		{src: "{\"a\": {{\n  Var \"a\"\n}}}", want: `{"a": null}`},
+		{src: `{"a": 1}`, want: `{"a": 1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, string(withoutTemplateActions([]byte(tt.src))))
+		})
+	}
+}
+
 func TestSchema_LoadTemplate_DummyFuncs(t *testing.T) {
 	t.Parallel()
 	s := &Schema{
-		srcDoc: []byte(`{{ ID }} {{ JSM "key" }}`),
+		srcDoc: []byte(`{{ ID }} {{ JSM "key" }} {{ Var "name" }}`),
 	}
-	err := s.loadTemplate("test.json")
+	err := s.loadTemplate("test.json", nil)
 	require.NoError(t, err)
 
 	// In loadTemplate, s.tmpl is assigned a *template.Template
@@ -1808,6 +1844,8 @@ func (c *failCompiler) SupportedSchemaVersions() []validator.Draft {
 	return []validator.Draft{validator.Draft7}
 }
 
+func (c *failCompiler) AddVendored(_ string, _ validator.JSONSchema) {}
+
 func (c *failCompiler) Clear() {}
 
 func TestRender_CompilerErrs(t *testing.T) {
@@ -1821,7 +1859,7 @@ func TestRender_CompilerErrs(t *testing.T) {
 		s := New(Key("domain_family_1_0_0"), r)
 		s.srcDoc = []byte(`{}`)
 		s.exists = true
-		require.NoError(t, s.loadTemplate("test.json"))
+		require.NoError(t, s.loadTemplate("test.json", nil))
 
 		ec := r.config.ProductionEnvConfig()
 		_, err := s.Render(ec)
@@ -1837,7 +1875,7 @@ func TestRender_CompilerErrs(t *testing.T) {
 		s := New(Key("domain_family_1_0_0"), r)
 		s.srcDoc = []byte(`{}`)
 		s.exists = true
-		require.NoError(t, s.loadTemplate("test.json"))
+		require.NoError(t, s.loadTemplate("test.json", nil))
 
 		ec := r.config.ProductionEnvConfig()
 		_, err := s.Render(ec)
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -51,7 +51,7 @@ func NewSearcher(r *Registry, s SearchScope) (*Searcher, error) {
 		return nil, err
 	}
 
-	if _, sErr := os.Stat(searchRoot); sErr != nil {
+	if _, sErr := r.stat(searchRoot); sErr != nil {
 		return nil, fmt.Errorf("search root does not exist: %w", sErr)
 	}
 
@@ -98,7 +98,7 @@ func (s *Searcher) Schemas(ctx context.Context) <-chan SearchResult {
 	go func() {
 		defer close(resC)
 
-		err := filepath.Walk(s.searchRoot, s.walkFunc(ctx, resC))
+		err := s.registry.walkDir(s.searchRoot, s.walkFunc(ctx, resC))
 		if err != nil {
 			select {
 			case <-ctx.Done():
@@ -111,17 +111,21 @@ func (s *Searcher) Schemas(ctx context.Context) <-chan SearchResult {
 	return resC
 }
 
-func (s *Searcher) walkFunc(ctx context.Context, resC chan<- SearchResult) filepath.WalkFunc {
-	return func(path string, info os.FileInfo, err error) error {
+func (s *Searcher) walkFunc(ctx context.Context, resC chan<- SearchResult) fs.WalkDirFunc {
+	return func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if info.IsDir() {
+		if d.IsDir() {
+			// Vendored schemas are not managed by JSM, so are not searched even if their names look like keys.
+			if path == filepath.Join(s.registry.rootDirectory, VendorDir) {
+				return fs.SkipDir
+			}
 			return nil
 		}
 
-		filename := info.Name()
+		filename := d.Name()
 		if !strings.HasSuffix(filename, SchemaSuffix) {
 			return nil
 		}
@@ -131,6 +135,10 @@ func (s *Searcher) walkFunc(ctx context.Context, resC chan<- SearchResult) filep
 		if kErr != nil {
 			return &InvalidSchemaFilenameError{Path: path}
 		}
+		// A schema file copied to the wrong directory would otherwise yield a key whose file does not exist.
+		if expected := core.Path(FilePath, s.registry.rootDirectory); expected != path {
+			return &SchemaLocationMismatchError{Key: core.Key(), Expected: expected, Actual: path}
+		}
 
 		select {
 		case <-ctx.Done():
@@ -483,6 +483,32 @@ func TestSearcher_Schemas_EarlyTerminationOnError(t *testing.T) {
 	}
 }
 
+func TestSearcher_Schemas_SchemaLocationMismatch(t *testing.T) {
+	t.Parallel()
+
+	r := setupTestRegistry(t)
+	dir := filepath.Join(r.rootDirectory, "domain", "family", "1", "1", "0")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	misplaced := filepath.Join(dir, "domain_family_1_0_0.schema.json")
+	require.NoError(t, os.WriteFile(misplaced, []byte("{}"), 0o600))
+
+	searcher, err := NewSearcher(r, SearchScope("domain"))
+	require.NoError(t, err)
+
+	var errs []error
+	for res := range searcher.Schemas(context.Background()) {
+		require.Empty(t, res.Key)
+		errs = append(errs, res.Err)
+	}
+	require.Len(t, errs, 1)
+
+	var target *SchemaLocationMismatchError
+	require.ErrorAs(t, errs[0], &target)
+	assert.Equal(t, Key("domain_family_1_0_0"), target.Key)
+	assert.Equal(t, New("domain_family_1_0_0", r).Path(FilePath), target.Expected)
+	assert.Equal(t, misplaced, target.Actual)
+}
+
 func TestSearcher_Schemas_ContextCancelledDuringErrorSend(t *testing.T) {
 	t.Parallel()
 
@@ -12,6 +12,7 @@ type Spec struct {
 	TestInfo       TestInfo    // information about the test document itself
 	TestDocType    TestDocType // the type of test document
 	ForwardVersion *SemVer     // If this spec uses a test document from a future version, this is that version
+	MigratedFrom   *Key        // If this spec uses a migrated test document from a previous major version, its key
 	Err            error       // Once the spec has run, if it didn't give the expected outcome, the related error
 }
 
@@ -25,12 +26,48 @@ func NewSpec(s *Schema, testInfo TestInfo, testDocType TestDocType, forwardVersi
 	}
 }
 
+// NewMigrationSpec sets up a new spec which checks that a pass test document from the previous major
+// version of a schema, transformed by the schema's migrations, is valid against the schema.
+// testInfo.Unmarshalled must hold the migrated document.
+func NewMigrationSpec(s *Schema, testInfo TestInfo, from Key) Spec {
+	return Spec{
+		Schema:       s,
+		TestInfo:     testInfo,
+		TestDocType:  TestDocTypePass,
+		MigratedFrom: &from,
+	}
+}
+
+// migrate applies the migrations in order to the spec's test document. If a migration cannot be applied,
+// the spec fails.
+func (s *Spec) migrate(migrations []Migration) error {
+	doc := s.TestInfo.Unmarshalled
+	for _, m := range migrations {
+		var err error
+		if doc, err = m.Apply(doc); err != nil {
+			s.Err = err
+			return err
+		}
+	}
+	s.TestInfo.Unmarshalled = doc
+	return nil
+}
+
 // Run executes the spec using the given validator.
 func (s *Spec) Run(v validator.Validator) error {
 	u := s.TestInfo.Unmarshalled
 
 	if s.TestDocType == TestDocTypePass {
 		err := v.Validate(u)
+		if err != nil && s.MigratedFrom != nil {
+			s.Err = &MigratedTestFailedError{
+				SchemaPath:  s.Schema.Path(FilePath),
+				TestDocPath: s.TestInfo.Path,
+				From:        *s.MigratedFrom,
+				Wrapped:     err,
+			}
+			return s.Err
+		}
 		if err != nil {
 			s.Err = &PassTestFailedError{
 				SchemaPath:  s.Schema.Path(FilePath),
@@ -54,6 +91,12 @@ func (s *Spec) Run(v validator.Validator) error {
 
 // ResultLabel returns a human-readable label for the result of the spec.
 func (s *Spec) ResultLabel() string {
+	if s.MigratedFrom != nil {
+		if s.Err != nil {
+			return "failed - migration from " + s.MigratedFrom.Version().String('.') + " did not produce a valid document"
+		}
+		return "migrated from " + s.MigratedFrom.Version().String('.') + " passed"
+	}
 	if s.TestDocType == TestDocTypePass {
 		if s.Err != nil {
 			if s.ForwardVersion != nil {
@@ -19,6 +19,36 @@ func TestNewSpec(t *testing.T) {
 	assert.Equal(t, TestDocTypePass, spec.TestDocType)
 }
 
+func TestNewMigrationSpec(t *testing.T) {
+	t.Parallel()
+	s := &Schema{}
+	ti := TestInfo{Path: "test.json"}
+	spec := NewMigrationSpec(s, ti, "domain_family_1_0_0")
+
+	assert.Equal(t, s, spec.Schema)
+	assert.Equal(t, ti, spec.TestInfo)
+	assert.Equal(t, TestDocTypePass, spec.TestDocType)
+	assert.Equal(t, Key("domain_family_1_0_0"), *spec.MigratedFrom)
+	assert.Nil(t, spec.ForwardVersion)
+}
+
+func TestSpec_Run_Migrated(t *testing.T) {
+	t.Parallel()
+	r := setupTestRegistry(t)
+	s := New(Key("domain_family_2_0_0"), r)
+	spec := NewMigrationSpec(s, TestInfo{Path: "test.json", Unmarshalled: map[string]interface{}{}},
+		"domain_family_1_0_0")
+
+	require.NoError(t, spec.Run(&mockValidator{}))
+
+	err := spec.Run(&mockValidator{Err: errors.New("validation failed")})
+	var target *MigratedTestFailedError
+	require.ErrorAs(t, err, &target)
+	assert.Equal(t, Key("domain_family_1_0_0"), target.From)
+	assert.Equal(t, "test.json", target.TestDocPath)
+	assert.Equal(t, err, spec.Err)
+}
+
 func TestSpec_Run(t *testing.T) {
 	t.Parallel()
 
@@ -89,6 +119,7 @@ func TestSpec_ResultLabel(t *testing.T) {
 		testDocType    TestDocType
 		err            error
 		forwardVersion *SemVer
+		migratedFrom   Key
 		want           string
 	}{
 		{
@@ -129,6 +160,19 @@ func TestSpec_ResultLabel(t *testing.T) {
 			forwardVersion: &SemVer{1, 1, 0},
 			want:           "test from future version 1.1.0 passed",
 		},
+		{
+			name:         "Migrated test doc - passed",
+			testDocType:  TestDocTypePass,
+			migratedFrom: "domain_family_1_2_0",
+			want:         "migrated from 1.2.0 passed",
+		},
+		{
+			name:         "Migrated test doc - failed",
+			testDocType:  TestDocTypePass,
+			err:          errors.New("fail"),
+			migratedFrom: "domain_family_1_2_0",
+			want:         "failed - migration from 1.2.0 did not produce a valid document",
+		},
 	}
 
 	for _, tt := range tests {
@@ -139,6 +183,9 @@ func TestSpec_ResultLabel(t *testing.T) {
 				Err:            tt.err,
 				ForwardVersion: tt.forwardVersion,
 			}
+			if tt.migratedFrom != "" {
+				spec.MigratedFrom = &tt.migratedFrom
+			}
 			assert.Equal(t, tt.want, spec.ResultLabel())
 		})
 	}
@@ -48,6 +48,8 @@ func (m *mockCompiler) SupportedSchemaVersions() []validator.Draft {
 	return []validator.Draft{validator.Draft7}
 }
 
+func (m *mockCompiler) AddVendored(_ string, _ validator.JSONSchema) {}
+
 func (m *mockCompiler) Clear() {}
 
 const testConfigData = `
@@ -78,6 +80,15 @@ func setupTestRegistry(t *testing.T) *Registry {
 	return r
 }
 
+// setupCompilingTestRegistry creates a test registry which uses a real compiler, for tests which
+// depend on documents being validated.
+func setupCompilingTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r := setupTestRegistry(t)
+	r.compiler = validator.NewSanthoshCompiler()
+	return r
+}
+
 // schemaMap maps schema Keys to their JSON content for specifying schemas in tests.
 type schemaMap map[Key]string
 
@@ -36,13 +36,20 @@ type TestReport struct {
 	// numFailures int     // The number of tests which identified a problem.
 	FailedTests TestLog // tests exposing a problem - i.e. a pass test that failed or a fail test that passed
 	PassedTests TestLog // tests that passed as expected - i.e a pass test that passed and a fail test that failed
+
+	FailedMigrations TestLog // migrated pass tests from a previous major version which the schema did not validate
+	PassedMigrations TestLog // migrated pass tests from a previous major version which the schema validated
+
+	UnusedPartials []string // template partials not included by any schema (only set when testing the whole registry)
 }
 
 // NewTestReport creates a new TestReport.
 func NewTestReport() *TestReport {
 	return &TestReport{
-		FailedTests: make(TestLog),
-		PassedTests: make(TestLog),
+		FailedTests:      make(TestLog),
+		PassedTests:      make(TestLog),
+		FailedMigrations: make(TestLog),
+		PassedMigrations: make(TestLog),
 	}
 }
 
@@ -59,3 +66,17 @@ func (r *TestReport) AddPassedTest(key Key, spec *Spec) {
 	defer r.mu.Unlock()
 	r.PassedTests[key] = append(r.PassedTests[key], *spec)
 }
+
+// AddFailedMigration adds a failed migration test to the report.
+func (r *TestReport) AddFailedMigration(key Key, spec *Spec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FailedMigrations[key] = append(r.FailedMigrations[key], *spec)
+}
+
+// AddPassedMigration adds a passed migration test to the report.
+func (r *TestReport) AddPassedMigration(key Key, spec *Spec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.PassedMigrations[key] = append(r.PassedMigrations[key], *spec)
+}
@@ -84,18 +84,24 @@ func (t *Tester) TestSingleSchema(ctx context.Context, k Key) (*TestReport, erro
 	t.report.StartTime = time.Now()
 	defer func() { t.report.EndTime = time.Now() }()
 
-	if err := t.testSchema(ctx, k); err != nil && !errors.Is(err, ErrStopTesting) {
+	if err := t.testSchemaAndCompatibility(ctx, k); err != nil && !errors.Is(err, ErrStopTesting) {
 		return nil, err
 	}
 
-	// If local tests had failures, don't run compatibility checks
-	if len(t.report.FailedTests) > 0 {
-		return t.report, nil
-	}
+	return t.report, nil
+}
 
-	// Run provider compatibility check against earlier versions
-	if !t.skipCompatible {
-		if err := t.testSchemaCompatibleWithEarlierVersions(ctx, k); err != nil && !errors.Is(err, ErrStopTesting) {
+// TestSchemas executes tests for each of the given schemas in turn, in the same way as TestSingleSchema,
+// and collects the results in a single report.
+func (t *Tester) TestSchemas(ctx context.Context, keys []Key) (*TestReport, error) {
+	t.report.StartTime = time.Now()
+	defer func() { t.report.EndTime = time.Now() }()
+
+	for _, k := range keys {
+		if err := t.testSchemaAndCompatibility(ctx, k); err != nil {
+			if errors.Is(err, ErrStopTesting) {
+				break
+			}
 			return nil, err
 		}
 	}
@@ -103,6 +109,22 @@ func (t *Tester) TestSingleSchema(ctx context.Context, k Key) (*TestReport, erro
 	return t.report, nil
 }
 
+// testSchemaAndCompatibility tests a schema, and if its local tests pass, checks that it is compatible
+// with earlier versions in the same major family.
+func (t *Tester) testSchemaAndCompatibility(ctx context.Context, k Key) error {
+	if err := t.testSchema(ctx, k); err != nil {
+		return err
+	}
+
+	// If local tests had failures, don't run compatibility checks
+	if len(t.report.FailedTests[k]) > 0 || t.skipCompatible {
+		return nil
+	}
+
+	// Run provider compatibility check against earlier versions
+	return t.testSchemaCompatibleWithEarlierVersions(ctx, k)
+}
+
 // TestSpecificDocument executes a single test document for a schema.
 func (t *Tester) TestSpecificDocument(ctx context.Context, k Key, testPath string) (*TestReport, error) {
 	t.report.StartTime = time.Now()
@@ -215,6 +237,15 @@ func (t *Tester) TestFoundSchemas(ctx context.Context, ss SearchScope) (*TestRep
 		return t.report, ctx.Err()
 	}
 
+	// Partials can only be identified as unused when the whole registry is being tested.
+	if finalErr == nil && ss == "" {
+		unused, uErr := t.registry.UnusedPartials(ctx)
+		if uErr != nil {
+			return t.report, uErr
+		}
+		t.report.UnusedPartials = unused
+	}
+
 	return t.report, finalErr
 }
 
@@ -303,6 +334,60 @@ func (t *Tester) testSchema(ctx context.Context, key Key) error {
 		}
 	}
 
+	// Migration tests use pass test documents, so only run them when pass tests are in scope.
+	if t.scope == TestScopeFail || t.scope == TestScopeConsumerBreaking {
+		return nil
+	}
+
+	return t.testMigrations(ctx, s, ri)
+}
+
+// testMigrations applies the schema's migrations to the pass test documents of the latest version of the
+// previous major version in the family, and checks that the migrated documents are valid against the schema.
+func (t *Tester) testMigrations(ctx context.Context, s *Schema, ri RenderInfo) error {
+	migrations, err := s.Migrations()
+	if err != nil || len(migrations) == 0 {
+		return err
+	}
+
+	prevKey, ok, err := s.PreviousMajorLatestSchema()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &MigrationsWithoutPreviousMajorError{Path: filepath.Join(s.Path(HomeDir), MigrationsDir)}
+	}
+
+	prev, err := t.registry.GetSchemaByKey(prevKey)
+	if err != nil {
+		return err
+	}
+
+	passTests, err := prev.TestDocuments(TestDocTypePass)
+	if err != nil {
+		return err
+	}
+
+	for _, ti := range passTests {
+		if ce := ctx.Err(); ce != nil {
+			return ce
+		}
+
+		spec := NewMigrationSpec(s, ti, prevKey)
+		if err = spec.migrate(migrations); err == nil {
+			err = spec.Run(ri.Validator)
+		}
+
+		if err != nil {
+			t.report.AddFailedMigration(s.Key(), &spec)
+			if t.stopOnFirstError {
+				return ErrStopTesting
+			}
+		} else {
+			t.report.AddPassedMigration(s.Key(), &spec)
+		}
+	}
+
 	return nil
 }
 
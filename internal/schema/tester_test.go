@@ -36,6 +36,11 @@ func TestTestReport_AddTests(t *testing.T) {
 	r.AddFailedTest(k, s2)
 	assert.Len(t, r.PassedTests[k], 1)
 	assert.Len(t, r.FailedTests[k], 1)
+
+	r.AddPassedMigration(k, s1)
+	r.AddFailedMigration(k, s2)
+	assert.Len(t, r.PassedMigrations[k], 1)
+	assert.Len(t, r.FailedMigrations[k], 1)
 }
 
 func TestTester_Configuration(t *testing.T) {
@@ -274,6 +279,97 @@ func TestTester_TestFoundSchemas(t *testing.T) {
 	assert.NotNil(t, report)
 }
 
+// createTestDirs creates empty pass and fail test directories for each of the given schemas.
+func createTestDirs(t *testing.T, r *Registry, keys ...Key) {
+	t.Helper()
+	for _, k := range keys {
+		for _, dt := range []TestDocType{TestDocTypePass, TestDocTypeFail} {
+			require.NoError(t, os.MkdirAll(filepath.Join(New(k, r).Path(HomeDir), string(dt)), 0o755))
+		}
+	}
+}
+
+func TestTester_TestFoundSchemas_UnusedPartials(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unused partials are reported when testing the whole registry", func(t *testing.T) {
+		t.Parallel()
+		r := setupPartialsTestRegistry(t)
+		createTestDirs(t, r, "domain_order_1_0_0", "domain_order_2_0_0", "domain_rate_1_0_0", "domain_plain_1_0_0")
+
+		report, err := NewTester(r).TestFoundSchemas(context.Background(), "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"address"}, report.UnusedPartials)
+	})
+
+	t.Run("unused partials are not reported for a scoped search", func(t *testing.T) {
+		t.Parallel()
+		r := setupPartialsTestRegistry(t)
+		createTestDirs(t, r, "domain_order_1_0_0", "domain_order_2_0_0", "domain_rate_1_0_0", "domain_plain_1_0_0")
+
+		report, err := NewTester(r).TestFoundSchemas(context.Background(), "domain/order")
+		require.NoError(t, err)
+		assert.Empty(t, report.UnusedPartials)
+	})
+
+	t.Run("partials cannot be loaded", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		writePartial(t, r, "money", `{{ end }}`)
+
+		_, err := NewTester(r).TestFoundSchemas(context.Background(), "")
+		require.ErrorAs(t, err, new(*InvalidPartialError))
+	})
+}
+
+func TestTester_TestSchemas(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) *Registry {
+		t.Helper()
+		r := setupCompilingTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{
+			"domain_a_1_0_0": `{"type": "object"}`,
+			"domain_b_1_0_0": `{"type": "string"}`,
+		})
+		createTestDirs(t, r, "domain_a_1_0_0", "domain_b_1_0_0")
+		for _, k := range []Key{"domain_a_1_0_0", "domain_b_1_0_0"} {
+			passDir := filepath.Join(New(k, r).Path(HomeDir), string(TestDocTypePass))
+			require.NoError(t, os.WriteFile(filepath.Join(passDir, "doc.json"), []byte("{}"), 0o600))
+		}
+		return r
+	}
+
+	t.Run("results for all schemas are collected", func(t *testing.T) {
+		t.Parallel()
+		tr := NewTester(setup(t))
+		tr.SetStopOnFirstError(false)
+
+		report, err := tr.TestSchemas(context.Background(), []Key{"domain_a_1_0_0", "domain_b_1_0_0"})
+		require.NoError(t, err)
+		assert.Len(t, report.PassedTests["domain_a_1_0_0"], 1)
+		assert.Len(t, report.FailedTests["domain_b_1_0_0"], 1)
+	})
+
+	t.Run("stop on first error", func(t *testing.T) {
+		t.Parallel()
+		tr := NewTester(setup(t))
+
+		report, err := tr.TestSchemas(context.Background(), []Key{"domain_b_1_0_0", "domain_a_1_0_0"})
+		require.NoError(t, err)
+		assert.Len(t, report.FailedTests["domain_b_1_0_0"], 1)
+		assert.Empty(t, report.PassedTests)
+	})
+
+	t.Run("schema cannot be loaded", func(t *testing.T) {
+		t.Parallel()
+		tr := NewTester(setup(t))
+
+		_, err := tr.TestSchemas(context.Background(), []Key{"domain_missing_1_0_0"})
+		require.Error(t, err)
+	})
+}
+
 func TestTester_testSchema_Errs(t *testing.T) {
 	t.Parallel()
 
@@ -1311,3 +1407,128 @@ func TestTester_testSchemaCompatibleWithEarlierVersions_ErrorPaths(t *testing.T)
 		assert.ErrorAs(t, err, new(*InvalidJSONError))
 	})
 }
+
+func TestTester_Migrations(t *testing.T) {
+	t.Parallel()
+
+	prevKey := Key("domain_family_1_1_0")
+	targetKey := Key("domain_family_2_0_0")
+
+	// setup creates a family where 2.0.0 renamed 'name' to 'familyName', and 1.1.0 has two pass documents.
+	setup := func(t *testing.T, migration string) *Registry {
+		t.Helper()
+		r := setupCompilingTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{
+			"domain_family_1_0_0": `{"type": "object"}`,
+			prevKey:               `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+			targetKey: `{"type": "object", "required": ["familyName"],
+				"properties": {"familyName": {"type": "string"}}, "not": {"required": ["name"]}}`,
+		})
+		for _, k := range []Key{prevKey, targetKey} {
+			hd := New(k, r).Path(HomeDir)
+			require.NoError(t, os.MkdirAll(filepath.Join(hd, "pass"), 0o755))
+			require.NoError(t, os.MkdirAll(filepath.Join(hd, "fail"), 0o755))
+		}
+		prevPass := filepath.Join(New(prevKey, r).Path(HomeDir), "pass")
+		require.NoError(t, os.WriteFile(filepath.Join(prevPass, "a.json"), []byte(`{"name": "Smith"}`), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(prevPass, "b.json"), []byte(`{"name": "Jones"}`), 0o600))
+		if migration != "" {
+			writeMigration(t, r, targetKey, "from-1.yml", migration)
+		}
+		return r
+	}
+
+	t.Run("migrated documents are valid", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t, "operations: [{op: rename, path: /name, to: familyName}]\n")
+		tr := NewTester(r)
+
+		report, err := tr.TestSingleSchema(context.Background(), targetKey)
+		require.NoError(t, err)
+		require.Len(t, report.PassedMigrations[targetKey], 2)
+		assert.Empty(t, report.FailedMigrations)
+		spec := report.PassedMigrations[targetKey][0]
+		assert.Equal(t, prevKey, *spec.MigratedFrom)
+		assert.Equal(t, filepath.Join(New(prevKey, r).Path(HomeDir), "pass", "a.json"), spec.TestInfo.Path)
+		// Migration specs are reported separately from ordinary tests
+		assert.Empty(t, report.PassedTests[targetKey])
+	})
+
+	t.Run("migrated documents are invalid", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t, "operations: [{op: default, path: /familyName, value: Unknown}]\n")
+		tr := NewTester(r)
+		tr.SetStopOnFirstError(false)
+
+		report, err := tr.TestSingleSchema(context.Background(), targetKey)
+		require.NoError(t, err)
+		require.Len(t, report.FailedMigrations[targetKey], 2)
+		assert.ErrorAs(t, report.FailedMigrations[targetKey][0].Err, new(*MigratedTestFailedError))
+	})
+
+	t.Run("migration cannot be applied", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t, "operations: [{op: delete, path: /name/first}]\n")
+		tr := NewTester(r)
+
+		report, err := tr.TestSingleSchema(context.Background(), targetKey)
+		require.NoError(t, err)
+		// Stops on the first failure by default
+		require.Len(t, report.FailedMigrations[targetKey], 1)
+		assert.ErrorAs(t, report.FailedMigrations[targetKey][0].Err, new(*MigrationFailedError))
+	})
+
+	t.Run("no migrations", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t, "")
+		tr := NewTester(r)
+
+		report, err := tr.TestSingleSchema(context.Background(), targetKey)
+		require.NoError(t, err)
+		assert.Empty(t, report.PassedMigrations)
+		assert.Empty(t, report.FailedMigrations)
+	})
+
+	t.Run("not run when pass tests are out of scope", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t, "operations: [{op: rename, path: /name, to: familyName}]\n")
+		tr := NewTester(r)
+		tr.SetScope(TestScopeFail)
+
+		report, err := tr.TestSingleSchema(context.Background(), targetKey)
+		require.NoError(t, err)
+		assert.Empty(t, report.PassedMigrations)
+	})
+
+	t.Run("invalid migration file", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t, "operations: [{op: copy, path: /name}]\n")
+		tr := NewTester(r)
+
+		_, err := tr.TestSingleSchema(context.Background(), targetKey)
+		require.ErrorAs(t, err, new(*InvalidMigrationError))
+	})
+
+	t.Run("no previous major version", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t, "")
+		writeMigration(t, r, "domain_family_1_0_0", "m.yml", "operations: [{op: delete, path: /name}]\n")
+		hd := New("domain_family_1_0_0", r).Path(HomeDir)
+		require.NoError(t, os.MkdirAll(filepath.Join(hd, "pass"), 0o755))
+		require.NoError(t, os.MkdirAll(filepath.Join(hd, "fail"), 0o755))
+		tr := NewTester(r)
+
+		_, err := tr.TestSingleSchema(context.Background(), "domain_family_1_0_0")
+		require.ErrorAs(t, err, new(*MigrationsWithoutPreviousMajorError))
+	})
+
+	t.Run("previous major has no pass directory", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t, "operations: [{op: rename, path: /name, to: familyName}]\n")
+		require.NoError(t, os.RemoveAll(filepath.Join(New(prevKey, r).Path(HomeDir), "pass")))
+		tr := NewTester(r)
+
+		_, err := tr.TestSingleSchema(context.Background(), targetKey)
+		require.ErrorAs(t, err, new(*TestDirMissingConfigError))
+	})
+}
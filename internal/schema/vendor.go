@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// VendorDir is the name of the optional directory in the registry root which holds vendored copies of
+// schemas which are referenced by $ref but not managed by JSM, such as GeoJSON or CloudEvents.
+const VendorDir = "vendor"
+
+// VendorLockFile is the name of the file in VendorDir which lists the vendored schemas.
+const VendorLockFile = "vendor.lock.yml"
+
+// vendorLockHeader is written at the top of the vendor lock file.
+const vendorLockHeader = "# Written by jsm vendor add. Do not edit: each file is checked against its sha256.\n"
+
+// VendorLock lists the vendored schemas of a registry.
+type VendorLock struct {
+	Schemas []VendoredSchema `yaml:"schemas"`
+}
+
+// VendoredSchema maps the URL of a schema which is not managed by JSM to its vendored copy.
+type VendoredSchema struct {
+	URL    string `yaml:"url"`
+	File   string `yaml:"file"`   // Relative to the vendor directory, using forward slashes
+	SHA256 string `yaml:"sha256"` // The hex encoded digest of the file
+}
+
+// loadVendored checks the digest of each vendored schema, and adds it to the compiler, so that the schemas
+// which reference it can be compiled without loading it from the network.
+func (r *Registry) loadVendored() error {
+	lock, err := r.vendorLock()
+	if err != nil {
+		return err
+	}
+
+	for _, vs := range lock.Schemas {
+		fp := filepath.Join(r.rootDirectory, VendorDir, filepath.FromSlash(vs.File))
+		data, rErr := r.readFile(fp)
+		if rErr != nil {
+			return rErr
+		}
+		if sha256Hex(data) != vs.SHA256 {
+			return &VendoredChecksumMismatchError{URL: vs.URL, Path: fp}
+		}
+		doc, uErr := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if uErr != nil {
+			return &InvalidJSONError{Path: fp, Wrapped: uErr}
+		}
+		r.compiler.AddVendored(vs.URL, doc)
+	}
+	return nil
+}
+
+// vendorLock returns the vendor lock file of the registry, which lists no schemas if there is none.
+func (r *Registry) vendorLock() (*VendorLock, error) {
+	fp := filepath.Join(r.rootDirectory, VendorDir, VendorLockFile)
+	data, err := r.readFile(fp)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &VendorLock{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lock VendorLock
+	if err = yaml.Unmarshal(data, &lock); err != nil {
+		return nil, &InvalidVendorLockError{Path: fp, Wrapped: err}
+	}
+	return &lock, nil
+}
+
+// Vendor copies the schema in the file at src into the vendor directory, as the copy of the schema at the
+// URL, and records it in the vendor lock file. The copy is written to vendor/<host>/<path of the URL>,
+// replacing any earlier copy of the same URL.
+func (r *Registry) Vendor(rawURL, src string) (VendoredSchema, error) {
+	file, err := vendorFile(rawURL)
+	if err != nil {
+		return VendoredSchema{}, err
+	}
+
+	data, err := os.ReadFile(src) //nolint:gosec // The user chooses the file to vendor
+	if err != nil {
+		return VendoredSchema{}, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return VendoredSchema{}, &InvalidJSONError{Path: src, Wrapped: err}
+	}
+
+	fp := filepath.Join(r.rootDirectory, VendorDir, filepath.FromSlash(file))
+	if err = os.MkdirAll(filepath.Dir(fp), 0o750); err != nil {
+		return VendoredSchema{}, err
+	}
+	if err = os.WriteFile(fp, data, 0o600); err != nil {
+		return VendoredSchema{}, fmt.Errorf("failed to write %s: %w", fp, err)
+	}
+
+	vs := VendoredSchema{URL: rawURL, File: file, SHA256: sha256Hex(data)}
+	if err = r.writeVendorLock(vs); err != nil {
+		return VendoredSchema{}, err
+	}
+	r.compiler.AddVendored(rawURL, doc)
+	return vs, nil
+}
+
+// writeVendorLock records a vendored schema in the vendor lock file, replacing any entry for its URL.
+// Entries are sorted by URL, so that the file is stable under version control.
+func (r *Registry) writeVendorLock(vs VendoredSchema) error {
+	lock, err := r.vendorLock()
+	if err != nil {
+		return err
+	}
+	lock.Schemas = slices.DeleteFunc(lock.Schemas, func(e VendoredSchema) bool { return e.URL == vs.URL })
+	lock.Schemas = append(lock.Schemas, vs)
+	slices.SortFunc(lock.Schemas, func(a, b VendoredSchema) int { return strings.Compare(a.URL, b.URL) })
+
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	fp := filepath.Join(r.rootDirectory, VendorDir, VendorLockFile)
+	if err = os.WriteFile(fp, append([]byte(vendorLockHeader), data...), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", fp, err)
+	}
+	return nil
+}
+
+// vendorFile returns the path, relative to the vendor directory, of the copy of the schema at the URL.
+// Only absolute http(s) URLs without a query or fragment identify a schema which can be vendored.
+func vendorFile(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.RawQuery != "" || u.Fragment != "" {
+		return "", &InvalidVendorURLError{URL: rawURL}
+	}
+
+	p := path.Clean("/" + u.Path)
+	if p == "/" {
+		return "", &InvalidVendorURLError{URL: rawURL}
+	}
+	// A port is kept in the directory name, but without the colon, which Windows does not permit.
+	return strings.ReplaceAll(u.Host, ":", "_") + p, nil
+}
+
+// sha256Hex returns the hex encoded SHA-256 digest of data.
+func sha256Hex(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
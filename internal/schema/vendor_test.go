@@ -0,0 +1,173 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitshepherds/json-schema-manager/internal/fsh"
+	"github.com/bitshepherds/json-schema-manager/internal/validator"
+)
+
+const (
+	pointURL    = "https://geojson.org/schema/Point.json"
+	pointSchema = `{"$id": "https://geojson.org/schema/Point.json", "type": "object", "required": ["coordinates"]}`
+)
+
+// writeVendorSource writes a schema to vendor to a temporary file, and returns its path.
+func writeVendorSource(t *testing.T, content string) string {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "Point.json")
+	require.NoError(t, os.WriteFile(src, []byte(content), 0o600))
+	return src
+}
+
+func TestRegistry_Vendor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("vendored schemas are referenced offline", func(t *testing.T) {
+		t.Parallel()
+		r := setupCompilingTestRegistry(t)
+		vs, err := r.Vendor(pointURL, writeVendorSource(t, pointSchema))
+		require.NoError(t, err)
+		assert.Equal(t, "geojson.org/schema/Point.json", vs.File)
+		assert.Equal(t, sha256Hex([]byte(pointSchema)), vs.SHA256)
+		assert.FileExists(t, filepath.Join(r.RootDirectory(), VendorDir, "geojson.org", "schema", "Point.json"))
+
+		createSchemaFiles(t, r, schemaMap{
+			"domain_place_1_0_0": `{"$id": "{{ ID }}", "properties": {"at": {"$ref": "` + pointURL + `"}}}`,
+			// Vendored copies are never read as JSM schemas.
+			"domain_other_1_0_0": `{"$id": "{{ ID }}"}`,
+		})
+		require.NoError(t, os.WriteFile(filepath.Join(r.RootDirectory(), VendorDir, "domain_x_1_0_0.schema.json"),
+			[]byte("{}"), 0o600))
+
+		// A new registry reads the vendored copy from the lock file.
+		r2, err := NewRegistry(r.RootDirectory(), validator.NewSanthoshCompiler(), fsh.NewPathResolver(),
+			fsh.NewEnvProvider())
+		require.NoError(t, err)
+		s, err := r2.GetSchemaByKey("domain_place_1_0_0")
+		require.NoError(t, err)
+		ri, err := s.Render(r2.config.ProductionEnvConfig())
+		require.NoError(t, err)
+		require.NoError(t, ri.Validator.Validate(map[string]any{"at": map[string]any{"coordinates": []any{}}}))
+		require.Error(t, ri.Validator.Validate(map[string]any{"at": map[string]any{}}))
+
+		searcher, err := NewSearcher(r2, "")
+		require.NoError(t, err)
+		var keys []Key
+		for res := range searcher.Schemas(context.Background()) {
+			require.NoError(t, res.Err)
+			keys = append(keys, res.Key)
+		}
+		assert.ElementsMatch(t, []Key{"domain_place_1_0_0", "domain_other_1_0_0"}, keys)
+	})
+
+	t.Run("lock file", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		_, err := r.Vendor("https://example.com:8443/b.json", writeVendorSource(t, `{"type": "string"}`))
+		require.NoError(t, err)
+		_, err = r.Vendor(pointURL, writeVendorSource(t, `{}`))
+		require.NoError(t, err)
+		_, err = r.Vendor(pointURL, writeVendorSource(t, pointSchema))
+		require.NoError(t, err)
+
+		lock, err := r.vendorLock()
+		require.NoError(t, err)
+		assert.Equal(t, []VendoredSchema{
+			{
+				URL:    "https://example.com:8443/b.json",
+				File:   "example.com_8443/b.json",
+				SHA256: sha256Hex([]byte(`{"type": "string"}`)),
+			},
+			{URL: pointURL, File: "geojson.org/schema/Point.json", SHA256: sha256Hex([]byte(pointSchema))},
+		}, lock.Schemas)
+
+		data, err := os.ReadFile(filepath.Join(r.RootDirectory(), VendorDir, VendorLockFile))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), vendorLockHeader)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		for _, u := range []string{"geojson.org/Point.json", "ftp://geojson.org/Point.json", "https://geojson.org/",
+			"https://geojson.org/Point.json?v=1", "https://geojson.org/Point.json#/x", "https://%zz"} {
+			_, err := r.Vendor(u, writeVendorSource(t, pointSchema))
+			var target *InvalidVendorURLError
+			require.ErrorAs(t, err, &target, u)
+		}
+
+		_, err := r.Vendor(pointURL, writeVendorSource(t, "{"))
+		var jsonErr *InvalidJSONError
+		require.ErrorAs(t, err, &jsonErr)
+
+		_, err = r.Vendor(pointURL, filepath.Join(t.TempDir(), "missing.json"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestRegistry_LoadVendored(t *testing.T) {
+	t.Parallel()
+
+	load := func(r *Registry) error {
+		_, err := NewRegistry(r.RootDirectory(), &mockCompiler{}, fsh.NewPathResolver(), fsh.NewEnvProvider())
+		return err
+	}
+
+	t.Run("modified copy", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		_, err := r.Vendor(pointURL, writeVendorSource(t, pointSchema))
+		require.NoError(t, err)
+		fp := filepath.Join(r.RootDirectory(), VendorDir, "geojson.org", "schema", "Point.json")
+		require.NoError(t, os.WriteFile(fp, []byte(`{"type": "object"}`), 0o600))
+
+		var target *VendoredChecksumMismatchError
+		require.ErrorAs(t, load(r), &target)
+		assert.Equal(t, pointURL, target.URL)
+		assert.Equal(t, fp, target.Path)
+	})
+
+	t.Run("invalid copy", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(r.RootDirectory(), VendorDir), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(r.RootDirectory(), VendorDir, "a.json"), []byte("{"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(r.RootDirectory(), VendorDir, VendorLockFile),
+			[]byte("schemas:\n  - url: https://a.com/a.json\n    file: a.json\n    sha256: "+sha256Hex([]byte("{"))+"\n"),
+			0o600))
+
+		var target *InvalidJSONError
+		require.ErrorAs(t, load(r), &target)
+	})
+
+	t.Run("missing copy", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(r.RootDirectory(), VendorDir), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(r.RootDirectory(), VendorDir, VendorLockFile),
+			[]byte("schemas:\n  - url: https://a.com/a.json\n    file: a.json\n"), 0o600))
+
+		require.ErrorIs(t, load(r), os.ErrNotExist)
+	})
+
+	t.Run("invalid lock file", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(r.RootDirectory(), VendorDir), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(r.RootDirectory(), VendorDir, VendorLockFile),
+			[]byte("schemas: {"), 0o600))
+
+		var target *InvalidVendorLockError
+		require.ErrorAs(t, load(r), &target)
+
+		_, err := r.Vendor(pointURL, writeVendorSource(t, pointSchema))
+		require.ErrorAs(t, err, &target)
+	})
+}
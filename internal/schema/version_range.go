@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bitshepherds/json-schema-manager/internal/config"
+)
+
+// VersionRange identifies the versions of a schema family within a major version (e.g. domain_family_1)
+// or a minor version (e.g. domain_family_1_2). It is used in {{ JSM }} to reference the latest version
+// in the range, so that dependents pick up new releases without being edited.
+type VersionRange struct {
+	core     Core
+	hasMinor bool
+}
+
+// ParseVersionRange creates a VersionRange from a string of the form <domain(s)>_<family>_<major>
+// or <domain(s)>_<family>_<major>_<minor>.
+func ParseVersionRange(s string) (*VersionRange, error) {
+	parts := strings.Split(s, KeySeparatorString)
+	n := len(parts)
+	if n < 3 {
+		return nil, &InvalidVersionRangeError{Range: s}
+	}
+
+	major, minor := parts[n-1], "0"
+	hasMinor := false
+	if _, err := strconv.ParseUint(parts[n-2], 10, 64); err == nil {
+		major, minor = parts[n-2], parts[n-1]
+		hasMinor = true
+		parts = parts[:n-1]
+		n--
+		if n < 3 {
+			return nil, &InvalidVersionRangeError{Range: s}
+		}
+	}
+
+	c, err := NewCore(parts[:n-2], parts[n-2], major, minor, "0")
+	if err != nil {
+		return nil, &InvalidVersionRangeError{Range: s}
+	}
+	return &VersionRange{core: *c, hasMinor: hasMinor}, nil
+}
+
+// String returns the range in the form in which it is written in {{ JSM }}.
+func (v *VersionRange) String() string {
+	parts := slices.Clone(v.core.domain)
+	parts = append(parts, v.core.familyName, strconv.FormatUint(v.core.version.Major(), 10))
+	if v.hasMinor {
+		parts = append(parts, strconv.FormatUint(v.core.version.Minor(), 10))
+	}
+	return strings.Join(parts, KeySeparatorString)
+}
+
+// Latest returns the key of the highest version in the range which exists in the registry.
+func (v *VersionRange) Latest(r *Registry) (Key, error) {
+	pr := r.pathResolver
+	majorDir := filepath.Join(v.core.Path(FamilyDir, r.rootDirectory), strconv.FormatUint(v.core.version.Major(), 10))
+
+	minors := []uint64{v.core.version.Minor()}
+	if !v.hasMinor {
+		var err error
+		if minors, err = pr.GetUintSubdirectories(majorDir); err != nil {
+			return "", err
+		}
+	}
+
+	// Search from the highest minor version down, as a minor version directory may have no patches.
+	for i := len(minors) - 1; i >= 0; i-- {
+		patches, err := pr.GetUintSubdirectories(filepath.Join(majorDir, strconv.FormatUint(minors[i], 10)))
+		if err != nil {
+			return "", err
+		}
+		if len(patches) == 0 {
+			continue
+		}
+
+		c := Core{domain: v.core.domain, familyName: v.core.familyName}
+		c.version.Set(v.core.version.Major(), minors[i], slices.Max(patches))
+		return c.Key(), nil
+	}
+
+	return "", &VersionRangeEmptyError{Range: v.String()}
+}
+
+// Dependency is a schema referenced with {{ JSM }} or {{ JSMRef }} when a schema is rendered.
+type Dependency struct {
+	Key   Key    // The referenced schema
+	Range string // The version range used in the reference, if the Key was resolved from a range
+}
+
+// RangeDependents renders every schema in the registry for the given environment, and returns the keys of
+// the schemas which reference one of the given keys via a version range. When a new version is added to a
+// family, these are the schemas whose rendered output changes.
+func (r *Registry) RangeDependents(ctx context.Context, ec *config.EnvConfig, keys []Key) ([]Key, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var dependents []Key
+	err := r.forEachSchema(ctx, func(s *Schema) error {
+		ri, err := s.Render(ec)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(ri.Dependencies, func(d Dependency) bool {
+			return d.Range != "" && slices.Contains(keys, d.Key)
+		}) {
+			dependents = append(dependents, s.Key())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dependents, nil
+}
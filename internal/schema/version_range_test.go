@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	t.Parallel()
+
+	valid := []string{
+		"domain_family_1",
+		"domain_family_1_2",
+		"domain-a_sub_family-b_10",
+		"domain-a_sub_family-b_10_0",
+	}
+	for _, s := range valid {
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+			vr, err := ParseVersionRange(s)
+			require.NoError(t, err)
+			assert.Equal(t, s, vr.String())
+		})
+	}
+
+	invalid := []string{
+		"",
+		"family_1",
+		"family_1_2",
+		"domain_family",
+		"domain_family_x",
+		"Domain_family_1",
+		"domain_family_1_x",
+	}
+	for _, s := range invalid {
+		t.Run("invalid "+s, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseVersionRange(s)
+			var target *InvalidVersionRangeError
+			require.ErrorAs(t, err, &target)
+			assert.Equal(t, s, target.Range)
+		})
+	}
+}
+
+func TestVersionRange_Latest(t *testing.T) {
+	t.Parallel()
+
+	r := setupTestRegistry(t)
+	createSchemaFiles(t, r, schemaMap{
+		"domain_family_1_0_0": "{}",
+		"domain_family_1_0_3": "{}",
+		"domain_family_1_2_0": "{}",
+		"domain_family_1_2_1": "{}",
+		"domain_family_2_0_0": "{}",
+	})
+	// A minor version directory without any patch versions is ignored.
+	require.NoError(t, os.MkdirAll(filepath.Join(r.RootDirectory(), "domain", "family", "1", "3"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(r.RootDirectory(), "domain", "family", "3"), 0o755))
+
+	tests := []struct {
+		vr   string
+		want Key
+	}{
+		{vr: "domain_family_1", want: "domain_family_1_2_1"},
+		{vr: "domain_family_1_0", want: "domain_family_1_0_3"},
+		{vr: "domain_family_2", want: "domain_family_2_0_0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vr, func(t *testing.T) {
+			t.Parallel()
+			vr, err := ParseVersionRange(tt.vr)
+			require.NoError(t, err)
+			got, err := vr.Latest(r)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("no versions in the range", func(t *testing.T) {
+		t.Parallel()
+		for _, s := range []string{"domain_family_3", "domain_family_1_3"} {
+			vr, err := ParseVersionRange(s)
+			require.NoError(t, err)
+			_, err = vr.Latest(r)
+			var target *VersionRangeEmptyError
+			require.ErrorAs(t, err, &target)
+			assert.Equal(t, s, target.Range)
+		}
+	})
+
+	t.Run("range does not exist", func(t *testing.T) {
+		t.Parallel()
+		for _, s := range []string{"domain_missing_1", "domain_family_4_0"} {
+			vr, err := ParseVersionRange(s)
+			require.NoError(t, err)
+			_, err = vr.Latest(r)
+			require.ErrorIs(t, err, os.ErrNotExist)
+		}
+	})
+}
+
+func TestRegistry_RangeDependents(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) *Registry {
+		t.Helper()
+		r := setupTestRegistry(t)
+		createSchemaFiles(t, r, schemaMap{
+			"domain_person_1_0_0": "{}",
+			"domain_person_1_1_0": "{}",
+			"domain_major_1_0_0":  `{"$ref": "{{ JSM "domain_person_1" }}"}`,
+			"domain_minor_1_0_0":  `{"$ref": "{{ JSM "domain_person_1_0" }}"}`,
+			"domain_pinned_1_0_0": `{"$ref": "{{ JSM "domain_person_1_1_0" }}"}`,
+		})
+		return r
+	}
+
+	t.Run("schemas referencing keys via a range", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t)
+
+		got, err := r.RangeDependents(context.Background(), r.config.ProductionEnvConfig(),
+			[]Key{"domain_person_1_1_0"})
+		require.NoError(t, err)
+		assert.Equal(t, []Key{"domain_major_1_0_0"}, got)
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t)
+
+		got, err := r.RangeDependents(context.Background(), r.config.ProductionEnvConfig(), nil)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("schema cannot be rendered", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t)
+		createSchemaFiles(t, r, schemaMap{"domain_bad_1_0_0": `{"$ref": "{{ JSM "domain_person_9" }}"}`})
+
+		_, err := r.RangeDependents(context.Background(), r.config.ProductionEnvConfig(),
+			[]Key{"domain_person_1_1_0"})
+		require.Error(t, err)
+	})
+}
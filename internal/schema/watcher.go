@@ -14,10 +14,13 @@ import (
 // WatchEvent describes a file change event in the registry.
 // Either the user changed a schema file, in which case we will run the
 // tests for that schema matching the test scope, or the user changed a test
-// document, in which case we will rerun the single test with its schema.
+// document, in which case we will rerun the single test with its schema, or
+// the user changed a template partial, in which case we will run the tests for
+// every schema which includes it.
 type WatchEvent struct {
 	Key      Key    // The Key of the schema that will be tested
 	TestPath string // If set, only this specific test document changed
+	Partial  string // If set, this template partial changed, and Key is not set
 }
 
 // eventWatcher is an interface that masks fsnotify.Watcher, allowing us to mock it in tests.
@@ -167,6 +170,14 @@ func (w *Watcher) mapToWatchEvent(path string) *WatchEvent {
 		return w.mapTestDocToWatchEvent(path)
 	}
 
+	if ext := filepath.Ext(path); ext == ".yml" || ext == ".yaml" {
+		return w.mapMigrationToWatchEvent(path)
+	}
+
+	if name, ok := w.registry.PartialNameFromPath(path); ok {
+		return &WatchEvent{Partial: name}
+	}
+
 	return nil
 }
 
@@ -177,10 +188,32 @@ func (w *Watcher) mapTestDocToWatchEvent(path string) *WatchEvent {
 		return nil
 	}
 
-	homeDir := filepath.Dir(dir)
+	key, ok := w.keyInHomeDir(filepath.Dir(dir))
+	if !ok {
+		return nil
+	}
+	return &WatchEvent{Key: key, TestPath: path}
+}
+
+// mapMigrationToWatchEvent maps a changed migration file to a full re-test of the schema it belongs to.
+func (w *Watcher) mapMigrationToWatchEvent(path string) *WatchEvent {
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) != MigrationsDir {
+		return nil
+	}
+
+	key, ok := w.keyInHomeDir(filepath.Dir(dir))
+	if !ok {
+		return nil
+	}
+	return &WatchEvent{Key: key}
+}
+
+// keyInHomeDir returns the key of the schema in the given schema home directory.
+func (w *Watcher) keyInHomeDir(homeDir string) (Key, bool) {
 	entries, err := os.ReadDir(homeDir)
 	if err != nil {
-		return nil
+		return "", false
 	}
 
 	for _, entry := range entries {
@@ -188,9 +221,9 @@ func (w *Watcher) mapTestDocToWatchEvent(path string) *WatchEvent {
 			schemaPath := filepath.Join(homeDir, entry.Name())
 			key, err := w.registry.KeyFromSchemaPath(schemaPath)
 			if err == nil {
-				return &WatchEvent{Key: key, TestPath: path}
+				return key, true
 			}
 		}
 	}
-	return nil
+	return "", false
 }
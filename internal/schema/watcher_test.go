@@ -219,6 +219,37 @@ func TestWatcher(t *testing.T) {
 		assert.Nil(t, w.mapTestDocToWatchEvent(testFile))
 	})
 
+	t.Run("mapToWatchEvent - migration files", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		w := NewWatcher(r, logger)
+		k := Key("domain_family_2_0_0")
+		createSchemaFiles(t, r, schemaMap{k: "{}"})
+
+		homeDir := New(k, r).Path(HomeDir)
+		migration := filepath.Join(homeDir, MigrationsDir, "from-1.yml")
+		assert.Equal(t, &WatchEvent{Key: k}, w.mapToWatchEvent(migration))
+
+		// YAML files outside a migrations directory are not relevant
+		assert.Nil(t, w.mapToWatchEvent(filepath.Join(homeDir, "other", "from-1.yml")))
+
+		// Migrations directory without a schema file
+		orphan := filepath.Join(r.RootDirectory(), "domain", "family", "3", "0", "0", MigrationsDir, "from-2.yml")
+		assert.Nil(t, w.mapToWatchEvent(orphan))
+	})
+
+	t.Run("mapToWatchEvent - partial files", func(t *testing.T) {
+		t.Parallel()
+		r := setupTestRegistry(t)
+		w := NewWatcher(r, logger)
+
+		partial := filepath.Join(r.RootDirectory(), PartialsDir, "money"+PartialSuffix)
+		assert.Equal(t, &WatchEvent{Partial: "money"}, w.mapToWatchEvent(partial))
+
+		// Partials are only read from the partials directory in the registry root
+		assert.Nil(t, w.mapToWatchEvent(filepath.Join(r.RootDirectory(), "domain", "money"+PartialSuffix)))
+	})
+
 	t.Run("addRecursive - walk error", func(t *testing.T) {
 		t.Parallel()
 		r := setupTestRegistry(t)
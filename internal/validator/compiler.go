@@ -46,6 +46,12 @@ type Compiler interface {
 	// SupportedSchemaVersions returns a slice of Draft representing the supported schema versions.
 	SupportedSchemaVersions() []Draft
 
+	// AddVendored registers a vendored copy of the schema at the given URL. A schema referenced by $ref
+	// which was not added with AddSchema is loaded from its vendored copy when a schema referencing it is
+	// compiled. No other schemas are loaded, so compiling never reads the network. Vendored copies are kept
+	// when the compiler is cleared.
+	AddVendored(url string, data JSONSchema)
+
 	// Clear resets the compiler state, removing all registered schemas.
 	Clear()
 }
@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
@@ -9,7 +10,9 @@ import (
 // NewSanthoshCompiler returns a concrete implementation of Compiler.
 // Using the santhosh-tekuri/jsonschema/v6 package.
 func NewSanthoshCompiler() Compiler {
-	return &santhoshCompiler{c: jsonschema.NewCompiler()}
+	s := &santhoshCompiler{vendored: make(vendoredLoader)}
+	s.c = s.newCompiler()
+	return s
 }
 
 // santhoshValidator wraps jsonschema.Schema to implement Validator.
@@ -24,8 +27,38 @@ func (sv *santhoshValidator) Validate(doc JSONDocument) error {
 
 // santhoshCompiler wraps jsonschema.Compiler to implement Compiler.
 type santhoshCompiler struct {
-	mu sync.Mutex
-	c  *jsonschema.Compiler
+	mu       sync.Mutex
+	c        *jsonschema.Compiler
+	vendored vendoredLoader
+}
+
+// newCompiler returns a jsonschema.Compiler which loads referenced schemas from their vendored copies only.
+func (s *santhoshCompiler) newCompiler() *jsonschema.Compiler {
+	c := jsonschema.NewCompiler()
+	c.UseLoader(s.vendored)
+	return c
+}
+
+// vendoredLoader is a jsonschema.URLLoader which loads schemas from their vendored copies, by URL.
+type vendoredLoader map[string]JSONSchema
+
+func (v vendoredLoader) Load(url string) (any, error) {
+	data, ok := v[url]
+	if !ok {
+		return nil, &NotVendoredError{URL: url}
+	}
+	return data, nil
+}
+
+// NotVendoredError is returned when a schema references a URL which has neither been added to the compiler
+// nor vendored.
+type NotVendoredError struct {
+	URL string
+}
+
+func (e *NotVendoredError) Error() string {
+	return fmt.Sprintf("%s is not a JSM schema and has not been vendored. Add it with: jsm vendor add %s <file>",
+		e.URL, e.URL)
 }
 
 func (s *santhoshCompiler) AddSchema(id string, schemaData JSONSchema) error {
@@ -54,8 +87,14 @@ func (s *santhoshCompiler) SupportedSchemaVersions() []Draft {
 	}
 }
 
+func (s *santhoshCompiler) AddVendored(url string, data JSONSchema) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vendored[url] = data
+}
+
 func (s *santhoshCompiler) Clear() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.c = jsonschema.NewCompiler()
+	s.c = s.newCompiler()
 }
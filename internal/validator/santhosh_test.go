@@ -70,6 +70,39 @@ func TestSanthoshCompiler_Compile(t *testing.T) {
 	})
 }
 
+func TestSanthoshCompiler_AddVendored(t *testing.T) {
+	t.Parallel()
+
+	const pointURL = "https://geojson.org/schema/Point.json"
+	referencing := map[string]interface{}{
+		"$id":  testSchemaID,
+		"$ref": pointURL,
+	}
+
+	t.Run("references are loaded from vendored copies", func(t *testing.T) {
+		t.Parallel()
+		c := NewSanthoshCompiler()
+		c.AddVendored(pointURL, map[string]interface{}{"$id": pointURL, "type": "object"})
+
+		// Vendored copies outlive the schemas added to the compiler.
+		c.Clear()
+		require.NoError(t, c.AddSchema(testSchemaID, referencing))
+		v, err := c.Compile(testSchemaID)
+		require.NoError(t, err)
+		require.NoError(t, v.Validate(map[string]interface{}{}))
+		require.Error(t, v.Validate("not an object"))
+	})
+
+	t.Run("references which are not vendored are not loaded", func(t *testing.T) {
+		t.Parallel()
+		c := NewSanthoshCompiler()
+		require.NoError(t, c.AddSchema(testSchemaID, referencing))
+		_, err := c.Compile(testSchemaID)
+		require.ErrorContains(t, err, (&NotVendoredError{URL: pointURL}).Error())
+		assert.Contains(t, err.Error(), "jsm vendor add "+pointURL+" <file>")
+	})
+}
+
 func TestSanthoshCompiler_SupportedSchemaVersions(t *testing.T) {
 	t.Parallel()
 	c := NewSanthoshCompiler()